@@ -0,0 +1,98 @@
+package analytics
+
+import "time"
+
+var now = time.Now
+
+// Period is how a report groups the driver's history
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Weekly  Period = "weekly"
+	Monthly Period = "monthly"
+)
+
+// Periods in the order the period selector shows them
+var Periods = []Period{Daily, Weekly, Monthly}
+
+// ParsePeriod reads a period from a request, defaulting to weekly
+func ParsePeriod(s string) Period {
+	for _, p := range Periods {
+		if Period(s) == p {
+			return p
+		}
+	}
+	return Weekly
+}
+
+func (p Period) Label() string {
+	switch p {
+	case Daily:
+		return "Daily"
+	case Monthly:
+		return "Monthly"
+	}
+	return "Weekly"
+}
+
+// span is how many buckets a report charts
+func (p Period) span() int {
+	switch p {
+	case Daily:
+		return 7
+	case Monthly:
+		return 6
+	}
+	return 8
+}
+
+// start returns the beginning of the bucket containing t; weeks start on Monday
+func (p Period) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch p {
+	case Daily:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+// next returns the beginning of the bucket after the one starting at t
+func (p Period) next(t time.Time) time.Time {
+	switch p {
+	case Daily:
+		return t.AddDate(0, 0, 1)
+	case Monthly:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 7)
+}
+
+func (p Period) label(t time.Time) string {
+	switch p {
+	case Daily:
+		return t.Format("Mon")
+	case Monthly:
+		return t.Format("Jan")
+	}
+	return t.Format("2 Jan")
+}
+
+// buckets returns the report's buckets, oldest first, ending with the current one
+func (p Period) buckets(at time.Time) []Bucket {
+	from := p.start(at)
+	for i := 1; i < p.span(); i++ {
+		from = p.start(from.Add(-time.Hour))
+	}
+
+	out := make([]Bucket, 0, p.span())
+	for len(out) < p.span() {
+		to := p.next(from)
+		out = append(out, Bucket{Label: p.label(from), From: from, To: to})
+		from = to
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuckets(t *testing.T) {
+	// a Wednesday afternoon
+	at := time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period    Period
+		wantCount int
+		wantFrom  time.Time
+		wantLast  time.Time
+		wantLabel string // of the current bucket
+	}{
+		{period: Daily, wantCount: 7, wantFrom: time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC), wantLast: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), wantLabel: "Wed"},
+		{period: Weekly, wantCount: 8, wantFrom: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), wantLast: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), wantLabel: "3 Mar"},
+		{period: Monthly, wantCount: 6, wantFrom: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), wantLast: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), wantLabel: "Mar"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			bs := tt.period.buckets(at)
+			if len(bs) != tt.wantCount {
+				t.Fatalf("%d buckets, want %d", len(bs), tt.wantCount)
+			}
+			last := bs[len(bs)-1]
+			if !bs[0].From.Equal(tt.wantFrom) || !last.From.Equal(tt.wantLast) || last.Label != tt.wantLabel {
+				t.Errorf("buckets run %s to %s (%q), want %s to %s (%q)", bs[0].From, last.From, last.Label, tt.wantFrom, tt.wantLast, tt.wantLabel)
+			}
+			if at.Before(last.From) || !at.Before(last.To) {
+				t.Errorf("current bucket %s-%s does not hold %s", last.From, last.To, at)
+			}
+			// buckets follow each other without gaps
+			for i := 1; i < len(bs); i++ {
+				if !bs[i].From.Equal(bs[i-1].To) {
+					t.Errorf("bucket %d starts %s, previous ends %s", i, bs[i].From, bs[i-1].To)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Period
+	}{
+		{in: "daily", want: Daily},
+		{in: "monthly", want: Monthly},
+		{in: "", want: Weekly},
+		{in: "yearly", want: Weekly},
+	}
+	for _, tt := range tests {
+		if got := ParsePeriod(tt.in); got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
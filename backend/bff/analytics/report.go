@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"backend/bff/expense"
+	"backend/bff/ledger"
+	"backend/bff/rating"
+	"backend/bff/routing"
+	"backend/bff/trip"
+	"math"
+	"time"
+)
+
+// Bucket is one bar of a report chart
+type Bucket struct {
+	Label    string        `json:"label"`
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Earnings ledger.Amount `json:"earnings"`
+	Km       float64       `json:"km"`
+	Trips    int           `json:"trips"`
+}
+
+// Report is a driver's earnings and performance over the charted buckets
+type Report struct {
+	DriverID      string        `json:"driverId"`
+	Period        Period        `json:"period"`
+	Buckets       []Bucket      `json:"buckets"`
+	Earnings      ledger.Amount `json:"earnings"`
+	Km            float64       `json:"km"`
+	Trips         int           `json:"trips"`
+	OnTime        int           `json:"onTime"`
+	Late          int           `json:"late"`
+	OnTimeRate    float64       `json:"onTimeRate"` // percent of deliveries with a promised time
+	Ratings       int           `json:"ratings"`
+	AvgRating     float64       `json:"avgRating"`
+	EarningsPerKm ledger.Amount `json:"earningsPerKm"`
+}
+
+// TripStats is how a single delivered trip went
+type TripStats struct {
+	TripID        string        `json:"tripId"`
+	Km            float64       `json:"km"`
+	Duration      time.Duration `json:"duration"` // leaving pickup to delivery
+	DeliveredAt   time.Time     `json:"deliveredAt"`
+	PromisedBy    time.Time     `json:"promisedBy,omitempty"`
+	OnTime        bool          `json:"onTime"`
+	Late          time.Duration `json:"late,omitempty"`
+	Earnings      ledger.Amount `json:"earnings"`
+	EarningsPerKm ledger.Amount `json:"earningsPerKm"`
+	FuelSpend     ledger.Amount `json:"fuelSpend"`
+	FuelPerKm     ledger.Amount `json:"fuelPerKm"`
+	Rating        float64       `json:"rating,omitempty"` // zero until the broker rates the driver
+}
+
+// Service aggregates a driver's trips and ledger into analytics
+type Service struct {
+	Trips    *trip.Store
+	Ledger   *ledger.Ledger
+	Routes   *routing.Engine
+	Expenses *expense.Service
+	Ratings  *rating.Store
+}
+
+// Default service used by the BFF handlers
+var Default = &Service{
+	Trips:    trip.Default,
+	Ledger:   ledger.Default,
+	Routes:   routing.Default,
+	Expenses: expense.Default,
+	Ratings:  rating.Default,
+}
+
+// Trip returns the stats of a delivered trip
+func (s *Service) Trip(t trip.Trip) (TripStats, bool) {
+	delivered, ok := t.Reached(trip.StatusDelivered)
+	if !ok {
+		return TripStats{}, false
+	}
+
+	st := TripStats{TripID: t.ID, DeliveredAt: delivered, PromisedBy: t.PromisedBy, OnTime: true}
+	if km, err := s.Routes.Provider.Distance(t.Pickup, t.Drop); err == nil {
+		st.Km = km
+	}
+	if left, ok := t.Reached(trip.StatusInTransit); ok {
+		st.Duration = delivered.Sub(left)
+	}
+	if !t.PromisedBy.IsZero() && delivered.After(t.PromisedBy) {
+		st.OnTime = false
+		st.Late = delivered.Sub(t.PromisedBy)
+	}
+	for _, e := range s.Ledger.Earnings(t.DriverID) {
+		if e.TripID == t.ID {
+			st.Earnings += e.Net(ledger.DriverWallet(t.DriverID), ledger.DriverPending(t.DriverID))
+		}
+	}
+	st.EarningsPerKm = perKm(st.Earnings, st.Km)
+	for _, x := range s.Expenses.ForTrip(t.ID) {
+		if x.Category == expense.CategoryFuel {
+			st.FuelSpend += x.Amount
+		}
+	}
+	st.FuelPerKm = perKm(st.FuelSpend, st.Km)
+	if r, ok := s.Ratings.Given(t.ID, t.BrokerID); ok && !r.Hidden {
+		st.Rating = r.Overall
+	}
+	return st, true
+}
+
+// Report aggregates the driver's history into the period's buckets
+func (s *Service) Report(driverID string, p Period) Report {
+	r := Report{DriverID: driverID, Period: p, Buckets: p.buckets(now())}
+	bucket := func(at time.Time) *Bucket {
+		for i := range r.Buckets {
+			if !at.Before(r.Buckets[i].From) && at.Before(r.Buckets[i].To) {
+				return &r.Buckets[i]
+			}
+		}
+		return nil
+	}
+
+	for _, e := range s.Ledger.Earnings(driverID) {
+		if b := bucket(e.At); b != nil {
+			b.Earnings += e.Net(ledger.DriverWallet(driverID), ledger.DriverPending(driverID))
+		}
+	}
+
+	var ratings float64
+	for _, t := range s.Trips.ForDriver(driverID) {
+		st, ok := s.Trip(t)
+		if !ok {
+			continue
+		}
+		b := bucket(st.DeliveredAt)
+		if b == nil {
+			continue
+		}
+		b.Km += st.Km
+		b.Trips++
+		if !t.PromisedBy.IsZero() {
+			if st.OnTime {
+				r.OnTime++
+			} else {
+				r.Late++
+			}
+		}
+		if st.Rating > 0 {
+			r.Ratings++
+			ratings += st.Rating
+		}
+	}
+
+	for _, b := range r.Buckets {
+		r.Earnings += b.Earnings
+		r.Km += b.Km
+		r.Trips += b.Trips
+	}
+	if r.OnTime+r.Late > 0 {
+		r.OnTimeRate = 100 * float64(r.OnTime) / float64(r.OnTime+r.Late)
+	}
+	if r.Ratings > 0 {
+		r.AvgRating = math.Round(10*ratings/float64(r.Ratings)) / 10
+	}
+	r.EarningsPerKm = perKm(r.Earnings, r.Km)
+	return r
+}
+
+func perKm(a ledger.Amount, km float64) ledger.Amount {
+	if km <= 0 {
+		return 0
+	}
+	return ledger.Amount(math.Round(float64(a) / km))
+}
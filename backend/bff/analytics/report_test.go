@@ -0,0 +1,136 @@
+package analytics
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/expense"
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"backend/bff/rating"
+	"backend/bff/routing"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	pickup = geo.Coord{Lat: 18, Lng: 73}
+	drop   = geo.Coord{Lat: 19, Lng: 73}
+)
+
+// delivered is a trip that left pickup and was delivered at the given times
+func delivered(id, driverID string, left, at, promisedBy time.Time) trip.Trip {
+	return trip.Trip{
+		ID:         id,
+		DriverID:   driverID,
+		BrokerID:   "BRK1",
+		Status:     trip.StatusDelivered,
+		Pickup:     pickup,
+		Drop:       drop,
+		PromisedBy: promisedBy,
+		History: []trip.Event{
+			{From: trip.StatusLoading, To: trip.StatusInTransit, At: left},
+			{From: trip.StatusInTransit, To: trip.StatusReachedDrop, At: at},
+			{From: trip.StatusReachedDrop, To: trip.StatusDelivered, At: at},
+		},
+	}
+}
+
+func newTestService(at time.Time) *Service {
+	trips := trip.NewStore()
+	trips.Add(delivered("TRK1", "DRV1", at.Add(-10*time.Hour), at.Add(-2*time.Hour), at.Add(-3*time.Hour)))
+	trips.Add(delivered("TRK2", "DRV1", at.Add(-5*time.Hour), at.Add(-time.Hour), at))
+	trips.Add(delivered("TRK3", "DRV1", at.AddDate(0, 0, -61), at.AddDate(0, 0, -60), time.Time{}))
+	trips.Add(delivered("TRK4", "DRV2", at.Add(-5*time.Hour), at.Add(-time.Hour), time.Time{}))
+	trips.Add(trip.Trip{ID: "TRK5", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusInTransit, Pickup: pickup, Drop: drop})
+
+	l := ledger.New()
+	for _, p := range []struct {
+		kind ledger.Kind
+		trip string
+		paid ledger.Amount
+		at   time.Time
+	}{
+		{ledger.KindAdvance, "TRK1", ledger.Rupees(5000), at.Add(-12 * time.Hour)},
+		{ledger.KindBalance, "TRK1", ledger.Rupees(20000), at.Add(-time.Hour)},
+		{ledger.KindBalance, "TRK3", ledger.Rupees(15000), at.AddDate(0, 0, -60)},
+	} {
+		l.Record(p.kind, ledger.Posting{DriverID: "DRV1", BrokerID: "BRK1", TripID: p.trip, Amount: p.paid, At: p.at})
+	}
+
+	return &Service{
+		Trips:    trips,
+		Ledger:   l,
+		Routes:   &routing.Engine{Provider: routing.GreatCircle{RoadFactor: 1}},
+		Expenses: expense.NewService(trips, l, docstore.NewMemory(), tracking.NewStore()),
+		Ratings:  rating.NewStore(trips),
+	}
+}
+
+func TestTripStats(t *testing.T) {
+	at := time.Now()
+	s := newTestService(at)
+	if _, err := s.Expenses.Add("DRV1", "TRK1", expense.AddRequest{Category: expense.CategoryFuel, Amount: ledger.Rupees(3000)}); err != nil {
+		t.Fatal(err)
+	}
+	km := geo.Haversine(pickup, drop)
+
+	tr, _ := s.Trips.Get("TRK1")
+	st, ok := s.Trip(tr)
+	if !ok {
+		t.Fatal("delivered trip has no stats")
+	}
+	if st.OnTime || st.Late != time.Hour || st.Duration != 8*time.Hour {
+		t.Errorf("on time %v, late %s after %s driving; want late by 1h after 8h", st.OnTime, st.Late, st.Duration)
+	}
+	if st.Earnings != ledger.Rupees(25000) || st.EarningsPerKm != ledger.Amount(math.Round(float64(ledger.Rupees(25000))/km)) {
+		t.Errorf("earned %s at %s/km", st.Earnings, st.EarningsPerKm)
+	}
+	if st.FuelSpend != ledger.Rupees(3000) || st.FuelPerKm != ledger.Amount(math.Round(float64(ledger.Rupees(3000))/km)) {
+		t.Errorf("fuel %s at %s/km", st.FuelSpend, st.FuelPerKm)
+	}
+
+	running, _ := s.Trips.Get("TRK5")
+	if _, ok := s.Trip(running); ok {
+		t.Error("a trip still on the road has stats")
+	}
+}
+
+func TestReport(t *testing.T) {
+	at := time.Now()
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService(at)
+	scores := map[rating.Criterion]int{}
+	for _, c := range rating.Criteria(rating.RoleBroker) {
+		scores[c] = 4
+	}
+	if _, err := s.Ratings.Submit("TRK2", "BRK1", scores, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.Report("DRV1", Daily)
+	km := 2 * geo.Haversine(pickup, drop)
+	// the trip and payment from two months ago fall outside the chart
+	if r.Trips != 2 || math.Abs(r.Km-km) > 0.01 || r.Earnings != ledger.Rupees(25000) {
+		t.Errorf("%d trips, %.1f km, %s earned; want 2, %.1f km, ₹25,000", r.Trips, r.Km, r.Earnings, km)
+	}
+	if r.OnTime != 1 || r.Late != 1 || r.OnTimeRate != 50 {
+		t.Errorf("%d on time, %d late (%.0f%%); want 1, 1 (50%%)", r.OnTime, r.Late, r.OnTimeRate)
+	}
+	if r.Ratings != 1 || r.AvgRating != 4 {
+		t.Errorf("%d ratings averaging %.1f, want 1 averaging 4", r.Ratings, r.AvgRating)
+	}
+
+	var trips int
+	var earned ledger.Amount
+	for _, b := range r.Buckets {
+		trips += b.Trips
+		earned += b.Earnings
+	}
+	if trips != r.Trips || earned != r.Earnings {
+		t.Errorf("buckets add up to %d trips and %s, report says %d and %s", trips, earned, r.Trips, r.Earnings)
+	}
+}
@@ -0,0 +1,229 @@
+// Package audit keeps an append-only record of every state-changing BFF
+// request: who did it, in which role and organization, what they touched,
+// how it changed, and from where. Entries are hash chained so a record that
+// is edited, reordered or removed after the fact breaks the chain. The chain
+// can't show entries cut from its end; that is caught against a head
+// recorded earlier, outside the log.
+package audit
+
+import (
+	"backend/bff/calls"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotFound = errors.New("audit entry not found")
+	ErrTampered = errors.New("audit log has been tampered with")
+)
+
+// Outcome is how the request ended as the app saw it
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeError   Outcome = "error"
+)
+
+// Entry is one state-changing request
+type Entry struct {
+	Seq       int             `json:"seq"`
+	ID        string          `json:"id"`
+	At        time.Time       `json:"at"`
+	RequestID string          `json:"requestId"`
+	Actor     string          `json:"actor"`
+	Role      string          `json:"role"`
+	OrgID     string          `json:"orgId,omitempty"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Action    string          `json:"action,omitempty"`
+	Target    Target          `json:"target"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Changes   []Change        `json:"changes,omitempty"`
+	IP        string          `json:"ip"`
+	Status    int             `json:"status"`
+	Outcome   Outcome         `json:"outcome"`
+	Message   string          `json:"message,omitempty"`
+	PrevHash  string          `json:"prevHash"`
+	Hash      string          `json:"hash"`
+}
+
+// Target is the entity a request acted on, e.g. {trip TRK789012}
+type Target struct {
+	Type string `json:"type,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Change is one field that differs between the before and after snapshots;
+// nested fields are dotted, list items indexed, e.g. "timeline.3.status"
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Filter narrows a query; zero fields match everything
+type Filter struct {
+	Actor     string
+	OrgID     string
+	Action    string
+	Target    Target
+	RequestID string
+	Outcome   Outcome
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (f Filter) match(e *Entry) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor,
+		f.OrgID != "" && e.OrgID != f.OrgID,
+		f.Action != "" && e.Action != f.Action,
+		f.Target.Type != "" && e.Target.Type != f.Target.Type,
+		f.Target.ID != "" && e.Target.ID != f.Target.ID,
+		f.RequestID != "" && e.RequestID != f.RequestID,
+		f.Outcome != "" && e.Outcome != f.Outcome,
+		!f.Since.IsZero() && e.At.Before(f.Since),
+		!f.Until.IsZero() && !e.At.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// digest hashes the entry with everything but its own hash, which chains it
+// to the entry before through PrevHash
+func (e Entry) digest() string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (e Entry) clone() Entry {
+	e.Before = append(json.RawMessage(nil), e.Before...)
+	e.After = append(json.RawMessage(nil), e.After...)
+	e.Changes = append([]Change(nil), e.Changes...)
+	return e
+}
+
+// Snapshot encodes an entity as it is stored in an entry, personal details
+// redacted
+func Snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	return Redact(b)
+}
+
+// Redact masks the personal details in an encoded entity, at any depth:
+// phone numbers keep their last three digits so a changed number still shows
+// as a change, and addresses are dropped. The log is kept for years and read
+// by support staff, who need to see what changed, not who lives where.
+func Redact(raw json.RawMessage) json.RawMessage {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, x := range v {
+				s, isString := x.(string)
+				name := strings.ToLower(k)
+				switch {
+				case isString && strings.HasSuffix(name, "phone"):
+					v[k] = calls.Redact(s)
+				case isString && strings.HasSuffix(name, "address"):
+					v[k] = redacted
+				default:
+					v[k] = walk(x)
+				}
+			}
+		case []interface{}:
+			for i, x := range v {
+				v[i] = walk(x)
+			}
+		}
+		return v
+	}
+	b, err := json.Marshal(walk(v))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// redacted stands in for a value left out of the log
+const redacted = "[redacted]"
+
+// Diff lists the fields that differ between two snapshots
+func Diff(before, after json.RawMessage) []Change {
+	b, a := flatten(before), flatten(after)
+	var out []Change
+	for k, v := range b {
+		if w, ok := a[k]; !ok || !equal(v, w) {
+			out = append(out, Change{Field: k, Before: v, After: a[k]})
+		}
+	}
+	for k, w := range a {
+		if _, ok := b[k]; !ok {
+			out = append(out, Change{Field: k, After: w})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+func flatten(raw json.RawMessage) map[string]interface{} {
+	out := map[string]interface{}{}
+	if len(raw) == 0 {
+		return out
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return out
+	}
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		join := func(k string) string {
+			if prefix == "" {
+				return k
+			}
+			return prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, x := range v {
+				walk(join(k), x)
+			}
+		case []interface{}:
+			for i, x := range v {
+				walk(join(strconv.Itoa(i)), x)
+			}
+		default:
+			out[prefix] = v
+		}
+	}
+	walk("", v)
+	return out
+}
+
+func equal(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
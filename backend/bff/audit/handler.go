@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// Query lets support staff search the log by actor, orgId, action,
+// targetType, targetId, requestId, outcome and an RFC 3339 since/until
+// window, newest first; limit defaults to 100
+func Query(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	f := Filter{
+		Actor:     c.Query("actor"),
+		OrgID:     c.Query("orgId"),
+		Action:    c.Query("action"),
+		Target:    Target{Type: c.Query("targetType"), ID: c.Query("targetId")},
+		RequestID: c.Query("requestId"),
+		Outcome:   Outcome(c.Query("outcome")),
+		Limit:     100,
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(400, bff.ActionResponse{Status: "error", Message: "since must be an RFC 3339 time"})
+			return
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if f.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(400, bff.ActionResponse{Status: "error", Message: "until must be an RFC 3339 time"})
+			return
+		}
+	}
+	if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+		f.Limit = n
+	}
+
+	entries := Default.Query(f)
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data: gin.H{
+			"entries": entries,
+			"count":   len(entries),
+		},
+	})
+}
+
+// Get returns one entry by ID
+func Get(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	e, err := Default.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	c.JSON(200, bff.ActionResponse{Status: "success", Data: gin.H{"entry": e}})
+}
+
+// Verify checks the hash chain end to end. Passing the head an earlier
+// verify returned also checks that no entries were cut from the end.
+func Verify(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	n, err := Default.Verify()
+	if head := c.Query("head"); err == nil && head != "" {
+		err = Default.Anchored(head)
+	}
+	if err != nil {
+		c.JSON(409, bff.ActionResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    gin.H{"valid": false, "verified": n},
+		})
+		return
+	}
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"valid": true, "verified": n, "head": Default.Head()},
+	})
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Log is append only: entries can be added and read but never changed or
+// removed, and each carries the hash of the one before it.
+type Log struct {
+	mu      sync.Mutex
+	entries []*Entry
+	byID    map[string]*Entry
+}
+
+func NewLog() *Log {
+	return &Log{byID: map[string]*Entry{}}
+}
+
+// Default log the middleware writes to
+var Default = NewLog()
+
+// Append stamps the entry, chains it to the last one and stores it
+func (l *Log) Append(e Entry) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = len(l.entries) + 1
+	e.ID = fmt.Sprintf("AUD%07d", e.Seq)
+	if e.At.IsZero() {
+		e.At = now()
+	}
+	e.PrevHash = l.head()
+	e.Hash = e.digest()
+
+	stored := e.clone()
+	l.entries = append(l.entries, &stored)
+	l.byID[e.ID] = &stored
+	return e.clone()
+}
+
+func (l *Log) Get(id string) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.byID[id]
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	return e.clone(), nil
+}
+
+// Query returns matching entries, newest first
+func (l *Log) Query(f Filter) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out []Entry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+		if e := l.entries[i]; f.match(e) {
+			out = append(out, e.clone())
+		}
+	}
+	return out
+}
+
+// Verify walks the chain from the first entry and reports the first one
+// whose contents or link no longer match its hash
+func (l *Log) Verify() (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev := ""
+	for i, e := range l.entries {
+		if e.Seq != i+1 {
+			return i, fmt.Errorf("%w: entry %d is out of sequence", ErrTampered, i+1)
+		}
+		if e.PrevHash != prev || e.digest() != e.Hash {
+			return i, fmt.Errorf("%w: entry %s does not match its hash", ErrTampered, e.ID)
+		}
+		prev = e.Hash
+	}
+	return len(l.entries), nil
+}
+
+// Anchored checks that the entry whose hash is head, recorded from an
+// earlier Head, is still in the log. The chain alone can't show that the
+// entries after it were cut.
+func (l *Log) Anchored(head string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, e := range l.entries {
+		if e.Hash == head {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the entry with head %s is missing", ErrTampered, head)
+}
+
+// Head is the hash of the latest entry, which commits to the whole log
+func (l *Log) Head() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.head()
+}
+
+func (l *Log) head() string {
+	if len(l.entries) == 0 {
+		return ""
+	}
+	return l.entries[len(l.entries)-1].Hash
+}
@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func testLog() *Log {
+	l := NewLog()
+	for _, actor := range []string{"BRK001", "DRV001", "BRK004"} {
+		l.Append(Entry{Actor: actor, Method: "POST", Path: "/bff/broker/money/action", Outcome: OutcomeSuccess})
+	}
+	return l
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func(l *Log)
+		wantErr      error
+		wantVerified int
+	}{
+		{
+			name:         "untouched",
+			tamper:       func(l *Log) {},
+			wantVerified: 3,
+		},
+		{
+			name:         "edited entry",
+			tamper:       func(l *Log) { l.entries[1].Actor = "BRK999" },
+			wantErr:      ErrTampered,
+			wantVerified: 1,
+		},
+		{
+			name: "edited entry with its hash recomputed",
+			tamper: func(l *Log) {
+				l.entries[1].Outcome = OutcomeError
+				l.entries[1].Hash = l.entries[1].digest()
+			},
+			wantErr:      ErrTampered,
+			wantVerified: 2,
+		},
+		{
+			name:         "removed entry",
+			tamper:       func(l *Log) { l.entries = append(l.entries[:1], l.entries[2:]...) },
+			wantErr:      ErrTampered,
+			wantVerified: 1,
+		},
+		{
+			name:         "reordered entries",
+			tamper:       func(l *Log) { l.entries[1], l.entries[2] = l.entries[2], l.entries[1] },
+			wantErr:      ErrTampered,
+			wantVerified: 1,
+		},
+		{
+			name:         "edited first entry",
+			tamper:       func(l *Log) { l.entries[0].IP = "10.0.0.1" },
+			wantErr:      ErrTampered,
+			wantVerified: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := testLog()
+			tt.tamper(l)
+
+			n, err := l.Verify()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if n != tt.wantVerified {
+				t.Errorf("verified %d entries, want %d", n, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestAppendChains(t *testing.T) {
+	l := testLog()
+	entries := l.Query(Filter{})
+	if len(entries) != 3 {
+		t.Fatalf("%d entries, want 3", len(entries))
+	}
+	// newest first
+	for i := 0; i < len(entries)-1; i++ {
+		if entries[i].PrevHash != entries[i+1].Hash {
+			t.Errorf("%s does not link to %s", entries[i].ID, entries[i+1].ID)
+		}
+	}
+	if entries[2].PrevHash != "" {
+		t.Errorf("first entry links to %q, want nothing", entries[2].PrevHash)
+	}
+	if l.Head() != entries[0].Hash {
+		t.Errorf("head = %s, want the latest hash %s", l.Head(), entries[0].Hash)
+	}
+
+	// entries handed out are copies; changing one leaves the log intact
+	e, _ := l.Get(entries[1].ID)
+	e.Actor = "BRK999"
+	if _, err := l.Verify(); err != nil {
+		t.Errorf("changing a returned entry broke the log: %v", err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "phone keeps its last digits",
+			in:   `{"driverPhone":"+91 98765 43210"}`,
+			want: `{"driverPhone":"+91 XXXXX XX210"}`,
+		},
+		{
+			name: "address is dropped",
+			in:   `{"senderAddress":"Plot 12, MIDC Bhiwandi"}`,
+			want: `{"senderAddress":"[redacted]"}`,
+		},
+		{
+			name: "nested in details and lists",
+			in:   `{"details":{"receiverPhone":"9812345678","cargo":"Steel"},"members":[{"phone":"+919820011223"}]}`,
+			want: `{"details":{"cargo":"Steel","receiverPhone":"XXXXX XX678"},"members":[{"phone":"+91 XXXXX XX223"}]}`,
+		},
+		{
+			name: "other fields are left alone",
+			in:   `{"id":"TRK1","amount":4500,"phoneVerified":true}`,
+			want: `{"amount":4500,"id":"TRK1","phoneVerified":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact(json.RawMessage(tt.in))
+			if string(got) != tt.want {
+				t.Errorf("Redact(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnchored(t *testing.T) {
+	l := testLog()
+	head := l.Head()
+	if err := l.Anchored(head); err != nil {
+		t.Fatalf("untouched log: %v", err)
+	}
+
+	l.Append(Entry{Actor: "BRK001", Method: "POST", Path: "/bff/broker/load/action", Outcome: OutcomeSuccess})
+	if err := l.Anchored(head); err != nil {
+		t.Errorf("log grown past the head: %v", err)
+	}
+
+	// cutting the latest entries leaves a chain that still verifies
+	l.entries = l.entries[:2]
+	if _, err := l.Verify(); err != nil {
+		t.Fatalf("truncated chain: %v", err)
+	}
+	if err := l.Anchored(head); !errors.Is(err, ErrTampered) {
+		t.Errorf("error = %v, want %v", err, ErrTampered)
+	}
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader carries the request ID in both directions; clients may
+// send their own so app logs and the audit log line up
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware records every request that can change state (anything but
+// GET, HEAD and OPTIONS) in the log, whether it succeeded or not. Paths in
+// skip, such as high-rate telemetry, are not recorded.
+func Middleware(l *Log, skip ...string) gin.HandlerFunc {
+	skipped := map[string]bool{}
+	for _, p := range skip {
+		skipped[p] = true
+	}
+
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Set("requestId", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if skipped[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// read the body for the action and its fields, then put it back for
+		// the handler
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		var req struct {
+			Action string                 `json:"action"`
+			Data   map[string]interface{} `json:"data"`
+		}
+		json.Unmarshal(body, &req)
+
+		path := c.Request.URL.Path
+		actorID, role, orgID := actor(path, c.Query)
+		field := func(k string) string {
+			if v := c.Param(k); v != "" {
+				return v
+			}
+			v, _ := req.Data[k].(string)
+			return v
+		}
+		t, snapshot := target(path, actorID, req.Action, field, c.Query)
+
+		var before json.RawMessage
+		if snapshot != nil {
+			before = Snapshot(snapshot())
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		var res struct {
+			Status  string          `json:"status"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}
+		json.Unmarshal(w.body.Bytes(), &res)
+
+		var after json.RawMessage
+		if snapshot != nil {
+			after = Snapshot(snapshot())
+		} else if len(res.Data) > 0 && res.Status == string(OutcomeSuccess) {
+			// nothing named up front, so the request created something;
+			// what it returned is the new state
+			after = Redact(res.Data)
+			var created struct {
+				ID string `json:"id"`
+			}
+			json.Unmarshal(res.Data, &created)
+			if t.ID == "" {
+				t = Target{Type: routeEntity(path), ID: created.ID}
+			}
+		}
+
+		outcome := OutcomeSuccess
+		if w.Status() >= 400 || res.Status == string(OutcomeError) {
+			outcome = OutcomeError
+		}
+		l.Append(Entry{
+			RequestID: requestID,
+			Actor:     actorID,
+			Role:      role,
+			OrgID:     orgID,
+			Method:    c.Request.Method,
+			Path:      path,
+			Action:    req.Action,
+			Target:    t,
+			Before:    before,
+			After:     after,
+			Changes:   Diff(before, after),
+			IP:        c.ClientIP(),
+			Status:    w.Status(),
+			Outcome:   outcome,
+			Message:   res.Message,
+		})
+	}
+}
+
+// RequestID returns the ID the middleware gave the request
+func RequestID(c *gin.Context) string {
+	return c.GetString("requestId")
+}
+
+// recorder keeps a copy of the response body for the log
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// routeEntity names what a route acts on from its path, e.g. "truck" for
+// /bff/broker/truck/action
+func routeEntity(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if p := parts[i]; p != "action" && p != "bff" {
+			return p
+		}
+	}
+	return ""
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "REQ-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"backend/bff/load"
+	"backend/bff/org"
+	"backend/bff/settlement"
+	"backend/bff/trip"
+	"backend/bff/vehicle"
+	"strings"
+)
+
+// resolver loads the current state of the entity a request names
+type resolver struct {
+	Type  string
+	Field string // request field or route param carrying the ID
+	Load  func(id string) (interface{}, error)
+}
+
+// resolvers are tried in order; the first whose field the request carries
+// names the target. Route params and body fields are tried before the query.
+var resolvers = []resolver{
+	{Type: "payment", Field: "paymentId", Load: func(id string) (interface{}, error) { return settlement.Default.Get(id) }},
+	{Type: "load", Field: "loadId", Load: func(id string) (interface{}, error) { return load.Default.Get(id) }},
+	{Type: "vehicle", Field: "truckNumber", Load: func(id string) (interface{}, error) { return vehicle.Default.Get(id) }},
+	{Type: "member", Field: "userId", Load: func(id string) (interface{}, error) {
+		_, m, err := org.Default.Of(id)
+		return m, err
+	}},
+	{Type: "invite", Field: "inviteId"},
+	{Type: "review", Field: "reviewId"},
+	{Type: "trip", Field: "tripId", Load: func(id string) (interface{}, error) { return trip.Default.Get(id) }},
+}
+
+// identityParams are the query params a caller names themselves with; they
+// pick the actor, never the target
+var identityParams = []string{"userId", "brokerId", "driverId"}
+
+// driverVehicleActions act on the driver's truck rather than their trip
+var driverVehicleActions = map[string]bool{
+	"UPDATE_ODOMETER": true,
+	"LOG_FUEL":        true,
+	"LOG_SERVICE":     true,
+}
+
+// actor works out who made the request the same way the handlers do: an
+// explicit user, broker or driver ID, or the demo account for the app
+func actor(path string, query func(string) string) (id, role, orgID string) {
+	for _, k := range identityParams {
+		if id = query(k); id != "" {
+			break
+		}
+	}
+	switch {
+	case strings.HasPrefix(path, "/bff/broker/"):
+		role = "broker"
+		if id == "" {
+			id = trip.DemoBrokerID
+		}
+	case strings.HasPrefix(path, "/bff/driver/"):
+		role = "driver"
+		if id == "" {
+			id = trip.DemoDriverID
+		}
+	case strings.HasPrefix(path, "/bff/payments/"):
+		id, role = "payment-provider", "system"
+	default:
+		role = "user"
+	}
+	if o, m, err := org.Default.Of(id); err == nil {
+		role, orgID = strings.Join(roleNames(m.Roles), ","), o.ID
+	}
+	return id, role, orgID
+}
+
+func roleNames(rs []org.Role) []string {
+	out := make([]string, len(rs))
+	for i, r := range rs {
+		out[i] = string(r)
+	}
+	return out
+}
+
+// target finds the entity the request acts on and a way to snapshot it,
+// from the route params and body first and the query after. Driver actions
+// that name nothing act on the driver's active trip or truck.
+func target(path, actorID, action string, field, query func(string) string) (Target, func() interface{}) {
+	for _, r := range resolvers {
+		if id := field(r.Field); id != "" {
+			return Target{Type: r.Type, ID: id}, snapshotter(r, id)
+		}
+	}
+	for _, r := range resolvers {
+		if identity(r.Field) {
+			continue
+		}
+		if id := query(r.Field); id != "" {
+			return Target{Type: r.Type, ID: id}, snapshotter(r, id)
+		}
+	}
+	if !strings.HasPrefix(path, "/bff/driver/") {
+		return Target{}, nil
+	}
+	if driverVehicleActions[action] {
+		v, err := vehicle.Default.ForDriver(actorID)
+		if err != nil {
+			return Target{}, nil
+		}
+		return Target{Type: "vehicle", ID: v.Number}, snapshotter(resolverFor("vehicle"), v.Number)
+	}
+	t, err := trip.Default.ActiveForDriver(actorID)
+	if err != nil {
+		return Target{}, nil
+	}
+	return Target{Type: "trip", ID: t.ID}, snapshotter(resolverFor("trip"), t.ID)
+}
+
+func identity(param string) bool {
+	for _, k := range identityParams {
+		if k == param {
+			return true
+		}
+	}
+	return false
+}
+
+func resolverFor(typ string) resolver {
+	for _, r := range resolvers {
+		if r.Type == typ {
+			return r
+		}
+	}
+	return resolver{Type: typ}
+}
+
+func snapshotter(r resolver, id string) func() interface{} {
+	if r.Load == nil {
+		return nil
+	}
+	return func() interface{} {
+		v, err := r.Load(id)
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+}
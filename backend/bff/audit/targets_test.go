@@ -0,0 +1,65 @@
+package audit
+
+import "testing"
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		action string
+		field  map[string]string // route params and body fields
+		query  map[string]string
+		want   Target
+	}{
+		{
+			name:  "chat names the trip in its route",
+			path:  "/bff/chat/TRK789012/action",
+			field: map[string]string{"tripId": "TRK789012"},
+			query: map[string]string{"userId": "BRK001"},
+			want:  Target{Type: "trip", ID: "TRK789012"},
+		},
+		{
+			name:  "rating names the trip in its body",
+			path:  "/bff/ratings/action",
+			field: map[string]string{"tripId": "TRK789012"},
+			query: map[string]string{"userId": "DRV001"},
+			want:  Target{Type: "trip", ID: "TRK789012"},
+		},
+		{
+			name:  "the caller is never the target",
+			path:  "/bff/notifications/action",
+			query: map[string]string{"userId": "BRK001"},
+		},
+		{
+			name:  "team action names a member in its body",
+			path:  "/bff/broker/team/action",
+			field: map[string]string{"userId": "BRK004"},
+			query: map[string]string{"brokerId": "BRK001"},
+			want:  Target{Type: "member", ID: "BRK004"},
+		},
+		{
+			name:  "body before query",
+			path:  "/bff/broker/money/action",
+			field: map[string]string{"paymentId": "PAY1"},
+			query: map[string]string{"loadId": "LD1"},
+			want:  Target{Type: "payment", ID: "PAY1"},
+		},
+		{
+			name:  "query when nothing else names one",
+			path:  "/bff/broker/load/action",
+			query: map[string]string{"loadId": "LD1", "brokerId": "BRK001"},
+			want:  Target{Type: "load", ID: "LD1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := func(m map[string]string) func(string) string {
+				return func(k string) string { return m[k] }
+			}
+			got, _ := target(tt.path, "BRK001", tt.action, get(tt.field), get(tt.query))
+			if got != tt.want {
+				t.Errorf("target = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package broker
+
+import "backend/bff"
+
+// actionError is the response an action handler gives when err stops the action
+func actionError(err error) bff.ActionResponse {
+	return bff.ActionResponse{
+		Status:  "error",
+		Message: err.Error(),
+	}
+}
@@ -92,8 +92,13 @@ func AddTruckScreen(c *gin.Context) {
 								Data: bff.ButtonData{
 									Text: "Add Truck",
 									Action: bff.ActionData{
-										Type: "MODAL",
-										Value: "SUCCESS_MODAL",
+										Type:   "action",
+										Value:  "addTruck",
+										Url:    "/bff/broker/truck/action",
+										Method: "POST",
+										Data: map[string]interface{}{
+											"fields": []string{"truckNumber", "truckType", "capacity", "model"},
+										},
 									},
 									Style: bff.ViewData{
 										BackgroundColor: "#ff0000",
@@ -0,0 +1,417 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/calls"
+	"backend/bff/chat"
+	"backend/bff/docstore"
+	"backend/bff/ewaybill"
+	"backend/bff/trip"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+// ChatScreen shows one trip's conversation, or the broker's chat list when
+// no tripId is given
+func ChatScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	broker := brokerID(c)
+	tripID := c.Query("tripId")
+	if tripID == "" {
+		chatListScreen(c, broker)
+		return
+	}
+
+	t, err := chat.Default.Conversation(tripID, broker)
+	if err != nil {
+		c.JSON(404, bff.ScreenResponse{
+			Status:  "error",
+			Screen:  "chat",
+			Message: "Chat not found",
+		})
+		return
+	}
+
+	t.Details = calls.Default.Mask(t, broker, t.Details)
+
+	// opening the chat reads everything on screen
+	chat.Default.MarkRead(tripID, broker, "")
+	page, _ := chat.Default.History(tripID, broker, "", "", 0)
+
+	base := "/bff/chat/" + tripID
+	actionURL := base + "/action?userId=" + broker
+
+	var bubbles []bff.UISnippet
+	if page.HasMore && len(page.Messages) > 0 {
+		bubbles = append(bubbles, bff.UISnippet{
+			Type: "TouchableOpacity",
+			Data: bff.TouchableOpacityData{
+				Style: bff.ViewData{AlignSelf: "center", PaddingVertical: 6},
+				OnPress: bff.ActionData{
+					Type:  "action",
+					Value: "history",
+					Url:   actionURL,
+					Data:  map[string]interface{}{"before": page.Messages[0].ID},
+				},
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "Text",
+					Data: bff.TextData{Text: "Load earlier messages", FontSize: 13, Color: "#ff0000"},
+				},
+			},
+		})
+	}
+	for _, m := range page.Messages {
+		bubbles = append(bubbles, createChatBubble(m, broker))
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "View",
+			Data: bff.ViewData{
+				Flex:            1,
+				BackgroundColor: "#FFFFFF",
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "StatusBar",
+					Data: bff.StatusBarData{
+						BackgroundColor: "#FFFFFF",
+						Style:           "dark",
+					},
+				},
+				// Header
+				{
+					Type: "View",
+					Data: bff.ViewData{
+						FlexDirection:     "row",
+						AlignItems:        "center",
+						PaddingHorizontal: 20,
+						PaddingVertical:   14,
+						BorderBottomWidth: 1,
+						BorderColor:       "#F0F0F0",
+						Gap:               12,
+					},
+					Children: []bff.UISnippet{
+						{
+							Type: "TouchableOpacity",
+							Data: bff.TouchableOpacityData{
+								OnPress: bff.ActionData{Type: "navigate", To: "back"},
+							},
+							Children: []bff.UISnippet{
+								{
+									Type: "Icon",
+									Data: bff.IconData{Name: "arrow-back", Size: 24, Color: "#1A1A1A"},
+								},
+							},
+						},
+						{
+							Type: "View",
+							Data: bff.ViewData{Flex: 1},
+							Children: []bff.UISnippet{
+								{
+									Type: "Text",
+									Data: bff.TextData{Text: t.Details["driverName"], FontSize: 18, FontWeight: "bold", Color: "#1A1A1A"},
+								},
+								{
+									Type: "Text",
+									Data: bff.TextData{
+										Text:     fmt.Sprintf("%s · %s → %s", t.Details["vehicleNumber"], t.Details["originCity"], t.Details["destinationCity"]),
+										FontSize: 12,
+										Color:    "#666",
+									},
+								},
+							},
+						},
+						{
+							Type: "TouchableOpacity",
+							Data: bff.TouchableOpacityData{
+								OnPress: bff.ActionData{
+									Type: "call",
+									Data: map[string]interface{}{"phone": t.Details["driverPhone"]},
+								},
+							},
+							Children: []bff.UISnippet{
+								{
+									Type: "Icon",
+									Data: bff.IconData{Name: "call", Size: 22, Color: "#ff0000"},
+								},
+							},
+						},
+					},
+				},
+				// Messages
+				{
+					Type:     "ScrollView",
+					Data:     bff.ViewData{Flex: 1, Padding: 16, Gap: 8, BackgroundColor: "#FAFAFA"},
+					Children: bubbles,
+				},
+				// Composer
+				{
+					Type: "View",
+					Data: bff.ViewData{
+						FlexDirection:  "row",
+						AlignItems:     "center",
+						Padding:        10,
+						Gap:            8,
+						BorderTopWidth: 1,
+						BorderColor:    "#F0F0F0",
+					},
+					Children: []bff.UISnippet{
+						createComposerButton("image-outline", actionURL, chat.KindImage),
+						{
+							Type: "INPUT",
+							Data: bff.InputData{
+								Id:          "text",
+								Placeholder: "Message " + t.Details["driverName"],
+								MaxLength:   2000,
+								FontSize:    15,
+								Style: bff.ViewData{
+									Flex:              1,
+									PaddingHorizontal: 14,
+									PaddingVertical:   10,
+									BorderRadius:      20,
+									BorderWidth:       1,
+									BorderColor:       "#F0F0F0",
+								},
+							},
+						},
+						createComposerButton("send", actionURL, chat.KindText),
+					},
+				},
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "chat",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"tripId":     tripID,
+			"socketUrl":  base + "/ws?userId=" + broker,
+			"historyUrl": base + "/messages?userId=" + broker,
+			"actionUrl":  actionURL,
+			"messages":   page.Messages,
+			"hasMore":    page.HasMore,
+		},
+	}
+
+	c.JSON(200, response)
+}
+
+// chatListScreen lists the broker's conversations with unread counts
+func chatListScreen(c *gin.Context, broker string) {
+	conversations := chat.Default.Conversations(broker)
+
+	var rows []bff.UISnippet
+	for _, s := range conversations {
+		if t, err := trip.Default.Get(s.TripID); err == nil {
+			rows = append(rows, createChatRow(t, s))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: "No conversations yet", FontSize: 14, Color: "#999", TextAlign: "center"},
+		})
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "View",
+			Data: bff.ViewData{
+				Flex:            1,
+				BackgroundColor: "#FFFFFF",
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "Text",
+					Data: bff.TextData{
+						Text:              fmt.Sprintf("Messages (%d)", chat.Default.TotalUnread(broker)),
+						FontSize:          22,
+						FontWeight:        "bold",
+						Color:             "#1A1A1A",
+						PaddingHorizontal: 20,
+						PaddingVertical:   16,
+					},
+				},
+				{
+					Type:     "ScrollView",
+					Data:     bff.ViewData{Flex: 1, PaddingHorizontal: 16},
+					Children: rows,
+				},
+			},
+		},
+	}
+
+	c.JSON(200, bff.ScreenResponse{
+		Status: "success",
+		Screen: "chats",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"conversations": conversations,
+		},
+	})
+}
+
+// Helper function to create a chat list row that opens the trip's chat
+func createChatRow(t trip.Trip, s chat.Summary) bff.UISnippet {
+	preview := s.Last.Text
+	switch s.Last.Kind {
+	case chat.KindImage:
+		preview = "📷 Photo"
+	case chat.KindLocation:
+		preview = "📍 " + s.Last.Place
+	}
+	if s.Last.SenderID == t.BrokerID {
+		preview = "You: " + preview
+	}
+
+	row := bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				FlexDirection:     "row",
+				AlignItems:        "center",
+				PaddingVertical:   14,
+				BorderBottomWidth: 1,
+				BorderColor:       "#F0F0F0",
+				Gap:               12,
+			},
+			OnPress: bff.ActionData{
+				Type: "navigate",
+				To:   "/chat/" + t.ID,
+				Data: map[string]interface{}{"tripId": t.ID},
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Icon",
+				Data: bff.IconData{Name: "person-circle", Size: 40, Color: "#CCC"},
+			},
+			{
+				Type: "View",
+				Data: bff.ViewData{Flex: 1},
+				Children: []bff.UISnippet{
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: t.Details["driverName"] + " · " + t.ID, FontSize: 15, FontWeight: "600", Color: "#1A1A1A"},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: preview, FontSize: 13, Color: "#666"},
+					},
+				},
+			},
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: bff.TimeAgo(s.Last.At), FontSize: 11, Color: "#999"},
+			},
+		},
+	}
+	if s.Unread > 0 {
+		row.Children = append(row.Children, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{
+				Text:              fmt.Sprint(s.Unread),
+				FontSize:          11,
+				FontWeight:        "bold",
+				Color:             "#FFFFFF",
+				BackgroundColor:   "#ff0000",
+				BorderRadius:      10,
+				PaddingHorizontal: 7,
+				PaddingVertical:   2,
+			},
+		})
+	}
+	return row
+}
+
+// Helper function to create a chat bubble, right aligned for the broker's own messages
+func createChatBubble(m chat.Message, broker string) bff.UISnippet {
+	mine := m.SenderID == broker
+	align, background, color := "flex-start", "#FFFFFF", "#1A1A1A"
+	if mine {
+		align, background, color = "flex-end", "#ff0000", "#FFFFFF"
+	}
+
+	var content []bff.UISnippet
+	switch m.Kind {
+	case chat.KindImage:
+		content = append(content, bff.UISnippet{
+			Type: "IMAGE",
+			Data: bff.ImageData{Url: docstore.URL(m.ImageID, broker), Width: 200, Height: 150, ResizeMode: "cover"},
+		})
+	case chat.KindLocation:
+		place := m.Place
+		if place == "" && m.Location != nil {
+			place = m.Location.String()
+		}
+		content = append(content, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: "📍 " + place, FontSize: 14, Color: color},
+		})
+	}
+	if m.Text != "" {
+		content = append(content, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: m.Text, FontSize: 14, Color: color},
+		})
+	}
+
+	footer := m.At.In(ewaybill.IST).Format("3:04 PM")
+	if mine {
+		switch m.Receipt() {
+		case "read":
+			footer += " · Read"
+		case "delivered":
+			footer += " · Delivered"
+		default:
+			footer += " · Sent"
+		}
+	}
+	content = append(content, bff.UISnippet{
+		Type: "Text",
+		Data: bff.TextData{Text: footer, FontSize: 10, Color: color, Opacity: 0.7, AlignSelf: "flex-end"},
+	})
+
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{
+			AlignSelf:         align,
+			MaxWidth:          "80%",
+			PaddingHorizontal: 12,
+			PaddingVertical:   8,
+			BorderRadius:      14,
+			BorderWidth:       1,
+			BorderColor:       "#F0F0F0",
+			BackgroundColor:   background,
+		},
+		Children: content,
+	}
+}
+
+// Helper function to create a composer button that sends a message of a kind
+func createComposerButton(icon, actionURL string, kind chat.Kind) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{Padding: 6},
+			OnPress: bff.ActionData{
+				Type:  "action",
+				Value: "send",
+				Url:   actionURL,
+				Data:  map[string]interface{}{"kind": string(kind)},
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Icon",
+				Data: bff.IconData{Name: icon, Size: 24, Color: "#ff0000"},
+			},
+		},
+	}
+}
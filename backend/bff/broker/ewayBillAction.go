@@ -0,0 +1,87 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/ewaybill"
+	"backend/bff/org"
+	"backend/bff/trip"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+func HandleEwayBillAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleEwayBillAction(brokerID(c), memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleEwayBillAction(brokerID, memberID string, req bff.ActionRequest) bff.ActionResponse {
+	tripId, _ := req.Data["tripId"].(string)
+	t, err := trip.Default.Get(tripId)
+	if err != nil || t.BrokerID != brokerID {
+		return actionError(trip.ErrNotFound)
+	}
+	b, err := ewaybill.Default.Get(t.ID)
+	if err != nil {
+		return actionError(err)
+	}
+
+	switch req.Action {
+	case "viewEwayBill":
+		return ewayBillResponse(t, b, "E-way bill "+b.Number)
+
+	case "extendEwayBill":
+		if err := org.Default.Check(memberID, org.PermManageTrips); err != nil {
+			return actionError(err)
+		}
+		remaining, _ := req.Data["remainingKm"].(float64)
+		place, _ := req.Data["place"].(string)
+		reason, _ := req.Data["reason"].(string)
+		b, err := ewaybill.Default.Extend(t.ID, ewaybill.ExtendRequest{
+			RemainingKm: int(remaining),
+			Place:       place,
+			Reason:      reason,
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return ewayBillResponse(t, b, "E-way bill extended until "+ewayBillValidity(b))
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
+
+func ewayBillResponse(t trip.Trip, b ewaybill.Bill, message string) bff.ActionResponse {
+	data := map[string]interface{}{
+		"ewayBill":   b,
+		"extendable": b.Extendable(time.Now()),
+	}
+	if w, ok := ewaybill.Default.Warn(t); ok {
+		data["warning"] = w
+	}
+	return bff.ActionResponse{
+		Status:  "success",
+		Message: message,
+		Data:    data,
+	}
+}
+
+// Helper function to format when a bill lapses, in Indian time
+func ewayBillValidity(b ewaybill.Bill) string {
+	return b.ValidUntil.In(ewaybill.IST).Format("02 Jan 2006, 3:04 PM")
+}
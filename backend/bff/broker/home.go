@@ -2,12 +2,20 @@ package broker
 
 import (
 	"backend/bff"
+	"backend/bff/chat"
+	"backend/bff/kpi"
+	"backend/bff/notify"
+	"fmt"
 	"github.com/gin-gonic/gin"
 )
 
 func HomeScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	stats := kpi.Default.Snapshot(brokerID(c), kpi.Month)
+	unread := notify.Default.Unread(brokerID(c))
+	messages := chat.Default.TotalUnread(brokerID(c))
+
 	ui := []bff.UISnippet{
 		// SafeAreaView
 		{
@@ -65,6 +73,8 @@ func HomeScreen(c *gin.Context) {
 								},
 							},
 						},
+						createHeaderBadgeIcon("chatbubbles-outline", "/chats", messages),
+						createNotificationBell(unread),
 					},
 				},
 				// ScrollView
@@ -133,7 +143,7 @@ func HomeScreen(c *gin.Context) {
 														{
 															Type: "Text",
 															Data: bff.TextData{
-																Text:      "12",
+																Text:      fmt.Sprint(stats.ActiveLoads),
 																FontSize:  28,
 																FontWeight: "bold",
 																Color:     "#FFFFFF",
@@ -281,7 +291,7 @@ func HomeScreen(c *gin.Context) {
 														AlignItems: "center",
 														JustifyContent: "center",
 													},
-													Children: []bff.UISnippet{
+													Children: append([]bff.UISnippet{
 														{
 															Type: "Icon",
 															Data: bff.IconData{
@@ -293,7 +303,7 @@ func HomeScreen(c *gin.Context) {
 														{
 															Type: "Text",
 															Data: bff.TextData{
-																Text:      "5",
+																Text:      fmt.Sprint(stats.TrucksOnRoad),
 																FontSize:  28,
 																FontWeight: "bold",
 																Color:     "#FFFFFF",
@@ -304,14 +314,14 @@ func HomeScreen(c *gin.Context) {
 														{
 															Type: "Text",
 															Data: bff.TextData{
-																Text:      "Live Trips",
+																Text:      "Trucks on Road",
 																FontSize:  12,
 																Color:     "#FFFFFF",
 																TextAlign: "center",
 																FontWeight: "500",
 															},
 														},
-													},
+													}, delayedBadge(stats.DelayedTrips)...),
 												},
 											},
 										},
@@ -361,7 +371,7 @@ func HomeScreen(c *gin.Context) {
 														{
 															Type: "Text",
 															Data: bff.TextData{
-																Text:      "3",
+																Text:      fmt.Sprint(stats.PendingPayments),
 																FontSize:  28,
 																FontWeight: "bold",
 																Color:     "#FFFFFF",
@@ -382,7 +392,7 @@ func HomeScreen(c *gin.Context) {
 														{
 															Type: "Text",
 															Data: bff.TextData{
-																Text:      "₹78,300",
+																Text:      stats.PendingAmount.String(),
 																FontSize:  12,
 																Color:     "#ffffff",
 																FontWeight: "bold",
@@ -393,6 +403,8 @@ func HomeScreen(c *gin.Context) {
 												},
 											},
 										},
+										// Stat Card 5 - Margin per Load
+										createKPICard("chart-line", stats.AvgMargin.Current.String(), "Avg Margin / Load", stats.AvgMargin.Label(kpi.Month), "/money"),
 									},
 								},
 							},
@@ -920,12 +932,19 @@ func HomeScreen(c *gin.Context) {
 		UI:     ui,
 		Data: map[string]interface{}{
 			"stats": map[string]interface{}{
-				"activeLoads":     12,
+				"activeLoads":     stats.ActiveLoads,
 				"pendingBids":     8,
-				"liveTrips":       5,
-				"pendingPayments": 3,
-				"paymentAmount":   "₹78,300",
+				"liveTrips":       stats.TrucksOnRoad,
+				"delayedTrips":    stats.DelayedTrips,
+				"pendingPayments": stats.PendingPayments,
+				"paymentAmount":   stats.PendingAmount.String(),
+				"paidThisMonth":   stats.PaidVolume.Current.String(),
+				"paidChange":      stats.PaidVolume.Label(kpi.Month),
+				"avgMargin":       stats.AvgMargin.Current.String(),
+				"marginRate":      stats.MarginRate,
 				"newBids":         "+2 new",
+				"notifications":   unread,
+				"messages":        messages,
 			},
 			"quickActions": []map[string]interface{}{
 				{
@@ -983,4 +1002,89 @@ func HomeScreen(c *gin.Context) {
 	}
 
 	c.JSON(200, response)
-}
\ No newline at end of file
+}
+
+// Helper function to flag trucks running behind their promised time
+func delayedBadge(delayed int) []bff.UISnippet {
+	if delayed == 0 {
+		return nil
+	}
+	return []bff.UISnippet{{
+		Type: "View",
+		Data: bff.ViewData{
+			Position:          "absolute",
+			Top:               12,
+			Right:             12,
+			BackgroundColor:   "#FFF0F0",
+			PaddingHorizontal: 8,
+			PaddingVertical:   2,
+			BorderRadius:      10,
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{
+					Text:       fmt.Sprintf("%d delayed", delayed),
+					FontSize:   10,
+					Color:      "#ff0000",
+					FontWeight: "600",
+				},
+			},
+		},
+	}}
+}
+
+// Helper function to create a KPI stat card with a period comparison
+func createKPICard(icon, value, label, change, route string) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				Width:         160,
+				Height:        120,
+				BorderRadius:  16,
+				MarginRight:   12,
+				Overflow:      "hidden",
+				ShadowColor:   "#000",
+				ShadowOffsetY: 2,
+				ShadowOpacity: 0.1,
+				ShadowRadius:  8,
+				Elevation:     4,
+			},
+			OnPress: bff.ActionData{
+				Type: "navigate",
+				To:   route,
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "View",
+				Data: bff.ViewData{
+					Flex:            1,
+					BackgroundColor: "#1a1a1a",
+					Padding:         16,
+					AlignItems:      "center",
+					JustifyContent:  "center",
+				},
+				Children: []bff.UISnippet{
+					{
+						Type: "Icon",
+						Data: bff.IconData{Name: icon, Size: 24, Color: "#fff"},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: value, FontSize: 22, FontWeight: "bold", Color: "#FFFFFF", MarginTop: 8, MarginBottom: 4},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: label, FontSize: 12, Color: "#FFFFFF", TextAlign: "center", FontWeight: "500"},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: change, FontSize: 10, Color: "#BBBBBB", MarginTop: 4},
+					},
+				},
+			},
+		},
+	}
+}
@@ -2,12 +2,28 @@ package broker
 
 import (
 	"backend/bff"
+	"backend/bff/calls"
+	"backend/bff/org"
+	"backend/bff/routing"
+	"backend/bff/safety"
+	"backend/bff/trip"
 	"github.com/gin-gonic/gin"
+	"strconv"
 )
 
 func LiveTripScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	broker := brokerID(c)
+	trips := trip.Default.List(func(t trip.Trip) bool {
+		return t.BrokerID == broker && t.Active()
+	})
+	var tripData []map[string]interface{}
+	for _, t := range trips {
+		t.Details = liveDetails(t)
+		tripData = append(tripData, liveTripData(t))
+	}
+
 	ui := []bff.UISnippet{
 		// Main Container View
 		{
@@ -253,14 +269,14 @@ func LiveTripScreen(c *gin.Context) {
 						ShowsVerticalScrollIndicator: false,
 						Padding:            16,
 					},
-					Children: []bff.UISnippet{
-						// Trip Card 1
-						createTripCard("TRIP-001", "Rajesh Kumar", "HR 38 XX 1234", "Mumbai, Maharashtra", "Delhi, NCR", "1,420 km", "in-transit", "12 hrs 30 min", "2 min ago", true),
-						// Trip Card 2
-						createTripCard("TRIP-002", "Amit Sharma", "DL 01 AB 5678", "Chennai, Tamil Nadu", "Bangalore, Karnataka", "350 km", "loading", "8 hrs 15 min", "5 min ago", false),
-						// Trip Card 3
-						createTripCard("TRIP-003", "Vikram Singh", "UP 32 CD 9012", "Kolkata, West Bengal", "Pune, Maharashtra", "1,850 km", "near-destination", "1 hr 45 min", "1 min ago", false),
-					},
+					Children: func() []bff.UISnippet {
+						var cards []bff.UISnippet
+						for i, t := range trips {
+							d := calls.Default.Mask(t, broker, t.Details)
+							cards = append(cards, createTripCard(t.ID, d["driverName"], d["driverPhone"], d["vehicleNumber"], d["originCity"], d["destinationCity"], d["distance"], string(t.Status), d["eta"], d["lastUpdated"], d["safetyScore"], i == 0, d["delayed"] == "true"))
+						}
+						return cards
+					}(),
 				},
 			},
 		},
@@ -278,131 +294,8 @@ func LiveTripScreen(c *gin.Context) {
 				{"id": "delayed", "label": "Delayed"},
 				{"id": "completed", "label": "Completed"},
 			},
-			"statusConfig": map[string]interface{}{
-				"in-transit": map[string]string{
-					"label":    "In Transit",
-					"color":    "#2E86AB",
-					"bgColor":  "#E8F4FD",
-				},
-				"pickup": map[string]string{
-					"label":    "Reached Pickup",
-					"color":    "#F39C12",
-					"bgColor":  "#FEF9E7",
-				},
-				"loading": map[string]string{
-					"label":    "Loading",
-					"color":    "#8E44AD",
-					"bgColor":  "#F4ECF7",
-				},
-				"unloading": map[string]string{
-					"label":    "Unloading",
-					"color":    "#16A085",
-					"bgColor":  "#E8F6F3",
-				},
-				"near-destination": map[string]string{
-					"label":    "Near Destination",
-					"color":    "#27AE60",
-					"bgColor":  "#EAFAF1",
-				},
-			},
-			"trips": []map[string]interface{}{
-				{
-					"id": "TRIP-001",
-					"driver": map[string]interface{}{
-						"name":  "Rajesh Kumar",
-						"phone": "+91 98765 43210",
-						"photo": nil,
-					},
-					"truck": map[string]interface{}{
-						"number": "HR 38 XX 1234",
-						"type":   "Open Half Body",
-					},
-					"route": map[string]interface{}{
-						"from":     "Mumbai, Maharashtra",
-						"to":       "Delhi, NCR",
-						"distance": "1,420 km",
-					},
-					"status":       "in-transit",
-					"eta":          "12 hrs 30 min",
-					"lastUpdated":  "2 min ago",
-					"currentLocation": "Near Jaipur, RJ",
-					"speed":        "65 km/h",
-					"distanceLeft": "320 km",
-					"timeline": map[string]bool{
-						"started":             true,
-						"reachedPickup":       true,
-						"loading":             true,
-						"inTransit":           true,
-						"unloading":           false,
-						"reachedDestination":  false,
-						"podUploaded":         false,
-					},
-				},
-				{
-					"id": "TRIP-002",
-					"driver": map[string]interface{}{
-						"name":  "Amit Sharma",
-						"phone": "+91 98765 43211",
-						"photo": nil,
-					},
-					"truck": map[string]interface{}{
-						"number": "DL 01 AB 5678",
-						"type":   "Container",
-					},
-					"route": map[string]interface{}{
-						"from":     "Chennai, Tamil Nadu",
-						"to":       "Bangalore, Karnataka",
-						"distance": "350 km",
-					},
-					"status":       "loading",
-					"eta":          "8 hrs 15 min",
-					"lastUpdated":  "5 min ago",
-					"currentLocation": "Chennai Warehouse",
-					"speed":        "0 km/h",
-					"distanceLeft": "350 km",
-					"timeline": map[string]bool{
-						"started":             true,
-						"reachedPickup":       true,
-						"loading":             true,
-						"inTransit":           false,
-						"unloading":           false,
-						"reachedDestination":  false,
-						"podUploaded":         false,
-					},
-				},
-				{
-					"id": "TRIP-003",
-					"driver": map[string]interface{}{
-						"name":  "Vikram Singh",
-						"phone": "+91 98765 43212",
-						"photo": nil,
-					},
-					"truck": map[string]interface{}{
-						"number": "UP 32 CD 9012",
-						"type":   "Trailer",
-					},
-					"route": map[string]interface{}{
-						"from":     "Kolkata, West Bengal",
-						"to":       "Pune, Maharashtra",
-						"distance": "1,850 km",
-					},
-					"status":       "near-destination",
-					"eta":          "1 hr 45 min",
-					"lastUpdated":  "1 min ago",
-					"currentLocation": "Pune Outer Ring",
-					"speed":        "45 km/h",
-					"distanceLeft": "35 km",
-					"timeline": map[string]bool{
-						"started":             true,
-						"reachedPickup":       true,
-						"loading":             true,
-						"inTransit":           true,
-						"unloading":           false,
-						"reachedDestination":  false,
-						"podUploaded":         false,
-					},
-				},
-			},
+			"statusConfig": tripStatusStyles,
+			"trips":        tripData,
 		},
 	}
 
@@ -410,28 +303,23 @@ func LiveTripScreen(c *gin.Context) {
 }
 
 // Helper function to create trip card
-func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta, lastUpdated string, isExpanded bool) bff.UISnippet {
-	statusConfig := map[string]map[string]string{
-		"in-transit": {
-			"label":    "In Transit",
-			"color":    "#2E86AB",
-			"bgColor":  "#E8F4FD",
-		},
-		"loading": {
-			"label":    "Loading",
-			"color":    "#8E44AD",
-			"bgColor":  "#F4ECF7",
-		},
-		"near-destination": {
-			"label":    "Near Destination",
-			"color":    "#27AE60",
-			"bgColor":  "#EAFAF1",
-		},
+func createTripCard(id, driverName, driverPhone, truckNumber, from, to, distance, status, eta, lastUpdated, safetyScore string, isExpanded, delayed bool) bff.UISnippet {
+	statusData := tripStatusStyles[status]
+	if statusData == nil {
+		statusData = tripStatusStyles[string(trip.StatusInTransit)] // Default
 	}
 
-	statusData := statusConfig[status]
-	if statusData == nil {
-		statusData = statusConfig["in-transit"] // Default
+	etaColor := "#333"
+	if delayed {
+		etaColor = "#ff0000"
+	}
+
+	safetyText, safetyColor := "–", "#333"
+	if safetyScore != "" {
+		safetyText = safetyScore + "/100"
+		if n, _ := strconv.Atoi(safetyScore); n < 70 {
+			safetyColor = "#ff0000"
+		}
 	}
 
 	return bff.UISnippet{
@@ -716,7 +604,7 @@ func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta
 									Text:      eta,
 									FontSize:  14,
 									FontWeight: "600",
-									Color:     "#333",
+									Color:     etaColor,
 								},
 							},
 						},
@@ -775,6 +663,33 @@ func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta
 							},
 						},
 					},
+					{
+						Type: "View",
+						Data: bff.ViewData{
+							AlignItems: "center",
+						},
+						Children: []bff.UISnippet{
+							{
+								Type: "Text",
+								Data: bff.TextData{
+									Text:         "Safety",
+									FontSize:     12,
+									Color:        "#999",
+									MarginBottom: 4,
+									FontWeight:   "500",
+								},
+							},
+							{
+								Type: "Text",
+								Data: bff.TextData{
+									Text:       safetyText,
+									FontSize:   14,
+									FontWeight: "600",
+									Color:      safetyColor,
+								},
+							},
+						},
+					},
 				},
 			},
 			// Action Buttons
@@ -801,7 +716,7 @@ func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta
 							OnPress: bff.ActionData{
 								Type: "call",
 								Data: map[string]interface{}{
-									"phone": "+91 98765 43210",
+									"phone": driverPhone,
 								},
 							},
 						},
@@ -841,9 +756,11 @@ func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta
 								Gap:              8,
 							},
 							OnPress: bff.ActionData{
-								Type: "message",
+								Type: "navigate",
+								To:   "/chat/" + id,
 								Data: map[string]interface{}{
 									"driver": driverName,
+									"tripId": id,
 								},
 							},
 						},
@@ -871,4 +788,82 @@ func createTripCard(id, driverName, truckNumber, from, to, distance, status, eta
 			},
 		},
 	}
-}
\ No newline at end of file
+}
+// Badge styles for every trip lifecycle status
+var tripStatusStyles = map[string]map[string]string{
+	string(trip.StatusAssigned):      {"label": "Assigned", "color": "#7F8C8D", "bgColor": "#F2F4F4"},
+	string(trip.StatusAccepted):      {"label": "Accepted", "color": "#7F8C8D", "bgColor": "#F2F4F4"},
+	string(trip.StatusReachedPickup): {"label": "Reached Pickup", "color": "#F39C12", "bgColor": "#FEF9E7"},
+	string(trip.StatusLoading):       {"label": "Loading", "color": "#8E44AD", "bgColor": "#F4ECF7"},
+	string(trip.StatusInTransit):     {"label": "In Transit", "color": "#2E86AB", "bgColor": "#E8F4FD"},
+	string(trip.StatusReachedDrop):   {"label": "Reached Drop", "color": "#27AE60", "bgColor": "#EAFAF1"},
+	string(trip.StatusDelivered):     {"label": "Delivered", "color": "#16A085", "bgColor": "#E8F6F3"},
+	string(trip.StatusPODUploaded):   {"label": "POD Uploaded", "color": "#16A085", "bgColor": "#E8F6F3"},
+	string(trip.StatusSettled):       {"label": "Settled", "color": "#1a1a1a", "bgColor": "#F0F0F0"},
+	string(trip.StatusCancelled):     {"label": "Cancelled", "color": "#ff0000", "bgColor": "#FFE6E6"},
+}
+
+// brokerID is the broker account the signed-in member works on; everyone
+// in an organization shares its loads, trucks, trips and payments
+func brokerID(c *gin.Context) string {
+	return org.Default.Account(memberID(c))
+}
+
+// memberID is the signed-in broker, whose roles decide what they may do
+func memberID(c *gin.Context) string {
+	return c.DefaultQuery("brokerId", trip.DemoBrokerID)
+}
+
+// Helper function to overlay position and ETA derived fields on a trip's details
+func liveDetails(t trip.Trip) map[string]string {
+	d := routing.Default.LiveDetails(t)
+	d["safetyScore"] = safety.Default.Report(t.ID).Text()
+	return d
+}
+
+// Helper function to build the data payload for a live trip
+func liveTripData(t trip.Trip) map[string]interface{} {
+	d := calls.Default.Mask(t, t.BrokerID, t.Details)
+	reached := func(s trip.Status) bool {
+		_, ok := t.Reached(s)
+		return ok
+	}
+
+	return map[string]interface{}{
+		"id": t.ID,
+		"driver": map[string]interface{}{
+			"name":  d["driverName"],
+			"phone": d["driverPhone"],
+			"photo": nil,
+		},
+		"truck": map[string]interface{}{
+			"number": d["vehicleNumber"],
+			"type":   d["truckType"],
+		},
+		"route": map[string]interface{}{
+			"from":     d["originCity"],
+			"to":       d["destinationCity"],
+			"distance": d["distance"],
+		},
+		"status":           t.Status,
+		"eta":              d["eta"],
+		"lastUpdated":      d["lastUpdated"],
+		"currentLocation":  d["currentLocation"],
+		"speed":            d["speed"],
+		"distanceLeft":     d["distanceLeft"],
+		"estimatedArrival": d["estimatedArrival"],
+		"delayed":          d["delayed"] == "true",
+		"delay":            d["delay"],
+		"safety":           safety.Default.Report(t.ID),
+		"timeline": map[string]bool{
+			"started":            reached(trip.StatusAccepted),
+			"reachedPickup":      reached(trip.StatusReachedPickup),
+			"loading":            reached(trip.StatusLoading),
+			"inTransit":          reached(trip.StatusInTransit),
+			"reachedDestination": reached(trip.StatusReachedDrop),
+			"unloading":          reached(trip.StatusDelivered),
+			"podUploaded":        reached(trip.StatusPODUploaded),
+		},
+		"history": t.History,
+	}
+}
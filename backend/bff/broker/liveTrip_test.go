@@ -0,0 +1,39 @@
+package broker
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLiveTripScreenShowsETA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/bff/broker/livetrip", LiveTripScreen)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/bff/broker/livetrip", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	var res struct {
+		Data struct {
+			Trips []map[string]interface{} `json:"trips"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data.Trips) == 0 {
+		t.Fatal("no live trips for the demo broker")
+	}
+	for _, tr := range res.Data.Trips {
+		for _, field := range []string{"eta", "distanceLeft", "estimatedArrival"} {
+			if v, _ := tr[field].(string); v == "" {
+				t.Errorf("trip %v has no %s", tr["id"], field)
+			}
+		}
+	}
+}
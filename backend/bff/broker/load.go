@@ -2,12 +2,39 @@ package broker
 
 import (
 	"backend/bff"
+	"backend/bff/calls"
+	"backend/bff/consignment"
+	"backend/bff/ewaybill"
+	"backend/bff/geofence"
+	"backend/bff/load"
+	"backend/bff/org"
+	"backend/bff/trip"
+	"backend/bff/vehicle"
+	"fmt"
+	"strconv"
+	"time"
 	"github.com/gin-gonic/gin"
 )
 
 func LoadScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	broker := brokerID(c)
+	loads := map[string][]map[string]interface{}{}
+	var cards []bff.UISnippet
+	for _, l := range load.Default.ForBroker(broker) {
+		tab := l.Status.Tab()
+		loads[tab] = append(loads[tab], loadData(l))
+		if tab == load.Tabs[0] {
+			cards = append(cards, createLoadCard(l.ID, l.Pickup, l.Drop, l.Cargo, l.VehicleType, l.Budget.String(), string(l.Status),
+				l.Bids, bff.Km(l.DistanceKm), l.Weight, l.Dimensions, l.Notes, loadTimeline(l)))
+		}
+	}
+	var trucks []map[string]interface{}
+	for _, v := range vehicle.Default.ForBroker(broker) {
+		trucks = append(trucks, truckData(v))
+	}
+
 	ui := []bff.UISnippet{
 		// Main Container
 		{
@@ -65,7 +92,7 @@ func LoadScreen(c *gin.Context) {
 								FlexDirection: "row",
 								AlignItems:    "center",
 							},
-							Children: []bff.UISnippet{
+							Children: append([]bff.UISnippet{
 								{
 									Type: "TouchableOpacity",
 									Data: bff.TouchableOpacityData{
@@ -108,7 +135,7 @@ func LoadScreen(c *gin.Context) {
 										},
 									},
 								},
-							},
+							}, createPostLoadButton(memberID(c))...),
 						},
 					},
 				},
@@ -136,24 +163,7 @@ func LoadScreen(c *gin.Context) {
 						Flex:               1,
 						ShowsVerticalScrollIndicator: false,
 					},
-					Children: []bff.UISnippet{
-						// Load Card 1
-						createLoadCard("LD-7891", "Mumbai Warehouse", "Delhi Distribution Center", 
-							"Electronics", "20ft Container Truck", "₹45,000", "Bidding Open", 5, 
-							"1400 km", "15 Tons", "20x8x8 ft", "Fragile items - Handle with care",
-							map[string]string{
-								"biddingStart": "2024-01-15 10:00",
-								"biddingEnd":   "2024-01-17 18:00",
-							}),
-						// Load Card 2
-						createLoadCard("LD-7892", "Ahmedabad Factory", "Chennai Port", 
-							"Textiles", "32ft Trailer", "₹38,000", "Bids Received", 3, 
-							"1600 km", "22 Tons", "32x8x8 ft", "Waterproof packaging required",
-							map[string]string{
-								"biddingStart": "2024-01-14 09:00",
-								"biddingEnd":   "2024-01-16 17:00",
-							}),
-					},
+					Children: cards,
 				},
 			},
 		},
@@ -165,88 +175,9 @@ func LoadScreen(c *gin.Context) {
 		Screen: "load",
 		UI:     ui,
 		Data: map[string]interface{}{
-			"tabs": []string{"Active Loads", "Pending Loads", "Completed Loads"},
-			"activeTab": "Active Loads",
-			"loads": map[string][]map[string]interface{}{
-				"Active Loads": {
-					{
-						"id":          "LD-7891",
-						"pickup":      "Mumbai Warehouse",
-						"drop":        "Delhi Distribution Center",
-						"cargoType":   "Electronics",
-						"vehicleType": "20ft Container Truck",
-						"budget":      "₹45,000",
-						"status":      "Bidding Open",
-						"bids":        5,
-						"distance":    "1400 km",
-						"weight":      "15 Tons",
-						"dimensions":  "20x8x8 ft",
-						"notes":       "Fragile items - Handle with care",
-						"timeline": map[string]string{
-							"biddingStart": "2024-01-15 10:00",
-							"biddingEnd":   "2024-01-17 18:00",
-						},
-					},
-					{
-						"id":          "LD-7892",
-						"pickup":      "Ahmedabad Factory",
-						"drop":        "Chennai Port",
-						"cargoType":   "Textiles",
-						"vehicleType": "32ft Trailer",
-						"budget":      "₹38,000",
-						"status":      "Bids Received",
-						"bids":        3,
-						"distance":    "1600 km",
-						"weight":      "22 Tons",
-						"dimensions":  "32x8x8 ft",
-						"notes":       "Waterproof packaging required",
-						"timeline": map[string]string{
-							"biddingStart": "2024-01-14 09:00",
-							"biddingEnd":   "2024-01-16 17:00",
-						},
-					},
-				},
-				"Pending Loads": {
-					{
-						"id":          "LD-7893",
-						"pickup":      "Pune Industrial Area",
-						"drop":        "Bangalore Tech Park",
-						"cargoType":   "Machinery Parts",
-						"vehicleType": "10ft Truck",
-						"budget":      "₹25,000",
-						"status":      "Driver Assigned",
-						"bids":        0,
-						"distance":    "850 km",
-						"weight":      "8 Tons",
-						"dimensions":  "10x6x6 ft",
-						"notes":       "Heavy machinery - secure properly",
-						"timeline": map[string]string{
-							"biddingStart": "2024-01-13 08:00",
-							"biddingEnd":   "2024-01-15 16:00",
-						},
-					},
-				},
-				"Completed Loads": {
-					{
-						"id":          "LD-7890",
-						"pickup":      "Chennai Warehouse",
-						"drop":        "Kolkata Depot",
-						"cargoType":   "Consumer Goods",
-						"vehicleType": "24ft Truck",
-						"budget":      "₹32,000",
-						"status":      "Delivered",
-						"bids":        0,
-						"distance":    "1700 km",
-						"weight":      "18 Tons",
-						"dimensions":  "24x8x8 ft",
-						"notes":       "Standard handling",
-						"timeline": map[string]string{
-							"biddingStart": "2024-01-10 10:00",
-							"biddingEnd":   "2024-01-12 18:00",
-						},
-					},
-				},
-			},
+			"tabs":      load.Tabs,
+			"activeTab": load.Tabs[0],
+			"loads":     loads,
 			"bidsData": []map[string]interface{}{
 				{
 					"id":            "BID-001",
@@ -254,7 +185,7 @@ func LoadScreen(c *gin.Context) {
 					"truckType":     "20ft Container Truck",
 					"rating":        4.8,
 					"bidPrice":      "₹42,500",
-					"phone":         "+91 98765 43210",
+					"phone":         calls.Redact("+91 98765 43210"),
 					"experience":    "5 years",
 					"completedTrips": 234,
 				},
@@ -264,7 +195,7 @@ func LoadScreen(c *gin.Context) {
 					"truckType":     "22ft Truck",
 					"rating":        4.6,
 					"bidPrice":      "₹44,000",
-					"phone":         "+91 98765 43211",
+					"phone":         calls.Redact("+91 98765 43211"),
 					"experience":    "3 years",
 					"completedTrips": 156,
 				},
@@ -274,29 +205,13 @@ func LoadScreen(c *gin.Context) {
 					"truckType":     "20ft Container Truck",
 					"rating":        4.9,
 					"bidPrice":      "₹43,000",
-					"phone":         "+91 98765 43212",
+					"phone":         calls.Redact("+91 98765 43212"),
 					"experience":    "7 years",
 					"completedTrips": 312,
 				},
 			},
-			"ownTrucksData": []map[string]interface{}{
-				{
-					"id":              "TRK-001",
-					"truckType":       "20ft Container Truck",
-					"capacity":        "15 Tons",
-					"registration":    "MH 01 AB 1234",
-					"driverName":      "Anil Sharma",
-					"documentVerified": true,
-				},
-				{
-					"id":              "TRK-002",
-					"truckType":       "24ft Truck",
-					"capacity":        "18 Tons",
-					"registration":    "GJ 05 CD 5678",
-					"driverName":      "Mahesh Reddy",
-					"documentVerified": true,
-				},
-			},
+			"ownTrucksData": trucks,
+			"canPostLoad":   org.Default.Check(memberID(c), org.PermPostLoad) == nil,
 			"statusStyles": map[string]map[string]string{
 				"Bidding Open": {
 					"bgColor": "#D4EDDA",
@@ -720,7 +635,7 @@ func LoadDetailScreen(c *gin.Context) {
 									Data: bff.ViewData{
 										Padding: 20,
 									},
-									Children: []bff.UISnippet{
+									Children: bff.Join([]bff.UISnippet{
 										// Load Information Section
 										createDetailSection("Load Information", []bff.UISnippet{
 											createDetailItem("Pickup Location", "Mumbai Warehouse"),
@@ -736,6 +651,14 @@ func LoadDetailScreen(c *gin.Context) {
 											createTimelineItem("Bidding Started", "2024-01-15 10:00"),
 											createTimelineItem("Bidding Ends", "2024-01-17 18:00"),
 										}),
+									},
+										// Trip Timeline Section
+										createTripTimelineSection(loadId),
+										// Lorry Receipt Section
+										createLorryReceiptSection(loadId),
+										// E-way Bill Section
+										createEwayBillSection(loadId),
+									[]bff.UISnippet{
 										// Driver Bids Section
 										{
 											Type: "View",
@@ -822,7 +745,7 @@ func LoadDetailScreen(c *gin.Context) {
 												},
 											},
 										},
-									},
+									}),
 								},
 							},
 						},
@@ -933,4 +856,218 @@ func createTimelineItem(label, value string) bff.UISnippet {
 			},
 		},
 	}
-}
\ No newline at end of file
+}
+
+// Helper function to create the timeline of the trip booked against a load
+func createTripTimelineSection(loadId string) []bff.UISnippet {
+	t, err := trip.Default.ForLoad(loadId)
+	if err != nil {
+		return nil
+	}
+
+	var items []bff.UISnippet
+	for _, entry := range t.FullTimeline() {
+		items = append(items, createTimelineItem(entry.Label, entry.At.Format("2006-01-02 15:04")))
+	}
+	for _, d := range geofence.Default.Detentions(t.ID) {
+		label := "Detention at " + string(d.Site)
+		if d.End.IsZero() {
+			label += " (ongoing)"
+		}
+		items = append(items, createTimelineItem(label, bff.Hours(d.Duration(time.Now()))))
+	}
+
+	return []bff.UISnippet{createDetailSection("Trip Timeline", items)}
+}
+
+// Helper function to create the lorry receipt section of the trip booked against a load
+func createLorryReceiptSection(loadId string) []bff.UISnippet {
+	t, err := trip.Default.ForLoad(loadId)
+	if err != nil {
+		return nil
+	}
+	n, err := consignment.Default.ForTrip(t.ID)
+	if err != nil {
+		return nil
+	}
+
+	version := fmt.Sprintf("Version %d", n.Version)
+	if n.Version > 1 {
+		version += " (" + n.Reason + ")"
+	}
+
+	return []bff.UISnippet{createDetailSection("Lorry Receipt", []bff.UISnippet{
+		createDetailItem("LR Number", n.Number),
+		createDetailItem("Version", version),
+		createDetailItem("Freight Terms", n.FreightTerms.Label()),
+		{
+			Type: "TouchableOpacity",
+			Data: bff.TouchableOpacityData{
+				Style: bff.ViewData{
+					BackgroundColor: "#fff",
+					BorderWidth:     1,
+					BorderColor:     "#ff0000",
+					BorderRadius:    8,
+					PaddingVertical: 10,
+					AlignItems:      "center",
+				},
+				OnPress: bff.ActionData{
+					Type:   "viewLR",
+					Url:    "/bff/broker/lr/action",
+					Method: "POST",
+					Data: map[string]interface{}{
+						"tripId": t.ID,
+					},
+				},
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "Text",
+					Data: bff.TextData{
+						Text:       "View & Share LR",
+						FontSize:   14,
+						FontWeight: "600",
+						Color:      "#ff0000",
+					},
+				},
+			},
+		},
+	})}
+}
+
+// Helper function to create the e-way bill section of the trip booked against a load
+func createEwayBillSection(loadId string) []bff.UISnippet {
+	t, err := trip.Default.ForLoad(loadId)
+	if err != nil {
+		return nil
+	}
+	b, err := ewaybill.Default.Get(t.ID)
+	if err != nil {
+		return []bff.UISnippet{createDetailSection("E-way Bill", []bff.UISnippet{
+			createDetailItem("Status", "Not captured yet"),
+		})}
+	}
+
+	items := []bff.UISnippet{
+		createDetailItem("E-way Bill No", b.Number),
+		createDetailItem("Distance", fmt.Sprintf("%d km", b.DistanceKm)),
+		createDetailItem("Valid Until", ewayBillValidity(b), b.Expired(time.Now())),
+	}
+	if len(b.Extensions) > 0 {
+		items = append(items, createDetailItem("Extensions", fmt.Sprintf("%d", len(b.Extensions))))
+	}
+	if w, ok := ewaybill.Default.Warn(t); ok {
+		items = append(items, createDetailItem("Warning", w.Message, true))
+	}
+	if b.Extendable(time.Now()) {
+		items = append(items, bff.UISnippet{
+			Type: "TouchableOpacity",
+			Data: bff.TouchableOpacityData{
+				Style: bff.ViewData{
+					BackgroundColor: "#ff0000",
+					BorderRadius:    8,
+					PaddingVertical: 10,
+					AlignItems:      "center",
+				},
+				OnPress: bff.ActionData{
+					Type:   "extendEwayBill",
+					Url:    "/bff/broker/ewaybill/action",
+					Method: "POST",
+					Data: map[string]interface{}{
+						"tripId": t.ID,
+						"fields": []string{"remainingKm", "place", "reason"},
+					},
+				},
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "Text",
+					Data: bff.TextData{
+						Text:       "Extend E-way Bill",
+						FontSize:   14,
+						FontWeight: "600",
+						Color:      "#fff",
+					},
+				},
+			},
+		})
+	}
+
+	return []bff.UISnippet{createDetailSection("E-way Bill", items)}
+}
+
+// Helper function to create the post load button, shown to dispatchers only
+func createPostLoadButton(member string) []bff.UISnippet {
+	if org.Default.Check(member, org.PermPostLoad) != nil {
+		return nil
+	}
+	return []bff.UISnippet{{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				MarginLeft: 16,
+			},
+			OnPress: bff.ActionData{
+				Type:   "action",
+				Value:  "postLoad",
+				Url:    "/bff/broker/load/action",
+				Method: "POST",
+				Data: map[string]interface{}{
+					"fields": []string{"pickup", "drop", "cargoType", "vehicleType", "budget", "distanceKm", "weight", "dimensions", "notes", "biddingHours"},
+				},
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Icon",
+				Data: bff.IconData{
+					Name:  "add-circle",
+					Size:  24,
+					Color: "#ff0000",
+				},
+			},
+		},
+	}}
+}
+
+// Helper function to describe a load for the apps
+func loadData(l load.Load) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          l.ID,
+		"pickup":      l.Pickup,
+		"drop":        l.Drop,
+		"cargoType":   l.Cargo,
+		"vehicleType": l.VehicleType,
+		"budget":      l.Budget.String(),
+		"status":      string(l.Status),
+		"bids":        l.Bids,
+		"distance":    bff.Km(l.DistanceKm),
+		"weight":      l.Weight,
+		"dimensions":  l.Dimensions,
+		"notes":       l.Notes,
+		"postedBy":    l.PostedBy,
+		"timeline":    loadTimeline(l),
+	}
+}
+
+func loadTimeline(l load.Load) map[string]string {
+	return map[string]string{
+		"biddingStart": l.BiddingStart.In(ewaybill.IST).Format("2006-01-02 15:04"),
+		"biddingEnd":   l.BiddingEnd.In(ewaybill.IST).Format("2006-01-02 15:04"),
+	}
+}
+
+// Helper function to describe a fleet truck for the apps
+func truckData(v vehicle.Vehicle) map[string]interface{} {
+	d := map[string]interface{}{
+		"id":           v.Number,
+		"truckType":    v.Type,
+		"registration": v.Number,
+		"driverName":   v.DriverName,
+		"model":        v.Model,
+	}
+	if v.Capacity > 0 {
+		d["capacity"] = strconv.FormatFloat(v.Capacity, 'f', -1, 64) + " Tons"
+	}
+	return d
+}
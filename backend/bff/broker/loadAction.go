@@ -0,0 +1,72 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/ledger"
+	"backend/bff/load"
+	"backend/bff/org"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleLoadAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleLoadAction(brokerID(c), memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleLoadAction(brokerID, memberID string, req bff.ActionRequest) bff.ActionResponse {
+	switch req.Action {
+	case "postLoad":
+		if err := org.Default.Check(memberID, org.PermPostLoad); err != nil {
+			return actionError(err)
+		}
+		pickup, _ := req.Data["pickup"].(string)
+		drop, _ := req.Data["drop"].(string)
+		cargo, _ := req.Data["cargoType"].(string)
+		vehicleType, _ := req.Data["vehicleType"].(string)
+		budget, _ := req.Data["budget"].(float64)
+		distance, _ := req.Data["distanceKm"].(float64)
+		weight, _ := req.Data["weight"].(string)
+		dimensions, _ := req.Data["dimensions"].(string)
+		notes, _ := req.Data["notes"].(string)
+		hours, _ := req.Data["biddingHours"].(float64)
+
+		l, err := load.Default.Post(brokerID, memberID, load.PostRequest{
+			Pickup:       pickup,
+			Drop:         drop,
+			Cargo:        cargo,
+			VehicleType:  vehicleType,
+			Budget:       ledger.FromRupees(budget),
+			DistanceKm:   distance,
+			Weight:       weight,
+			Dimensions:   dimensions,
+			Notes:        notes,
+			BiddingHours: int(hours),
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Load " + l.ID + " posted, bidding is open",
+			Data:    loadData(l),
+		}
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/consignment"
+	"backend/bff/docstore"
+	"backend/bff/org"
+	"backend/bff/trip"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleLRAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleLRAction(brokerID(c), memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleLRAction(brokerID, memberID string, req bff.ActionRequest) bff.ActionResponse {
+	tripId, _ := req.Data["tripId"].(string)
+	if loadId, _ := req.Data["loadId"].(string); tripId == "" && loadId != "" {
+		t, _ := trip.Default.ForLoad(loadId)
+		tripId = t.ID
+	}
+	n, err := consignment.Default.ForTrip(tripId)
+	if err != nil || n.BrokerID != brokerID {
+		return actionError(consignment.ErrNotFound)
+	}
+
+	switch req.Action {
+	case "viewLR":
+		return lrResponse(brokerID, n, "Lorry receipt "+n.Number)
+
+	case "amendLR":
+		if err := org.Default.Check(memberID, org.PermManageTrips); err != nil {
+			return actionError(err)
+		}
+		reason, _ := req.Data["reason"].(string)
+		raw, _ := req.Data["changes"].(map[string]interface{})
+		changes := map[string]string{}
+		for field, value := range raw {
+			changes[field] = fmt.Sprint(value)
+		}
+		n, err := consignment.Default.Amend(n.TripID, consignment.Amendment{
+			Changes: changes,
+			Reason:  reason,
+			Actor:   trip.ActorBroker,
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return lrResponse(brokerID, n, fmt.Sprintf("Lorry receipt amended, now version %d", n.Version))
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
+
+func lrResponse(brokerID string, n consignment.Note, message string) bff.ActionResponse {
+	var links []map[string]interface{}
+	for _, l := range consignment.Default.Links(n.Number) {
+		links = append(links, map[string]interface{}{
+			"role":  l.Role,
+			"phone": l.Phone,
+			"url":   l.URL(),
+		})
+	}
+	var versions []map[string]interface{}
+	for _, v := range consignment.Default.Versions(n.Number) {
+		versions = append(versions, map[string]interface{}{
+			"version":   v.Version,
+			"status":    v.Status,
+			"reason":    v.Reason,
+			"amendedBy": v.AmendedBy,
+			"at":        v.At,
+			"url":       docstore.URL(v.DocumentID, brokerID),
+		})
+	}
+
+	return bff.ActionResponse{
+		Status:  "success",
+		Message: message,
+		Data: map[string]interface{}{
+			"lr":         n,
+			"url":        docstore.URL(n.DocumentID, brokerID),
+			"shareLinks": links,
+			"versions":   versions,
+		},
+	}
+}
@@ -0,0 +1,254 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/geo"
+	"backend/bff/routing"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"github.com/gin-gonic/gin"
+	"math"
+)
+
+// Seconds between map refreshes while a trip is moving
+const mapRefreshSeconds = 30
+
+func MapScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	t, err := trip.Default.Get(c.Param("id"))
+	if err != nil || t.BrokerID != brokerID(c) {
+		c.JSON(404, bff.ScreenResponse{
+			Status:  "error",
+			Screen:  "tripMap",
+			Message: "Trip not found",
+		})
+		return
+	}
+
+	details := liveDetails(t)
+	planned := routing.Default.PlannedRoute(t)
+	trail := tracking.Default.Track(t.ID)
+	latest, moving := tracking.Default.Latest(t.ID)
+
+	// Every point on the map goes into the region so nothing is cut off
+	all := append([]geo.Coord(nil), planned...)
+	for _, p := range trail {
+		all = append(all, p.Coord())
+	}
+
+	mapLayers := []bff.UISnippet{
+		createMapRegion(all),
+		createPolyline("planned", planned, "#9E9E9E", 3, []int{10, 6}),
+		createPolyline("trail", trailCoords(trail), "#ff0000", 4, nil),
+		createMarker("pickup", t.Pickup, "Pickup", details["originCity"], "location-on", "#4CAF50", 0),
+		createMarker("drop", t.Drop, "Drop", details["destinationCity"], "flag", "#2196F3", 0),
+	}
+	if moving {
+		mapLayers = append(mapLayers, createMarker("truck", latest.Coord(), details["vehicleNumber"], details["currentLocation"], "local-shipping", "#ff0000", latest.Heading))
+	}
+
+	ui := []bff.UISnippet{
+		// Main Container View
+		{
+			Type: "View",
+			Data: bff.ViewData{
+				Flex:            1,
+				BackgroundColor: "#FFFFFF",
+			},
+			Children: []bff.UISnippet{
+				// Header
+				{
+					Type: "View",
+					Data: bff.ViewData{
+						FlexDirection:     "row",
+						JustifyContent:    "space-between",
+						AlignItems:        "center",
+						PaddingHorizontal: 20,
+						PaddingTop:        10,
+						PaddingBottom:     15,
+						BorderBottomWidth: 1,
+						BorderColor:       "#F0F0F0",
+					},
+					Children: []bff.UISnippet{
+						{
+							Type: "View",
+							Data: bff.ViewData{},
+							Children: []bff.UISnippet{
+								{
+									Type: "Text",
+									Data: bff.TextData{
+										Text:       t.ID,
+										FontSize:   20,
+										FontWeight: "700",
+										Color:      "#333",
+									},
+								},
+								{
+									Type: "Text",
+									Data: bff.TextData{
+										Text:     details["originCity"] + " → " + details["destinationCity"],
+										FontSize: 14,
+										Color:    "#666",
+									},
+								},
+							},
+						},
+						{
+							Type: "TouchableOpacity",
+							Data: bff.TouchableOpacityData{
+								Style: bff.ViewData{
+									Padding: 5,
+								},
+								OnPress: bff.ActionData{
+									Type: "navigate",
+									To:   "/refresh",
+								},
+							},
+							Children: []bff.UISnippet{
+								{
+									Type: "Icon",
+									Data: bff.IconData{
+										Name:  "refresh",
+										Size:  24,
+										Color: "#333",
+									},
+								},
+							},
+						},
+					},
+				},
+				// Map
+				{
+					Type: "Map",
+					Data: bff.MapData{
+						Style: bff.ViewData{
+							Flex: 1,
+						},
+					},
+					Children: mapLayers,
+				},
+				// Trip Summary
+				{
+					Type: "View",
+					Data: bff.ViewData{
+						FlexDirection:  "row",
+						JustifyContent: "space-between",
+						Padding:        20,
+						BorderTopWidth: 1,
+						BorderColor:    "#F0F0F0",
+					},
+					Children: []bff.UISnippet{
+						createMapStat("Status", t.Status.Label()),
+						createMapStat("Distance Left", details["distanceLeft"]),
+						createMapStat("ETA", details["estimatedArrival"]),
+					},
+				},
+			},
+		},
+	}
+
+	c.JSON(200, bff.ScreenResponse{
+		Status: "success",
+		Screen: "tripMap",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"tripId":         t.ID,
+			"status":         t.Status,
+			"lastUpdated":    details["lastUpdated"],
+			"refreshSeconds": mapRefreshSeconds,
+		},
+	})
+}
+
+// Helper function to create a map region framing all coordinates with some padding
+func createMapRegion(coords []geo.Coord) bff.UISnippet {
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLng, maxLng := math.Inf(1), math.Inf(-1)
+	for _, c := range coords {
+		minLat, maxLat = math.Min(minLat, c.Lat), math.Max(maxLat, c.Lat)
+		minLng, maxLng = math.Min(minLng, c.Lng), math.Max(maxLng, c.Lng)
+	}
+
+	return bff.UISnippet{
+		Type: "Region",
+		Data: bff.RegionData{
+			Latitude:       (minLat + maxLat) / 2,
+			Longitude:      (minLng + maxLng) / 2,
+			LatitudeDelta:  math.Max((maxLat-minLat)*1.2, 0.05),
+			LongitudeDelta: math.Max((maxLng-minLng)*1.2, 0.05),
+		},
+	}
+}
+
+// Helper function to create a polyline layer
+func createPolyline(id string, coords []geo.Coord, color string, width int, dash []int) bff.UISnippet {
+	points := make([]bff.LatLng, 0, len(coords))
+	for _, c := range coords {
+		points = append(points, bff.LatLng{Latitude: c.Lat, Longitude: c.Lng})
+	}
+
+	return bff.UISnippet{
+		Type: "Polyline",
+		Data: bff.PolylineData{
+			ID:              id,
+			Coordinates:     points,
+			StrokeColor:     color,
+			StrokeWidth:     width,
+			LineDashPattern: dash,
+		},
+	}
+}
+
+// Helper function to create a map marker
+func createMarker(id string, at geo.Coord, title, description, icon, color string, rotation float64) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "Marker",
+		Data: bff.MarkerData{
+			ID:          id,
+			Coordinate:  bff.LatLng{Latitude: at.Lat, Longitude: at.Lng},
+			Title:       title,
+			Description: description,
+			Icon:        icon,
+			PinColor:    color,
+			Rotation:    rotation,
+		},
+	}
+}
+
+// Helper function to create a stat below the map
+func createMapStat(label, value string) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{
+			AlignItems: "center",
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{
+					Text:     label,
+					FontSize: 12,
+					Color:    "#999",
+				},
+			},
+			{
+				Type: "Text",
+				Data: bff.TextData{
+					Text:       value,
+					FontSize:   14,
+					FontWeight: "600",
+					Color:      "#333",
+				},
+			},
+		},
+	}
+}
+
+func trailCoords(points []tracking.Point) []geo.Coord {
+	coords := make([]geo.Coord, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, p.Coord())
+	}
+	return coords
+}
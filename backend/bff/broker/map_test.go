@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+)
+
+// snippet decodes a UI tree leaving each node's data for the caller
+type snippet struct {
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data"`
+	Children []snippet       `json:"children"`
+}
+
+func findSnippet(ui []snippet, typ string) (snippet, bool) {
+	for _, s := range ui {
+		if s.Type == typ {
+			return s, true
+		}
+		if found, ok := findSnippet(s.Children, typ); ok {
+			return found, true
+		}
+	}
+	return snippet{}, false
+}
+
+func TestMapScreen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/bff/broker/map/:id", MapScreen)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{name: "own trip", url: "/bff/broker/map/TRIP-003", wantCode: 200},
+		{name: "another broker's trip", url: "/bff/broker/map/TRIP%234498", wantCode: 404},
+		{name: "unknown trip", url: "/bff/broker/map/NOPE", wantCode: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.url+"?brokerId="+trip.DemoBrokerID, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMapScreenLayers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/bff/broker/map/:id", MapScreen)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/bff/broker/map/TRIP-003", nil))
+	var res struct {
+		UI []snippet `json:"ui"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	m, ok := findSnippet(res.UI, "Map")
+	if !ok {
+		t.Fatal("screen has no map")
+	}
+
+	var region bff.RegionData
+	lines := map[string]bff.PolylineData{}
+	markers := map[string]bff.MarkerData{}
+	for _, layer := range m.Children {
+		switch layer.Type {
+		case "Region":
+			json.Unmarshal(layer.Data, &region)
+		case "Polyline":
+			var p bff.PolylineData
+			json.Unmarshal(layer.Data, &p)
+			lines[p.ID] = p
+		case "Marker":
+			var mk bff.MarkerData
+			json.Unmarshal(layer.Data, &mk)
+			markers[mk.ID] = mk
+		}
+	}
+
+	trail := tracking.Default.Track("TRIP-003")
+	if got := len(lines["trail"].Coordinates); got != len(trail) {
+		t.Errorf("trail has %d points, want %d", got, len(trail))
+	}
+	if len(lines["planned"].Coordinates) < 2 {
+		t.Errorf("planned route = %+v", lines["planned"])
+	}
+	for _, id := range []string{"pickup", "drop", "truck"} {
+		if _, ok := markers[id]; !ok {
+			t.Errorf("no %s marker", id)
+		}
+	}
+	last := trail[len(trail)-1]
+	if truck := markers["truck"].Coordinate; truck.Latitude != last.Lat || truck.Longitude != last.Lng {
+		t.Errorf("truck at %+v, want the latest point %v", truck, last.Coord())
+	}
+
+	// everything drawn sits inside the framed region
+	for _, line := range lines {
+		for _, p := range line.Coordinates {
+			if d := p.Latitude - region.Latitude; d > region.LatitudeDelta/2 || -d > region.LatitudeDelta/2 {
+				t.Errorf("%s point %+v is outside the region %+v", line.ID, p, region)
+			}
+			if d := p.Longitude - region.Longitude; d > region.LongitudeDelta/2 || -d > region.LongitudeDelta/2 {
+				t.Errorf("%s point %+v is outside the region %+v", line.ID, p, region)
+			}
+		}
+	}
+}
@@ -2,12 +2,30 @@ package broker
 
 import (
 	"backend/bff"
+	"backend/bff/calls"
+	"backend/bff/kpi"
+	"backend/bff/settlement"
+	"fmt"
 	"github.com/gin-gonic/gin"
 )
 
 func MoneyScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	var paymentCards []bff.UISnippet
+	var paymentList []map[string]interface{}
+	for _, p := range settlement.Default.ForBroker(brokerID(c)) {
+		paymentCards = append(paymentCards, createPaymentCard(p.ID, p.DriverName, calls.Redact(p.DriverPhone), p.TruckNumber, p.Gross.String(), p.Status(), p.POD.Label(), paymentTripDetails(p)))
+		paymentList = append(paymentList, paymentData(p))
+	}
+
+	period := kpi.ParsePeriod(c.Query("period"))
+	stats := kpi.Default.Snapshot(brokerID(c), period)
+	pendingCount := paymentCount(stats.PendingPayments)
+	completedCount := paymentCount(stats.CompletedPayments)
+	paidTitle := "Total Paid This " + periodTitle(period)
+	paidChange := stats.PaidVolume.Label(period)
+
 	ui := []bff.UISnippet{
 		// Main Container
 		{
@@ -67,11 +85,11 @@ func MoneyScreen(c *gin.Context) {
 									},
 									Children: []bff.UISnippet{
 										// Card 1: Pending Payments
-										createSummaryCard("1", "Pending Payments", "₹45,200", "12 Payments", "clock-outline", "#ff0000"),
+										createSummaryCard("1", "Pending Payments", stats.PendingAmount.String(), pendingCount, "clock-outline", "#ff0000"),
 										// Card 2: Completed Payments
-										createSummaryCard("2", "Completed Payments", "₹1,85,500", "28 Payments", "check-circle-outline", "#28A745"),
+										createSummaryCard("2", "Completed Payments", stats.CompletedAmount.String(), completedCount, "check-circle-outline", "#28A745"),
 										// Card 3: Total Paid This Month
-										createSummaryCard("3", "Total Paid This Month", "₹2,30,700", "+15% from last month", "calendar-month", "#1a1a1a"),
+										createSummaryCard("3", paidTitle, stats.PaidVolume.Current.String(), paidChange, "calendar-month", "#1a1a1a"),
 									},
 								},
 							},
@@ -181,80 +199,7 @@ func MoneyScreen(c *gin.Context) {
 									Data: bff.ViewData{
 										Gap: 12,
 									},
-									Children: []bff.UISnippet{
-										// Payment Card 1
-										createPaymentCard(
-											"TRK-2458",
-											"Rajesh Kumar",
-											"+91 98765 43210",
-											"MH12 AB 1234",
-											"₹18,500",
-											"Pending",
-											"Waiting",
-											map[string]string{
-												"cargoType":     "Electronics",
-												"from":          "Mumbai",
-												"to":            "Delhi",
-												"distance":      "1400 km",
-												"commission":    "₹1,850",
-												"payableAmount": "₹16,650",
-											},
-										),
-										// Payment Card 2
-										createPaymentCard(
-											"TRK-2457",
-											"Suresh Patel",
-											"+91 98765 43211",
-											"GJ01 CD 5678",
-											"₹22,300",
-											"Completed",
-											"Approved",
-											map[string]string{
-												"cargoType":     "Textiles",
-												"from":          "Ahmedabad",
-												"to":            "Chennai",
-												"distance":      "1600 km",
-												"commission":    "₹2,230",
-												"payableAmount": "₹20,070",
-											},
-										),
-										// Payment Card 3
-										createPaymentCard(
-											"TRK-2456",
-											"Vikram Singh",
-											"+91 98765 43212",
-											"DL09 EF 9012",
-											"₹15,800",
-											"Pending",
-											"Approved",
-											map[string]string{
-												"cargoType":     "Automobile Parts",
-												"from":          "Pune",
-												"to":            "Bangalore",
-												"distance":      "850 km",
-												"commission":    "₹1,580",
-												"payableAmount": "₹14,220",
-											},
-										),
-										// Payment Card 4
-										createPaymentCard(
-											"TRK-2455",
-											"Anil Sharma",
-											"+91 98765 43213",
-											"KA05 GH 3456",
-											"₹28,900",
-											"Completed",
-											"Approved",
-											map[string]string{
-												"cargoType":     "Machinery",
-												"from":          "Chennai",
-												"to":            "Kolkata",
-												"distance":      "1700 km",
-												"commission":    "₹2,890",
-												"payableAmount": "₹26,010",
-											},
-										),
-									},
+									Children: paymentCards,
 								},
 							},
 						},
@@ -341,100 +286,32 @@ func MoneyScreen(c *gin.Context) {
 				{
 					"id":     "1",
 					"title":  "Pending Payments",
-					"amount": "₹45,200",
-					"count":  "12 Payments",
+					"amount": stats.PendingAmount.String(),
+					"count":  pendingCount,
 					"icon":   "clock-outline",
 					"color":  "#ff0000",
 				},
 				{
 					"id":     "2",
 					"title":  "Completed Payments",
-					"amount": "₹1,85,500",
-					"count":  "28 Payments",
+					"amount": stats.CompletedAmount.String(),
+					"count":  completedCount,
 					"icon":   "check-circle-outline",
 					"color":  "#28A745",
 				},
 				{
 					"id":     "3",
-					"title":  "Total Paid This Month",
-					"amount": "₹2,30,700",
-					"count":  "+15% from last month",
+					"title":  paidTitle,
+					"amount": stats.PaidVolume.Current.String(),
+					"count":  paidChange,
 					"icon":   "calendar-month",
 					"color":  "#1a1a1a",
 				},
 			},
+			"kpis": stats,
 			"filters": []string{"All", "Pending", "Done"},
 			"selectedFilter": "All",
-			"payments": []map[string]interface{}{
-				{
-					"id":         "TRK-2458",
-					"driverName": "Rajesh Kumar",
-					"phone":      "+91 98765 43210",
-					"truckNumber": "MH12 AB 1234",
-					"amount":     "₹18,500",
-					"status":     "Pending",
-					"podStatus":  "Waiting",
-					"tripDetails": map[string]string{
-						"cargoType":     "Electronics",
-						"from":          "Mumbai",
-						"to":            "Delhi",
-						"distance":      "1400 km",
-						"commission":    "₹1,850",
-						"payableAmount": "₹16,650",
-					},
-				},
-				{
-					"id":         "TRK-2457",
-					"driverName": "Suresh Patel",
-					"phone":      "+91 98765 43211",
-					"truckNumber": "GJ01 CD 5678",
-					"amount":     "₹22,300",
-					"status":     "Completed",
-					"podStatus":  "Approved",
-					"tripDetails": map[string]string{
-						"cargoType":     "Textiles",
-						"from":          "Ahmedabad",
-						"to":            "Chennai",
-						"distance":      "1600 km",
-						"commission":    "₹2,230",
-						"payableAmount": "₹20,070",
-					},
-				},
-				{
-					"id":         "TRK-2456",
-					"driverName": "Vikram Singh",
-					"phone":      "+91 98765 43212",
-					"truckNumber": "DL09 EF 9012",
-					"amount":     "₹15,800",
-					"status":     "Pending",
-					"podStatus":  "Approved",
-					"tripDetails": map[string]string{
-						"cargoType":     "Automobile Parts",
-						"from":          "Pune",
-						"to":            "Bangalore",
-						"distance":      "850 km",
-						"commission":    "₹1,580",
-						"payableAmount": "₹14,220",
-					},
-				},
-				{
-					"id":         "TRK-2455",
-					"driverName": "Anil Sharma",
-					"phone":      "+91 98765 43213",
-					"truckNumber": "KA05 GH 3456",
-					"amount":     "₹28,900",
-					"status":     "Completed",
-					"podStatus":  "Approved",
-					"tripDetails": map[string]string{
-						"cargoType":     "Machinery",
-						"from":          "Chennai",
-						"to":            "Kolkata",
-						"distance":      "1700 km",
-						"commission":    "₹2,890",
-						"payableAmount": "₹26,010",
-					},
-				},
-			},
+			"payments": paymentList,
 			"statusStyles": map[string]map[string]string{
 				"Pending": {
 					"bgColor": "#FFE6E6",
@@ -461,6 +338,22 @@ func MoneyScreen(c *gin.Context) {
 	c.JSON(200, response)
 }
 
+// Helper function to title a KPI period ("Month")
+func periodTitle(p kpi.Period) string {
+	if p == kpi.Week {
+		return "Week"
+	}
+	return "Month"
+}
+
+// Helper function to count payments on a summary card ("12 Payments")
+func paymentCount(n int) string {
+	if n == 1 {
+		return "1 Payment"
+	}
+	return fmt.Sprintf("%d Payments", n)
+}
+
 // Helper function to create summary card
 func createSummaryCard(id, title, amount, count, icon, color string) bff.UISnippet {
 	return bff.UISnippet{
@@ -816,6 +709,15 @@ func PaymentDetailScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
 	paymentId := c.Query("paymentId")
+	p, err := settlement.Default.Get(paymentId)
+	if err != nil || p.BrokerID != brokerID(c) {
+		c.JSON(404, bff.ScreenResponse{
+			Status:  "error",
+			Screen:  "paymentDetail",
+			Message: "Payment not found",
+		})
+		return
+	}
 
 	ui := []bff.UISnippet{
 		{
@@ -893,10 +795,10 @@ func PaymentDetailScreen(c *gin.Context) {
 									Data: bff.ViewData{
 										Padding: 20,
 									},
-									Children: createPaymentDetailContent(paymentId),
+									Children: append(createPaymentDetailContent(p), createRatingSection(p.TripID, p.BrokerID)...),
 								},
 								// Modal Footer
-								createPaymentDetailFooter(paymentId),
+								createPaymentDetailFooter(p),
 							},
 						},
 					},
@@ -911,6 +813,7 @@ func PaymentDetailScreen(c *gin.Context) {
 		UI:     ui,
 		Data: map[string]interface{}{
 			"paymentId": paymentId,
+			"payment":   paymentData(p),
 		},
 	}
 
@@ -918,7 +821,36 @@ func PaymentDetailScreen(c *gin.Context) {
 }
 
 // Helper function to create payment detail content
-func createPaymentDetailContent(paymentId string) []bff.UISnippet {
+func createPaymentDetailContent(p settlement.Payment) []bff.UISnippet {
+	breakdown := []bff.UISnippet{
+		createBreakdownRow("Trip Amount", p.Gross.String(), false),
+		createBreakdownRow("Commission", p.Commission.String(), false),
+	}
+	if p.Advance > 0 {
+		breakdown = append(breakdown, createBreakdownRow("Advance Paid", p.Advance.String(), false))
+	}
+	for _, r := range p.Releases {
+		breakdown = append(breakdown, createBreakdownRow("Paid "+r.At.Format("2 Jan"), r.Amount.String(), false))
+		for _, d := range r.Deductions {
+			breakdown = append(breakdown, createBreakdownRow("Deduction: "+d.Reason, d.Amount.String(), false))
+		}
+	}
+	breakdown = append(breakdown,
+		bff.UISnippet{
+			Type: "View",
+			Data: bff.ViewData{
+				Height:          1,
+				BackgroundColor: "#E5E5E5",
+			},
+		},
+		createBreakdownRow("Balance Due", p.Balance().String(), true),
+	)
+
+	podText := "POD: " + p.POD.Label()
+	if p.PODNote != "" {
+		podText += " • " + p.PODNote
+	}
+
 	return []bff.UISnippet{
 		// Trip Summary Section
 		{
@@ -930,10 +862,10 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 				{
 					Type: "Text",
 					Data: bff.TextData{
-						Text:      "Trip Summary",
-						FontSize:  18,
-						FontWeight: "bold",
-						Color:     "#1a1a1a",
+						Text:         "Trip Summary",
+						FontSize:     18,
+						FontWeight:   "bold",
+						Color:        "#1a1a1a",
 						MarginBottom: 12,
 					},
 				},
@@ -949,19 +881,19 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 						{
 							Type: "Text",
 							Data: bff.TextData{
-								Text:      "Trip ID: " + paymentId,
-								FontSize:  16,
+								Text:       "Trip ID: " + p.ID,
+								FontSize:   16,
 								FontWeight: "600",
-								Color:     "#1a1a1a",
+								Color:      "#1a1a1a",
 							},
 						},
 						{
 							Type: "Text",
 							Data: bff.TextData{
-								Text:      "Cargo: Electronics",
-								FontSize:  16,
+								Text:       "Cargo: " + p.Cargo,
+								FontSize:   16,
 								FontWeight: "600",
-								Color:     "#1a1a1a",
+								Color:      "#1a1a1a",
 							},
 						},
 						{
@@ -983,9 +915,9 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 								{
 									Type: "Text",
 									Data: bff.TextData{
-										Text:      "Mumbai → Delhi",
-										FontSize:  14,
-										Color:     "#666",
+										Text:       p.From + " → " + p.To,
+										FontSize:   14,
+										Color:      "#666",
 										FontWeight: "500",
 									},
 								},
@@ -994,9 +926,18 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 						{
 							Type: "Text",
 							Data: bff.TextData{
-								Text:      "1400 km",
-								FontSize:  14,
-								Color:     "#666",
+								Text:     p.Distance,
+								FontSize: 14,
+								Color:    "#666",
+							},
+						},
+						{
+							Type: "Text",
+							Data: bff.TextData{
+								Text:       podText,
+								FontSize:   14,
+								FontWeight: "600",
+								Color:      "#1a1a1a",
 							},
 						},
 					},
@@ -1013,10 +954,10 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 				{
 					Type: "Text",
 					Data: bff.TextData{
-						Text:      "Payment Breakdown",
-						FontSize:  18,
-						FontWeight: "bold",
-						Color:     "#1a1a1a",
+						Text:         "Payment Breakdown",
+						FontSize:     18,
+						FontWeight:   "bold",
+						Color:        "#1a1a1a",
 						MarginBottom: 12,
 					},
 				},
@@ -1028,113 +969,65 @@ func createPaymentDetailContent(paymentId string) []bff.UISnippet {
 						Padding:         16,
 						Gap:             12,
 					},
-					Children: []bff.UISnippet{
-						{
-							Type: "View",
-							Data: bff.ViewData{
-								FlexDirection: "row",
-								JustifyContent: "space-between",
-								AlignItems:    "center",
-							},
-							Children: []bff.UISnippet{
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "Trip Amount",
-										FontSize:  14,
-										Color:     "#666",
-									},
-								},
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "₹18,500",
-										FontSize:  14,
-										FontWeight: "600",
-										Color:     "#1a1a1a",
-									},
-								},
-							},
-						},
-						{
-							Type: "View",
-							Data: bff.ViewData{
-								FlexDirection: "row",
-								JustifyContent: "space-between",
-								AlignItems:    "center",
-							},
-							Children: []bff.UISnippet{
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "Commission",
-										FontSize:  14,
-										Color:     "#666",
-									},
-								},
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "₹1,850",
-										FontSize:  14,
-										FontWeight: "600",
-										Color:     "#1a1a1a",
-									},
-								},
-							},
-						},
-						{
-							Type: "View",
-							Data: bff.ViewData{
-								Height:           1,
-								BackgroundColor: "#E5E5E5",
-							},
-						},
-						{
-							Type: "View",
-							Data: bff.ViewData{
-								FlexDirection: "row",
-								JustifyContent: "space-between",
-								AlignItems:    "center",
-							},
-							Children: []bff.UISnippet{
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "Final Payable Amount",
-										FontSize:  16,
-										FontWeight: "bold",
-										Color:     "#1a1a1a",
-									},
-								},
-								{
-									Type: "Text",
-									Data: bff.TextData{
-										Text:      "₹16,650",
-										FontSize:  18,
-										FontWeight: "bold",
-										Color:     "#ff0000",
-									},
-								},
-							},
-						},
-					},
+					Children: breakdown,
 				},
 			},
 		},
 	}
 }
 
+// Helper function to create a label and amount row in the payment breakdown
+func createBreakdownRow(label, value string, total bool) bff.UISnippet {
+	labelData := bff.TextData{
+		Text:     label,
+		FontSize: 14,
+		Color:    "#666",
+	}
+	valueData := bff.TextData{
+		Text:       value,
+		FontSize:   14,
+		FontWeight: "600",
+		Color:      "#1a1a1a",
+	}
+	if total {
+		labelData = bff.TextData{
+			Text:       label,
+			FontSize:   16,
+			FontWeight: "bold",
+			Color:      "#1a1a1a",
+		}
+		valueData = bff.TextData{
+			Text:       value,
+			FontSize:   18,
+			FontWeight: "bold",
+			Color:      "#ff0000",
+		}
+	}
+
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{
+			FlexDirection:  "row",
+			JustifyContent: "space-between",
+			AlignItems:     "center",
+		},
+		Children: []bff.UISnippet{
+			{Type: "Text", Data: labelData},
+			{Type: "Text", Data: valueData},
+		},
+	}
+}
+
 // Helper function to create payment detail footer
-func createPaymentDetailFooter(paymentId string) bff.UISnippet {
+func createPaymentDetailFooter(p settlement.Payment) bff.UISnippet {
 	return bff.UISnippet{
 		Type: "View",
 		Data: bff.ViewData{
-			FlexDirection: "row",
-			Padding:       20,
-			Gap:           12,
+			FlexDirection:  "row",
+			Padding:        20,
+			Gap:            12,
 			BorderTopWidth: 1,
-			BorderColor:   "#f0f0f0",
+			BorderColor:    "#f0f0f0",
 		},
 		Children: []bff.UISnippet{
 			// Download Invoice Button
@@ -1142,24 +1035,19 @@ func createPaymentDetailFooter(paymentId string) bff.UISnippet {
 				Type: "TouchableOpacity",
 				Data: bff.TouchableOpacityData{
 					Style: bff.ViewData{
-						Flex:             1,
-						FlexDirection:   "row",
-						BackgroundColor: "#fff",
-						PaddingVertical: 16,
+						Flex:              1,
+						FlexDirection:     "row",
+						BackgroundColor:   "#fff",
+						PaddingVertical:   16,
 						PaddingHorizontal: 12,
-						BorderRadius:    12,
-						AlignItems:      "center",
-						JustifyContent:  "center",
-						BorderWidth:     1,
-						BorderColor:     "#ff0000",
-						Gap:             2,
-					},
-					OnPress: bff.ActionData{
-						Type: "downloadInvoice",
-						Data: map[string]interface{}{
-							"paymentId": paymentId,
-						},
+						BorderRadius:      12,
+						AlignItems:        "center",
+						JustifyContent:    "center",
+						BorderWidth:       1,
+						BorderColor:       "#ff0000",
+						Gap:               2,
 					},
+					OnPress: moneyAction("downloadInvoice", p.ID),
 				},
 				Children: []bff.UISnippet{
 					{
@@ -1173,55 +1061,109 @@ func createPaymentDetailFooter(paymentId string) bff.UISnippet {
 					{
 						Type: "Text",
 						Data: bff.TextData{
-							Text:      "Download Invoice",
-							Color:     "#ff0000",
-							FontSize:  16,
+							Text:       "Download Invoice",
+							Color:      "#ff0000",
+							FontSize:   16,
 							FontWeight: "600",
 						},
 					},
 				},
 			},
-			// Make Payment Button
+			createPaymentPrimaryButton(p),
+		},
+	}
+}
+
+// Helper function to create the footer button for the next settlement step
+func createPaymentPrimaryButton(p settlement.Payment) bff.UISnippet {
+	icon, label, color := "credit-card", "Make Payment", "#ff0000"
+	var action bff.ActionData
+	switch {
+	case p.Balance() <= 0:
+		icon, label, color = "check-circle", "Paid", "#28A745"
+	case p.POD == settlement.PODSubmitted:
+		icon, label = "file-check", "Approve POD"
+		action = moneyAction("approvePOD", p.ID)
+	case p.POD != settlement.PODApproved:
+		icon, label, color = "clock-outline", "Awaiting POD", "#999"
+	default:
+		action = moneyAction("makePayment", p.ID)
+	}
+
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				Flex:            1,
+				FlexDirection:   "row",
+				BackgroundColor: color,
+				PaddingVertical: 16,
+				BorderRadius:    12,
+				AlignItems:      "center",
+				JustifyContent:  "center",
+				Gap:             8,
+			},
+			OnPress: action,
+		},
+		Children: []bff.UISnippet{
 			{
-				Type: "TouchableOpacity",
-				Data: bff.TouchableOpacityData{
-					Style: bff.ViewData{
-						Flex:             1,
-						FlexDirection:   "row",
-						BackgroundColor: "#ff0000",
-						PaddingVertical: 16,
-						BorderRadius:    12,
-						AlignItems:      "center",
-						JustifyContent:  "center",
-						Gap:             8,
-					},
-					OnPress: bff.ActionData{
-						Type: "makePayment",
-						Data: map[string]interface{}{
-							"paymentId": paymentId,
-						},
-					},
+				Type: "Icon",
+				Data: bff.IconData{
+					Name:  icon,
+					Size:  20,
+					Color: "#fff",
 				},
-				Children: []bff.UISnippet{
-					{
-						Type: "Icon",
-						Data: bff.IconData{
-							Name:  "credit-card",
-							Size:  20,
-							Color: "#fff",
-						},
-					},
-					{
-						Type: "Text",
-						Data: bff.TextData{
-							Text:      "Make Payment",
-							Color:     "#fff",
-							FontSize:  16,
-							FontWeight: "600",
-						},
-					},
+			},
+			{
+				Type: "Text",
+				Data: bff.TextData{
+					Text:       label,
+					Color:      "#fff",
+					FontSize:   16,
+					FontWeight: "600",
 				},
 			},
 		},
 	}
-}
\ No newline at end of file
+}
+
+// Helper function to create an action posted to the money action endpoint
+func moneyAction(action, paymentId string) bff.ActionData {
+	return bff.ActionData{
+		Type:   action,
+		Url:    "/bff/broker/money/action",
+		Method: "POST",
+		Data: map[string]interface{}{
+			"paymentId": paymentId,
+		},
+	}
+}
+
+// Helper function to build the trip details shown on a payment card
+func paymentTripDetails(p settlement.Payment) map[string]string {
+	return map[string]string{
+		"cargoType":     p.Cargo,
+		"from":          p.From,
+		"to":            p.To,
+		"distance":      p.Distance,
+		"commission":    p.Commission.String(),
+		"payableAmount": p.Payable().String(),
+	}
+}
+
+// Helper function to build the data payload for a payment
+func paymentData(p settlement.Payment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          p.ID,
+		"tripId":      p.TripID,
+		"driverName":  p.DriverName,
+		"phone":       calls.Redact(p.DriverPhone),
+		"truckNumber": p.TruckNumber,
+		"amount":      p.Gross.String(),
+		"status":      p.Status(),
+		"podStatus":   p.POD.Label(),
+		"balanceDue":  p.Balance().String(),
+		"tripDetails": paymentTripDetails(p),
+		"releases":    p.Releases,
+	}
+}
@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/invoice"
+	"backend/bff/ledger"
+	"backend/bff/org"
+	"backend/bff/payments"
+	"backend/bff/settlement"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleMoneyAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleMoneyAction(brokerID(c), memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleMoneyAction(brokerID, memberID string, req bff.ActionRequest) bff.ActionResponse {
+	if req.Action == "addFunds" {
+		if err := org.Default.Check(memberID, org.PermAddFunds); err != nil {
+			return actionError(err)
+		}
+		amount, _ := req.Data["amount"].(float64)
+		method, _ := req.Data["method"].(string)
+		account, _ := req.Data["account"].(string)
+
+		t, err := payments.Default.Collect(brokerID, ledger.FromRupees(amount), payments.Method(method), account)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: fmt.Sprintf("Collection of %s is %s", t.Amount, t.State),
+			Data:    t,
+		}
+	}
+
+	paymentId, _ := req.Data["paymentId"].(string)
+	p, err := settlement.Default.Get(paymentId)
+	if err != nil || p.BrokerID != brokerID {
+		return actionError(settlement.ErrNotFound)
+	}
+
+	switch req.Action {
+	case "approvePOD":
+		if err := org.Default.Check(memberID, org.PermReviewPOD); err != nil {
+			return actionError(err)
+		}
+		p, err := settlement.Default.ApprovePOD(p.ID)
+		if err != nil {
+			return actionError(err)
+		}
+		return paymentResponse(p, "POD approved, balance payment unlocked")
+
+	case "rejectPOD":
+		if err := org.Default.Check(memberID, org.PermReviewPOD); err != nil {
+			return actionError(err)
+		}
+		reason, _ := req.Data["reason"].(string)
+		p, err := settlement.Default.RejectPOD(p.ID, reason)
+		if err != nil {
+			return actionError(err)
+		}
+		return paymentResponse(p, "POD rejected, driver asked to upload again")
+
+	case "makePayment":
+		if err := org.Default.Check(memberID, org.PermReleasePayment); err != nil {
+			return actionError(err)
+		}
+		amount, _ := req.Data["amount"].(float64)
+		note, _ := req.Data["note"].(string)
+		p, rel, err := settlement.Default.Release(p.ID, settlement.ReleaseRequest{
+			Amount:     ledger.FromRupees(amount),
+			Deductions: deductions(req.Data["deductions"]),
+			Note:       note,
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return paymentResponse(p, fmt.Sprintf("%s released to %s", rel.Amount, p.DriverName))
+
+	case "downloadInvoice":
+		doc, inv, err := invoice.Default.Invoice(p.ID)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Invoice " + inv.Number,
+			Data: map[string]interface{}{
+				"paymentId":     p.ID,
+				"invoiceNumber": inv.Number,
+				"total":         inv.Total.String(),
+				"documentId":    doc.ID,
+				"url":           doc.URL(brokerID),
+			},
+		}
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
+
+// Helper function to read deductions sent as [{"reason": "...", "amount": 500}]
+func deductions(raw interface{}) []settlement.Deduction {
+	list, _ := raw.([]interface{})
+	var out []settlement.Deduction
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		reason, _ := m["reason"].(string)
+		amount, _ := m["amount"].(float64)
+		out = append(out, settlement.Deduction{Reason: reason, Amount: ledger.FromRupees(amount)})
+	}
+	return out
+}
+
+func paymentResponse(p settlement.Payment, message string) bff.ActionResponse {
+	return bff.ActionResponse{
+		Status:  "success",
+		Message: message,
+		Data:    paymentData(p),
+	}
+}
@@ -0,0 +1,213 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/notify"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func NotificationsScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	broker := brokerID(c)
+	alerts := notify.Default.List(broker)
+	unread := notify.Default.Unread(broker)
+	actionURL := "/bff/notifications/action?userId=" + broker
+
+	var items []bff.UISnippet
+	for _, a := range alerts {
+		items = append(items, createNotificationItem(a, actionURL))
+	}
+	if len(items) == 0 {
+		items = append(items, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: "No notifications yet", FontSize: 14, Color: "#999", TextAlign: "center"},
+		})
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "View",
+			Data: bff.ViewData{
+				Flex:            1,
+				BackgroundColor: "#FFFFFF",
+			},
+			Children: []bff.UISnippet{
+				// Header
+				{
+					Type: "View",
+					Data: bff.ViewData{
+						FlexDirection:     "row",
+						JustifyContent:    "space-between",
+						AlignItems:        "center",
+						PaddingHorizontal: 20,
+						PaddingVertical:   16,
+						BorderBottomWidth: 1,
+						BorderColor:       "#F0F0F0",
+					},
+					Children: []bff.UISnippet{
+						{
+							Type: "Text",
+							Data: bff.TextData{
+								Text:       fmt.Sprintf("Notifications (%d)", unread),
+								FontSize:   22,
+								FontWeight: "bold",
+								Color:      "#1A1A1A",
+							},
+						},
+						{
+							Type: "TouchableOpacity",
+							Data: bff.TouchableOpacityData{
+								OnPress: bff.ActionData{
+									Type:  "action",
+									Value: "markAllRead",
+									Url:   actionURL,
+								},
+							},
+							Children: []bff.UISnippet{
+								{
+									Type: "Text",
+									Data: bff.TextData{Text: "Mark all read", FontSize: 14, FontWeight: "600", Color: "#ff0000"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Type:     "ScrollView",
+					Data:     bff.ViewData{Flex: 1, Padding: 16},
+					Children: items,
+				},
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "notifications",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"unread":        unread,
+			"notifications": alerts,
+		},
+	}
+
+	c.JSON(200, response)
+}
+
+// Helper function to create a notification row that marks itself read when tapped
+func createNotificationItem(a notify.Alert, actionURL string) bff.UISnippet {
+	background := "#FFFFFF"
+	if !a.Read {
+		background = "#FFF5F5"
+	}
+
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				FlexDirection:   "row",
+				AlignItems:      "center",
+				Padding:         14,
+				MarginBottom:    10,
+				BorderRadius:    12,
+				BorderWidth:     1,
+				BorderColor:     "#F0F0F0",
+				BackgroundColor: background,
+			},
+			OnPress: bff.ActionData{
+				Type:  "action",
+				Value: "markRead",
+				Url:   actionURL,
+				Data:  map[string]interface{}{"id": a.ID, "ref": a.Ref},
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Icon",
+				Data: bff.IconData{Name: notificationIcon(a.Kind), Size: 22, Color: "#ff0000"},
+			},
+			{
+				Type: "View",
+				Data: bff.ViewData{Flex: 1, MarginLeft: 12},
+				Children: []bff.UISnippet{
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: a.Title, FontSize: 14, FontWeight: "600", Color: "#1A1A1A"},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: a.Message, FontSize: 13, Color: "#666"},
+					},
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: bff.TimeAgo(a.At), FontSize: 11, Color: "#999"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Helper function to create the header bell with the unread count
+func createNotificationBell(unread int) bff.UISnippet {
+	return createHeaderBadgeIcon("bell-outline", "/notifications", unread)
+}
+
+// Helper function to create a header icon that navigates, with a count badge
+func createHeaderBadgeIcon(icon, to string, unread int) bff.UISnippet {
+	bell := bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{Padding: 8},
+			OnPress: bff.ActionData{
+				Type: "navigate",
+				To:   to,
+			},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Icon",
+				Data: bff.IconData{Name: icon, Size: 26, Color: "#1A1A1A"},
+			},
+		},
+	}
+	if unread > 0 {
+		bell.Children = append(bell.Children, bff.UISnippet{
+			Type: "View",
+			Data: bff.ViewData{
+				Position:          "absolute",
+				Top:               2,
+				Right:             2,
+				BackgroundColor:   "#ff0000",
+				BorderRadius:      10,
+				PaddingHorizontal: 6,
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "Text",
+					Data: bff.TextData{Text: fmt.Sprint(unread), FontSize: 10, FontWeight: "bold", Color: "#FFFFFF"},
+				},
+			},
+		})
+	}
+	return bell
+}
+
+// Helper function to pick the icon for a notification kind
+func notificationIcon(kind string) string {
+	switch kind {
+	case notify.KindLoad:
+		return "package-variant"
+	case notify.KindTrip:
+		return "truck-delivery"
+	case notify.KindDocument, notify.KindPOD:
+		return "file-document"
+	case notify.KindPayment:
+		return "cash"
+	case "eway_bill":
+		return "alert-circle-outline"
+	}
+	return "bell-outline"
+}
@@ -2,6 +2,7 @@ package broker
 
 import (
 	"backend/bff"
+	"backend/bff/org"
 	"github.com/gin-gonic/gin"
 )
 
@@ -251,6 +252,9 @@ func ProfileScreen(c *gin.Context) {
 								return result
 							}(),
 						},
+
+						// Team Section
+						teamEntry(memberID(c)),
 					},
 				},
 			},
@@ -265,3 +269,33 @@ func ProfileScreen(c *gin.Context) {
 
 	c.JSON(200, response)
 }
+
+// Helper function to create the profile's team entry with the member's role
+func teamEntry(member string) bff.UISnippet {
+	text := "Invite dispatchers, accountants and viewers"
+	if o, me, err := org.Default.Of(member); err == nil {
+		text = o.Name + " · " + me.RoleText()
+	}
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				BackgroundColor: "#FAFAFA",
+				BorderRadius:    12,
+				Padding:         16,
+				MarginTop:       16,
+			},
+			OnPress: bff.ActionData{Type: "navigate", To: "/team"},
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: "Team", FontSize: 18, Color: "#1A1A1A"},
+			},
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: text, FontSize: 12, Color: "#666666"},
+			},
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/rating"
+	"backend/bff/trip"
+	"fmt"
+)
+
+// Helper function to create the rating section of a delivered trip: the
+// prompt to rate the driver, and the driver's review of the broker
+func createRatingSection(tripID, broker string) []bff.UISnippet {
+	t, err := trip.Default.Get(tripID)
+	if err != nil {
+		return nil
+	}
+	actionURL := "/bff/ratings/action?userId=" + broker
+	driver := t.Details["driverName"]
+
+	var rows []bff.UISnippet
+	if rating.Default.Due(t, broker) {
+		rows = append(rows, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: "Score " + driver + " from 1 to 5 on each", FontSize: 14, Color: "#666"},
+		})
+		for _, c := range rating.Criteria(rating.RoleBroker) {
+			rows = append(rows, bff.UISnippet{
+				Type: "View",
+				Data: bff.ViewData{FlexDirection: "row", JustifyContent: "space-between", AlignItems: "center"},
+				Children: []bff.UISnippet{
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: c.Label(), FontSize: 14, Color: "#1a1a1a"},
+					},
+					{
+						Type: "INPUT",
+						Data: bff.InputData{
+							Id:           string(c),
+							Placeholder:  "1-5",
+							KeyboardType: "number-pad",
+							MaxLength:    1,
+							FontSize:     14,
+							Style:        bff.ViewData{Width: 56, Padding: 8, BorderRadius: 8, BorderWidth: 1, BorderColor: "#E5E5E5"},
+						},
+					},
+				},
+			})
+		}
+		rows = append(rows,
+			bff.UISnippet{
+				Type: "INPUT",
+				Data: bff.InputData{
+					Id:          "comment",
+					Placeholder: "Comment (optional)",
+					MaxLength:   500,
+					FontSize:    14,
+					Style:       bff.ViewData{Padding: 12, BorderRadius: 8, BorderWidth: 1, BorderColor: "#E5E5E5"},
+				},
+			},
+			bff.UISnippet{
+				Type: "TouchableOpacity",
+				Data: bff.TouchableOpacityData{
+					Style: bff.ViewData{BackgroundColor: "#ff0000", PaddingVertical: 12, BorderRadius: 8, AlignItems: "center"},
+					OnPress: bff.ActionData{
+						Type:  "action",
+						Value: "submit",
+						Url:   actionURL,
+						Data:  map[string]interface{}{"tripId": t.ID},
+					},
+				},
+				Children: []bff.UISnippet{
+					{
+						Type: "Text",
+						Data: bff.TextData{Text: "Submit Rating", FontSize: 15, FontWeight: "600", Color: "#fff"},
+					},
+				},
+			},
+		)
+	} else if r, ok := rating.Default.Given(t.ID, broker); ok {
+		rows = append(rows, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: fmt.Sprintf("You rated %s %.1f ★", driver, r.Overall), FontSize: 14, FontWeight: "600", Color: "#1a1a1a"},
+		})
+	}
+
+	if r, ok := rating.Default.Given(t.ID, t.DriverID); ok && !r.Hidden {
+		rows = append(rows, bff.UISnippet{
+			Type: "Text",
+			Data: bff.TextData{Text: fmt.Sprintf("%s rated you %.1f ★", driver, r.Overall), FontSize: 14, FontWeight: "600", Color: "#1a1a1a"},
+		})
+		if r.Comment != "" {
+			rows = append(rows,
+				bff.UISnippet{
+					Type: "Text",
+					Data: bff.TextData{Text: "“" + r.Comment + "”", FontSize: 14, Color: "#666"},
+				},
+				bff.UISnippet{
+					Type: "TouchableOpacity",
+					Data: bff.TouchableOpacityData{
+						OnPress: bff.ActionData{
+							Type:  "action",
+							Value: "report",
+							Url:   actionURL,
+							Data:  map[string]interface{}{"reviewId": r.ID},
+						},
+					},
+					Children: []bff.UISnippet{
+						{
+							Type: "Text",
+							Data: bff.TextData{Text: "Report this review", FontSize: 13, Color: "#ff0000"},
+						},
+					},
+				},
+			)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return []bff.UISnippet{{
+		Type: "View",
+		Data: bff.ViewData{
+			MarginBottom: 24,
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{
+					Text:         "Trip Rating",
+					FontSize:     18,
+					FontWeight:   "bold",
+					Color:        "#1a1a1a",
+					MarginBottom: 12,
+				},
+			},
+			{
+				Type: "View",
+				Data: bff.ViewData{
+					BackgroundColor: "#f8f9fa",
+					BorderRadius:    12,
+					Padding:         16,
+					Gap:             12,
+				},
+				Children: rows,
+			},
+		},
+	}}
+}
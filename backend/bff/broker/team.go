@@ -0,0 +1,253 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/org"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func TeamScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	member := memberID(c)
+	o, me, err := org.Default.Of(member)
+	if err != nil {
+		// not in a team yet: offer the invitations sent to the signed-in number
+		invites := org.Default.InvitesFor(org.Default.Phone(member))
+		var cards []bff.UISnippet
+		for _, i := range invites {
+			cards = append(cards, createInvitationCard(i))
+		}
+		if len(cards) == 0 {
+			cards = append(cards, bff.UISnippet{
+				Type: "Text",
+				Data: bff.TextData{Text: "You are not part of a team. Ask your company owner to invite your number.", FontSize: 14, Color: "#666"},
+			})
+		}
+		c.JSON(200, bff.ScreenResponse{
+			Status: "success",
+			Screen: "Team",
+			UI:     []bff.UISnippet{createTeamPage("Team", "Invitations", cards)},
+			Data:   map[string]interface{}{"invites": invites},
+		})
+		return
+	}
+
+	manage := me.Can(org.PermManageTeam)
+	var rows []bff.UISnippet
+	members := org.Default.Members(o.ID)
+	for _, m := range members {
+		rows = append(rows, createMemberRow(m, member, manage))
+	}
+
+	var invites []org.Invite
+	if manage {
+		rows = append(rows, createInviteForm())
+		for _, i := range org.Default.Invites(o.ID) {
+			if i.Status != org.InvitePending {
+				continue
+			}
+			invites = append(invites, i)
+			rows = append(rows, createPendingInviteRow(i))
+		}
+	}
+	if !me.Has(org.RoleOwner) {
+		rows = append(rows, createTeamButton("Leave Team", "#fff", "#ff0000", teamAction("leave", nil)))
+	}
+
+	permissions := map[org.Role][]string{}
+	for _, r := range org.Roles {
+		for _, p := range []org.Permission{org.PermManageTeam, org.PermPostLoad, org.PermManageTrucks, org.PermManageTrips,
+			org.PermReviewPOD, org.PermReleasePayment, org.PermAddFunds} {
+			if r.Can(p) {
+				permissions[r] = append(permissions[r], string(p))
+			}
+		}
+	}
+
+	c.JSON(200, bff.ScreenResponse{
+		Status: "success",
+		Screen: "Team",
+		UI:     []bff.UISnippet{createTeamPage(o.Name, fmt.Sprintf("%d members · You are %s", len(members), me.RoleText()), rows)},
+		Data: map[string]interface{}{
+			"org":         o,
+			"me":          me,
+			"members":     members,
+			"invites":     invites,
+			"roles":       org.Roles,
+			"permissions": permissions,
+		},
+	})
+}
+
+// Helper function to create the team page around its rows
+func createTeamPage(title, subtitle string, rows []bff.UISnippet) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "ScrollView",
+		Data: bff.ViewData{
+			Flex:            1,
+			BackgroundColor: "#ffffff",
+			Padding:         20,
+		},
+		Children: append([]bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: title, FontSize: 28, FontWeight: "bold", Color: "#1a1a1a", MarginBottom: 4},
+			},
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: subtitle, FontSize: 14, Color: "#666", MarginBottom: 20},
+			},
+		}, rows...),
+	}
+}
+
+// Helper function to create a member row with the manager's controls
+func createMemberRow(m org.Member, viewer string, manage bool) bff.UISnippet {
+	name := m.Name
+	if m.UserID == viewer {
+		name += " (you)"
+	}
+	children := []bff.UISnippet{
+		{
+			Type: "Text",
+			Data: bff.TextData{Text: name, FontSize: 16, FontWeight: "600", Color: "#1a1a1a"},
+		},
+		{
+			Type: "Text",
+			Data: bff.TextData{Text: m.RoleText() + " · " + m.Phone, FontSize: 13, Color: "#666"},
+		},
+	}
+	if manage && m.UserID != viewer {
+		children = append(children, bff.UISnippet{
+			Type: "View",
+			Data: bff.ViewData{FlexDirection: "row", Gap: 8, MarginTop: 8},
+			Children: []bff.UISnippet{
+				createTeamButton("Change Role", "#f8f9fa", "#1a1a1a", teamAction("setRoles", map[string]interface{}{
+					"userId":  m.UserID,
+					"roles":   m.Roles,
+					"options": org.Roles,
+				})),
+				createTeamButton("Remove", "#fff", "#ff0000", teamAction("remove", map[string]interface{}{"userId": m.UserID})),
+			},
+		})
+	}
+	return bff.UISnippet{
+		Type:     "View",
+		Data:     bff.ViewData{BackgroundColor: "#f8f9fa", BorderRadius: 12, Padding: 16, MarginBottom: 12},
+		Children: children,
+	}
+}
+
+// Helper function to create the invite by phone form
+func createInviteForm() bff.UISnippet {
+	var roles []bff.UISnippet
+	for _, r := range org.Roles {
+		if r == org.RoleOwner {
+			continue
+		}
+		roles = append(roles, createTeamButton("Invite as "+r.Label(), "#ff0000", "#fff", teamAction("invite", map[string]interface{}{
+			"roles":  []org.Role{r},
+			"fields": []string{"phone"},
+		})))
+	}
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{BorderWidth: 1, BorderColor: "#f0f0f0", BorderRadius: 12, Padding: 16, Gap: 8, MarginBottom: 12},
+		Children: append([]bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: "Invite a teammate", FontSize: 16, FontWeight: "600", Color: "#1a1a1a"},
+			},
+			{
+				Type: "INPUT",
+				Data: bff.InputData{
+					Id:           "phone",
+					Placeholder:  "Mobile number",
+					KeyboardType: "phone-pad",
+					MaxLength:    14,
+					FontSize:     14,
+					Style:        bff.ViewData{Padding: 12, BorderRadius: 8, BorderWidth: 1, BorderColor: "#E5E5E5"},
+				},
+			},
+		}, roles...),
+	}
+}
+
+func createPendingInviteRow(i org.Invite) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{FlexDirection: "row", JustifyContent: "space-between", AlignItems: "center", Padding: 12, MarginBottom: 8},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: fmt.Sprintf("%s · %s · invited %s", i.Phone, i.RoleText(), bff.TimeAgo(i.At)), FontSize: 13, Color: "#666"},
+			},
+			createTeamButton("Revoke", "#fff", "#ff0000", teamAction("revoke", map[string]interface{}{"inviteId": i.ID})),
+		},
+	}
+}
+
+// Helper function to create an invitation the signed-in number can answer
+func createInvitationCard(i org.Invite) bff.UISnippet {
+	o, _ := org.Default.Get(i.OrgID)
+	data := map[string]interface{}{"inviteId": i.ID}
+	accept := map[string]interface{}{"inviteId": i.ID, "fields": []string{"name"}}
+	return bff.UISnippet{
+		Type: "View",
+		Data: bff.ViewData{BackgroundColor: "#f8f9fa", BorderRadius: 12, Padding: 16, Gap: 8, MarginBottom: 12},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: o.Name, FontSize: 16, FontWeight: "600", Color: "#1a1a1a"},
+			},
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: "Invited as " + i.RoleText(), FontSize: 13, Color: "#666"},
+			},
+			{
+				Type: "View",
+				Data: bff.ViewData{FlexDirection: "row", Gap: 8},
+				Children: []bff.UISnippet{
+					createTeamButton("Accept", "#ff0000", "#fff", teamAction("accept", accept)),
+					createTeamButton("Decline", "#fff", "#1a1a1a", teamAction("decline", data)),
+				},
+			},
+		},
+	}
+}
+
+func createTeamButton(label, background, color string, action bff.ActionData) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "TouchableOpacity",
+		Data: bff.TouchableOpacityData{
+			Style: bff.ViewData{
+				BackgroundColor:   background,
+				PaddingVertical:   8,
+				PaddingHorizontal: 12,
+				BorderRadius:      8,
+				BorderWidth:       1,
+				BorderColor:       color,
+				AlignItems:        "center",
+			},
+			OnPress: action,
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "Text",
+				Data: bff.TextData{Text: label, FontSize: 14, FontWeight: "600", Color: color},
+			},
+		},
+	}
+}
+
+func teamAction(action string, data map[string]interface{}) bff.ActionData {
+	return bff.ActionData{
+		Type:   "action",
+		Value:  action,
+		Url:    "/bff/broker/team/action",
+		Method: "POST",
+		Data:   data,
+	}
+}
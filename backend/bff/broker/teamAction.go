@@ -0,0 +1,125 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/org"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleTeamAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleTeamAction(memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleTeamAction(memberID string, req bff.ActionRequest) bff.ActionResponse {
+	userID, _ := req.Data["userId"].(string)
+	inviteID, _ := req.Data["inviteId"].(string)
+	phone, _ := req.Data["phone"].(string)
+
+	switch req.Action {
+	case "invite":
+		i, err := org.Default.Invite(memberID, phone, teamRoles(req.Data))
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Invitation sent to " + i.Phone,
+			Data:    i,
+		}
+
+	case "revoke":
+		i, err := org.Default.Revoke(memberID, inviteID)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Invitation to " + i.Phone + " withdrawn",
+			Data:    i,
+		}
+
+	case "setRoles":
+		m, err := org.Default.SetRoles(memberID, userID, teamRoles(req.Data))
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: m.Name + " is now " + m.RoleText(),
+			Data:    m,
+		}
+
+	case "remove", "leave":
+		if req.Action == "leave" {
+			userID = memberID
+		}
+		if err := org.Default.Remove(memberID, userID); err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Removed from the team",
+			Data:    map[string]interface{}{"userId": userID},
+		}
+
+	case "accept":
+		// the invitation must be to the number the member signed in with,
+		// not one typed into the request
+		name, _ := req.Data["name"].(string)
+		m, err := org.Default.Accept(inviteID, memberID, name)
+		if err != nil {
+			return actionError(err)
+		}
+		o, _ := org.Default.Get(m.OrgID)
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Welcome to " + o.Name,
+			Data:    m,
+		}
+
+	case "decline":
+		i, err := org.Default.Decline(inviteID, memberID)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Invitation declined",
+			Data:    i,
+		}
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
+
+// Helper function to read roles sent as ["dispatcher", "accounts"] or a single "role"
+func teamRoles(data map[string]interface{}) []org.Role {
+	var out []org.Role
+	list, _ := data["roles"].([]interface{})
+	for _, r := range list {
+		if s, ok := r.(string); ok {
+			out = append(out, org.Role(s))
+		}
+	}
+	if r, _ := data["role"].(string); r != "" {
+		out = append(out, org.Role(r))
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package broker
+
+import (
+	"backend/bff"
+	"backend/bff/org"
+	"backend/bff/vehicle"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleTruckAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	response := handleTruckAction(brokerID(c), memberID(c), req)
+	c.JSON(200, response)
+}
+
+func handleTruckAction(brokerID, memberID string, req bff.ActionRequest) bff.ActionResponse {
+	switch req.Action {
+	case "addTruck":
+		if err := org.Default.Check(memberID, org.PermManageTrucks); err != nil {
+			return actionError(err)
+		}
+		number, _ := req.Data["truckNumber"].(string)
+		truckType, _ := req.Data["truckType"].(string)
+		capacity, _ := req.Data["capacity"].(float64)
+		model, _ := req.Data["model"].(string)
+
+		v, err := vehicle.Default.Register(vehicle.Vehicle{
+			Number:   number,
+			BrokerID: brokerID,
+			Model:    model,
+			Type:     truckType,
+			Capacity: capacity,
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Truck " + v.Number + " added to your fleet",
+			Data:    truckData(v),
+		}
+
+	default:
+		return bff.ActionResponse{
+			Status:  "error",
+			Message: "Unknown action",
+		}
+	}
+}
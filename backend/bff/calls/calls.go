@@ -0,0 +1,264 @@
+package calls
+
+import (
+	"backend/bff/trip"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotParticipant   = errors.New("only the trip's driver and broker can place calls")
+	ErrNoContact        = errors.New("this contact is not on the trip")
+	ErrExpired          = errors.New("calls through the app end once the trip is settled")
+	ErrUnknownNumber    = errors.New("number is not assigned to a trip")
+	ErrCallerRejected   = errors.New("caller is not a party to this trip")
+	ErrInvalidSignature = errors.New("callback signature does not match")
+	ErrNoSecret         = errors.New("callbacks are refused until a signing secret is configured")
+)
+
+type Role string
+
+const (
+	RoleDriver   Role = "driver"
+	RoleBroker   Role = "broker"
+	RoleSender   Role = "sender"
+	RoleReceiver Role = "receiver"
+)
+
+var roles = []Role{RoleDriver, RoleBroker, RoleSender, RoleReceiver}
+
+// Label is how the role is shown on contact cards
+func (r Role) Label() string {
+	switch r {
+	case RoleDriver:
+		return "Driver"
+	case RoleBroker:
+		return "Broker"
+	case RoleSender:
+		return "Sender"
+	case RoleReceiver:
+		return "Receiver"
+	}
+	return string(r)
+}
+
+// Contact is a trip party as the other parties see them: the number is a
+// virtual one that forwards to the real phone
+type Contact struct {
+	TripID string `json:"tripId"`
+	Role   Role   `json:"role"`
+	Name   string `json:"name"`
+	Number string `json:"number"`
+}
+
+type line struct {
+	Contact
+	route Route
+}
+
+type session struct {
+	lines   map[Role]*line
+	expired time.Time
+}
+
+// Service masks the phone numbers of a trip's parties behind per-trip
+// virtual numbers. The numbers are released when the trip is settled or
+// cancelled, after which calls through the app stop connecting.
+type Service struct {
+	Trips    *trip.Store
+	Provider Provider
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	byNumber map[string]*line
+}
+
+func NewService(trips *trip.Store, p Provider) *Service {
+	s := &Service{
+		Trips:    trips,
+		Provider: p,
+		sessions: map[string]*session{},
+		byNumber: map[string]*line{},
+	}
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		if !t.Active() {
+			s.Expire(t.ID)
+		}
+	})
+	return s
+}
+
+// Default service used by the BFF handlers. Its stub has no secret, so it
+// refuses callbacks until main sets a provider with one.
+var Default = NewService(trip.Default, NewStub(""))
+
+// RoleOf returns the role userID plays on the trip
+func RoleOf(t trip.Trip, userID string) (Role, error) {
+	switch {
+	case userID == "":
+	case userID == t.DriverID:
+		return RoleDriver, nil
+	case userID == t.BrokerID:
+		return RoleBroker, nil
+	}
+	return "", ErrNotParticipant
+}
+
+// Contact returns the masked number userID dials to reach the party in role
+func (s *Service) Contact(tripID, userID string, role Role) (Contact, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Contact{}, err
+	}
+	caller, err := RoleOf(t, userID)
+	if err != nil {
+		return Contact{}, err
+	}
+	if role == caller || realNumber(t, role) == "" {
+		return Contact{}, ErrNoContact
+	}
+	if !t.Active() {
+		return Contact{}, ErrExpired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.sessions[t.ID]
+	if sess == nil {
+		sess = &session{lines: map[Role]*line{}}
+		s.sessions[t.ID] = sess
+	}
+	if !sess.expired.IsZero() {
+		return Contact{}, ErrExpired
+	}
+	if l, ok := sess.lines[role]; ok {
+		return l.Contact, nil
+	}
+
+	route := Route{TripID: t.ID, Callee: role, To: realNumber(t, role)}
+	for _, r := range roles {
+		if r != role && realNumber(t, r) != "" {
+			route.Callers = append(route.Callers, realNumber(t, r))
+		}
+	}
+	number, err := s.Provider.Allocate(route)
+	if err != nil {
+		return Contact{}, err
+	}
+
+	l := &line{
+		Contact: Contact{TripID: t.ID, Role: role, Name: t.Details[string(role)+"Name"], Number: number},
+		route:   route,
+	}
+	sess.lines[role] = l
+	s.byNumber[digits(number)] = l
+	return l.Contact, nil
+}
+
+// Contacts lists everyone userID can call on the trip. It is empty once the
+// trip has ended.
+func (s *Service) Contacts(tripID, userID string) []Contact {
+	var out []Contact
+	for _, r := range roles {
+		if c, err := s.Contact(tripID, userID, r); err == nil {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Connect resolves an incoming call to a virtual number: the provider asks
+// where to forward a call from the caller's real number
+func (s *Service) Connect(number, from string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.byNumber[digits(number)]
+	if !ok {
+		return "", ErrUnknownNumber
+	}
+	if sess := s.sessions[l.TripID]; sess == nil || !sess.expired.IsZero() {
+		return "", ErrExpired
+	}
+	for _, caller := range l.route.Callers {
+		if digits(caller) == digits(from) {
+			return l.route.To, nil
+		}
+	}
+	return "", ErrCallerRejected
+}
+
+// Expire releases a trip's virtual numbers; later calls are refused
+func (s *Service) Expire(tripID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.sessions[tripID]
+	if sess == nil {
+		sess = &session{lines: map[Role]*line{}}
+		s.sessions[tripID] = sess
+	}
+	if !sess.expired.IsZero() {
+		return
+	}
+	sess.expired = now()
+	for _, l := range sess.lines {
+		delete(s.byNumber, digits(l.Number))
+		s.Provider.Release(l.Number)
+	}
+}
+
+// Mask replaces the real numbers of userID's counterparties in a copy of a
+// trip's details ("brokerPhone" and so on) with their virtual numbers, or
+// drops them when calls are no longer offered
+func (s *Service) Mask(t trip.Trip, userID string, details map[string]string) map[string]string {
+	caller, _ := RoleOf(t, userID)
+	out := make(map[string]string, len(details))
+	for k, v := range details {
+		out[k] = v
+	}
+	for _, r := range roles {
+		key := string(r) + "Phone"
+		if r == caller || out[key] == "" {
+			continue
+		}
+		delete(out, key)
+		if c, err := s.Contact(t.ID, userID, r); err == nil {
+			out[key] = c.Number
+		}
+	}
+	return out
+}
+
+// Redact hides all but the last three digits of a number
+// ("+91 98765 43210" becomes "+91 XXXXX XX210") for places that show a
+// party without offering a call
+func Redact(phone string) string {
+	d := digits(phone)
+	if len(d) < 10 {
+		return phone
+	}
+	masked := "XXXXX XX" + d[len(d)-3:]
+	if code := d[:len(d)-10]; code != "" {
+		masked = "+" + code + " " + masked
+	}
+	return masked
+}
+
+func realNumber(t trip.Trip, r Role) string {
+	return t.Details[string(r)+"Phone"]
+}
+
+func digits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
@@ -0,0 +1,151 @@
+package calls
+
+import (
+	"backend/bff/trip"
+	"errors"
+	"testing"
+)
+
+func newTestService() *Service {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{
+		ID:       "TRK1",
+		DriverID: "DRV1",
+		BrokerID: "BRK1",
+		Status:   trip.StatusLoading,
+		Details: map[string]string{
+			"driverName":  "Rajesh Kumar",
+			"driverPhone": "+91 98765 43210",
+			"brokerName":  "Sharma Logistics",
+			"brokerPhone": "+91 98200 11111",
+			"senderName":  "Shree Textiles",
+			"senderPhone": "+91 98200 22222",
+		},
+	})
+	return NewService(trips, NewStub("s3cret"))
+}
+
+func TestContact(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		role    Role
+		wantErr error
+	}{
+		{name: "driver calls broker", userID: "DRV1", role: RoleBroker},
+		{name: "broker calls driver", userID: "BRK1", role: RoleDriver},
+		{name: "driver calls sender", userID: "DRV1", role: RoleSender},
+		{name: "calling yourself", userID: "DRV1", role: RoleDriver, wantErr: ErrNoContact},
+		{name: "no receiver number", userID: "DRV1", role: RoleReceiver, wantErr: ErrNoContact},
+		{name: "outsider", userID: "DRV2", role: RoleBroker, wantErr: ErrNotParticipant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			c, err := s.Contact("TRK1", tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			tr, _ := s.Trips.Get("TRK1")
+			if c.Number == "" || digits(c.Number) == digits(realNumber(tr, tt.role)) {
+				t.Errorf("contact number = %q, want a virtual number", c.Number)
+			}
+			if again, _ := s.Contact("TRK1", tt.userID, tt.role); again.Number != c.Number {
+				t.Errorf("second lookup gave %s, want %s", again.Number, c.Number)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	s := newTestService()
+	broker, err := s.Contact("TRK1", "DRV1", RoleBroker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		number  string
+		from    string
+		want    string
+		wantErr error
+	}{
+		{name: "driver", number: broker.Number, from: "+91 98765 43210", want: "+91 98200 11111"},
+		{name: "sender, formatted differently", number: broker.Number, from: "919820022222", want: "+91 98200 11111"},
+		{name: "stranger", number: broker.Number, from: "+91 99999 99999", wantErr: ErrCallerRejected},
+		{name: "number not in use", number: "+91 80 4000 9999", from: "+91 98765 43210", wantErr: ErrUnknownNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to, err := s.Connect(tt.number, tt.from)
+			if !errors.Is(err, tt.wantErr) || to != tt.want {
+				t.Errorf("Connect = %q, %v; want %q, %v", to, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNumbersEndWithTheTrip(t *testing.T) {
+	s := newTestService()
+	broker, err := s.Contact("TRK1", "DRV1", RoleBroker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Trips.Transition("TRK1", trip.StatusCancelled, trip.ActorBroker, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Connect(broker.Number, "+91 98765 43210"); !errors.Is(err, ErrUnknownNumber) {
+		t.Errorf("call after the trip ended: %v, want %v", err, ErrUnknownNumber)
+	}
+	if _, err := s.Contact("TRK1", "DRV1", RoleBroker); !errors.Is(err, ErrExpired) {
+		t.Errorf("contact after the trip ended: %v, want %v", err, ErrExpired)
+	}
+	if got := s.Contacts("TRK1", "DRV1"); len(got) != 0 {
+		t.Errorf("contacts after the trip ended = %+v", got)
+	}
+
+	// the released number goes to the next trip that needs one
+	s.Trips.Add(trip.Trip{ID: "TRK2", DriverID: "DRV2", BrokerID: "BRK1", Details: map[string]string{"brokerPhone": "+91 98200 11111"}})
+	if next, _ := s.Contact("TRK2", "DRV2", RoleBroker); next.Number != broker.Number {
+		t.Errorf("next trip got %s, want the released %s", next.Number, broker.Number)
+	}
+}
+
+func TestMask(t *testing.T) {
+	s := newTestService()
+	tr, _ := s.Trips.Get("TRK1")
+
+	masked := s.Mask(tr, "DRV1", tr.Details)
+	if masked["driverPhone"] != tr.Details["driverPhone"] {
+		t.Errorf("driver's own number changed to %q", masked["driverPhone"])
+	}
+	for _, key := range []string{"brokerPhone", "senderPhone"} {
+		if masked[key] == "" || masked[key] == tr.Details[key] {
+			t.Errorf("%s = %q, want a virtual number", key, masked[key])
+		}
+	}
+	if tr.Details["brokerPhone"] != "+91 98200 11111" {
+		t.Error("Mask changed the trip's own details")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{phone: "+91 98765 43210", want: "+91 XXXXX XX210"},
+		{phone: "9876543210", want: "XXXXX XX210"},
+		{phone: "100", want: "100"},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.phone); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
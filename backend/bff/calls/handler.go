@@ -0,0 +1,59 @@
+package calls
+
+import (
+	"backend/bff"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Contacts lists the masked numbers a user can call on a trip
+func Contacts(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"contacts": Default.Contacts(c.Query("tripId"), bff.UserID(c))},
+	})
+}
+
+// Connect is the provider callback for an incoming call: given the virtual
+// number dialled and the caller's number it answers where to forward the
+// call. The answer is a real number, so only signed callbacks get one.
+func Connect(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Invalid request format"})
+		return
+	}
+	switch err := Default.Provider.Verify(body, c.GetHeader("X-Signature")); {
+	case errors.Is(err, ErrNoSecret):
+		c.JSON(503, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(401, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	var req struct {
+		Number string `json:"number"`
+		From   string `json:"from"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	to, err := Default.Connect(req.Number, req.From)
+	switch {
+	case errors.Is(err, ErrUnknownNumber):
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+	case err != nil:
+		c.JSON(403, bff.ActionResponse{Status: "error", Message: err.Error()})
+	default:
+		c.JSON(200, bff.ActionResponse{Status: "success", Data: gin.H{"forwardTo": to}})
+	}
+}
@@ -0,0 +1,38 @@
+package calls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestStubVerify(t *testing.T) {
+	body := `{"number":"+91 80 4000 0001","from":"+91 98765 43210"}`
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		wantErr   error
+	}{
+		{name: "signed", secret: "s3cret", signature: sign("s3cret", body)},
+		{name: "signed with another secret", secret: "s3cret", signature: sign("guess", body), wantErr: ErrInvalidSignature},
+		{name: "unsigned", secret: "s3cret", wantErr: ErrInvalidSignature},
+		{name: "no secret configured", signature: sign("", body), wantErr: ErrNoSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewStub(tt.secret).Verify([]byte(body), tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
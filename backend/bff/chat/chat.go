@@ -0,0 +1,423 @@
+package chat
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotParticipant  = errors.New("only the trip's driver and broker can use its chat")
+	ErrUnknownKind     = errors.New("message kind must be text, image or location")
+	ErrEmptyMessage    = errors.New("message is empty")
+	ErrMessageTooLong  = errors.New("message is longer than 2000 characters")
+	ErrImageTooLarge   = errors.New("image is larger than 5 MB")
+	ErrInvalidLocation = errors.New("location needs a valid latitude and longitude")
+)
+
+const (
+	maxTextLength = 2000
+	maxImageBytes = 5 << 20
+	// Messages returned per history page unless the client asks for fewer
+	pageSize = 30
+)
+
+type Kind string
+
+const (
+	KindText     Kind = "text"
+	KindImage    Kind = "image"
+	KindLocation Kind = "location"
+)
+
+func (k Kind) Valid() bool {
+	return k == KindText || k == KindImage || k == KindLocation
+}
+
+// Message is one entry in a trip's conversation
+type Message struct {
+	ID       string     `json:"id"`
+	TripID   string     `json:"tripId"`
+	SenderID string     `json:"senderId"`
+	Sender   string     `json:"sender"` // trip.ActorDriver or trip.ActorBroker
+	Kind     Kind       `json:"kind"`
+	Text     string     `json:"text,omitempty"`
+	ImageID  string     `json:"imageId,omitempty"`
+	Location *geo.Coord `json:"location,omitempty"`
+	Place    string     `json:"place,omitempty"`
+	// Echoed back so the sending app can match its optimistic bubble
+	ClientID    string    `json:"clientId,omitempty"`
+	At          time.Time `json:"at"`
+	DeliveredAt time.Time `json:"deliveredAt,omitempty"`
+	ReadAt      time.Time `json:"readAt,omitempty"`
+}
+
+// Receipt is how far a message got: "sent", "delivered" or "read"
+func (m Message) Receipt() string {
+	switch {
+	case !m.ReadAt.IsZero():
+		return "read"
+	case !m.DeliveredAt.IsZero():
+		return "delivered"
+	}
+	return "sent"
+}
+
+// SendRequest is a new message from a participant
+type SendRequest struct {
+	Kind      Kind
+	Text      string
+	Image     []byte
+	ImageType string
+	Location  geo.Coord
+	ClientID  string
+}
+
+// Page is a slice of a conversation, oldest first
+type Page struct {
+	Messages []Message `json:"messages"`
+	// More older messages exist before the first one
+	HasMore bool `json:"hasMore"`
+}
+
+// Update is pushed to live subscribers of a conversation
+type Update struct {
+	Type    string  `json:"type"` // "message" or "receipt"
+	Message Message `json:"message"`
+}
+
+type listener struct {
+	userID  string
+	updates chan Update
+}
+
+// Store keeps trip conversations and fans new messages and receipts out to
+// the participants who are connected
+type Store struct {
+	Trips *trip.Store
+	Docs  docstore.Store
+
+	mu        sync.Mutex
+	seq       int
+	lseq      int
+	messages  map[string][]*Message
+	members   map[string][2]string // driver and broker of each conversation
+	listeners map[string]map[int]*listener
+}
+
+func NewStore(trips *trip.Store, docs docstore.Store) *Store {
+	return &Store{
+		Trips:     trips,
+		Docs:      docs,
+		messages:  map[string][]*Message{},
+		members:   map[string][2]string{},
+		listeners: map[string]map[int]*listener{},
+	}
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore(trip.Default, docstore.Default))
+
+// Conversation returns the trip if userID takes part in its chat
+func (s *Store) Conversation(tripID, userID string) (trip.Trip, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return trip.Trip{}, err
+	}
+	if userID == "" || (userID != t.DriverID && userID != t.BrokerID) {
+		return trip.Trip{}, ErrNotParticipant
+	}
+	return t, nil
+}
+
+// Send adds a message and pushes it to whoever is connected
+func (s *Store) Send(tripID, userID string, req SendRequest) (Message, error) {
+	t, err := s.Conversation(tripID, userID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	m := Message{
+		TripID:   t.ID,
+		SenderID: userID,
+		Sender:   trip.ActorBroker,
+		Kind:     req.Kind,
+		ClientID: req.ClientID,
+	}
+	if userID == t.DriverID {
+		m.Sender = trip.ActorDriver
+	}
+
+	switch req.Kind {
+	case KindText:
+		m.Text = strings.TrimSpace(req.Text)
+		if m.Text == "" {
+			return Message{}, ErrEmptyMessage
+		}
+		if len([]rune(m.Text)) > maxTextLength {
+			return Message{}, ErrMessageTooLong
+		}
+	case KindImage:
+		if len(req.Image) == 0 {
+			return Message{}, ErrEmptyMessage
+		}
+		if len(req.Image) > maxImageBytes {
+			return Message{}, ErrImageTooLarge
+		}
+		m.Text = strings.TrimSpace(req.Text) // optional caption
+	case KindLocation:
+		if !req.Location.Valid() || req.Location.IsZero() {
+			return Message{}, ErrInvalidLocation
+		}
+		at := req.Location
+		m.Location, m.Place = &at, geo.Describe(at)
+	default:
+		return Message{}, ErrUnknownKind
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	m.ID = fmt.Sprintf("CHT%06d", s.seq)
+	m.At = now()
+
+	if m.Kind == KindImage {
+		contentType := req.ImageType
+		if contentType == "" {
+			contentType = "image/jpeg"
+		}
+		doc, err := s.Docs.Put(docstore.Document{
+			Kind:        "chat_image",
+			Ref:         m.ID,
+			Name:        "chat-" + m.ID,
+			ContentType: contentType,
+			Owners:      []string{t.DriverID, t.BrokerID},
+		}, req.Image)
+		if err != nil {
+			return Message{}, err
+		}
+		m.ImageID = doc.ID
+	}
+
+	stored := &m
+	s.messages[t.ID] = append(s.messages[t.ID], stored)
+	s.members[t.ID] = [2]string{t.DriverID, t.BrokerID}
+
+	// a connected recipient has the message as soon as it is pushed
+	if s.connected(t.ID, s.other(t, userID)) {
+		stored.DeliveredAt = m.At
+	}
+	s.broadcast(t.ID, Update{Type: "message", Message: *stored})
+	return *stored, nil
+}
+
+// History pages backwards from before (the newest page when empty) or, for
+// clients polling without a socket, forwards from after. Fetching counts as
+// delivery of the other side's messages.
+func (s *Store) History(tripID, userID, before, after string, limit int) (Page, error) {
+	if _, err := s.Conversation(tripID, userID); err != nil {
+		return Page{}, err
+	}
+	if limit <= 0 || limit > pageSize {
+		limit = pageSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := s.messages[tripID]
+	lo, hi := 0, len(all)
+	switch {
+	case after != "":
+		lo = index(all, after) + 1
+		if lo+limit < hi {
+			hi = lo + limit
+		}
+	case before != "":
+		if i := index(all, before); i >= 0 {
+			hi = i
+		}
+		fallthrough
+	default:
+		if hi-limit > lo {
+			lo = hi - limit
+		}
+	}
+
+	page := Page{Messages: []Message{}, HasMore: lo > 0}
+	var delivered []*Message
+	for _, m := range all[lo:hi] {
+		if m.SenderID != userID && m.DeliveredAt.IsZero() {
+			m.DeliveredAt = now()
+			delivered = append(delivered, m)
+		}
+		page.Messages = append(page.Messages, *m)
+	}
+	for _, m := range delivered {
+		s.broadcast(tripID, Update{Type: "receipt", Message: *m})
+	}
+	return page, nil
+}
+
+// MarkRead marks the other side's messages read up to and including upTo,
+// or all of them when upTo is empty, and returns how many changed
+func (s *Store) MarkRead(tripID, userID, upTo string) (int, error) {
+	if _, err := s.Conversation(tripID, userID); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := now()
+	n := 0
+	for _, m := range s.messages[tripID] {
+		if m.SenderID != userID && m.ReadAt.IsZero() {
+			if m.DeliveredAt.IsZero() {
+				m.DeliveredAt = at
+			}
+			m.ReadAt = at
+			n++
+			s.broadcast(tripID, Update{Type: "receipt", Message: *m})
+		}
+		if m.ID == upTo {
+			break
+		}
+	}
+	return n, nil
+}
+
+// Unread counts the messages waiting for userID in one trip's chat
+func (s *Store) Unread(tripID, userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return unread(s.messages[tripID], userID)
+}
+
+// UnreadByTrip counts the waiting messages in every chat userID takes part in
+func (s *Store) UnreadByTrip(userID string) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := map[string]int{}
+	for tripID, all := range s.messages {
+		if m := s.members[tripID]; m[0] != userID && m[1] != userID {
+			continue
+		}
+		if n := unread(all, userID); n > 0 {
+			out[tripID] = n
+		}
+	}
+	return out
+}
+
+// TotalUnread is the count shown on the home screens
+func (s *Store) TotalUnread(userID string) int {
+	total := 0
+	for _, n := range s.UnreadByTrip(userID) {
+		total += n
+	}
+	return total
+}
+
+// Summary is one conversation in a user's chat list
+type Summary struct {
+	TripID string  `json:"tripId"`
+	Last   Message `json:"last"`
+	Unread int     `json:"unread"`
+}
+
+// Conversations lists the chats userID takes part in, latest first
+func (s *Store) Conversations(userID string) []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Summary
+	for tripID, all := range s.messages {
+		if m := s.members[tripID]; m[0] != userID && m[1] != userID {
+			continue
+		}
+		out = append(out, Summary{TripID: tripID, Last: *all[len(all)-1], Unread: unread(all, userID)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Last.At.After(out[j].Last.At) })
+	return out
+}
+
+// Subscribe streams the conversation's new messages and receipts to userID
+// until cancel is called
+func (s *Store) Subscribe(tripID, userID string) (<-chan Update, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lseq++
+	id := s.lseq
+	l := &listener{userID: userID, updates: make(chan Update, 64)}
+	if s.listeners[tripID] == nil {
+		s.listeners[tripID] = map[int]*listener{}
+	}
+	s.listeners[tripID][id] = l
+
+	return l.updates, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.listeners[tripID][id]; ok {
+			delete(s.listeners[tripID], id)
+			close(l.updates)
+		}
+	}
+}
+
+// broadcast hands an update to every listener without waiting; a listener
+// that has fallen behind catches up from History
+func (s *Store) broadcast(tripID string, u Update) {
+	for _, l := range s.listeners[tripID] {
+		select {
+		case l.updates <- u:
+		default:
+		}
+	}
+}
+
+func (s *Store) connected(tripID, userID string) bool {
+	for _, l := range s.listeners[tripID] {
+		if l.userID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) other(t trip.Trip, userID string) string {
+	if userID == t.DriverID {
+		return t.BrokerID
+	}
+	return t.DriverID
+}
+
+func index(all []*Message, id string) int {
+	for i, m := range all {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func unread(all []*Message, userID string) int {
+	n := 0
+	for _, m := range all {
+		if m.SenderID != userID && m.ReadAt.IsZero() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,172 @@
+package chat
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestStore() *Store {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", BrokerID: "BRK1"})
+	return NewStore(trips, docstore.NewMemory())
+}
+
+func text(s string) SendRequest {
+	return SendRequest{Kind: KindText, Text: s}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		req        SendRequest
+		wantErr    error
+		wantSender string
+	}{
+		{name: "driver", userID: "DRV1", req: text("Reached the toll plaza"), wantSender: trip.ActorDriver},
+		{name: "broker", userID: "BRK1", req: text("OK"), wantSender: trip.ActorBroker},
+		{name: "photo", userID: "DRV1", req: SendRequest{Kind: KindImage, Image: []byte("jpeg")}, wantSender: trip.ActorDriver},
+		{name: "location", userID: "DRV1", req: SendRequest{Kind: KindLocation, Location: geo.Coord{Lat: 18.75, Lng: 73.4}}, wantSender: trip.ActorDriver},
+		{name: "outsider", userID: "DRV2", req: text("Hi"), wantErr: ErrNotParticipant},
+		{name: "nobody", req: text("Hi"), wantErr: ErrNotParticipant},
+		{name: "blank", userID: "DRV1", req: text("   "), wantErr: ErrEmptyMessage},
+		{name: "too long", userID: "DRV1", req: text(strings.Repeat("क", maxTextLength+1)), wantErr: ErrMessageTooLong},
+		{name: "no photo", userID: "DRV1", req: SendRequest{Kind: KindImage}, wantErr: ErrEmptyMessage},
+		{name: "photo too large", userID: "DRV1", req: SendRequest{Kind: KindImage, Image: make([]byte, maxImageBytes+1)}, wantErr: ErrImageTooLarge},
+		{name: "no location", userID: "DRV1", req: SendRequest{Kind: KindLocation}, wantErr: ErrInvalidLocation},
+		{name: "unknown kind", userID: "DRV1", req: SendRequest{Kind: "video"}, wantErr: ErrUnknownKind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore()
+			m, err := s.Send("TRK1", tt.userID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if m.Sender != tt.wantSender || m.Receipt() != "sent" {
+				t.Errorf("sent by %s, %s; want %s, sent", m.Sender, m.Receipt(), tt.wantSender)
+			}
+			if m.Kind == KindImage && m.ImageID == "" {
+				t.Error("photo not stored")
+			}
+			if m.Kind == KindLocation && m.Place == "" {
+				t.Error("location not described")
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s := newTestStore()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		m, err := s.Send("TRK1", "DRV1", text("update"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, m.ID)
+	}
+
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		limit    int
+		want     []string
+		wantMore bool
+	}{
+		{name: "newest page", limit: 2, want: ids[3:], wantMore: true},
+		{name: "older page", before: ids[3], limit: 2, want: ids[1:3], wantMore: true},
+		{name: "oldest page", before: ids[1], limit: 2, want: ids[:1]},
+		{name: "polling for new", after: ids[2], limit: 10, want: ids[3:], wantMore: true},
+		{name: "nothing new", after: ids[4], want: nil, wantMore: true},
+		{name: "everything", want: ids},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.History("TRK1", "BRK1", tt.before, tt.after, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range page.Messages {
+				got = append(got, m.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || page.HasMore != tt.wantMore {
+				t.Errorf("page = %v (more %v), want %v (more %v)", got, page.HasMore, tt.want, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestReceipts(t *testing.T) {
+	s := newTestStore()
+	first, _ := s.Send("TRK1", "DRV1", text("Loaded"))
+	second, _ := s.Send("TRK1", "DRV1", text("Leaving now"))
+	if n := s.Unread("TRK1", "BRK1"); n != 2 {
+		t.Fatalf("broker has %d unread, want 2", n)
+	}
+	if n := s.Unread("TRK1", "DRV1"); n != 0 {
+		t.Errorf("driver has %d unread of their own messages", n)
+	}
+
+	// the broker's app fetching the chat delivers the driver's messages
+	page, _ := s.History("TRK1", "BRK1", "", "", 0)
+	for _, m := range page.Messages {
+		if m.Receipt() != "delivered" {
+			t.Errorf("%s is %s after fetch, want delivered", m.ID, m.Receipt())
+		}
+	}
+
+	if n, err := s.MarkRead("TRK1", "BRK1", first.ID); err != nil || n != 1 {
+		t.Fatalf("MarkRead = %d, %v; want 1", n, err)
+	}
+	if n := s.Unread("TRK1", "BRK1"); n != 1 {
+		t.Errorf("broker has %d unread, want 1", n)
+	}
+	if n, _ := s.MarkRead("TRK1", "BRK1", ""); n != 1 {
+		t.Errorf("marked %d read, want the remaining 1", n)
+	}
+	page, _ = s.History("TRK1", "DRV1", "", "", 0)
+	if got := page.Messages[1]; got.ID != second.ID || got.Receipt() != "read" {
+		t.Errorf("%s is %s, want read", got.ID, got.Receipt())
+	}
+	if total := s.TotalUnread("BRK1"); total != 0 {
+		t.Errorf("broker has %d unread in total, want 0", total)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s := newTestStore()
+	updates, cancel := s.Subscribe("TRK1", "BRK1")
+
+	// a connected broker has the message at once
+	m, _ := s.Send("TRK1", "DRV1", text("Reached"))
+	if m.Receipt() != "delivered" {
+		t.Errorf("message to a connected broker is %s, want delivered", m.Receipt())
+	}
+	if u := <-updates; u.Type != "message" || u.Message.ID != m.ID {
+		t.Errorf("update = %+v, want the new message", u)
+	}
+
+	s.MarkRead("TRK1", "BRK1", "")
+	if u := <-updates; u.Type != "receipt" || u.Message.Receipt() != "read" {
+		t.Errorf("update = %+v, want a read receipt", u)
+	}
+
+	cancel()
+	if _, open := <-updates; open {
+		t.Error("updates still open after cancel")
+	}
+	// once the broker has gone, messages wait as sent
+	if m, _ := s.Send("TRK1", "DRV1", text("Unloading")); m.Receipt() != "sent" {
+		t.Errorf("message to a disconnected broker is %s, want sent", m.Receipt())
+	}
+}
@@ -0,0 +1,257 @@
+package chat
+
+import (
+	"backend/bff"
+	"backend/bff/docstore"
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// History returns a page of a trip's messages:
+// GET /bff/chat/:tripId/messages?userId=&before=&after=&limit=
+func History(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	userID, tripID := bff.UserID(c), c.Param("tripId")
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	page, err := Default.History(tripID, userID, c.Query("before"), c.Query("after"), limit)
+	if err != nil {
+		c.JSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data: gin.H{
+			"messages": views(page.Messages, userID),
+			"hasMore":  page.HasMore,
+			"unread":   Default.Unread(tripID, userID),
+		},
+	})
+}
+
+// HandleAction is the HTTP fallback for clients without a socket:
+// "send" with kind and text, image or latitude/longitude, and "markRead"
+func HandleAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	status, response := act(c.Param("tripId"), bff.UserID(c), req)
+	c.JSON(status, response)
+}
+
+// Unread returns a user's unread chat messages per trip
+func Unread(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	byTrip := Default.UnreadByTrip(bff.UserID(c))
+	total := 0
+	for _, n := range byTrip {
+		total += n
+	}
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"unread": total, "byTrip": byTrip},
+	})
+}
+
+// Socket streams a trip's chat over a WebSocket. The server pushes
+// {"type":"message"} and {"type":"receipt"} updates; the client sends the
+// same actions as HandleAction and gets {"type":"response"} replies.
+func Socket(c *gin.Context) {
+	userID, tripID := bff.UserID(c), c.Param("tripId")
+	if _, err := Default.Conversation(tripID, userID); err != nil {
+		c.JSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ws, err := upgrade(c.Writer, c.Request)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	updates, cancel := Default.Subscribe(tripID, userID)
+	defer cancel()
+
+	go func() {
+		ping := time.NewTicker(pingPeriod)
+		defer ping.Stop()
+		for {
+			select {
+			case u, ok := <-updates:
+				if !ok {
+					return
+				}
+				frame, _ := json.Marshal(gin.H{"type": u.Type, "message": view(u.Message, userID)})
+				if ws.WriteText(frame) != nil {
+					ws.Close()
+					return
+				}
+			case <-ping.C:
+				if ws.Ping() != nil {
+					ws.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		raw, err := ws.Read()
+		if err != nil {
+			return
+		}
+
+		var req bff.ActionRequest
+		var reply struct {
+			Type string `json:"type"`
+			bff.ActionResponse
+		}
+		reply.Type = "response"
+		if err := json.Unmarshal(raw, &req); err != nil {
+			reply.ActionResponse = bff.ActionResponse{Status: "error", Message: "Invalid request format"}
+		} else {
+			_, reply.ActionResponse = act(tripID, userID, req)
+		}
+
+		frame, _ := json.Marshal(reply)
+		if ws.WriteText(frame) != nil {
+			return
+		}
+	}
+}
+
+// act runs a chat action for both the socket and the HTTP fallback
+func act(tripID, userID string, req bff.ActionRequest) (int, bff.ActionResponse) {
+	switch req.Action {
+	case "send":
+		kind, _ := req.Data["kind"].(string)
+		text, _ := req.Data["text"].(string)
+		clientID, _ := req.Data["clientId"].(string)
+		lat, _ := req.Data["latitude"].(float64)
+		lng, _ := req.Data["longitude"].(float64)
+		if kind == "" {
+			kind = string(KindText)
+		}
+		image, imageType, err := decodeImage(req.Data["image"])
+		if err != nil {
+			return 400, bff.ActionResponse{Status: "error", Message: err.Error()}
+		}
+
+		m, err := Default.Send(tripID, userID, SendRequest{
+			Kind:      Kind(kind),
+			Text:      text,
+			Image:     image,
+			ImageType: imageType,
+			Location:  geo.Coord{Lat: lat, Lng: lng},
+			ClientID:  clientID,
+		})
+		if err != nil {
+			return errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()}
+		}
+		return 200, bff.ActionResponse{
+			Status: "success",
+			Data:   gin.H{"message": view(m, userID)},
+		}
+
+	case "markRead":
+		upTo, _ := req.Data["upTo"].(string)
+		n, err := Default.MarkRead(tripID, userID, upTo)
+		if err != nil {
+			return errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()}
+		}
+		return 200, bff.ActionResponse{
+			Status: "success",
+			Data:   gin.H{"marked": n, "unread": Default.Unread(tripID, userID)},
+		}
+
+	case "history":
+		before, _ := req.Data["before"].(string)
+		after, _ := req.Data["after"].(string)
+		limit, _ := req.Data["limit"].(float64)
+		page, err := Default.History(tripID, userID, before, after, int(limit))
+		if err != nil {
+			return errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()}
+		}
+		return 200, bff.ActionResponse{
+			Status: "success",
+			Data:   gin.H{"messages": views(page.Messages, userID), "hasMore": page.HasMore},
+		}
+	}
+	return 400, bff.ActionResponse{Status: "error", Message: "Unknown action: " + req.Action}
+}
+
+// MessageView is a message as one participant's app shows it
+type MessageView struct {
+	Message
+	Mine     bool   `json:"mine"`
+	Receipt  string `json:"receipt"`
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+func view(m Message, userID string) MessageView {
+	v := MessageView{Message: m, Mine: m.SenderID == userID, Receipt: m.Receipt()}
+	if m.ImageID != "" {
+		v.ImageURL = docstore.URL(m.ImageID, userID)
+	}
+	return v
+}
+
+func views(messages []Message, userID string) []MessageView {
+	out := make([]MessageView, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, view(m, userID))
+	}
+	return out
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, trip.ErrNotFound):
+		return 404
+	case errors.Is(err, ErrNotParticipant):
+		return 403
+	}
+	return 400
+}
+
+// decodeImage reads a base64 image, optionally sent as a data URL
+func decodeImage(raw interface{}) ([]byte, string, error) {
+	s, _ := raw.(string)
+	if s == "" {
+		return nil, "", nil
+	}
+
+	contentType := ""
+	if strings.HasPrefix(s, "data:") {
+		meta, payload, ok := strings.Cut(s, ",")
+		if !ok {
+			return nil, "", fmt.Errorf("image is not a valid data URL")
+		}
+		contentType = strings.TrimSuffix(strings.TrimPrefix(meta, "data:"), ";base64")
+		s = payload
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, "", fmt.Errorf("image must be base64 encoded")
+	}
+	return data, contentType, nil
+}
@@ -0,0 +1,39 @@
+package chat
+
+import (
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"time"
+)
+
+// seed starts the demo trip's conversation; the broker's last message is
+// still unread by the driver
+func seed(s *Store) *Store {
+	const tripID = "TRK789012"
+	start := now().Add(-5 * time.Hour)
+
+	script := []struct {
+		from string
+		req  SendRequest
+		at   time.Duration
+	}{
+		{trip.DemoBrokerID, SendRequest{Kind: KindText, Text: "Namaste Rajesh ji, the consignee wants delivery before 6 PM on day 3."}, 0},
+		{trip.DemoDriverID, SendRequest{Kind: KindText, Text: "Ji sir, noted. Loading is done, leaving the port now."}, 10 * time.Minute},
+		{trip.DemoDriverID, SendRequest{Kind: KindLocation, Location: geo.Coord{Lat: 19.2183, Lng: 72.9781}}, 2 * time.Hour},
+		{trip.DemoBrokerID, SendRequest{Kind: KindText, Text: "Thanks. Please share the e-way bill once the shipper sends it."}, 4 * time.Hour},
+	}
+	for i, line := range script {
+		if _, err := s.Send(tripID, line.from, line.req); err != nil {
+			panic(err)
+		}
+
+		// backdate and settle the receipts of everything but the last message
+		stored := s.messages[tripID][len(s.messages[tripID])-1]
+		stored.At = start.Add(line.at)
+		if i < len(script)-1 {
+			stored.DeliveredAt = stored.At.Add(time.Minute)
+			stored.ReadAt = stored.At.Add(2 * time.Minute)
+		}
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Largest message a client may send; base64 images make this generous
+const maxFrameBytes = 8 << 20
+
+const (
+	// How long a socket may go without hearing from the client, pongs
+	// included, before it is dropped
+	pongWait = 60 * time.Second
+	// How often the server pings; well inside pongWait so a live client
+	// always answers in time
+	pingPeriod = 30 * time.Second
+	writeWait  = 10 * time.Second
+)
+
+// AllowedOrigins are the web origins, besides the server's own, that may open
+// a chat socket. The mobile apps send no Origin and are always let in.
+var AllowedOrigins []string
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkOrigin,
+}
+
+type socket struct {
+	conn *websocket.Conn
+
+	// writes come from the reader (replies) and the update pump
+	mu sync.Mutex
+}
+
+// upgrade completes the WebSocket handshake and takes over the connection.
+// On failure the upgrader has already answered the request.
+func upgrade(w http.ResponseWriter, r *http.Request) (*socket, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(maxFrameBytes)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	return &socket{conn: conn}, nil
+}
+
+// Read returns the next complete text or binary message. Pings are answered
+// and pongs push the read deadline back on the way; it fails once the client
+// closes or goes quiet for longer than pongWait.
+func (s *socket) Read() ([]byte, error) {
+	_, message, err := s.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	return message, nil
+}
+
+// WriteText sends one text message
+func (s *socket) WriteText(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return s.conn.WriteMessage(websocket.TextMessage, p)
+}
+
+// Ping keeps idle connections open through proxies and lets Read notice a
+// client that has gone away
+func (s *socket) Ping() error {
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+}
+
+func (s *socket) Close() error {
+	return s.conn.Close()
+}
+
+// checkOrigin lets in requests without an Origin, from the server's own host
+// or from one of AllowedOrigins
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package consignment
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+)
+
+// Share serves the latest lorry receipt to whoever holds a share link
+func Share(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	doc, content, err := Default.Shared(c.Param("token"))
+	if err != nil {
+		c.JSON(404, bff.ActionResponse{
+			Status:  "error",
+			Message: ErrLinkNotFound.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+doc.Name+`"`)
+	c.Data(200, doc.ContentType, content)
+}
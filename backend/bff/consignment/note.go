@@ -0,0 +1,198 @@
+package consignment
+
+import (
+	"backend/bff/trip"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var now = time.Now
+
+// FreightTerms says who pays the freight and when
+type FreightTerms string
+
+const (
+	TermsPaid       FreightTerms = "paid"   // consignor paid at booking
+	TermsToPay      FreightTerms = "to_pay" // consignee pays on delivery
+	TermsToBeBilled FreightTerms = "tbb"    // billed to the consignor later
+)
+
+func (f FreightTerms) Valid() bool {
+	switch f {
+	case TermsPaid, TermsToPay, TermsToBeBilled:
+		return true
+	}
+	return false
+}
+
+func (f FreightTerms) Label() string {
+	switch f {
+	case TermsPaid:
+		return "Paid"
+	case TermsToPay:
+		return "To Pay"
+	case TermsToBeBilled:
+		return "To Be Billed"
+	}
+	return string(f)
+}
+
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	ErrNotFound       = errors.New("lorry receipt not found")
+	ErrFinal          = errors.New("lorry receipt can no longer be amended")
+	ErrNoChanges      = errors.New("amendment changes nothing")
+	ErrReasonRequired = errors.New("amendment reason is required")
+	ErrUnknownField   = errors.New("field cannot be amended")
+	ErrInvalidTerms   = errors.New("freight terms must be paid, to_pay or tbb")
+	ErrLinkNotFound   = errors.New("share link not found")
+)
+
+type Party struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone,omitempty"`
+	GSTIN   string `json:"gstin,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// Note is one version of a lorry receipt; every amendment adds a version under the same number
+type Note struct {
+	Number       string       `json:"number"`
+	Version      int          `json:"version"`
+	Status       Status       `json:"status"`
+	TripID       string       `json:"tripId"`
+	BrokerID     string       `json:"brokerId"`
+	DriverID     string       `json:"driverId"`
+	Consignor    Party        `json:"consignor"`
+	Consignee    Party        `json:"consignee"`
+	Vehicle      string       `json:"vehicle"`
+	DriverName   string       `json:"driverName"`
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	Goods        string       `json:"goods"`
+	Weight       string       `json:"weight"`
+	Packages     string       `json:"packages,omitempty"`
+	FreightTerms FreightTerms `json:"freightTerms"`
+	Freight      string       `json:"freight,omitempty"`
+	DocumentID   string       `json:"documentId"`
+	Reason       string       `json:"reason,omitempty"` // why this version was made
+	AmendedBy    string       `json:"amendedBy,omitempty"`
+	IssuedAt     time.Time    `json:"issuedAt"`
+	At           time.Time    `json:"at"`
+}
+
+// fromTrip fills a first version from the trip's booking details
+func fromTrip(t trip.Trip) Note {
+	d := t.Details
+	terms := FreightTerms(d["freightTerms"])
+	if !terms.Valid() {
+		terms = TermsToBeBilled
+	}
+
+	n := Note{
+		Version:  1,
+		Status:   StatusActive,
+		TripID:   t.ID,
+		BrokerID: t.BrokerID,
+		DriverID: t.DriverID,
+		Consignor: Party{
+			Name:    d["senderName"],
+			Phone:   d["senderPhone"],
+			GSTIN:   d["senderGSTIN"],
+			Address: d["senderAddress"],
+		},
+		Consignee: Party{
+			Name:    d["receiverName"],
+			Phone:   d["receiverPhone"],
+			GSTIN:   d["receiverGSTIN"],
+			Address: d["receiverAddress"],
+		},
+		Vehicle:      d["vehicleNumber"],
+		DriverName:   d["driverName"],
+		From:         firstOf(d["origin"], d["originCity"]),
+		To:           firstOf(d["destination"], d["destinationCity"]),
+		Goods:        d["cargo"],
+		Weight:       d["weight"],
+		Packages:     d["packages"],
+		FreightTerms: terms,
+		IssuedAt:     now(),
+	}
+	// the freight amount is only printed when someone collects it against this LR
+	if terms != TermsToBeBilled {
+		n.Freight = d["payment"]
+	}
+	n.At = n.IssuedAt
+	return n
+}
+
+// Amendment changes fields of the latest version
+type Amendment struct {
+	Changes map[string]string
+	Reason  string
+	Actor   string
+}
+
+// Fields that can be amended, keyed by the names the apps send
+var amendable = map[string]func(n *Note) *string{
+	"consigneeName":    func(n *Note) *string { return &n.Consignee.Name },
+	"consigneePhone":   func(n *Note) *string { return &n.Consignee.Phone },
+	"consigneeGSTIN":   func(n *Note) *string { return &n.Consignee.GSTIN },
+	"consigneeAddress": func(n *Note) *string { return &n.Consignee.Address },
+	"to":               func(n *Note) *string { return &n.To },
+	"vehicle":          func(n *Note) *string { return &n.Vehicle },
+	"driverName":       func(n *Note) *string { return &n.DriverName },
+	"goods":            func(n *Note) *string { return &n.Goods },
+	"weight":           func(n *Note) *string { return &n.Weight },
+	"packages":         func(n *Note) *string { return &n.Packages },
+	"freight":          func(n *Note) *string { return &n.Freight },
+	"freightTerms":     func(n *Note) *string { return (*string)(&n.FreightTerms) },
+}
+
+// amend returns the next version with the changes applied
+func (n Note) amend(a Amendment) (Note, error) {
+	if a.Reason == "" {
+		return Note{}, ErrReasonRequired
+	}
+
+	next := n
+	changed := false
+	for field, value := range a.Changes {
+		target, ok := amendable[field]
+		if !ok {
+			return Note{}, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		if p := target(&next); *p != value {
+			*p = value
+			changed = true
+		}
+	}
+	if !changed {
+		return Note{}, ErrNoChanges
+	}
+	if !next.FreightTerms.Valid() {
+		return Note{}, ErrInvalidTerms
+	}
+
+	next.Version++
+	next.Reason = a.Reason
+	next.AmendedBy = a.Actor
+	next.DocumentID = ""
+	next.At = now()
+	return next, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
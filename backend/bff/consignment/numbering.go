@@ -0,0 +1,28 @@
+package consignment
+
+import (
+	"backend/bff"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sequencer hands out LR numbers, one series per broker per financial year
+type Sequencer struct {
+	mu   sync.Mutex
+	last map[string]int
+}
+
+func NewSequencer() *Sequencer {
+	return &Sequencer{last: map[string]int{}}
+}
+
+// Next returns the next number, e.g. "LR/BRK001/2627/00001"
+func (s *Sequencer) Next(brokerID string, date time.Time) string {
+	series := fmt.Sprintf("LR/%s/%s", brokerID, bff.FinancialYear(date))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last[series]++
+	return fmt.Sprintf("%s/%05d", series, s.last[series])
+}
@@ -0,0 +1,89 @@
+package consignment
+
+import (
+	"backend/bff/pdf"
+	"fmt"
+)
+
+const (
+	left  = 50.0
+	right = pdf.PageWidth - 50
+	mid   = 310.0
+)
+
+// PDF renders one version of the lorry receipt
+func (n Note) PDF() []byte {
+	d := pdf.New()
+	d.Text(left, 60, 20, true, "LORRY RECEIPT")
+	d.TextRight(right, 60, 10, true, "LR No: "+n.Number)
+	d.TextRight(right, 75, 10, false, "Date: "+n.IssuedAt.Format("02 Jan 2006"))
+	if n.Version > 1 {
+		d.TextRight(right, 90, 10, false, fmt.Sprintf("Amendment %d, %s", n.Version-1, n.At.Format("02 Jan 2006 15:04")))
+	}
+	if n.Status == StatusCancelled {
+		d.Text(left, 85, 14, true, "CANCELLED")
+	}
+
+	y := 115.0
+	a := partyBlock(d, left, y, "Consignor", n.Consignor)
+	b := partyBlock(d, mid, y, "Consignee", n.Consignee)
+	y = max(a, b) + 10
+
+	d.Shade(left, y, right-left, 20)
+	d.Text(left+5, y+14, 10, true, "From")
+	d.Text(mid+5, y+14, 10, true, "To")
+	y += 36
+	d.Text(left+5, y, 10, false, n.From)
+	d.Text(mid+5, y, 10, false, n.To)
+	y += 25
+
+	d.Shade(left, y, right-left, 20)
+	d.Text(left+5, y+14, 10, true, "Description of Goods")
+	d.Text(300, y+14, 10, true, "Packages")
+	d.TextRight(right-5, y+14, 10, true, "Weight")
+	y += 36
+	d.Text(left+5, y, 10, false, n.Goods)
+	d.Text(300, y, 10, false, n.Packages)
+	d.TextRight(right-5, y, 10, false, n.Weight)
+	y += 12
+	d.Line(left, y, right, y, 0.5)
+
+	y += 20
+	y = row(d, y, "Vehicle No", n.Vehicle)
+	y = row(d, y, "Driver", n.DriverName)
+	y = row(d, y, "Freight Terms", n.FreightTerms.Label())
+	if n.Freight != "" {
+		y = row(d, y, "Freight", n.Freight)
+	}
+	if n.Version > 1 {
+		y = row(d, y, "Amendment Reason", n.Reason)
+	}
+
+	d.Text(left, y+30, 9, false, "Trip "+n.TripID+". Goods carried at owner's risk; subject to the carrier's conditions of carriage.")
+	d.Text(left, y+90, 10, false, "Consignor's Signature")
+	d.TextRight(right, y+90, 10, false, "For the Carrier")
+	return d.Bytes()
+}
+
+func partyBlock(d *pdf.Document, x, y float64, title string, p Party) float64 {
+	d.Text(x, y, 10, true, title)
+	y += 15
+	d.Text(x, y, 10, false, p.Name)
+	for _, line := range []string{p.Address, p.Phone} {
+		if line != "" {
+			y += 15
+			d.Text(x, y, 9, false, line)
+		}
+	}
+	if p.GSTIN != "" {
+		y += 15
+		d.Text(x, y, 9, false, "GSTIN: "+p.GSTIN)
+	}
+	return y + 15
+}
+
+func row(d *pdf.Document, y float64, label, value string) float64 {
+	d.Text(left, y, 10, true, label)
+	d.Text(170, y, 10, false, value)
+	return y + 16
+}
@@ -0,0 +1,199 @@
+package consignment
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/trip"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Document kind lorry receipts are filed under
+const DocumentKind = "lorry_receipt"
+
+// Service issues a lorry receipt for every assigned trip and keeps its versions
+type Service struct {
+	Trips   *trip.Store
+	Docs    docstore.Store
+	Numbers *Sequencer
+
+	mu       sync.Mutex
+	versions map[string][]Note // by LR number, oldest first
+	byTrip   map[string]string
+	links    map[string]Link   // by token
+	shares   map[string][]Link // by LR number
+}
+
+func NewService(trips *trip.Store, docs docstore.Store) *Service {
+	s := &Service{
+		Trips:    trips,
+		Docs:     docs,
+		Numbers:  NewSequencer(),
+		versions: map[string][]Note{},
+		byTrip:   map[string]string{},
+		links:    map[string]Link{},
+		shares:   map[string][]Link{},
+	}
+	trips.OnAssign(func(t trip.Trip) {
+		s.Issue(t)
+	})
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		if e.To == trip.StatusCancelled {
+			s.cancel(t.ID, e.Actor)
+		}
+	})
+
+	// trips assigned before the service started
+	for _, t := range trips.List(func(t trip.Trip) bool { return t.Status != trip.StatusCancelled }) {
+		s.Issue(t)
+	}
+	return s
+}
+
+// Default service used by the BFF handlers
+var Default = NewService(trip.Default, docstore.Default)
+
+// Issue creates the trip's lorry receipt; a trip only ever gets one number
+func (s *Service) Issue(t trip.Trip) (Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if number, ok := s.byTrip[t.ID]; ok {
+		return s.latest(number), nil
+	}
+
+	n := fromTrip(t)
+	n.Number = s.Numbers.Next(t.BrokerID, n.IssuedAt)
+	n, err := s.file(n)
+	if err != nil {
+		return Note{}, err
+	}
+	s.byTrip[t.ID] = n.Number
+
+	for _, l := range []Link{
+		{Role: RoleConsignor, Phone: n.Consignor.Phone},
+		{Role: RoleConsignee, Phone: n.Consignee.Phone},
+	} {
+		l.Token = newToken()
+		l.Number = n.Number
+		l.CreatedAt = n.IssuedAt
+		s.links[l.Token] = l
+		s.shares[n.Number] = append(s.shares[n.Number], l)
+	}
+	return n, nil
+}
+
+// ForTrip returns the latest version of the trip's lorry receipt
+func (s *Service) ForTrip(tripID string) (Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	number, ok := s.byTrip[tripID]
+	if !ok {
+		return Note{}, ErrNotFound
+	}
+	return s.latest(number), nil
+}
+
+// Versions returns every version of a lorry receipt, oldest first
+func (s *Service) Versions(number string) []Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Note(nil), s.versions[number]...)
+}
+
+// Amend files a new version of the trip's lorry receipt; goods in hand can still be corrected, delivered ones cannot
+func (s *Service) Amend(tripID string, a Amendment) (Note, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Note{}, err
+	}
+	if _, delivered := t.Reached(trip.StatusDelivered); delivered {
+		return Note{}, ErrFinal
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	number, ok := s.byTrip[tripID]
+	if !ok {
+		return Note{}, ErrNotFound
+	}
+	current := s.latest(number)
+	if current.Status != StatusActive {
+		return Note{}, ErrFinal
+	}
+	next, err := current.amend(a)
+	if err != nil {
+		return Note{}, err
+	}
+	return s.file(next)
+}
+
+// Links returns the sender and receiver share links of a lorry receipt
+func (s *Service) Links(number string) []Link {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Link(nil), s.shares[number]...)
+}
+
+// Shared returns the latest document behind a share link, so amendments reach both parties
+func (s *Service) Shared(token string) (docstore.Document, []byte, error) {
+	s.mu.Lock()
+	l, ok := s.links[token]
+	var n Note
+	if ok {
+		n = s.latest(l.Number)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return docstore.Document{}, nil, ErrLinkNotFound
+	}
+	return s.Docs.Get(n.DocumentID)
+}
+
+// Owners of an LR document: the broker and the driver carrying it
+func owners(n Note) []string {
+	return []string{n.BrokerID, n.DriverID}
+}
+
+// cancel files a final cancelled version when the trip is called off
+func (s *Service) cancel(tripID, actor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	number, ok := s.byTrip[tripID]
+	if !ok {
+		return
+	}
+	n := s.latest(number)
+	n.Version++
+	n.Status = StatusCancelled
+	n.Reason = "Trip cancelled"
+	n.AmendedBy = actor
+	n.At = now()
+	s.file(n)
+}
+
+// file renders and stores a version; callers hold the lock
+func (s *Service) file(n Note) (Note, error) {
+	doc, err := s.Docs.Put(docstore.Document{
+		Kind:        DocumentKind,
+		Ref:         n.Number,
+		Name:        strings.ReplaceAll(n.Number, "/", "-") + "-v" + strconv.Itoa(n.Version) + ".pdf",
+		ContentType: "application/pdf",
+		Owners:      owners(n),
+	}, n.PDF())
+	if err != nil {
+		return Note{}, err
+	}
+	n.DocumentID = doc.ID
+	s.versions[n.Number] = append(s.versions[n.Number], n)
+	return n, nil
+}
+
+func (s *Service) latest(number string) Note {
+	v := s.versions[number]
+	return v[len(v)-1]
+}
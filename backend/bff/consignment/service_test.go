@@ -0,0 +1,118 @@
+package consignment
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/trip"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestService() (*Service, *trip.Store) {
+	trips := trip.NewStore()
+	s := NewService(trips, docstore.NewMemory())
+	trips.Add(trip.Trip{
+		ID:       "TRK1",
+		DriverID: "DRV1",
+		BrokerID: "BRK1",
+		Status:   trip.StatusAssigned,
+		Details: map[string]string{
+			"senderName":    "Shree Textiles",
+			"senderPhone":   "+919800000001",
+			"receiverName":  "Pune Fabrics",
+			"receiverPhone": "+919800000002",
+			"origin":        "Mumbai",
+			"destination":   "Pune",
+			"freightTerms":  "to_pay",
+			"payment":       "₹24,000",
+		},
+	})
+	return s, trips
+}
+
+func TestIssueOnAssign(t *testing.T) {
+	at := time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s, trips := newTestService()
+	n, err := s.ForTrip("TRK1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Number != "LR/BRK1/2627/00001" || n.Version != 1 || n.DocumentID == "" {
+		t.Errorf("note = %s v%d, document %q", n.Number, n.Version, n.DocumentID)
+	}
+	if n.FreightTerms != TermsToPay || n.Freight != "₹24,000" {
+		t.Errorf("freight = %s %q, want to pay ₹24,000", n.FreightTerms, n.Freight)
+	}
+
+	// issuing again returns the same number
+	tr, _ := trips.Get("TRK1")
+	if again, _ := s.Issue(tr); again.Number != n.Number {
+		t.Errorf("reissued as %s, want %s", again.Number, n.Number)
+	}
+	if links := s.Links(n.Number); len(links) != 2 {
+		t.Errorf("%d share links, want one each for consignor and consignee", len(links))
+	}
+}
+
+func TestAmend(t *testing.T) {
+	tests := []struct {
+		name        string
+		amendment   Amendment
+		wantErr     error
+		wantVersion int
+	}{
+		{name: "corrected weight", amendment: Amendment{Changes: map[string]string{"weight": "12 MT"}, Reason: "Weighbridge slip"}, wantVersion: 2},
+		{name: "no reason", amendment: Amendment{Changes: map[string]string{"weight": "12 MT"}}, wantErr: ErrReasonRequired, wantVersion: 1},
+		{name: "consignor is fixed", amendment: Amendment{Changes: map[string]string{"consignorName": "Someone"}, Reason: "Typo"}, wantErr: ErrUnknownField, wantVersion: 1},
+		{name: "nothing changed", amendment: Amendment{Changes: map[string]string{"to": "Pune"}, Reason: "Typo"}, wantErr: ErrNoChanges, wantVersion: 1},
+		{name: "unknown freight terms", amendment: Amendment{Changes: map[string]string{"freightTerms": "cod"}, Reason: "Typo"}, wantErr: ErrInvalidTerms, wantVersion: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService()
+			_, err := s.Amend("TRK1", tt.amendment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			n, _ := s.ForTrip("TRK1")
+			if n.Version != tt.wantVersion || len(s.Versions(n.Number)) != tt.wantVersion {
+				t.Errorf("version %d with %d filed, want %d", n.Version, len(s.Versions(n.Number)), tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestAmendAfterCancel(t *testing.T) {
+	s, trips := newTestService()
+	if _, err := trips.Transition("TRK1", trip.StatusCancelled, "BRK1", ""); err != nil {
+		t.Fatal(err)
+	}
+	n, _ := s.ForTrip("TRK1")
+	if n.Status != StatusCancelled || n.Version != 2 {
+		t.Fatalf("after cancel: %s v%d", n.Status, n.Version)
+	}
+	if _, err := s.Amend("TRK1", Amendment{Changes: map[string]string{"weight": "12 MT"}, Reason: "Late fix"}); !errors.Is(err, ErrFinal) {
+		t.Errorf("error = %v, want %v", err, ErrFinal)
+	}
+}
+
+func TestSharedFollowsAmendments(t *testing.T) {
+	s, _ := newTestService()
+	n, _ := s.ForTrip("TRK1")
+	token := s.Links(n.Number)[1].Token
+
+	amended, err := s.Amend("TRK1", Amendment{Changes: map[string]string{"consigneeAddress": "Hadapsar, Pune"}, Reason: "Address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := s.Shared(token)
+	if err != nil || doc.ID != amended.DocumentID {
+		t.Errorf("shared %q, %v; want the amended document %q", doc.ID, err, amended.DocumentID)
+	}
+	if _, _, err := s.Shared("nope"); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("error = %v, want %v", err, ErrLinkNotFound)
+	}
+}
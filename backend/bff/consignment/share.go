@@ -0,0 +1,35 @@
+package consignment
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Share link roles
+const (
+	RoleConsignor = "consignor"
+	RoleConsignee = "consignee"
+)
+
+// Link lets the sender or receiver open the latest LR without an account
+type Link struct {
+	Token     string    `json:"token"`
+	Number    string    `json:"number"`
+	Role      string    `json:"role"`
+	Phone     string    `json:"phone,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// URL is the public path the link opens
+func (l Link) URL() string {
+	return "/bff/lr/share/" + l.Token
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,73 @@
+package delivery
+
+import (
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrUnknownTemplate  = errors.New("unknown message template")
+	ErrNoUser           = errors.New("userId is required")
+	ErrInvalidToken     = errors.New("device token is required")
+	ErrInvalidRecipient = errors.New("recipient is not reachable on this channel")
+	ErrInvalidQuietTime = errors.New("quiet hours must be given as HH:MM")
+)
+
+type Channel string
+
+const (
+	ChannelPush Channel = "push"
+	ChannelSMS  Channel = "sms"
+)
+
+func (c Channel) Valid() bool {
+	return c == ChannelPush || c == ChannelSMS
+}
+
+// Message is one rendered notification addressed to a device or phone
+type Message struct {
+	ID      string            `json:"id"`
+	Channel Channel           `json:"channel"`
+	To      string            `json:"to"` // device token or phone number
+	Title   string            `json:"title,omitempty"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Status of a delivery
+type Status string
+
+const (
+	StatusQueued Status = "queued"
+	StatusHeld   Status = "held" // waiting for the user's quiet hours to end
+	StatusSent   Status = "sent"
+	StatusFailed Status = "failed"
+)
+
+// Attempt is one try at handing a message to a provider
+type Attempt struct {
+	Provider   string    `json:"provider"`
+	ProviderID string    `json:"providerId,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Delivery is the record of a message sent to one user over one channel
+type Delivery struct {
+	ID       string    `json:"id"`
+	UserID   string    `json:"userId"`
+	Template string    `json:"template"`
+	Lang     Lang      `json:"lang"`
+	Message  Message   `json:"message"`
+	Status   Status    `json:"status"`
+	Attempts []Attempt `json:"attempts"`
+	NextAt   time.Time `json:"nextAt,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+func (d Delivery) clone() Delivery {
+	d.Attempts = append([]Attempt(nil), d.Attempts...)
+	return d
+}
@@ -0,0 +1,102 @@
+package delivery
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+)
+
+// Settings returns a user's delivery preferences, devices and recent deliveries
+func Settings(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	userID := bff.UserID(c)
+	deliveries := Default.Deliveries(userID)
+	if len(deliveries) > 20 {
+		deliveries = deliveries[:20]
+	}
+
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data: gin.H{
+			"preferences": Default.Preferences(userID),
+			"devices":     Default.Devices(userID),
+			"deliveries":  deliveries,
+		},
+	})
+}
+
+// HandleAction registers devices and updates preferences:
+// "registerDevice" and "unregisterDevice" with a token (and platform),
+// "updatePreferences" with any of lang, push, sms, phone, quietFrom, quietTo
+func HandleAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	userID := bff.UserID(c)
+	token, _ := req.Data["token"].(string)
+	switch req.Action {
+	case "registerDevice":
+		platform, _ := req.Data["platform"].(string)
+		d, err := Default.RegisterDevice(userID, token, platform)
+		if err != nil {
+			c.JSON(400, bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: "Device registered for notifications",
+			Data:    gin.H{"device": d},
+		})
+
+	case "unregisterDevice":
+		if !Default.UnregisterDevice(userID, token) {
+			c.JSON(404, bff.ActionResponse{Status: "error", Message: "Device not registered"})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{Status: "success", Message: "Device removed"})
+
+	case "updatePreferences":
+		p := Default.Preferences(userID)
+		if v, ok := req.Data["lang"].(string); ok {
+			p.Lang = Lang(v)
+		}
+		if v, ok := req.Data["push"].(bool); ok {
+			p.Push = v
+		}
+		if v, ok := req.Data["sms"].(bool); ok {
+			p.SMS = v
+		}
+		if v, ok := req.Data["phone"].(string); ok {
+			p.Phone = v
+		}
+		if v, ok := req.Data["quietFrom"].(string); ok {
+			p.QuietFrom = v
+		}
+		if v, ok := req.Data["quietTo"].(string); ok {
+			p.QuietTo = v
+		}
+
+		p, err := Default.SetPreferences(p)
+		if err != nil {
+			c.JSON(400, bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: "Notification preferences saved",
+			Data:    gin.H{"preferences": p},
+		})
+
+	default:
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Unknown action: " + req.Action})
+	}
+}
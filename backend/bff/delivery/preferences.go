@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quiet hours are set in Indian time whatever the server's zone
+var ist = time.FixedZone("IST", 5*3600+1800)
+
+// Preferences say how a user wants to be reached outside the app
+type Preferences struct {
+	UserID string `json:"userId"`
+	Lang   Lang   `json:"lang"`
+	Push   bool   `json:"push"`
+	SMS    bool   `json:"sms"`
+	Phone  string `json:"phone,omitempty"`
+	// Messages are held from QuietFrom until QuietTo ("22:00" to "06:00");
+	// empty means no quiet hours
+	QuietFrom string `json:"quietFrom,omitempty"`
+	QuietTo   string `json:"quietTo,omitempty"`
+}
+
+func defaultPreferences(userID string) Preferences {
+	return Preferences{UserID: userID, Lang: English, Push: true}
+}
+
+// Enabled reports whether the user accepts messages on c
+func (p Preferences) Enabled(c Channel) bool {
+	switch c {
+	case ChannelPush:
+		return p.Push
+	case ChannelSMS:
+		return p.SMS && p.Phone != ""
+	}
+	return false
+}
+
+func (p Preferences) validate() error {
+	if p.UserID == "" {
+		return ErrNoUser
+	}
+	if !p.Lang.Valid() {
+		return fmt.Errorf("language must be %q or %q", English, Hindi)
+	}
+	if (p.QuietFrom == "") != (p.QuietTo == "") {
+		return ErrInvalidQuietTime
+	}
+	if p.QuietFrom == "" {
+		return nil
+	}
+	if _, err := clock(p.QuietFrom); err != nil {
+		return err
+	}
+	_, err := clock(p.QuietTo)
+	return err
+}
+
+// QuietUntil returns when the quiet hours around t end, if t falls inside them
+func (p Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	from, err1 := clock(p.QuietFrom)
+	to, err2 := clock(p.QuietTo)
+	if err1 != nil || err2 != nil || from == to {
+		return time.Time{}, false
+	}
+
+	local := t.In(ist)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ist)
+	at := local.Sub(day)
+
+	switch {
+	case from < to && at >= from && at < to:
+		return day.Add(to), true
+	case from > to && at >= from: // overnight, before midnight
+		return day.AddDate(0, 0, 1).Add(to), true
+	case from > to && at < to: // overnight, after midnight
+		return day.Add(to), true
+	}
+	return time.Time{}, false
+}
+
+// clock parses "HH:MM" into an offset from midnight
+func clock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidQuietTime
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,186 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Provider hands messages on one channel to the outside world
+type Provider interface {
+	Name() string
+	// Send returns the provider's message ID. ErrInvalidRecipient means
+	// retrying will not help, e.g. an expired device token.
+	Send(m Message) (string, error)
+}
+
+// Console notes each message in the log; for local runs. Device tokens and
+// phone numbers are credentials and personal data, so only their last
+// characters are logged, and the text not at all.
+type Console struct{}
+
+func (Console) Name() string {
+	return "console"
+}
+
+func (Console) Send(m Message) (string, error) {
+	log.Printf("[%s] %s to %s: %s", m.Channel, m.ID, redact(m.To), m.Data["template"])
+	return "console-" + m.ID, nil
+}
+
+// redact keeps the last four characters of a token or number
+func redact(to string) string {
+	if len(to) <= 4 {
+		return "****"
+	}
+	return "****" + to[len(to)-4:]
+}
+
+// File appends messages as JSON lines to Path; for local runs and demos
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+func (f *File) Name() string {
+	return "file"
+}
+
+func (f *File) Send(m Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return "", err
+	}
+	out, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	line, _ := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sentAt"`
+	}{m, now()})
+	if _, err := out.Write(append(line, '\n')); err != nil {
+		return "", err
+	}
+	return "file-" + m.ID, nil
+}
+
+// FCM sends push messages through the Firebase Cloud Messaging HTTP v1 API
+type FCM struct {
+	ProjectID string
+	// OAuth access token for the service account
+	AccessToken string
+	Endpoint    string
+	Client      *http.Client
+}
+
+func NewFCM(projectID, accessToken string) *FCM {
+	return &FCM{
+		ProjectID:   projectID,
+		AccessToken: accessToken,
+		Endpoint:    "https://fcm.googleapis.com/v1/projects/" + projectID + "/messages:send",
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (f *FCM) Name() string {
+	return "fcm"
+}
+
+func (f *FCM) Send(m Message) (string, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        m.To,
+			"notification": map[string]string{"title": m.Title, "body": m.Body},
+			"data":         m.Data,
+		},
+	})
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	status, err := post(f.Client, f.Endpoint, "Bearer "+f.AccessToken, body, &out)
+	// FCM answers 404 UNREGISTERED for tokens of uninstalled apps
+	if status == http.StatusNotFound || status == http.StatusBadRequest {
+		return "", ErrInvalidRecipient
+	}
+	return out.Name, err
+}
+
+// Gateway sends SMS through an HTTP gateway that takes a JSON body of
+// sender, to and message and answers with a message ID
+type Gateway struct {
+	URL    string
+	APIKey string
+	// Registered sender ID, e.g. "LOGIBR"
+	Sender string
+	Client *http.Client
+}
+
+func NewGateway(url, apiKey, sender string) *Gateway {
+	return &Gateway{
+		URL:    url,
+		APIKey: apiKey,
+		Sender: sender,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *Gateway) Name() string {
+	return "sms-gateway"
+}
+
+func (g *Gateway) Send(m Message) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"sender":  g.Sender,
+		"to":      m.To,
+		"message": m.Body,
+	})
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	status, err := post(g.Client, g.URL, "Bearer "+g.APIKey, body, &out)
+	if status == http.StatusUnprocessableEntity {
+		return "", ErrInvalidRecipient
+	}
+	return out.ID, err
+}
+
+// post sends a JSON body and decodes a 2xx reply into out
+func post(client *http.Client, url, auth string, body []byte, out interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("%s answered %d: %s", url, resp.StatusCode, bytes.TrimSpace(raw))
+	}
+	return resp.StatusCode, json.Unmarshal(raw, out)
+}
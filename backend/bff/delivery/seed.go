@@ -0,0 +1,26 @@
+package delivery
+
+import "backend/bff/trip"
+
+// seed gives the demo driver and broker a phone each and their preferences
+func seed(s *Service) *Service {
+	s.RegisterDevice(trip.DemoDriverID, "demo-driver-android-token", "android")
+	s.RegisterDevice(trip.DemoBrokerID, "demo-broker-ios-token", "ios")
+
+	s.SetPreferences(Preferences{
+		UserID:    trip.DemoDriverID,
+		Lang:      Hindi,
+		Push:      true,
+		SMS:       true,
+		Phone:     "+91 98765 43210",
+		QuietFrom: "22:00",
+		QuietTo:   "06:00",
+	})
+	s.SetPreferences(Preferences{
+		UserID: trip.DemoBrokerID,
+		Lang:   English,
+		Push:   true,
+		Phone:  "+91 9876543210",
+	})
+	return s
+}
@@ -0,0 +1,372 @@
+package delivery
+
+import (
+	"backend/bff/events"
+	"backend/bff/vehicle"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Device is a phone registered for push messages
+type Device struct {
+	Token        string    `json:"token"`
+	UserID       string    `json:"userId"`
+	Platform     string    `json:"platform"` // android or ios
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// Service renders messages for users who are away from the app and sends
+// them over push and SMS, respecting their preferences and quiet hours.
+// Messages go out on the next Dispatch; failed sends are retried after each
+// Backoff step, then given up.
+type Service struct {
+	Push    Provider
+	SMS     Provider
+	Backoff []time.Duration
+
+	mu         sync.Mutex
+	seq        int
+	devices    map[string]*Device
+	prefs      map[string]Preferences
+	deliveries map[string]*Delivery
+}
+
+func NewService(push, sms Provider) *Service {
+	return &Service{
+		Push:       push,
+		SMS:        sms,
+		Backoff:    []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute},
+		devices:    map[string]*Device{},
+		prefs:      map[string]Preferences{},
+		deliveries: map[string]*Delivery{},
+	}
+}
+
+// Default service logs push messages without their content and writes SMS
+// to a file in the temp directory; swap in NewFCM and NewGateway for real
+// delivery
+var Default = seed(listen(NewService(
+	Console{},
+	NewFile(filepath.Join(os.TempDir(), "logibroker", "sms.jsonl")),
+), events.Default, vehicle.Default))
+
+// RegisterDevice ties a push token to a user; a token moves with the
+// latest user who signs in on that phone
+func (s *Service) RegisterDevice(userID, token, platform string) (Device, error) {
+	if token == "" || userID == "" {
+		return Device{}, ErrInvalidToken
+	}
+	if platform == "" {
+		platform = "android"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := &Device{Token: token, UserID: userID, Platform: platform, RegisteredAt: now()}
+	s.devices[token] = d
+	return *d, nil
+}
+
+// UnregisterDevice forgets a user's token, e.g. on logout; a token now
+// registered to someone else is left alone
+func (s *Service) UnregisterDevice(userID, token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.devices[token]
+	if !ok || d.UserID != userID {
+		return false
+	}
+	delete(s.devices, token)
+	return true
+}
+
+func (s *Service) Devices(userID string) []Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.devicesOf(userID)
+}
+
+func (s *Service) devicesOf(userID string) []Device {
+	var out []Device
+	for _, d := range s.devices {
+		if d.UserID == userID {
+			out = append(out, *d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RegisteredAt.Before(out[j].RegisteredAt) })
+	return out
+}
+
+func (s *Service) Preferences(userID string) Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.preferencesOf(userID)
+}
+
+func (s *Service) preferencesOf(userID string) Preferences {
+	if p, ok := s.prefs[userID]; ok {
+		return p
+	}
+	return defaultPreferences(userID)
+}
+
+func (s *Service) SetPreferences(p Preferences) (Preferences, error) {
+	if p.Lang == "" {
+		p.Lang = English
+	}
+	if err := p.validate(); err != nil {
+		return Preferences{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prefs[p.UserID] = p
+	return p, nil
+}
+
+// Notify renders a template for the user and queues it on every channel
+// they have enabled: one push per registered device and one SMS
+func (s *Service) Notify(userID, template string, vars map[string]string) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.preferencesOf(userID)
+	text, err := Render(template, p.Lang, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var to []Message
+	if p.Enabled(ChannelPush) {
+		for _, d := range s.devicesOf(userID) {
+			to = append(to, Message{Channel: ChannelPush, To: d.Token, Title: text.Title})
+		}
+	}
+	if p.Enabled(ChannelSMS) {
+		to = append(to, Message{Channel: ChannelSMS, To: p.Phone})
+	}
+
+	at := now()
+	held, quiet := p.QuietUntil(at)
+
+	var out []Delivery
+	for _, m := range to {
+		s.seq++
+		m.ID = fmt.Sprintf("MSG%06d", s.seq)
+		m.Body = text.Body
+		m.Data = map[string]string{"template": template}
+		for k, v := range vars {
+			m.Data[k] = v
+		}
+
+		d := &Delivery{
+			ID:       m.ID,
+			UserID:   userID,
+			Template: template,
+			Lang:     p.Lang,
+			Message:  m,
+			Status:   StatusQueued,
+			NextAt:   at,
+			At:       at,
+		}
+		if quiet {
+			d.Status, d.NextAt = StatusHeld, held
+		}
+		s.deliveries[d.ID] = d
+		out = append(out, d.clone())
+	}
+	return out, nil
+}
+
+// Text sends an SMS to a number that may not belong to a user yet, such as
+// an invitation; there are no preferences to honour, so it goes in English
+// straight away
+func (s *Service) Text(phone, template string, vars map[string]string) (Delivery, error) {
+	if phone == "" {
+		return Delivery{}, ErrInvalidRecipient
+	}
+	text, err := Render(template, English, vars)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	m := Message{
+		ID:      fmt.Sprintf("MSG%06d", s.seq),
+		Channel: ChannelSMS,
+		To:      phone,
+		Body:    text.Body,
+		Data:    map[string]string{"template": template},
+	}
+	for k, v := range vars {
+		m.Data[k] = v
+	}
+	at := now()
+	d := &Delivery{
+		ID:       m.ID,
+		Template: template,
+		Lang:     English,
+		Message:  m,
+		Status:   StatusQueued,
+		NextAt:   at,
+		At:       at,
+	}
+	s.deliveries[d.ID] = d
+	return d.clone(), nil
+}
+
+// Deliveries lists a user's delivery records, newest first
+func (s *Service) Deliveries(userID string) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Delivery
+	for _, d := range s.deliveries {
+		if d.UserID == userID {
+			out = append(out, d.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].At.Equal(out[j].At) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].At.After(out[j].At)
+	})
+	return out
+}
+
+// Dispatch sends every delivery due by at: new messages, held ones whose
+// quiet hours have ended and retries whose backoff has passed. Deliveries
+// are sent oldest first; only one Dispatch should run at a time.
+func (s *Service) Dispatch(at time.Time) {
+	s.mu.Lock()
+	var due []*Delivery
+	for _, d := range s.deliveries {
+		if (d.Status == StatusQueued || d.Status == StatusHeld) && !d.NextAt.After(at) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAt.Equal(due[j].NextAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextAt.Before(due[j].NextAt)
+	})
+	ids := make([]string, len(due))
+	for i, d := range due {
+		ids[i] = d.ID
+	}
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.attempt(id)
+	}
+}
+
+// Watch dispatches due deliveries every so often
+func (s *Service) Watch(every time.Duration) {
+	time.AfterFunc(every, func() {
+		s.Dispatch(now())
+		s.Watch(every)
+	})
+}
+
+// attempt sends a queued or held delivery once and records the outcome
+func (s *Service) attempt(id string) {
+	s.mu.Lock()
+	d, ok := s.deliveries[id]
+	if !ok || d.Status == StatusSent || d.Status == StatusFailed {
+		s.mu.Unlock()
+		return
+	}
+	m := d.Message
+	provider := s.Push
+	if m.Channel == ChannelSMS {
+		provider = s.SMS
+	}
+	s.mu.Unlock()
+
+	// the provider may be a slow network call, so send without the lock
+	providerID, err := provider.Send(m)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := Attempt{Provider: provider.Name(), ProviderID: providerID, At: now()}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	d.Attempts = append(d.Attempts, a)
+
+	switch {
+	case err == nil:
+		d.Status, d.NextAt = StatusSent, time.Time{}
+	case errors.Is(err, ErrInvalidRecipient):
+		d.Status, d.NextAt = StatusFailed, time.Time{}
+		if m.Channel == ChannelPush {
+			delete(s.devices, m.To)
+		}
+	case len(d.Attempts) > len(s.Backoff):
+		d.Status, d.NextAt = StatusFailed, time.Time{}
+	default:
+		d.Status, d.NextAt = StatusQueued, a.At.Add(s.Backoff[len(d.Attempts)-1])
+	}
+}
+
+// listen sends the events worth a push or SMS to the people they concern.
+// It only follows new events so restarts do not resend old news.
+func listen(s *Service, bus *events.Bus, fleet *vehicle.Service) *Service {
+	bus.Follow(func(e events.Event) {
+		switch e.Type {
+		case events.MemberInvited:
+			s.Text(e.Data["phone"], OrgInvite, e.Data)
+			return
+		case events.LoadPosted:
+			// a new load goes to every free driver whose truck can carry it
+			for _, v := range fleet.Idle(e.Data["vehicleType"]) {
+				s.Notify(v.DriverID, LoadMatch, e.Data)
+			}
+			return
+		}
+		userID, template := route(e)
+		if userID == "" {
+			return
+		}
+		vars := map[string]string{"tripId": e.Subject}
+		for k, v := range e.Data {
+			vars[k] = v
+		}
+		s.Notify(userID, template, vars)
+	})
+	return s
+}
+
+// route picks the recipient and template for an event
+func route(e events.Event) (string, string) {
+	switch e.Type {
+	case events.TripAssigned:
+		// trips booked against a load are announced as an accepted bid
+		if e.Data["loadId"] == "" {
+			return e.DriverID, TripAssigned
+		}
+	case events.LoadAssigned:
+		return e.DriverID, BidAccepted
+	case events.PaymentReleased:
+		return e.DriverID, PaymentReleased
+	case events.PODApproved:
+		return e.DriverID, PODApproved
+	case events.PODRejected:
+		return e.DriverID, PODRejected
+	}
+	return "", ""
+}
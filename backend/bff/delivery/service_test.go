@@ -0,0 +1,200 @@
+package delivery
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// script is a provider that answers with its errors in turn, then succeeds
+type script struct {
+	errs []error
+	sent int
+}
+
+func (p *script) Name() string {
+	return "script"
+}
+
+func (p *script) Send(m Message) (string, error) {
+	p.sent++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		if err != nil {
+			return "", err
+		}
+	}
+	return "sent-" + m.ID, nil
+}
+
+func newTestService(push *script) *Service {
+	s := NewService(push, &script{})
+	s.Backoff = []time.Duration{30 * time.Second, 2 * time.Minute}
+	s.RegisterDevice("DRV1", "token-1", "android")
+	return s
+}
+
+func TestQuietHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		at       time.Time
+		wantHeld time.Time // zero when the message goes at once
+	}{
+		{name: "daytime", at: time.Date(2025, 3, 1, 12, 0, 0, 0, ist)},
+		{name: "before midnight", at: time.Date(2025, 3, 1, 23, 0, 0, 0, ist), wantHeld: time.Date(2025, 3, 2, 6, 0, 0, 0, ist)},
+		{name: "after midnight", at: time.Date(2025, 3, 2, 5, 0, 0, 0, ist), wantHeld: time.Date(2025, 3, 2, 6, 0, 0, 0, ist)},
+		{name: "as quiet hours end", at: time.Date(2025, 3, 2, 6, 0, 0, 0, ist)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := tt.at
+			now = func() time.Time { return at }
+			defer func() { now = time.Now }()
+
+			push := &script{}
+			s := newTestService(push)
+			s.SetPreferences(Preferences{UserID: "DRV1", Push: true, QuietFrom: "22:00", QuietTo: "06:00"})
+
+			ds, err := s.Notify("DRV1", PaymentReleased, map[string]string{"amount": "₹24,000"})
+			if err != nil || len(ds) != 1 {
+				t.Fatalf("Notify = %d deliveries, %v", len(ds), err)
+			}
+			s.Dispatch(at)
+			if tt.wantHeld.IsZero() {
+				if push.sent != 1 {
+					t.Errorf("sent %d, want 1", push.sent)
+				}
+				return
+			}
+			if ds[0].Status != StatusHeld || !ds[0].NextAt.Equal(tt.wantHeld) {
+				t.Errorf("delivery %s until %s, want held until %s", ds[0].Status, ds[0].NextAt, tt.wantHeld)
+			}
+			if push.sent != 0 {
+				t.Fatalf("sent %d during quiet hours", push.sent)
+			}
+			at = tt.wantHeld
+			s.Dispatch(at)
+			if push.sent != 1 {
+				t.Errorf("sent %d once quiet hours ended, want 1", push.sent)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	down := errors.New("provider unavailable")
+	type step struct {
+		after      time.Duration // since the message was queued
+		wantSent   int
+		wantStatus Status
+	}
+	tests := []struct {
+		name  string
+		errs  []error
+		steps []step
+	}{
+		{
+			name: "sent first time",
+			steps: []step{
+				{wantSent: 1, wantStatus: StatusSent},
+			},
+		},
+		{
+			name: "retried after each backoff",
+			errs: []error{down, down},
+			steps: []step{
+				{wantSent: 1, wantStatus: StatusQueued},
+				{after: 29 * time.Second, wantSent: 1, wantStatus: StatusQueued},
+				{after: 30 * time.Second, wantSent: 2, wantStatus: StatusQueued},
+				{after: 2 * time.Minute, wantSent: 2, wantStatus: StatusQueued},
+				{after: 2*time.Minute + 30*time.Second, wantSent: 3, wantStatus: StatusSent},
+			},
+		},
+		{
+			name: "given up after the last backoff",
+			errs: []error{down, down, down, down},
+			steps: []step{
+				{wantSent: 1, wantStatus: StatusQueued},
+				{after: 30 * time.Second, wantSent: 2, wantStatus: StatusQueued},
+				{after: 2*time.Minute + 30*time.Second, wantSent: 3, wantStatus: StatusFailed},
+				{after: time.Hour, wantSent: 3, wantStatus: StatusFailed},
+			},
+		},
+		{
+			name: "unreachable recipient is not retried",
+			errs: []error{ErrInvalidRecipient},
+			steps: []step{
+				{wantSent: 1, wantStatus: StatusFailed},
+				{after: time.Hour, wantSent: 1, wantStatus: StatusFailed},
+			},
+		},
+	}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, ist)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := start
+			now = func() time.Time { return at }
+			defer func() { now = time.Now }()
+
+			push := &script{errs: tt.errs}
+			s := newTestService(push)
+			if _, err := s.Notify("DRV1", PaymentReleased, nil); err != nil {
+				t.Fatal(err)
+			}
+			for i, st := range tt.steps {
+				at = start.Add(st.after)
+				s.Dispatch(at)
+				d := s.Deliveries("DRV1")[0]
+				if push.sent != st.wantSent || d.Status != st.wantStatus {
+					t.Errorf("step %d: sent %d, %s; want %d, %s", i, push.sent, d.Status, st.wantSent, st.wantStatus)
+				}
+				if len(d.Attempts) != push.sent {
+					t.Errorf("step %d: %d attempts recorded for %d sends", i, len(d.Attempts), push.sent)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidTokenIsForgotten(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, ist)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	push := &script{errs: []error{ErrInvalidRecipient}}
+	s := newTestService(push)
+	at = at.Add(time.Minute)
+	s.RegisterDevice("DRV1", "token-2", "ios")
+
+	if _, err := s.Notify("DRV1", PaymentReleased, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.Dispatch(at)
+
+	// the first device's token was refused; the second still gets messages
+	devices := s.Devices("DRV1")
+	if len(devices) != 1 || devices[0].Token != "token-2" {
+		t.Fatalf("devices = %+v, want only token-2", devices)
+	}
+	ds, _ := s.Notify("DRV1", PaymentReleased, nil)
+	if len(ds) != 1 || ds[0].Message.To != "token-2" {
+		t.Errorf("next message went to %+v, want token-2 only", ds)
+	}
+}
+
+func TestConsoleRedacts(t *testing.T) {
+	tests := []struct {
+		to   string
+		want string
+	}{
+		{to: "fcm-device-token-abcd1234", want: "****1234"},
+		{to: "+91 98765 43210", want: "****3210"},
+		{to: "abc", want: "****"},
+	}
+	for _, tt := range tests {
+		if got := redact(tt.to); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package delivery
+
+import "strings"
+
+type Lang string
+
+const (
+	English Lang = "en"
+	Hindi   Lang = "hi"
+)
+
+func (l Lang) Valid() bool {
+	return l == English || l == Hindi
+}
+
+// Template keys
+const (
+	LoadMatch       = "load_match"
+	BidAccepted     = "bid_accepted"
+	TripAssigned    = "trip_assigned"
+	PaymentReleased = "payment_released"
+	PODApproved     = "pod_approved"
+	PODRejected     = "pod_rejected"
+	OrgInvite       = "org_invite"
+)
+
+// Text is a message in one language; {name} placeholders are filled from vars
+type Text struct {
+	Title string
+	Body  string
+}
+
+var templates = map[string]map[Lang]Text{
+	LoadMatch: {
+		English: {"New load for you", "{origin} to {destination}, {weight}. Freight {amount}. Open the app to bid."},
+		Hindi:   {"आपके लिए नया लोड", "{origin} से {destination}, {weight}. भाड़ा {amount}. बोली लगाने के लिए ऐप खोलें।"},
+	},
+	BidAccepted: {
+		English: {"Bid accepted", "Your bid for {origin} to {destination} was accepted. Trip {tripId}."},
+		Hindi:   {"बोली स्वीकार", "{origin} से {destination} के लिए आपकी बोली स्वीकार हो गई। ट्रिप {tripId}."},
+	},
+	TripAssigned: {
+		English: {"New trip assigned", "Trip {tripId}: {origin} to {destination}. Open the app for details."},
+		Hindi:   {"नई ट्रिप मिली", "ट्रिप {tripId}: {origin} से {destination}. जानकारी के लिए ऐप खोलें।"},
+	},
+	PaymentReleased: {
+		English: {"Payment received", "{amount} for trip {tripId} is credited to your wallet."},
+		Hindi:   {"भुगतान मिला", "ट्रिप {tripId} के लिए {amount} आपके वॉलेट में जमा हो गए।"},
+	},
+	PODApproved: {
+		English: {"POD approved", "Proof of delivery for trip {tripId} is approved. The balance payment is on its way."},
+		Hindi:   {"POD स्वीकृत", "ट्रिप {tripId} का डिलीवरी प्रमाण स्वीकार हो गया। बकाया भुगतान जल्द मिलेगा।"},
+	},
+	PODRejected: {
+		English: {"POD rejected", "Proof of delivery for trip {tripId} was rejected: {reason}. Please upload it again."},
+		Hindi:   {"POD अस्वीकृत", "ट्रिप {tripId} का डिलीवरी प्रमाण अस्वीकार: {reason}. कृपया फिर से अपलोड करें।"},
+	},
+	OrgInvite: {
+		English: {"Join {orgName}", "{inviter} invited you to {orgName} as {roles}. Sign in with this number to accept."},
+		Hindi:   {"{orgName} से जुड़ें", "{inviter} ने आपको {orgName} में {roles} के रूप में जोड़ा है। स्वीकार करने के लिए इसी नंबर से साइन इन करें।"},
+	},
+}
+
+// Render fills a template in lang, falling back to English
+func Render(key string, lang Lang, vars map[string]string) (Text, error) {
+	byLang, ok := templates[key]
+	if !ok {
+		return Text{}, ErrUnknownTemplate
+	}
+	t, ok := byLang[lang]
+	if !ok {
+		t = byLang[English]
+	}
+
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+	return Text{Title: r.Replace(t.Title), Body: r.Replace(t.Body)}, nil
+}
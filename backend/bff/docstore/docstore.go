@@ -0,0 +1,113 @@
+package docstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+var ErrNotFound = errors.New("document not found")
+
+// Document is the metadata of a stored file
+type Document struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Ref         string    `json:"ref"` // what the document is about, e.g. a payment ID
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Owners      []string  `json:"-"` // driver and broker IDs allowed to download it
+	Size        int       `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (d Document) OwnedBy(userID string) bool {
+	for _, o := range d.Owners {
+		if o != "" && o == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// URL is the download path the apps open
+func (d Document) URL(userID string) string {
+	return URL(d.ID, userID)
+}
+
+// URL is the download path of a document by ID
+func URL(id, userID string) string {
+	return fmt.Sprintf("/bff/documents/%s?userId=%s", id, userID)
+}
+
+// Store keeps generated and uploaded documents
+type Store interface {
+	Put(doc Document, content []byte) (Document, error)
+	Get(id string) (Document, []byte, error)
+	// Find returns the latest document of a kind about a ref
+	Find(kind, ref string) (Document, bool)
+}
+
+// Memory is a Store that keeps documents in process memory
+type Memory struct {
+	mu    sync.RWMutex
+	seq   int
+	docs  map[string]Document
+	files map[string][]byte
+	order []string
+}
+
+func NewMemory() *Memory {
+	return &Memory{docs: map[string]Document{}, files: map[string][]byte{}}
+}
+
+// Default store used by the BFF handlers
+var Default Store = NewMemory()
+
+func (m *Memory) Put(doc Document, content []byte) (Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	sum := sha256.Sum256(content)
+	doc.ID = fmt.Sprintf("DOC%06d", m.seq)
+	doc.Size = len(content)
+	doc.SHA256 = hex.EncodeToString(sum[:])
+	doc.Owners = append([]string(nil), doc.Owners...)
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = now()
+	}
+
+	m.docs[doc.ID] = doc
+	m.files[doc.ID] = append([]byte(nil), content...)
+	m.order = append(m.order, doc.ID)
+	return doc, nil
+}
+
+func (m *Memory) Get(id string) (Document, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	doc, ok := m.docs[id]
+	if !ok {
+		return Document{}, nil, ErrNotFound
+	}
+	return doc, m.files[id], nil
+}
+
+func (m *Memory) Find(kind, ref string) (Document, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.order) - 1; i >= 0; i-- {
+		if doc := m.docs[m.order[i]]; doc.Kind == kind && doc.Ref == ref {
+			return doc, true
+		}
+	}
+	return Document{}, false
+}
@@ -0,0 +1,23 @@
+package docstore
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+)
+
+// Download serves a stored document to one of its owners
+func Download(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	doc, content, err := Default.Get(c.Param("id"))
+	if err != nil || !doc.OwnedBy(c.Query("userId")) {
+		c.JSON(404, bff.ActionResponse{
+			Status:  "error",
+			Message: ErrNotFound.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+doc.Name+`"`)
+	c.Data(200, doc.ContentType, content)
+}
@@ -2,6 +2,11 @@ package driver
 
 import (
 	"backend/bff"
+	"backend/bff/analytics"
+	"backend/bff/expense"
+	"backend/bff/safety"
+	"backend/bff/trip"
+	"fmt"
 	"time"
 	"github.com/gin-gonic/gin"
 )
@@ -18,7 +23,7 @@ func TripCompletedScreen(c *gin.Context) {
 		"distance":      "1,450 km",
 		"duration":      "2 days 4 hours",
 		"payment":       "₹45,000",
-		"rating":        "4.8",
+		"rating":        "4.8/5",
 		"completedAt":   completedAt.Format("Monday, 2 January 2006"),
 		"completedTime": completedAt.Format("15:04"),
 	}
@@ -31,12 +36,42 @@ func TripCompletedScreen(c *gin.Context) {
 		{ID: "safety", Name: "Safe Driving", Description: "98%"},
 	}
 
+	// The driver's finished trip, when there is one
+	driver := driverID(c)
+	var ratingPrompt []bff.UISnippet
+	var safetySection []bff.UISnippet
+	t, found := deliveredTrip(driver, c.Query("tripId"))
+	if found {
+		details := liveDetails(t)
+		completedAt, _ = t.Reached(trip.StatusDelivered)
+		tripData["tripId"] = t.ID
+		tripData["origin"] = details["originCity"]
+		tripData["destination"] = details["destinationCity"]
+		tripData["payment"] = details["payment"]
+		tripData["completedAt"] = completedAt.Format("Monday, 2 January 2006")
+		tripData["completedTime"] = completedAt.Format("15:04")
+		tripData["rating"] = "awaiting broker"
+		ratingPrompt = tripRatingSection(t, driver)
+		report := safety.Default.Report(t.ID)
+		tripStats = safetyStat(report, tripStats)
+		safetySection = tripSafetySection(report)
+
+		if st, ok := analytics.Default.Trip(t); ok {
+			tripData["distance"] = bff.Km(st.Km)
+			tripData["duration"] = bff.Hours(st.Duration)
+			if st.Rating > 0 {
+				tripData["rating"] = fmt.Sprintf("%.1f/5", st.Rating)
+			}
+			tripStats = tripCompletedStats(st, tripStats)
+		}
+	}
+
 	// Build UI snippets
 	ui := []bff.UISnippet{
 		{
 			Type: "VIEW",
 			Data: bff.ViewData{Flex: 1, BackgroundColor: "#F8FAFC"},
-			Children: []bff.UISnippet{
+			Children: bff.Join([]bff.UISnippet{
 				// Success Header
 				{
 					Type: "TEXT",
@@ -117,7 +152,7 @@ func TripCompletedScreen(c *gin.Context) {
 								},
 								{
 									Type: "TEXT",
-									Data: bff.TextData{Text: "Rating: " + tripData["rating"] + "/5", FontSize: 18, FontWeight: "700"},
+									Data: bff.TextData{Text: "Rating: " + tripData["rating"], FontSize: 18, FontWeight: "700"},
 								},
 							},
 						},
@@ -153,29 +188,44 @@ func TripCompletedScreen(c *gin.Context) {
 						return stats
 					}(),
 				},
+			},
+				// Driving safety
+				safetySection,
+				// Trip Expenses
+				tripExpensesSection(expense.Default.Summary(tripData["tripId"])),
+				// Rate the broker
+				ratingPrompt,
 				// Action Buttons
-				{
-					Type: "BUTTON",
-					Data: bff.ButtonData{
-						Text:   "Go to Home",
-						Action: bff.ActionData{Type: "NAVIGATE", Navigate: "/(footbar)/home"},
+				[]bff.UISnippet{
+					{
+						Type: "BUTTON",
+						Data: bff.ButtonData{
+							Text:   "Go to Home",
+							Action: bff.ActionData{Type: "NAVIGATE", Navigate: "/(footbar)/home"},
+						},
 					},
-				},
-				{
-					Type: "BUTTON",
-					Data: bff.ButtonData{
-						Text:   "Rate This Trip",
-						Action: bff.ActionData{Type: "ACTION", Value: "rateTrip"},
+					{
+						Type: "BUTTON",
+						Data: bff.ButtonData{
+							Text: "Download Receipt",
+							Action: bff.ActionData{
+								Type:   "ACTION",
+								Value:  "DOWNLOAD_RECEIPT",
+								Url:    "/bff/driver/home/action",
+								Method: "POST",
+								Data:   map[string]interface{}{"tripId": tripData["tripId"]},
+							},
+						},
 					},
-				},
-				{
-					Type: "BUTTON",
-					Data: bff.ButtonData{
-						Text:   "View Detailed Report",
-						Action: bff.ActionData{Type: "ACTION", Value: "viewTripDetails"},
+					{
+						Type: "BUTTON",
+						Data: bff.ButtonData{
+							Text:   "View Detailed Report",
+							Action: bff.ActionData{Type: "ACTION", Value: "viewTripDetails"},
+						},
 					},
 				},
-			},
+			),
 		},
 	}
 
@@ -187,3 +237,82 @@ func TripCompletedScreen(c *gin.Context) {
 
 	c.JSON(200, response)
 }
+
+// deliveredTrip finds the requested trip, or else the driver's latest delivered one
+func deliveredTrip(driverID, tripID string) (trip.Trip, bool) {
+	trips := trip.Default.List(func(t trip.Trip) bool {
+		_, delivered := t.Reached(trip.StatusDelivered)
+		return t.DriverID == driverID && delivered && (tripID == "" || t.ID == tripID)
+	})
+	if len(trips) == 0 {
+		return trip.Trip{}, false
+	}
+	return trips[len(trips)-1], true
+}
+
+// Helper function to replace the sample stats with how the trip actually went
+func tripCompletedStats(st analytics.TripStats, stats []bff.RouteData) []bff.RouteData {
+	onTime := "On time"
+	if !st.OnTime {
+		onTime = "Late by " + bff.Hours(st.Late)
+	}
+
+	out := []bff.RouteData{
+		{ID: "distance", Name: "Distance Covered", Description: bff.Km(st.Km)},
+		{ID: "ontime", Name: "On-time Delivery", Description: onTime},
+		{ID: "earningsPerKm", Name: "Earnings per Km", Description: st.EarningsPerKm.String()},
+	}
+	if st.FuelPerKm > 0 {
+		out = append(out, bff.RouteData{ID: "fuel", Name: "Fuel Cost per Km", Description: st.FuelPerKm.String()})
+	}
+	for _, s := range stats {
+		if s.ID == "safety" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Helper function to summarize what the driver spent on the trip
+func tripExpensesSection(sum expense.Summary) []bff.UISnippet {
+	if sum.Count == 0 {
+		return nil
+	}
+
+	rows := []bff.UISnippet{
+		{
+			Type: "TEXT",
+			Data: bff.TextData{Text: "Trip Expenses", FontSize: 18, FontWeight: "700", Color: "#1E293B"},
+		},
+	}
+	for _, c := range sum.Categories {
+		rows = append(rows, expenseRow(fmt.Sprintf("%s (%d)", c.Category.Label(), c.Count), c.Amount.String(), "#475569"))
+	}
+
+	reimbursed := "Reimbursable (pending)"
+	if sum.Reimbursed {
+		reimbursed = "Reimbursed to wallet"
+	}
+	rows = append(rows,
+		expenseRow("Total Spent", sum.Total.String(), "#1E293B"),
+		expenseRow(reimbursed, sum.Reimbursable.String(), "#059669"),
+		expenseRow("Borne by You", sum.Deductible.String(), "#DC2626"),
+	)
+
+	return []bff.UISnippet{{
+		Type:     "VIEW",
+		Data:     bff.ViewData{BackgroundColor: "#FFFFFF", BorderRadius: 16, Padding: 16, Gap: 8},
+		Children: rows,
+	}}
+}
+
+func expenseRow(label, value, color string) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{FlexDirection: "row", JustifyContent: "space-between"},
+		Children: []bff.UISnippet{
+			{Type: "TEXT", Data: bff.TextData{Text: label, FontSize: 14, Color: "#64748B"}},
+			{Type: "TEXT", Data: bff.TextData{Text: value, FontSize: 14, FontWeight: "600", Color: color}},
+		},
+	}
+}
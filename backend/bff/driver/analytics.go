@@ -0,0 +1,247 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/analytics"
+	"backend/bff/ledger"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+func AnalyticsScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	period := analytics.ParsePeriod(c.Query("period"))
+	report := analytics.Default.Report(driverID(c), period)
+
+	var labels []string
+	var earnings, km, trips []float64
+	for _, b := range report.Buckets {
+		labels = append(labels, b.Label)
+		earnings = append(earnings, float64(b.Earnings)/100)
+		km = append(km, float64(int(b.Km+0.5)))
+		trips = append(trips, float64(b.Trips))
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "STATUS_BAR",
+			Data: bff.StatusBarData{
+				BackgroundColor: "#1a237e",
+				Style:           "light",
+			},
+		},
+		{
+			Type: "SCROLL",
+			Data: bff.ViewData{
+				FlexGrow:        1,
+				BackgroundColor: "#f5f7fa",
+				PaddingTop:      20,
+				PaddingBottom:   20,
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{PaddingHorizontal: 20},
+					Children: []bff.UISnippet{
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:       "My Analytics",
+								FontSize:   24,
+								FontWeight: "bold",
+								Color:      "#1a237e",
+							},
+						},
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:     fmt.Sprintf("%d trips delivered", report.Trips),
+								FontSize: 14,
+								Color:    "#666",
+							},
+						},
+					},
+				},
+				periodSelector(period),
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{FlexDirection: "row", Gap: 12, PaddingHorizontal: 20, MarginBottom: 12},
+					Children: []bff.UISnippet{
+						analyticsStat("Earnings", report.Earnings.String(), "wallet-outline", "#4CAF50"),
+						analyticsStat("Km Driven", bff.Km(report.Km), "speedometer-outline", "#2196F3"),
+					},
+				},
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{FlexDirection: "row", Gap: 12, PaddingHorizontal: 20, MarginBottom: 20},
+					Children: []bff.UISnippet{
+						analyticsStat("On-time", onTimeText(report), "time-outline", "#FF9800"),
+						analyticsStat("Rating", ratingText(report), "star-outline", "#FFC107"),
+						analyticsStat("Per Km", report.EarningsPerKm.String(), "trending-up-outline", "#00BCD4"),
+					},
+				},
+				chartCard("BAR_CHART", bff.ChartData{
+					Title:  "Earnings",
+					Labels: labels,
+					Series: []bff.ChartSeries{{Name: "Earnings", Values: earnings, Color: "#4CAF50"}},
+					Prefix: "₹",
+					Height: 220,
+				}),
+				chartCard("LINE_CHART", bff.ChartData{
+					Title:  "Km Driven",
+					Labels: labels,
+					Series: []bff.ChartSeries{{Name: "Km", Values: km, Color: "#2196F3"}},
+					Suffix: " km",
+					Height: 200,
+				}),
+				chartCard("BAR_CHART", bff.ChartData{
+					Title:  "Trips Completed",
+					Labels: labels,
+					Series: []bff.ChartSeries{{Name: "Trips", Values: trips, Color: "#9C27B0"}},
+					Height: 160,
+				}),
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "Analytics",
+		UI:     ui,
+		Data:   report,
+	}
+
+	c.JSON(200, response)
+}
+
+// Helper function to create the daily / weekly / monthly selector
+func periodSelector(selected analytics.Period) bff.UISnippet {
+	var buttons []bff.UISnippet
+	for _, p := range analytics.Periods {
+		background := "#FFFFFF"
+		if p == selected {
+			background = "#1a237e"
+		}
+		buttons = append(buttons, bff.UISnippet{
+			Type: "BUTTON",
+			Data: bff.ButtonData{
+				Text:     p.Label(),
+				Disabled: p == selected,
+				Style: bff.ViewData{
+					Flex:            1,
+					PaddingVertical: 10,
+					BorderRadius:    20,
+					BackgroundColor: background,
+					BorderWidth:     1,
+					BorderColor:     "#1a237e",
+				},
+				Action: bff.ActionData{
+					Type: "NAVIGATE",
+					To:   "/analytics",
+					Url:  "/bff/driver/analytics?period=" + string(p),
+					Data: map[string]interface{}{"period": string(p)},
+				},
+			},
+		})
+	}
+
+	return bff.UISnippet{
+		Type:     "VIEW",
+		Data:     bff.ViewData{FlexDirection: "row", Gap: 8, PaddingHorizontal: 20, MarginTop: 16, MarginBottom: 16},
+		Children: buttons,
+	}
+}
+
+// Helper function to create a headline number card
+func analyticsStat(label, value, icon, color string) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{
+			Flex:            1,
+			BackgroundColor: "#fff",
+			BorderRadius:    12,
+			Padding:         12,
+			Gap:             4,
+			Elevation:       1,
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "ICON",
+				Data: bff.IconData{Name: icon, Size: 18, Color: color},
+			},
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: value, FontSize: 18, FontWeight: "bold", Color: color},
+			},
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: label, FontSize: 12, Color: "#666"},
+			},
+		},
+	}
+}
+
+// Helper function to wrap a chart in a titled card
+func chartCard(kind string, chart bff.ChartData) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{
+			BackgroundColor:  "#fff",
+			BorderRadius:     16,
+			Padding:          16,
+			MarginHorizontal: 20,
+			MarginBottom:     16,
+			Elevation:        1,
+		},
+		Children: []bff.UISnippet{
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: chart.Title, FontSize: 16, FontWeight: "700", Color: "#1a237e"},
+			},
+			{
+				Type: kind,
+				Data: chart,
+			},
+		},
+	}
+}
+
+func onTimeText(r analytics.Report) string {
+	if r.OnTime+r.Late == 0 {
+		return "-"
+	}
+	return strconv.Itoa(int(r.OnTimeRate+0.5)) + "%"
+}
+
+func ratingText(r analytics.Report) string {
+	if r.Ratings == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", r.AvgRating)
+}
+
+// Helper function to summarize this month's performance for the home screen
+func monthlyMetrics(driverID string) map[string]string {
+	r := analytics.Default.Report(driverID, analytics.Monthly)
+	last := r.Buckets[len(r.Buckets)-1]
+	metrics := map[string]string{
+		"onTime":        onTimeText(r),
+		"rating":        ratingText(r),
+		"earningsPerKm": r.EarningsPerKm.String(),
+		"monthEarnings": last.Earnings.String(),
+		"monthKm":       bff.Km(last.Km),
+	}
+	if r.OnTime+r.Late > 0 {
+		metrics["onTimeProgress"] = strconv.Itoa(int(r.OnTimeRate + 0.5))
+	}
+	if r.Ratings > 0 {
+		metrics["ratingProgress"] = strconv.Itoa(int(r.AvgRating*20 + 0.5))
+	}
+	if r.EarningsPerKm > 0 {
+		// against a ₹50/km benchmark
+		metrics["earningsPerKmProgress"] = strconv.Itoa(int(min(100, 100*r.EarningsPerKm/ledger.Rupees(50))))
+	}
+	return metrics
+}
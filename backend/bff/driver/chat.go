@@ -0,0 +1,308 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/chat"
+	"backend/bff/docstore"
+	"backend/bff/ewaybill"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func ChatScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	driver := driverID(c)
+	// without a tripId the chat is about the active trip
+	tripID, _ := actionTripID(driver, map[string]interface{}{"tripId": c.Query("tripId")})
+	t, err := chat.Default.Conversation(tripID, driver)
+	if err != nil {
+		c.JSON(404, bff.ScreenResponse{
+			Status:  "error",
+			Screen:  "Chat",
+			Message: "No chat for this trip",
+		})
+		return
+	}
+
+	// opening the chat reads everything on screen
+	chat.Default.MarkRead(tripID, driver, "")
+	page, _ := chat.Default.History(tripID, driver, "", "", 0)
+
+	base := "/bff/chat/" + tripID
+	actionURL := base + "/action?userId=" + driver
+
+	var bubbles []bff.UISnippet
+	if page.HasMore && len(page.Messages) > 0 {
+		bubbles = append(bubbles, bff.UISnippet{
+			Type: "BUTTON",
+			Data: bff.ButtonData{
+				Text:  "Load earlier messages",
+				Style: bff.ViewData{AlignSelf: "center", PaddingVertical: 6, PaddingHorizontal: 12},
+				Action: bff.ActionData{
+					Type:  "ACTION",
+					Value: "history",
+					Url:   actionURL,
+					Data:  map[string]interface{}{"before": page.Messages[0].ID},
+				},
+			},
+		})
+	}
+	for _, m := range page.Messages {
+		bubbles = append(bubbles, chatBubble(m, driver))
+	}
+	if len(page.Messages) == 0 {
+		bubbles = append(bubbles, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: "Say hello to your broker", FontSize: 14, Color: "#999", TextAlign: "center"},
+		})
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "STATUS_BAR",
+			Data: bff.StatusBarData{
+				BackgroundColor: "#1a237e",
+				Style:           "light",
+			},
+		},
+		// Header
+		{
+			Type: "VIEW",
+			Data: bff.ViewData{
+				FlexDirection:     "row",
+				AlignItems:        "center",
+				Gap:               12,
+				PaddingHorizontal: 16,
+				PaddingVertical:   12,
+				BackgroundColor:   "#1a237e",
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "ICON_BUTTON",
+					Data: bff.IconButtonData{
+						Icon:    "arrow-back",
+						Size:    22,
+						Color:   "#fff",
+						OnPress: bff.ActionData{Type: "NAVIGATE", To: "back"},
+					},
+				},
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{Flex: 1},
+					Children: []bff.UISnippet{
+						{
+							Type: "TEXT",
+							Data: bff.TextData{Text: t.Details["brokerName"], FontSize: 16, FontWeight: "bold", Color: "#fff"},
+						},
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:     fmt.Sprintf("Trip %s · %s → %s", t.ID, t.Details["originCity"], t.Details["destinationCity"]),
+								FontSize: 12,
+								Color:    "#c5cae9",
+							},
+						},
+					},
+				},
+				{
+					Type: "ICON_BUTTON",
+					Data: bff.IconButtonData{
+						Icon:  "call",
+						Size:  22,
+						Color: "#fff",
+						OnPress: bff.ActionData{
+							Type:  "ACTION",
+							Value: "CALL_CONTACT",
+							Url:   "/bff/driver/home/action",
+							Data:  map[string]interface{}{"contactType": "broker", "tripId": t.ID},
+						},
+					},
+				},
+			},
+		},
+		// Messages
+		{
+			Type: "SCROLL",
+			Data: bff.ViewData{
+				FlexGrow:        1,
+				BackgroundColor: "#f5f7fa",
+				Padding:         12,
+				Gap:             8,
+			},
+			Children: bubbles,
+		},
+		// Composer
+		{
+			Type: "VIEW",
+			Data: bff.ViewData{
+				FlexDirection:   "row",
+				AlignItems:      "center",
+				Gap:             8,
+				Padding:         10,
+				BackgroundColor: "#fff",
+				BorderTopWidth:  1,
+				BorderColor:     "#e0e0e0",
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "ICON_BUTTON",
+					Data: bff.IconButtonData{
+						Icon:  "location",
+						Size:  24,
+						Color: "#1a237e",
+						OnPress: bff.ActionData{
+							Type:  "ACTION",
+							Value: "send",
+							Url:   actionURL,
+							Data:  map[string]interface{}{"kind": "location"},
+						},
+					},
+				},
+				{
+					Type: "ICON_BUTTON",
+					Data: bff.IconButtonData{
+						Icon:  "camera",
+						Size:  24,
+						Color: "#1a237e",
+						OnPress: bff.ActionData{
+							Type:  "ACTION",
+							Value: "send",
+							Url:   actionURL,
+							Data:  map[string]interface{}{"kind": "image"},
+						},
+					},
+				},
+				{
+					Type: "INPUT",
+					Data: bff.InputData{
+						Id:          "text",
+						Placeholder: "Type a message",
+						MaxLength:   2000,
+						FontSize:    15,
+						Style: bff.ViewData{
+							Flex:              1,
+							PaddingHorizontal: 14,
+							PaddingVertical:   10,
+							BorderRadius:      20,
+							BackgroundColor:   "#f0f2f5",
+						},
+					},
+				},
+				{
+					Type: "ICON_BUTTON",
+					Data: bff.IconButtonData{
+						Icon:  "send",
+						Size:  24,
+						Color: "#1a237e",
+						OnPress: bff.ActionData{
+							Type:  "ACTION",
+							Value: "send",
+							Url:   actionURL,
+							Data:  map[string]interface{}{"kind": "text"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "Chat",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"tripId":     tripID,
+			"socketUrl":  base + "/ws?userId=" + driver,
+			"historyUrl": base + "/messages?userId=" + driver,
+			"actionUrl":  actionURL,
+			"messages":   page.Messages,
+			"hasMore":    page.HasMore,
+		},
+	}
+
+	c.JSON(200, response)
+}
+
+// Helper function to create a chat bubble, right aligned for the driver's own messages
+func chatBubble(m chat.Message, driverID string) bff.UISnippet {
+	mine := m.SenderID == driverID
+	align, background, color := "flex-start", "#ffffff", "#1a237e"
+	if mine {
+		align, background, color = "flex-end", "#1a237e", "#ffffff"
+	}
+
+	var content []bff.UISnippet
+	switch m.Kind {
+	case chat.KindImage:
+		content = append(content, bff.UISnippet{
+			Type: "IMAGE",
+			Data: bff.ImageData{Url: docstore.URL(m.ImageID, driverID), Width: 200, Height: 150, ResizeMode: "cover"},
+		})
+	case chat.KindLocation:
+		content = append(content, bff.UISnippet{
+			Type: "VIEW",
+			Data: bff.ViewData{FlexDirection: "row", AlignItems: "center", Gap: 6},
+			Children: []bff.UISnippet{
+				{
+					Type: "ICON",
+					Data: bff.IconData{Name: "location", Size: 18, Color: color},
+				},
+				{
+					Type: "TEXT",
+					Data: bff.TextData{Text: locationText(m), FontSize: 14, Color: color},
+				},
+			},
+		})
+	}
+	if m.Text != "" {
+		content = append(content, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: m.Text, FontSize: 14, Color: color},
+		})
+	}
+
+	footer := m.At.In(ewaybill.IST).Format("3:04 PM")
+	if mine {
+		footer += " " + receiptTicks(m.Receipt())
+	}
+	content = append(content, bff.UISnippet{
+		Type: "TEXT",
+		Data: bff.TextData{Text: footer, FontSize: 10, Color: color, Opacity: 0.7, AlignSelf: "flex-end"},
+	})
+
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{
+			AlignSelf:         align,
+			MaxWidth:          "80%",
+			PaddingHorizontal: 12,
+			PaddingVertical:   8,
+			BorderRadius:      14,
+			BackgroundColor:   background,
+		},
+		Children: content,
+	}
+}
+
+// Helper function to describe a shared location
+func locationText(m chat.Message) string {
+	if m.Place != "" {
+		return m.Place
+	}
+	if m.Location != nil {
+		return m.Location.String()
+	}
+	return "Shared location"
+}
+
+// Helper function to show a receipt as ticks
+func receiptTicks(receipt string) string {
+	switch receipt {
+	case "read":
+		return "✓✓ Read"
+	case "delivered":
+		return "✓✓"
+	}
+	return "✓"
+}
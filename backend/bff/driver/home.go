@@ -2,8 +2,28 @@ package driver
 
 import (
 	"backend/bff"
+	"backend/bff/calls"
+	"backend/bff/chat"
+	"backend/bff/consignment"
+	"backend/bff/docstore"
+	"backend/bff/ewaybill"
+	"backend/bff/expense"
+	"backend/bff/geo"
+	"backend/bff/invoice"
+	"backend/bff/ledger"
+	"backend/bff/notify"
+	"backend/bff/payments"
+	"backend/bff/rating"
+	"backend/bff/routing"
+	"backend/bff/safety"
+	"backend/bff/settlement"
+	"backend/bff/trip"
+	"backend/bff/vehicle"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 	"github.com/gin-gonic/gin"
 )
@@ -12,7 +32,7 @@ func HomeScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
 	// Fetch or generate home data
-	homeData := getHomeScreenData()
+	homeData := getHomeScreenData(driverID(c))
 
 	// Generate UI with new design
 	ui := generateModernUI(homeData)
@@ -28,69 +48,87 @@ func HomeScreen(c *gin.Context) {
 	c.JSON(200, response)
 }
 
-func getHomeScreenData() bff.HomeScreenData {
-	return bff.HomeScreenData{
-		IsTripStarted:   false,
-		LocationSharing: false,
-		TripStatus:      "not_started",
-		DocumentsUploaded: map[string]bool{
-			"eWayBill":      false,
-			"invoice":       false,
-			"vehicleRC":     true,
-			"driverLicense": true,
-			"insurance":     true,
-			"pollutionCert": true,
-		},
-		ActiveTrip: map[string]string{
-			"id":               "TRK789012",
-			"tripNumber":       "TRIP-2024-001",
-			"origin":           "Mumbai Port",
-			"originCity":       "Mumbai, MH",
-			"destination":      "Delhi Logistics Park",
-			"destinationCity":  "Delhi, DL",
-			"cargo":            "Electronics & Appliances",
-			"cargoType":        "General Goods",
-			"weight":           "15 Tons",
-			"payment":          "₹68,500",
-			"advancePaid":      "₹20,000",
-			"balanceDue":       "₹48,500",
-			"distance":         "1,412 km",
-			"estimatedTime":    "26 hrs",
-			"startTime":        "Today, 10:00 AM",
-			"brokerName":       "Sharma Logistics Pvt. Ltd.",
-			"brokerPhone":      "+91 9876543210",
-			"brokerRating":     "4.8",
-			"senderName":       "TechCorp India Ltd.",
-			"senderPhone":      "+91 9876543211",
-			"receiverName":     "Metro Retail Chains",
-			"receiverPhone":    "+91 9876543212",
-			"vehicleNumber":    "MH01AB1234",
-			"driverName":       "Rajesh Kumar",
-			"currentLocation":  "Pune, MH",
-			"progress":         "35",
-			"estimatedArrival": "Tomorrow, 12:00 PM",
-			"fuelLevel":        "65",
-			"vehicleHealth":    "Good",
-			"tripScore":        "92",
-		},
+// Until login issues tokens the apps identify the driver by query param
+func driverID(c *gin.Context) string {
+	return c.DefaultQuery("driverId", trip.DemoDriverID)
+}
+
+func getHomeScreenData(driverID string) bff.HomeScreenData {
+	data := bff.HomeScreenData{
+		TripStatus:        "no_trip",
+		DocumentsUploaded: map[string]bool{},
+		ActiveTrip:        map[string]string{},
 		QuickActions: []bff.QuickAction{
 			{ID: 1, Icon: "play-circle", Title: "Start Trip", Color: "#4CAF50"},
 			{ID: 2, Icon: "search", Title: "Find Loads", Color: "#2196F3"},
 			{ID: 3, Icon: "wallet", Title: "Payments", Color: "#FF9800"},
 			{ID: 4, Icon: "document-text", Title: "Docs", Color: "#9C27B0"},
-			{ID: 5, Icon: "car", Title: "My Vehicle", Color: "#607D8B"},
-			{ID: 6, Icon: "stats-chart", Title: "Analytics", Color: "#00BCD4"},
-		},
-		RecentActivities: []bff.RecentActivity{
-			{ID: 1, Type: "payment", Message: "Advance received ₹20,000", Time: "2 hours ago", Icon: "checkmark-circle", Color: "#4CAF50"},
-			{ID: 2, Type: "assignment", Message: "New trip assigned: Mumbai to Delhi", Time: "4 hours ago", Icon: "car", Color: "#2196F3"},
-			{ID: 3, Type: "maintenance", Message: "Vehicle service due in 500 km", Time: "1 day ago", Icon: "construct", Color: "#FF9800"},
-			{ID: 4, Type: "alert", Message: "Toll payment reminder", Time: "2 days ago", Icon: "alert-circle", Color: "#F44336"},
+			{ID: 5, Icon: "car", Title: "My Vehicle", Color: "#607D8B", Navigate: "/vehicle"},
+			{ID: 6, Icon: "stats-chart", Title: "Analytics", Color: "#00BCD4", Navigate: "/analytics"},
 		},
+		RecentActivities: notificationActivities(driverID, 3),
+		Notifications:    notify.Default.Unread(driverID),
+		Messages:         chat.Default.TotalUnread(driverID),
+		Rating:           rating.Default.Aggregate(driverID).Text(),
+	}
+	data.RecentActivities = append(data.RecentActivities, expenseActivities(driverID, len(data.RecentActivities))...)
+	data.Metrics = monthlyMetrics(driverID)
+
+	t, err := trip.Default.ActiveForDriver(driverID)
+	if err != nil {
+		return data
+	}
+
+	data.IsTripStarted = t.Started()
+	data.LocationSharing = t.LocationSharing
+	data.TripStatus = string(t.Status)
+	data.DocumentsUploaded = t.Documents
+	// counterparties' phones are replaced by the trip's virtual numbers
+	data.ActiveTrip = calls.Default.Mask(t, driverID, liveDetails(t))
+	data.ActiveTrip["id"] = t.ID
+	if n, err := consignment.Default.ForTrip(t.ID); err == nil {
+		data.ActiveTrip["lrNumber"] = n.Number
 	}
+	if b, err := ewaybill.Default.Get(t.ID); err == nil {
+		data.ActiveTrip["ewayBillNumber"] = b.Number
+		data.ActiveTrip["ewayBillValidUntil"] = b.ValidUntil.In(ewaybill.IST).Format("02 Jan, 3:04 PM")
+	}
+	if w, ok := ewaybill.Default.Warn(t); ok {
+		data.ActiveTrip["ewayBillWarning"] = w.Message
+		data.ActiveTrip["ewayBillWarningLevel"] = w.Level
+	}
+	for _, next := range trip.Next(t.Status) {
+		data.NextStatuses = append(data.NextStatuses, string(next))
+	}
+	return data
 }
 
 func generateModernUI(data bff.HomeScreenData) []bff.UISnippet {
+	sections := []bff.UISnippet{
+		// Header Section
+		headerSection(data),
+
+		// Quick Actions
+		quickActionsSection(data.QuickActions),
+
+		// Documents Status
+		documentsSection(data.DocumentsUploaded, data.IsTripStarted),
+	}
+
+	// E-way Bill Warning
+	sections = append(sections, ewayBillBanner(data.ActiveTrip)...)
+
+	// Active Trip Card
+	sections = append(sections, activeTripSection(data)...)
+
+	sections = append(sections,
+		// Recent Activities
+		recentActivitiesSection(data.RecentActivities),
+
+		// Performance Metrics (if trip started)
+		performanceMetricsSection(data),
+	)
+
 	return []bff.UISnippet{
 		// Status Bar
 		{
@@ -109,30 +147,12 @@ func generateModernUI(data bff.HomeScreenData) []bff.UISnippet {
 				PaddingTop:      20,
 				PaddingBottom:   20,
 			},
-			Children: []bff.UISnippet{
-				// Header Section
-				headerSection(),
-
-				// Quick Actions
-				quickActionsSection(data.QuickActions),
-
-				// Documents Status
-				documentsSection(data.DocumentsUploaded, data.IsTripStarted),
-
-				// Active Trip Card
-				activeTripSection(data),
-
-				// Recent Activities
-				recentActivitiesSection(data.RecentActivities),
-
-				// Performance Metrics (if trip started)
-				performanceMetricsSection(data),
-			},
+			Children: sections,
 		},
 	}
 }
 
-func headerSection() bff.UISnippet {
+func headerSection(data bff.HomeScreenData) bff.UISnippet {
 	return bff.UISnippet{
 		Type: "VIEW",
 		Data: bff.ViewData{
@@ -181,12 +201,26 @@ func headerSection() bff.UISnippet {
 							Gap:           12,
 						},
 						Children: []bff.UISnippet{
+							{
+								Type: "ICON_BUTTON",
+								Data: bff.IconButtonData{
+									Icon:  "chatbubbles-outline",
+									Size:  24,
+									Color: "#1a237e",
+									Badge: data.Messages,
+									OnPress: bff.ActionData{
+										Type: "NAVIGATE",
+										To:   "/chat",
+									},
+								},
+							},
 							{
 								Type: "ICON_BUTTON",
 								Data: bff.IconButtonData{
 									Icon:  "notifications-outline",
 									Size:  24,
 									Color: "#1a237e",
+									Badge: data.Notifications,
 									OnPress: bff.ActionData{
 										Type: "NAVIGATE",
 										To:   "/notifications",
@@ -237,7 +271,7 @@ func headerSection() bff.UISnippet {
 				Children: []bff.UISnippet{
 					statItem("Trips", "12", "this month", "#4CAF50", "car-outline"),
 					statItem("Earnings", "₹2.4L", "current month", "#FF9800", "wallet-outline"),
-					statItem("Rating", "4.8", "driver score", "#2196F3", "star-outline"),
+					statItem("Rating", data.Rating, "driver score", "#2196F3", "star-outline"),
 				},
 			},
 		},
@@ -305,6 +339,15 @@ func quickActionsSection(actions []bff.QuickAction) bff.UISnippet {
 	var actionChildren []bff.UISnippet
 
 	for _, action := range actions {
+		onPress := bff.ActionData{
+			Type:  "ACTION",
+			Value: action.Title,
+			Url:   "/bff/driver/home/action",
+		}
+		if action.Navigate != "" {
+			onPress = bff.ActionData{Type: "NAVIGATE", To: action.Navigate}
+		}
+
 		actionChildren = append(actionChildren, bff.UISnippet{
 			Type: "PRESSABLE_CARD",
 			Data: bff.PressableCardData{
@@ -314,11 +357,7 @@ func quickActionsSection(actions []bff.QuickAction) bff.UISnippet {
 					BorderRadius:    12,
 					BorderWidth:     1,
 					BorderColor:     action.Color + "30", // 30 = 18% opacity
-					OnPress: bff.ActionData{
-						Type:  "ACTION",
-						Value: action.Title,
-						Url:   "/bff/driver/home/action",
-					},
+					OnPress:         onPress,
 				},
 				Children: []bff.UISnippet{
 					{
@@ -473,12 +512,7 @@ func documentsSection(docs map[string]bool, isTripStarted bool) bff.UISnippet {
 							BorderRadius:      6,
 							BackgroundColor:   "#F44336",
 						},
-						Action: bff.ActionData{
-							Type:  "ACTION",
-							Value: "UPLOAD_DOCUMENT",
-							Url:   "/bff/driver/home/action",
-							Data:  map[string]interface{}{"documentType": doc},
-						},
+						Action: uploadAction(doc),
 					},
 				},
 			},
@@ -549,10 +583,14 @@ func documentsSection(docs map[string]bool, isTripStarted bool) bff.UISnippet {
 	}
 }
 
-func activeTripSection(data bff.HomeScreenData) bff.UISnippet {
+// activeTripSection is left out of the home screen when there is no trip
+func activeTripSection(data bff.HomeScreenData) []bff.UISnippet {
 	trip := data.ActiveTrip
+	if trip["id"] == "" {
+		return nil
+	}
 
-	return bff.UISnippet{
+	return []bff.UISnippet{{
 		Type: "VIEW",
 		Data: bff.ViewData{
 			PaddingHorizontal: 20,
@@ -601,7 +639,7 @@ func activeTripSection(data bff.HomeScreenData) bff.UISnippet {
 							{
 								Type: "TEXT",
 								Data: bff.TextData{
-									Text:              data.TripStatus,
+									Text:              statusLabel(data.TripStatus),
 									FontSize:          12,
 									FontWeight:        "600",
 									PaddingHorizontal: 10,
@@ -894,36 +932,14 @@ func activeTripSection(data bff.HomeScreenData) bff.UISnippet {
 							FlexDirection: "row",
 							Gap:           12,
 						},
-						Children: []bff.UISnippet{
-							{
-								Type: "BUTTON",
-								Data: bff.ButtonData{
-									Text: func() string {
-										if data.IsTripStarted {
-											return "Trip in Progress"
-										}
-										return "Start Trip"
-									}(),
-									Disabled: data.IsTripStarted || len(getRequiredDocs(data.DocumentsUploaded)) > 0,
-									Style: bff.ViewData{
-										Flex:            1,
-										PaddingVertical: 16,
-										BorderRadius:    12,
-										BackgroundColor: "#4CAF50",
-									},
-									Action: bff.ActionData{
-										Type:  "ACTION",
-										Value: "START_TRIP",
-										Url:   "/bff/driver/home/action",
-									},
-								},
-							},
-						},
+						Children: append([]bff.UISnippet{
+							tripActionButton(data),
+						}, addExpenseButton(data)...),
 					},
 				},
 			},
 		},
-	}
+	}}
 }
 
 // Add these helper functions at the bottom of home.go file
@@ -1055,46 +1071,217 @@ func recentActivitiesSection(activities []bff.RecentActivity) bff.UISnippet {
 }
 
 func performanceMetricsSection(data bff.HomeScreenData) bff.UISnippet {
-	if !data.IsTripStarted {
+	if !data.IsTripStarted && data.Metrics == nil {
 		return bff.UISnippet{}
 	}
 
 	trip := data.ActiveTrip
+	metrics := data.Metrics
 
-	return bff.UISnippet{
+	section := bff.UISnippet{
 		Type: "VIEW",
 		Data: bff.ViewData{
 			PaddingHorizontal: 20,
 			MarginBottom:      20,
+			Gap:               12,
 		},
 		Children: []bff.UISnippet{
 			{
 				Type: "TEXT",
 				Data: bff.TextData{
-					Text:         "Trip Performance",
+					Text:         "Performance",
 					FontSize:     18,
 					FontWeight:   "bold",
 					Color:        "#1a237e",
-					MarginBottom: 16,
+					MarginBottom: 4,
+				},
+			},
+		},
+	}
+
+	if data.IsTripStarted {
+		section.Children = append(section.Children, bff.UISnippet{
+			Type: "VIEW",
+			Data: bff.ViewData{
+				FlexDirection: "row",
+				Gap:           12,
+			},
+			Children: []bff.UISnippet{
+				metricCard("Fuel Level", trip["fuelLevel"]+"%", "speedometer-outline", "#FF9800", trip["fuelLevel"]),
+				metricCard("Trip Score", trip["tripScore"], "trophy-outline", "#4CAF50", trip["tripScore"]),
+				metricCard("Vehicle Health", trip["vehicleHealth"], "checkmark-circle-outline", "#2196F3", trip["vehicleHealthProgress"]),
+			},
+		})
+	}
+
+	if metrics != nil {
+		section.Children = append(section.Children, bff.UISnippet{
+			Type: "VIEW",
+			Data: bff.ViewData{
+				FlexDirection: "row",
+				Gap:           12,
+			},
+			Children: []bff.UISnippet{
+				metricCard("On-time", metrics["onTime"], "time-outline", "#00BCD4", metrics["onTimeProgress"]),
+				metricCard("Rating", metrics["rating"], "star-outline", "#FFC107", metrics["ratingProgress"]),
+				metricCard("Per Km", metrics["earningsPerKm"], "trending-up-outline", "#4CAF50", metrics["earningsPerKmProgress"]),
+			},
+		})
+	}
+	return section
+}
+
+// Helper functions
+// Helper function to list the driver's latest trip expenses as activities
+func expenseActivities(driverID string, after int) []bff.RecentActivity {
+	var activities []bff.RecentActivity
+	for i, x := range expense.Default.ForDriver(driverID) {
+		if i == 2 {
+			break
+		}
+		message := fmt.Sprintf("%s %s paid on %s", x.Category.Label(), x.Amount, x.TripID)
+		if x.Treatment == expense.Reimbursable {
+			message += " (reimbursable)"
+		}
+		activities = append(activities, bff.RecentActivity{
+			ID:      after + i + 1,
+			Type:    "expense",
+			Message: message,
+			Time:    bff.TimeAgo(x.At),
+			Icon:    "receipt",
+			Color:   "#795548",
+		})
+	}
+	return activities
+}
+
+// Helper function to create the add expense button shown while a trip is running
+func addExpenseButton(data bff.HomeScreenData) []bff.UISnippet {
+	switch trip.Status(data.TripStatus) {
+	case trip.StatusAssigned, trip.StatusSettled, trip.StatusCancelled:
+		return nil
+	}
+
+	var categories []map[string]string
+	for _, c := range expense.Categories {
+		categories = append(categories, map[string]string{"value": string(c), "label": c.Label()})
+	}
+
+	return []bff.UISnippet{{
+		Type: "BUTTON",
+		Data: bff.ButtonData{
+			Text: "Add Expense",
+			Style: bff.ViewData{
+				PaddingVertical:   16,
+				PaddingHorizontal: 16,
+				BorderRadius:      12,
+				BackgroundColor:   "#795548",
+			},
+			Action: bff.ActionData{
+				Type:  "ACTION",
+				Value: "ADD_EXPENSE",
+				Url:   "/bff/driver/home/action",
+				Data: map[string]interface{}{
+					"tripId":     data.ActiveTrip["id"],
+					"categories": categories,
+					"paidFrom":   []string{"cash", "wallet", "fastag"},
+					"fields":     []string{"category", "amount", "paidFrom", "note", "receiptImage"},
 				},
 			},
+		},
+	}}
+}
+
+// Helper function to read a base64 image, optionally sent as a data URL
+func decodeImage(raw interface{}) ([]byte, string, error) {
+	s, _ := raw.(string)
+	if s == "" {
+		return nil, "", nil
+	}
+
+	contentType := ""
+	if strings.HasPrefix(s, "data:") {
+		meta, payload, ok := strings.Cut(s, ",")
+		if !ok {
+			return nil, "", fmt.Errorf("receiptImage is not a valid data URL")
+		}
+		contentType = strings.TrimSuffix(strings.TrimPrefix(meta, "data:"), ";base64")
+		s = payload
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, "", fmt.Errorf("receiptImage must be base64 encoded")
+	}
+	return data, contentType, nil
+}
+
+// Helper function to pick the action of a document's upload button
+func uploadAction(doc string) bff.ActionData {
+	if doc == "eWayBill" {
+		return bff.ActionData{
+			Type:  "ACTION",
+			Value: "CAPTURE_EWAY_BILL",
+			Url:   "/bff/driver/home/action",
+			Data: map[string]interface{}{
+				"fields": []string{"ewayBillNumber", "generatedAt", "distanceKm"},
+			},
+		}
+	}
+	return bff.ActionData{
+		Type:  "ACTION",
+		Value: "UPLOAD_DOCUMENT",
+		Url:   "/bff/driver/home/action",
+		Data:  map[string]interface{}{"documentType": doc},
+	}
+}
+
+// Helper function to warn about an e-way bill lapsing mid-trip
+func ewayBillBanner(trip map[string]string) []bff.UISnippet {
+	if trip["ewayBillWarning"] == "" {
+		return nil
+	}
+
+	color := "#FF9800"
+	if trip["ewayBillWarningLevel"] == ewaybill.WarnExpired {
+		color = "#F44336"
+	}
+
+	return []bff.UISnippet{{
+		Type: "VIEW",
+		Data: bff.ViewData{
+			FlexDirection:    "row",
+			AlignItems:       "center",
+			Gap:              12,
+			MarginHorizontal: 20,
+			MarginBottom:     20,
+			Padding:          16,
+			BorderRadius:     12,
+			BorderWidth:      1,
+			BorderColor:      color,
+			BackgroundColor:  color + "15",
+		},
+		Children: []bff.UISnippet{
 			{
-				Type: "VIEW",
-				Data: bff.ViewData{
-					FlexDirection: "row",
-					Gap:           12,
+				Type: "ICON",
+				Data: bff.IconData{
+					Name:  "alert-circle",
+					Size:  24,
+					Color: color,
 				},
-				Children: []bff.UISnippet{
-					metricCard("Fuel Level", trip["fuelLevel"]+"%", "speedometer-outline", "#FF9800", trip["fuelLevel"]),
-					metricCard("Trip Score", trip["tripScore"], "trophy-outline", "#4CAF50", trip["tripScore"]),
-					metricCard("Vehicle Health", trip["vehicleHealth"], "checkmark-circle-outline", "#2196F3", "85"),
+			},
+			{
+				Type: "TEXT",
+				Data: bff.TextData{
+					Text:     trip["ewayBillWarning"],
+					FontSize: 13,
+					Color:    "#1a237e",
+					Flex:     1,
 				},
 			},
 		},
-	}
+	}}
 }
 
-// Helper functions
 func getDocumentIcon(docType string) string {
 	switch docType {
 	case "eWayBill":
@@ -1134,22 +1321,104 @@ func getDocumentName(docType string) string {
 }
 
 func getStatusColor(status string) string {
-	switch status {
-	case "not_started":
+	switch trip.Status(status) {
+	case trip.StatusAssigned, trip.StatusAccepted:
 		return "#9E9E9E"
-	case "reached_origin":
+	case trip.StatusReachedPickup, trip.StatusLoading:
 		return "#4CAF50"
-	case "in_transit":
+	case trip.StatusInTransit:
 		return "#2196F3"
-	case "reached_destination":
+	case trip.StatusReachedDrop, trip.StatusDelivered:
 		return "#FF9800"
-	case "completed":
+	case trip.StatusPODUploaded, trip.StatusSettled:
 		return "#9C27B0"
+	case trip.StatusCancelled:
+		return "#F44336"
 	default:
 		return "#666"
 	}
 }
 
+func statusLabel(status string) string {
+	return trip.Status(status).Label()
+}
+
+// liveDetails overlays the position and ETA derived fields on a trip's stored details
+func liveDetails(t trip.Trip) map[string]string {
+	d := routing.Default.LiveDetails(t)
+	if a := rating.Default.Aggregate(t.BrokerID); a.Count > 0 {
+		d["brokerRating"] = a.Text()
+	}
+	if score := safety.Default.Report(t.ID).Text(); score != "" {
+		d["tripScore"] = score
+	}
+	// fuel and health come from the truck's logs once it is registered
+	if v, err := vehicle.Default.Get(t.Details["vehicleNumber"]); err == nil {
+		if level, ok := vehicle.Default.FuelLevel(v.Number); ok {
+			d["fuelLevel"] = strconv.Itoa(level)
+		}
+		if h, err := vehicle.Default.Health(v.Number); err == nil {
+			d["vehicleHealth"] = h.Label
+			d["vehicleHealthProgress"] = strconv.Itoa(h.Percent)
+		}
+	}
+	return d
+}
+
+// tripActionButton offers the driver the next step of the trip lifecycle
+func tripActionButton(data bff.HomeScreenData) bff.UISnippet {
+	tripID := data.ActiveTrip["id"]
+	text := "Trip in Progress"
+	action := bff.ActionData{
+		Type: "ACTION",
+		Url:  "/bff/driver/home/action",
+		Data: map[string]interface{}{"tripId": tripID},
+	}
+	disabled := false
+
+	switch trip.Status(data.TripStatus) {
+	case trip.StatusAssigned:
+		text, action.Value = "Accept Trip", "UPDATE_STATUS"
+		action.Data["status"] = string(trip.StatusAccepted)
+	case trip.StatusAccepted:
+		text, action.Value = "Reached Pickup", "UPDATE_STATUS"
+		action.Data["status"] = string(trip.StatusReachedPickup)
+	case trip.StatusReachedPickup:
+		text, action.Value = "Start Loading", "UPDATE_STATUS"
+		action.Data["status"] = string(trip.StatusLoading)
+	case trip.StatusLoading:
+		text, action.Value = "Start Trip", "START_TRIP"
+		disabled = len(getRequiredDocs(data.DocumentsUploaded)) > 0
+	case trip.StatusInTransit:
+		text, action.Value = "Reached Drop", "UPDATE_STATUS"
+		action.Data["status"] = string(trip.StatusReachedDrop)
+	case trip.StatusReachedDrop:
+		text, action.Value = "Mark Delivered", "UPDATE_STATUS"
+		action.Data["status"] = string(trip.StatusDelivered)
+	case trip.StatusDelivered:
+		text, action.Value = "Upload POD", "UPLOAD_POD"
+	case trip.StatusPODUploaded:
+		text, disabled = "Awaiting Settlement", true
+	default:
+		disabled = true
+	}
+
+	return bff.UISnippet{
+		Type: "BUTTON",
+		Data: bff.ButtonData{
+			Text:     text,
+			Disabled: disabled,
+			Style: bff.ViewData{
+				Flex:            1,
+				PaddingVertical: 16,
+				BorderRadius:    12,
+				BackgroundColor: getStatusColor(data.TripStatus),
+			},
+			Action: action,
+		},
+	}
+}
+
 func getRequiredDocs(docs map[string]bool) []string {
 	var required []string
 	for doc, uploaded := range docs {
@@ -1419,77 +1688,215 @@ func HandleHomeAction(c *gin.Context) {
 		return
 	}
 
-	response := handleModernAction(req)
+	response := handleModernAction(driverID(c), req)
 	c.JSON(200, response)
 }
 
 
-func handleModernAction(req bff.ActionRequest) bff.ActionResponse {
+func handleModernAction(driverID string, req bff.ActionRequest) bff.ActionResponse {
 	switch req.Action {
 	case "START_TRIP":
-		// Check if documents are uploaded
-		docsUploaded := req.Data["documentsUploaded"].(map[string]interface{})
-		allUploaded := true
-		var missingDocs []string
-
-		for doc, uploaded := range docsUploaded {
-			if !uploaded.(bool) {
-				allUploaded = false
-				missingDocs = append(missingDocs, doc)
-			}
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
 		}
 
-		if !allUploaded {
+		t, err := trip.Default.Transition(tripID, trip.StatusInTransit, trip.ActorDriver, "")
+		if errors.Is(err, trip.ErrDocumentsMissing) {
+			current, _ := trip.Default.Get(tripID)
 			return bff.ActionResponse{
 				Status:  "error",
 				Message: "Please upload all required documents first",
 				Data: map[string]interface{}{
-					"missingDocuments": missingDocs,
+					"missingDocuments": current.MissingDocuments(),
 				},
 			}
 		}
+		if err != nil {
+			return actionError(err)
+		}
 
 		// Start trip
 		return bff.ActionResponse{
 			Status:  "success",
 			Message: "Trip started successfully! Location sharing enabled.",
 			Data: map[string]interface{}{
-				"isTripStarted":   true,
-				"locationSharing": true,
-				"tripStatus":      "in_transit",
-				"startTime":       time.Now().Format(time.RFC3339),
+				"tripId":          t.ID,
+				"isTripStarted":   t.Started(),
+				"locationSharing": t.LocationSharing,
+				"tripStatus":      t.Status,
+				"startTime":       t.UpdatedAt.Format(time.RFC3339),
 			},
 		}
 
 	case "UPLOAD_DOCUMENT":
-		docType := req.Data["documentType"].(string)
-		// Simulate document upload
+		docType, _ := req.Data["documentType"].(string)
+		if docType == "" {
+			return actionError(fmt.Errorf("documentType is required"))
+		}
+		if docType == "eWayBill" {
+			return actionError(fmt.Errorf("e-way bill needs its number, use CAPTURE_EWAY_BILL"))
+		}
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+
+		t, err := trip.Default.MarkDocument(tripID, docType)
+		if err != nil {
+			return actionError(err)
+		}
 		return bff.ActionResponse{
 			Status:  "success",
 			Message: fmt.Sprintf("%s uploaded successfully", getDocumentName(docType)),
 			Data: map[string]interface{}{
-				"documentType": docType,
-				"uploaded":     true,
-				"uploadedAt":   time.Now().Format(time.RFC3339),
+				"documentType":     docType,
+				"uploaded":         true,
+				"uploadedAt":       t.UpdatedAt.Format(time.RFC3339),
+				"missingDocuments": t.MissingDocuments(),
 			},
 		}
 
-	case "UPDATE_STATUS":
-		status := req.Data["status"].(string)
-		tripID := req.Data["tripId"].(string)
+	case "CAPTURE_EWAY_BILL":
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		number, _ := req.Data["ewayBillNumber"].(string)
+		distance, _ := req.Data["distanceKm"].(float64)
+		odc, _ := req.Data["odc"].(bool)
+		capture := ewaybill.CaptureRequest{Number: number, DistanceKm: int(distance), ODC: odc}
+		if at, _ := req.Data["generatedAt"].(string); at != "" {
+			if capture.GeneratedAt, err = time.Parse(time.RFC3339, at); err != nil {
+				return actionError(fmt.Errorf("generatedAt must be an RFC 3339 time"))
+			}
+		}
 
+		b, err := ewaybill.Default.Capture(tripID, capture)
+		if err != nil {
+			return actionError(err)
+		}
+		t, _ := trip.Default.Get(tripID)
 		return bff.ActionResponse{
 			Status:  "success",
-			Message: fmt.Sprintf("Trip status updated to: %s", status),
+			Message: fmt.Sprintf("E-way bill %s verified, valid until %s", b.Number, b.ValidUntil.In(ewaybill.IST).Format("02 Jan, 3:04 PM")),
 			Data: map[string]interface{}{
-				"tripId":     tripID,
-				"tripStatus": status,
-				"updatedAt":  time.Now().Format(time.RFC3339),
+				"ewayBill":         b,
+				"missingDocuments": t.MissingDocuments(),
 			},
 		}
 
+	case "ADD_EXPENSE":
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		category, _ := req.Data["category"].(string)
+		amount, _ := req.Data["amount"].(float64)
+		paidFrom, _ := req.Data["paidFrom"].(string)
+		note, _ := req.Data["note"].(string)
+		lat, _ := req.Data["latitude"].(float64)
+		lng, _ := req.Data["longitude"].(float64)
+		receipt, receiptType, err := decodeImage(req.Data["receiptImage"])
+		if err != nil {
+			return actionError(err)
+		}
+
+		x, err := expense.Default.Add(driverID, tripID, expense.AddRequest{
+			Category:    expense.Category(category),
+			Amount:      ledger.FromRupees(amount),
+			PaidFrom:    expense.PaidFrom(paidFrom),
+			Note:        note,
+			Receipt:     receipt,
+			ReceiptType: receiptType,
+			Location:    geo.Coord{Lat: lat, Lng: lng},
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		message := fmt.Sprintf("%s of %s recorded", x.Category.Label(), x.Amount)
+		if x.Treatment == expense.Reimbursable {
+			message += ", your broker will reimburse it"
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: message,
+			Data: map[string]interface{}{
+				"expense": x,
+				"summary": expense.Default.Summary(x.TripID),
+			},
+		}
+
+	case "TRIP_EXPENSES":
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		t, err := trip.Default.Get(tripID)
+		if err != nil || t.DriverID != driverID {
+			return actionError(trip.ErrNotFound)
+		}
+		return bff.ActionResponse{
+			Status: "success",
+			Data: map[string]interface{}{
+				"expenses": expense.Default.ForTrip(t.ID),
+				"summary":  expense.Default.Summary(t.ID),
+			},
+		}
+
+	case "UPLOAD_POD":
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		// a POD the broker rejected goes back for approval; the trip itself
+		// is already waiting on it
+		if t, err := trip.Default.Get(tripID); err == nil && t.Status == trip.StatusPODUploaded {
+			resubmitted := false
+			for _, p := range settlement.Default.ForTrip(t.ID) {
+				if p.POD != settlement.PODRejected {
+					continue
+				}
+				if _, err := settlement.Default.SubmitPOD(p.ID); err != nil {
+					return actionError(err)
+				}
+				resubmitted = true
+			}
+			if resubmitted {
+				return statusResponse(t, "Proof of delivery sent again for approval")
+			}
+		}
+
+		t, err := trip.Default.UploadPOD(tripID, trip.ActorDriver)
+		if err != nil {
+			return actionError(err)
+		}
+		return statusResponse(t, "Proof of delivery uploaded")
+
+	case "UPDATE_STATUS":
+		status, _ := req.Data["status"].(string)
+		note, _ := req.Data["note"].(string)
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+
+		// settlement and cancellation belong to the broker, the POD to UPLOAD_POD
+		if !driverStatuses[trip.Status(status)] {
+			return actionError(fmt.Errorf("%w: drivers cannot move a trip to %s", trip.ErrInvalidTransition, status))
+		}
+
+		t, err := trip.Default.Transition(tripID, trip.Status(status), trip.ActorDriver, note)
+		if err != nil {
+			return actionError(err)
+		}
+		return statusResponse(t, fmt.Sprintf("Trip status updated to: %s", t.Status.Label()))
+
 	case "VIEW_TRIP_DETAILS":
-		tripID := req.Data["tripId"].(string)
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
 		return bff.ActionResponse{
 			Status: "success",
 			Data: map[string]interface{}{
@@ -1498,27 +1905,186 @@ func handleModernAction(req bff.ActionRequest) bff.ActionResponse {
 		}
 
 	case "CHAT_WITH_BROKER":
-		brokerID := req.Data["brokerId"].(string)
+		// chats are per trip; without a tripId it is the active trip's
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		t, err := chat.Default.Conversation(tripID, driverID)
+		if err != nil {
+			return actionError(err)
+		}
 		return bff.ActionResponse{
 			Status: "success",
 			Data: map[string]interface{}{
-				"navigateTo": "/chat/" + brokerID,
+				"navigateTo": "/chat/" + t.ID,
+				"brokerId":   t.BrokerID,
+				"unread":     chat.Default.Unread(t.ID, driverID),
 			},
 		}
 
 	case "CALL_CONTACT":
-		contactType := req.Data["contactType"].(string)
-		phoneNumber := req.Data["phoneNumber"].(string)
+		// the number is looked up here so the app only ever dials the masked one
+		contactType, _ := req.Data["contactType"].(string)
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		contact, err := calls.Default.Contact(tripID, driverID, calls.Role(contactType))
+		if err != nil {
+			return actionError(err)
+		}
 
 		return bff.ActionResponse{
 			Status:  "success",
-			Message: fmt.Sprintf("Calling %s...", contactType),
+			Message: fmt.Sprintf("Calling %s...", contact.Role.Label()),
 			Data: map[string]interface{}{
 				"action":      "call",
-				"phoneNumber": phoneNumber,
+				"phoneNumber": contact.Number,
 				"contactType": contactType,
+				"name":        contact.Name,
 			},
 		}
+
+	case "DOWNLOAD_RECEIPT":
+		p, err := receiptPayment(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		doc, r, err := invoice.Default.Receipt(p.ID)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Receipt " + r.Number,
+			Data: map[string]interface{}{
+				"receiptNumber": r.Number,
+				"paid":          r.Paid.String(),
+				"documentId":    doc.ID,
+				"url":           doc.URL(driverID),
+			},
+		}
+
+	case "DOWNLOAD_LR":
+		tripID, err := actionTripID(driverID, req.Data)
+		if err != nil {
+			return actionError(err)
+		}
+		n, err := consignment.Default.ForTrip(tripID)
+		if err != nil || n.DriverID != driverID {
+			return actionError(consignment.ErrNotFound)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Lorry receipt " + n.Number,
+			Data: map[string]interface{}{
+				"lrNumber": n.Number,
+				"version":  n.Version,
+				"url":      docstore.URL(n.DocumentID, driverID),
+			},
+		}
+
+	case "UPDATE_ODOMETER":
+		v, err := vehicle.Default.ForDriver(driverID)
+		if err != nil {
+			return actionError(err)
+		}
+		odometer, _ := req.Data["odometer"].(float64)
+
+		v, err = vehicle.Default.UpdateOdometer(v.Number, odometer)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: "Odometer updated to " + bff.Km(v.Odometer),
+			Data:    v,
+		}
+
+	case "LOG_FUEL":
+		v, err := vehicle.Default.ForDriver(driverID)
+		if err != nil {
+			return actionError(err)
+		}
+		odometer, _ := req.Data["odometer"].(float64)
+		litres, _ := req.Data["litres"].(float64)
+		amount, _ := req.Data["amount"].(float64)
+		full, _ := req.Data["fullTank"].(bool)
+		station, _ := req.Data["station"].(string)
+		paidFrom, _ := req.Data["paidFrom"].(string)
+
+		f, err := vehicle.Default.LogFuel(v.Number, vehicle.FuelRequest{
+			Odometer: odometer,
+			Litres:   litres,
+			Amount:   ledger.FromRupees(amount),
+			Full:     full,
+			Station:  station,
+			PaidFrom: expense.PaidFrom(paidFrom),
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		message := fmt.Sprintf("%.0f L of fuel logged", f.Litres)
+		if f.Efficiency > 0 {
+			message += fmt.Sprintf(", %.2f km/L since the last full tank", f.Efficiency)
+		}
+		if f.ExpenseID != "" {
+			message += "; added to your trip expenses"
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: message,
+			Data:    f,
+		}
+
+	case "LOG_SERVICE":
+		v, err := vehicle.Default.ForDriver(driverID)
+		if err != nil {
+			return actionError(err)
+		}
+		var items []vehicle.Item
+		raw, _ := req.Data["items"].([]interface{})
+		for _, i := range raw {
+			if item, ok := i.(string); ok {
+				items = append(items, vehicle.Item(item))
+			}
+		}
+		odometer, _ := req.Data["odometer"].(float64)
+		cost, _ := req.Data["cost"].(float64)
+		garage, _ := req.Data["garage"].(string)
+		note, _ := req.Data["note"].(string)
+
+		r, err := vehicle.Default.LogService(v.Number, vehicle.ServiceRequest{
+			Items:    items,
+			Odometer: odometer,
+			Cost:     ledger.FromRupees(cost),
+			Garage:   garage,
+			Note:     note,
+		})
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: fmt.Sprintf("Service of %s logged", r.Cost),
+			Data:    r,
+		}
+
+	case "WITHDRAW":
+		amount, _ := req.Data["amount"].(float64)
+		method, _ := req.Data["method"].(string)
+		account, _ := req.Data["account"].(string)
+
+		t, err := payments.Default.Withdraw(driverID, ledger.FromRupees(amount), payments.Method(method), account)
+		if err != nil {
+			return actionError(err)
+		}
+		return bff.ActionResponse{
+			Status:  "success",
+			Message: fmt.Sprintf("Withdrawal of %s is %s", t.Amount, t.State),
+			Data:    t,
+		}
 	}
 
 	return bff.ActionResponse{
@@ -1526,3 +2092,78 @@ func handleModernAction(req bff.ActionRequest) bff.ActionResponse {
 		Message: "Unknown action",
 	}
 }
+
+// receiptPayment picks the payment a receipt is for: the given trip or payment, else the latest one paid
+func receiptPayment(driverID string, data map[string]interface{}) (settlement.Payment, error) {
+	tripID, _ := data["tripId"].(string)
+	paymentID, _ := data["paymentId"].(string)
+	for _, p := range settlement.Default.List(func(p settlement.Payment) bool { return p.DriverID == driverID }) {
+		switch {
+		case paymentID != "" && p.ID != paymentID,
+			tripID != "" && p.TripID != tripID,
+			paymentID == "" && tripID == "" && len(p.Releases) == 0:
+			continue
+		}
+		return p, nil
+	}
+	return settlement.Payment{}, invoice.ErrNothingPaid
+}
+
+// Statuses a driver may set through UPDATE_STATUS
+var driverStatuses = map[trip.Status]bool{
+	trip.StatusAccepted:      true,
+	trip.StatusReachedPickup: true,
+	trip.StatusLoading:       true,
+	trip.StatusInTransit:     true,
+	trip.StatusReachedDrop:   true,
+	trip.StatusDelivered:     true,
+}
+
+// actionTripID resolves the trip an action targets, defaulting to the driver's active trip.
+// Trips assigned to someone else are reported as not found.
+func actionTripID(driverID string, data map[string]interface{}) (string, error) {
+	t, err := actionTrip(driverID, data)
+	if err != nil {
+		return "", err
+	}
+	return t.ID, nil
+}
+
+// actionTrip is actionTripID for handlers that need the whole trip
+func actionTrip(driverID string, data map[string]interface{}) (trip.Trip, error) {
+	if id, _ := data["tripId"].(string); id != "" {
+		t, err := trip.Default.Get(id)
+		if err != nil || t.DriverID != driverID {
+			return trip.Trip{}, trip.ErrNotFound
+		}
+		return t, nil
+	}
+	return trip.Default.ActiveForDriver(driverID)
+}
+
+func actionError(err error) bff.ActionResponse {
+	return bff.ActionResponse{
+		Status:  "error",
+		Message: err.Error(),
+	}
+}
+
+func statusResponse(t trip.Trip, message string) bff.ActionResponse {
+	var next []string
+	for _, s := range trip.Next(t.Status) {
+		next = append(next, string(s))
+	}
+	return bff.ActionResponse{
+		Status:  "success",
+		Message: message,
+		Data: map[string]interface{}{
+			"tripId":          t.ID,
+			"tripStatus":      t.Status,
+			"isTripStarted":   t.Started(),
+			"locationSharing": t.LocationSharing,
+			"nextStatuses":    next,
+			"history":         t.History,
+			"updatedAt":       t.UpdatedAt.Format(time.RFC3339),
+		},
+	}
+}
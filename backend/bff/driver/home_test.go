@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/trip"
+	"testing"
+)
+
+func TestViewTripDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string // where the app is sent, empty for an error
+	}{
+		{name: "own trip", data: map[string]interface{}{"tripId": "TRK789012"}, want: "/trip-details/TRK789012"},
+		{name: "active trip by default", data: map[string]interface{}{}, want: "/trip-details/TRK789012"},
+		{name: "another driver's trip", data: map[string]interface{}{"tripId": "TRIP-002"}},
+		{name: "unknown trip", data: map[string]interface{}{"tripId": "TRK404"}},
+		{name: "trip ID that isn't a string", data: map[string]interface{}{"tripId": 42.0}, want: "/trip-details/TRK789012"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := handleModernAction(trip.DemoDriverID, bff.ActionRequest{Action: "VIEW_TRIP_DETAILS", Data: tt.data})
+			if tt.want == "" {
+				if res.Status != "error" {
+					t.Errorf("status = %s, want an error", res.Status)
+				}
+				return
+			}
+			data, _ := res.Data.(map[string]interface{})
+			if res.Status != "success" || data["navigateTo"] != tt.want {
+				t.Errorf("response = %s %v, want navigation to %s", res.Status, res.Data, tt.want)
+			}
+		})
+	}
+}
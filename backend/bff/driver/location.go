@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/ewaybill"
+	"backend/bff/geofence"
+	"backend/bff/safety"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+type LocationBatch struct {
+	TripID string           `json:"tripId"`
+	Points []tracking.Point `json:"points"`
+	Motion []safety.Motion  `json:"motion,omitempty"` // accelerometer readings, when the phone has one
+}
+
+type LocationResult struct {
+	tracking.Result
+	GeofenceEvents []geofence.Event  `json:"geofenceEvents,omitempty"`
+	SafetyEvents   []safety.Event    `json:"safetyEvents,omitempty"`
+	EwayBill       *ewaybill.Warning `json:"ewayBillWarning,omitempty"`
+}
+
+// LocationIngest accepts batched GPS points from the driver app for the active trip
+func LocationIngest(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	var req LocationBatch
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	t, err := actionTrip(driverID(c), map[string]interface{}{"tripId": req.TripID})
+	if err != nil {
+		c.JSON(404, actionError(err))
+		return
+	}
+	if !trackable(t) {
+		c.JSON(409, actionError(fmt.Errorf("location updates are closed for a %s trip", t.Status.Label())))
+		return
+	}
+
+	res, err := tracking.Default.Ingest(t.ID, req.Points)
+	if err != nil {
+		c.JSON(400, actionError(err))
+		return
+	}
+
+	result := LocationResult{
+		Result:         res,
+		GeofenceEvents: geofence.Default.Observe(t, res.Points),
+		SafetyEvents:   safety.Default.Observe(t, res.Points, req.Motion),
+	}
+	if w, ok := ewaybill.Default.Check(t); ok {
+		result.EwayBill = &w
+	}
+
+	c.JSON(200, bff.ActionResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("%d of %d points accepted", res.Accepted, res.Received),
+		Data:    result,
+	})
+}
+
+// Positions are only collected between accepting a trip and handing over the goods
+func trackable(t trip.Trip) bool {
+	switch t.Status {
+	case trip.StatusAccepted, trip.StatusReachedPickup, trip.StatusLoading, trip.StatusInTransit, trip.StatusReachedDrop:
+		return true
+	}
+	return false
+}
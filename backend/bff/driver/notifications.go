@@ -0,0 +1,202 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/notify"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+func NotificationsScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	driver := driverID(c)
+	alerts := notify.Default.List(driver)
+	unread := notify.Default.Unread(driver)
+	actionURL := "/bff/notifications/action?userId=" + driver
+
+	var cards []bff.UISnippet
+	for _, a := range alerts {
+		cards = append(cards, notificationCard(a, actionURL))
+	}
+	if len(cards) == 0 {
+		cards = append(cards, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: "You're all caught up", FontSize: 14, Color: "#999", TextAlign: "center"},
+		})
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "STATUS_BAR",
+			Data: bff.StatusBarData{
+				BackgroundColor: "#ffffff",
+				Style:           "dark",
+			},
+		},
+		{
+			Type: "SCROLL",
+			Data: bff.ViewData{
+				FlexGrow:        1,
+				BackgroundColor: "#f5f7fa",
+				PaddingTop:      16,
+				PaddingBottom:   16,
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{
+						FlexDirection:     "row",
+						JustifyContent:    "space-between",
+						AlignItems:        "center",
+						PaddingHorizontal: 16,
+						MarginBottom:      16,
+					},
+					Children: []bff.UISnippet{
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:       fmt.Sprintf("Notifications (%d unread)", unread),
+								FontSize:   20,
+								FontWeight: "bold",
+								Color:      "#1a237e",
+							},
+						},
+						{
+							Type: "BUTTON",
+							Data: bff.ButtonData{
+								Text:     "Mark all read",
+								Disabled: unread == 0,
+								Style: bff.ViewData{
+									PaddingVertical:   8,
+									PaddingHorizontal: 12,
+									BorderRadius:      8,
+									BackgroundColor:   "#1a237e",
+								},
+								Action: bff.ActionData{
+									Type:  "ACTION",
+									Value: "markAllRead",
+									Url:   actionURL,
+								},
+							},
+						},
+					},
+				},
+				{
+					Type:     "VIEW",
+					Data:     bff.ViewData{PaddingHorizontal: 16, Gap: 10},
+					Children: cards,
+				},
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "Notifications",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"unread":        unread,
+			"notifications": alerts,
+		},
+	}
+
+	c.JSON(200, response)
+}
+
+// Helper function to create a notification card that marks itself read when opened
+func notificationCard(a notify.Alert, actionURL string) bff.UISnippet {
+	icon, color := notificationStyle(a.Kind)
+	background, weight := "#FFFFFF", "600"
+	if !a.Read {
+		background, weight = "#EEF2FF", "700"
+	}
+
+	return bff.UISnippet{
+		Type: "PRESSABLE_CARD",
+		Data: bff.PressableCardData{
+			CardData: bff.CardData{
+				BackgroundColor: background,
+				Padding:         14,
+				BorderRadius:    12,
+				BorderWidth:     1,
+				BorderColor:     "#f0f0f0",
+				OnPress: bff.ActionData{
+					Type:  "ACTION",
+					Value: "markRead",
+					Url:   actionURL,
+					Data:  map[string]interface{}{"id": a.ID, "ref": a.Ref},
+				},
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{FlexDirection: "row", AlignItems: "center", Gap: 12},
+					Children: []bff.UISnippet{
+						{
+							Type: "ICON",
+							Data: bff.IconData{Name: icon, Size: 22, Color: color},
+						},
+						{
+							Type: "VIEW",
+							Data: bff.ViewData{Flex: 1},
+							Children: []bff.UISnippet{
+								{
+									Type: "TEXT",
+									Data: bff.TextData{Text: a.Title, FontSize: 14, FontWeight: weight, Color: "#1a237e"},
+								},
+								{
+									Type: "TEXT",
+									Data: bff.TextData{Text: a.Message, FontSize: 13, Color: "#555"},
+								},
+								{
+									Type: "TEXT",
+									Data: bff.TextData{Text: bff.TimeAgo(a.At), FontSize: 11, Color: "#999"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Helper function to pick the icon and color for a notification kind
+func notificationStyle(kind string) (string, string) {
+	switch kind {
+	case notify.KindPayment:
+		return "checkmark-circle", "#4CAF50"
+	case notify.KindTrip:
+		return "car", "#2196F3"
+	case notify.KindPOD, notify.KindDocument:
+		return "document-text", "#9C27B0"
+	case "eway_bill":
+		return "warning", "#FF9800"
+	case "transfer":
+		return "wallet", "#FF9800"
+	case "vehicle":
+		return "construct", "#607D8B"
+	}
+	return "notifications", "#607D8B"
+}
+
+// Helper function to show the latest notifications as home screen activities
+func notificationActivities(driverID string, limit int) []bff.RecentActivity {
+	var activities []bff.RecentActivity
+	for i, a := range notify.Default.List(driverID) {
+		if i == limit {
+			break
+		}
+		icon, color := notificationStyle(a.Kind)
+		activities = append(activities, bff.RecentActivity{
+			ID:      i + 1,
+			Type:    a.Kind,
+			Message: a.Title,
+			Time:    bff.TimeAgo(a.At),
+			Icon:    icon,
+			Color:   color,
+		})
+	}
+	return activities
+}
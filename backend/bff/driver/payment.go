@@ -2,13 +2,39 @@ package driver
 
 import (
 	"backend/bff"
+	"backend/bff/ledger"
+	"backend/bff/notify"
 	"strings"
+	"time"
 	"github.com/gin-gonic/gin"
 )
 
 func PaymentScreen(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	driver := driverID(c)
+	wallet := ledger.Default.Wallet(driver, ledger.MonthStart(time.Now()))
+
+	var transactions []bff.UISnippet
+	cleared, payouts := clearedTrips(driver, ledger.KindRelease), payoutStatus(driver)
+	reimbursed := clearedTrips(driver, ledger.KindReimbursed)
+	for i, e := range ledger.Default.Transactions(driver) {
+		if i == 5 {
+			break
+		}
+		transactions = append(transactions, ledgerTransactionCard(driver, e, cleared, reimbursed, payouts))
+	}
+
+	var settlements []bff.UISnippet
+	for i, e := range ledger.Default.Earnings(driver) {
+		if i == 5 {
+			break
+		}
+		settlements = append(settlements, ledgerSettlementCard(driver, e, cleared))
+	}
+
+	alert := latestPaymentAlert(driver)
+
 	ui := []bff.UISnippet{
 		// Status Bar
 		{
@@ -73,7 +99,7 @@ func PaymentScreen(c *gin.Context) {
 								{
 									Type: "TEXT",
 									Data: bff.TextData{
-										Text:         "Payment received for trip 4587",
+										Text:         alert.Title,
 										FontSize:     16,
 										FontWeight:   "bold",
 										Color:        "#1a1a1a",
@@ -83,7 +109,7 @@ func PaymentScreen(c *gin.Context) {
 								{
 									Type: "TEXT",
 									Data: bff.TextData{
-										Text:         "₹5,200 credited to wallet",
+										Text:         alert.Message,
 										FontSize:     18,
 										FontWeight:   "bold",
 										Color:        "#28A745",
@@ -93,7 +119,7 @@ func PaymentScreen(c *gin.Context) {
 								{
 									Type: "TEXT",
 									Data: bff.TextData{
-										Text:     "2 hours ago",
+										Text:     bff.TimeAgo(alert.At),
 										FontSize: 14,
 										Color:    "#666666",
 										Opacity:  0.8,
@@ -183,9 +209,9 @@ func PaymentScreen(c *gin.Context) {
 								Gap:            12,
 							},
 							Children: []bff.UISnippet{
-								walletCardEnhanced("wallet", "#28A745", "Current Balance", "₹4,520", "Available for use"),
-								walletCardEnhanced("cash-multiple", "#007AFF", "Total Earnings", "₹8,500", "This month"),
-								walletCardEnhanced("clock-outline", "#FF9500", "Pending", "₹2,300", "Awaiting clearance"),
+								walletCardEnhanced("wallet", "#28A745", "Current Balance", wallet.Available.String(), "Available for use"),
+								walletCardEnhanced("cash-multiple", "#007AFF", "Total Earnings", wallet.Earned.String(), "This month"),
+								walletCardEnhanced("clock-outline", "#FF9500", "Pending", wallet.Pending.String(), "Awaiting clearance"),
 							},
 						},
 					},
@@ -200,7 +226,7 @@ func PaymentScreen(c *gin.Context) {
 						PaddingHorizontal: 16,
 						MarginBottom:      24,
 					},
-					Children: []bff.UISnippet{
+					Children: append([]bff.UISnippet{
 						{
 							Type: "VIEW",
 							Data: bff.ViewData{
@@ -230,25 +256,7 @@ func PaymentScreen(c *gin.Context) {
 								},
 							},
 						},
-						transactionCardEnhanced(
-							"truck-check",
-							true,
-							"Commission Earned",
-							"TRIP#4587 • Mumbai → Delhi",
-							"₹2,500",
-							"Success",
-							"2024-09-15 • 10:30 AM",
-						),
-						transactionCardEnhanced(
-							"bank-transfer",
-							false,
-							"Withdrawal",
-							"Bank Transfer",
-							"₹5,000",
-							"Pending",
-							"2024-09-13 • 09:45 AM",
-						),
-					},
+					}, transactions...),
 				},
 
 				/* =======================
@@ -320,7 +328,7 @@ func PaymentScreen(c *gin.Context) {
 						PaddingHorizontal: 16,
 						MarginBottom:      24,
 					},
-					Children: []bff.UISnippet{
+					Children: append([]bff.UISnippet{
 						{
 							Type: "VIEW",
 							Data: bff.ViewData{
@@ -350,23 +358,7 @@ func PaymentScreen(c *gin.Context) {
 								},
 							},
 						},
-						settlementCardEnhanced(
-							"SET#789456",
-							"₹15,200",
-							"ABC Logistics",
-							"Bank Transfer",
-							"2024-09-15",
-							"Full payment received",
-						),
-						settlementCardEnhanced(
-							"SET#789455",
-							"₹8,500",
-							"XYZ Transport",
-							"UPI",
-							"2024-09-14",
-							"Commission deducted: ₹500",
-						),
-					},
+					}, settlements...),
 				},
 
 				/* =======================
@@ -407,6 +399,126 @@ func PaymentScreen(c *gin.Context) {
 	c.JSON(200, response)
 }
 
+// Helper function to show a ledger entry as a transaction row
+func ledgerTransactionCard(driverID string, e ledger.Entry, cleared, reimbursed map[string]bool, payouts map[string]string) bff.UISnippet {
+	amount := e.Net(ledger.DriverWallet(driverID), ledger.DriverPending(driverID))
+	if e.Kind == ledger.KindRelease || e.Kind == ledger.KindReimbursed {
+		amount = e.Net(ledger.DriverWallet(driverID))
+	}
+
+	subtitle := e.Mode
+	if e.TripID != "" {
+		subtitle = e.TripID + " • " + e.Memo
+	}
+	status := "Success"
+	switch {
+	case e.Kind == ledger.KindBalance && !cleared[e.TripID]:
+		status = "Pending"
+	case e.Kind == ledger.KindReimbursement && !reimbursed[e.TripID]:
+		status = "Pending"
+	case e.Kind == ledger.KindPayout && e.Ref != "":
+		status = payouts[e.Ref]
+	}
+	abs := amount
+	if abs < 0 {
+		abs = -abs
+	}
+
+	return transactionCardEnhanced(
+		ledgerIcons[e.Kind],
+		amount > 0,
+		e.Kind.Label(),
+		subtitle,
+		abs.String(),
+		status,
+		e.At.Format("2006-01-02 • 03:04 PM"),
+	)
+}
+
+// Helper function to show a broker payment as a settlement row
+func ledgerSettlementCard(driverID string, e ledger.Entry, cleared map[string]bool) bff.UISnippet {
+	remarks := "Advance for " + e.TripID
+	if e.Kind == ledger.KindBalance {
+		remarks = "Balance for " + e.TripID
+		if !cleared[e.TripID] {
+			remarks += " • awaiting clearance"
+		}
+	}
+
+	return settlementCardEnhanced(
+		"SET#"+e.ID,
+		e.Net(ledger.DriverWallet(driverID), ledger.DriverPending(driverID)).String(),
+		e.Counterparty,
+		e.Mode,
+		e.At.Format("2006-01-02"),
+		remarks,
+	)
+}
+
+// Latest payment alert, falling back to the last payment in the ledger
+func latestPaymentAlert(driverID string) notify.Alert {
+	for _, a := range notify.Default.List(driverID) {
+		if a.Kind == "payment" {
+			return a
+		}
+	}
+	if earnings := ledger.Default.Earnings(driverID); len(earnings) > 0 {
+		e := earnings[0]
+		return notify.Alert{
+			Kind:    "payment",
+			Title:   "Payment received for trip " + e.TripID,
+			Message: e.Net(ledger.DriverWallet(driverID), ledger.DriverPending(driverID)).String() + " credited to wallet",
+			At:      e.At,
+		}
+	}
+	return notify.Alert{Title: "No payments yet", Message: "₹0 credited to wallet"}
+}
+
+// Outcome of each withdrawal sent to the payment provider, keyed by reference
+func payoutStatus(driverID string) map[string]string {
+	status := map[string]string{}
+	for _, e := range ledger.Default.Entries(func(e ledger.Entry) bool {
+		return e.Touches(ledger.DriverPayout(driverID))
+	}) {
+		switch e.Kind {
+		case ledger.KindPayout:
+			if status[e.Ref] == "" {
+				status[e.Ref] = "Pending"
+			}
+		case ledger.KindPayoutSettled:
+			status[e.Ref] = "Success"
+		case ledger.KindPayoutReversed:
+			status[e.Ref] = "Failed"
+		}
+	}
+	return status
+}
+
+// Trips whose pending money has been cleared into the wallet by an entry of the given kind
+func clearedTrips(driverID string, kind ledger.Kind) map[string]bool {
+	cleared := map[string]bool{}
+	for _, e := range ledger.Default.Transactions(driverID) {
+		if e.Kind == kind {
+			cleared[e.TripID] = true
+		}
+	}
+	return cleared
+}
+
+var ledgerIcons = map[ledger.Kind]string{
+	ledger.KindAdvance:   "truck-check",
+	ledger.KindBalance:   "cash-multiple",
+	ledger.KindRelease:   "check-circle",
+	ledger.KindDeduction: "minus-circle",
+	ledger.KindToll:      "road-variant",
+	ledger.KindPayout:    "bank-transfer",
+	ledger.KindExpense:   "receipt",
+
+	ledger.KindPayoutReversed: "bank-transfer",
+	ledger.KindReimbursement:  "cash-refund",
+	ledger.KindReimbursed:     "check-circle",
+}
+
 // Enhanced Helper Functions
 func walletCardEnhanced(icon string, color string, title string, value string, subtitle string) bff.UISnippet {
 	return bff.UISnippet{
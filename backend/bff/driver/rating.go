@@ -0,0 +1,119 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/rating"
+	"backend/bff/trip"
+	"fmt"
+)
+
+// Helper function to ask the driver to rate the broker after delivery, and
+// show what the broker said about the driver
+func tripRatingSection(t trip.Trip, driverID string) []bff.UISnippet {
+	actionURL := "/bff/ratings/action?userId=" + driverID
+	broker := t.Details["brokerName"]
+	if broker == "" {
+		broker = "the broker"
+	}
+
+	var rows []bff.UISnippet
+	if rating.Default.Due(t, driverID) {
+		rows = append(rows,
+			bff.UISnippet{
+				Type: "TEXT",
+				Data: bff.TextData{Text: "Rate " + broker, FontSize: 18, FontWeight: "700", Color: "#1E293B"},
+			},
+			bff.UISnippet{
+				Type: "TEXT",
+				Data: bff.TextData{Text: "Score each from 1 to 5 stars", FontSize: 13, Color: "#64748B"},
+			},
+		)
+		for _, c := range rating.Criteria(rating.RoleDriver) {
+			rows = append(rows, bff.UISnippet{
+				Type: "VIEW",
+				Data: bff.ViewData{FlexDirection: "row", JustifyContent: "space-between", AlignItems: "center"},
+				Children: []bff.UISnippet{
+					{Type: "TEXT", Data: bff.TextData{Text: "⭐ " + c.Label(), FontSize: 15, Color: "#1E293B"}},
+					{
+						Type: "INPUT",
+						Data: bff.InputData{
+							Id:           string(c),
+							Placeholder:  "1-5",
+							KeyboardType: "number-pad",
+							MaxLength:    1,
+							FontSize:     15,
+							Style:        bff.ViewData{Width: 56, Padding: 8, BorderRadius: 8, BorderWidth: 1, BorderColor: "#E2E8F0"},
+						},
+					},
+				},
+			})
+		}
+		rows = append(rows,
+			bff.UISnippet{
+				Type: "INPUT",
+				Data: bff.InputData{
+					Id:          "comment",
+					Placeholder: "Anything other drivers should know? (optional)",
+					MaxLength:   500,
+					FontSize:    14,
+					Style:       bff.ViewData{Padding: 12, BorderRadius: 10, BorderWidth: 1, BorderColor: "#E2E8F0"},
+				},
+			},
+			bff.UISnippet{
+				Type: "BUTTON",
+				Data: bff.ButtonData{
+					Text: "Submit Rating",
+					Action: bff.ActionData{
+						Type:   "ACTION",
+						Value:  "submit",
+						Url:    actionURL,
+						Method: "POST",
+						Data:   map[string]interface{}{"tripId": t.ID},
+					},
+				},
+			},
+		)
+	} else if r, ok := rating.Default.Given(t.ID, driverID); ok {
+		rows = append(rows, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: fmt.Sprintf("You rated %s %.1f ⭐", broker, r.Overall), FontSize: 15, FontWeight: "600", Color: "#1E293B"},
+		})
+	}
+
+	if r, ok := rating.Default.Given(t.ID, t.BrokerID); ok && !r.Hidden {
+		rows = append(rows, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: fmt.Sprintf("%s rated you %.1f ⭐", broker, r.Overall), FontSize: 15, FontWeight: "600", Color: "#1E293B"},
+		})
+		if r.Comment != "" {
+			rows = append(rows,
+				bff.UISnippet{
+					Type: "TEXT",
+					Data: bff.TextData{Text: "“" + r.Comment + "”", FontSize: 14, Color: "#475569"},
+				},
+				bff.UISnippet{
+					Type: "BUTTON",
+					Data: bff.ButtonData{
+						Text: "Report Review",
+						Action: bff.ActionData{
+							Type:   "ACTION",
+							Value:  "report",
+							Url:    actionURL,
+							Method: "POST",
+							Data:   map[string]interface{}{"reviewId": r.ID},
+						},
+					},
+				},
+			)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return []bff.UISnippet{{
+		Type:     "VIEW",
+		Data:     bff.ViewData{BackgroundColor: "#FFFFFF", BorderRadius: 16, Padding: 16, Gap: 10},
+		Children: rows,
+	}}
+}
@@ -0,0 +1,50 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/safety"
+	"fmt"
+)
+
+// Helper function to replace the sample safe driving stat with the trip's score
+func safetyStat(r safety.Report, stats []bff.RouteData) []bff.RouteData {
+	score := "No data"
+	if r.Samples > 0 {
+		score = r.Text() + "/100"
+	}
+	for i := range stats {
+		if stats[i].ID == "safety" {
+			stats[i].Description = score
+		}
+	}
+	return stats
+}
+
+// Helper function to show what took points off the trip's safety score
+func tripSafetySection(r safety.Report) []bff.UISnippet {
+	if r.Samples == 0 {
+		return nil
+	}
+
+	rows := []bff.UISnippet{
+		{
+			Type: "TEXT",
+			Data: bff.TextData{Text: "Driving Safety: " + r.Text() + "/100", FontSize: 18, FontWeight: "700", Color: "#1E293B"},
+		},
+	}
+	if len(r.Breakdown) == 0 {
+		rows = append(rows, bff.UISnippet{
+			Type: "TEXT",
+			Data: bff.TextData{Text: "No risky driving on this trip. Well done!", FontSize: 14, Color: "#059669"},
+		})
+	}
+	for _, c := range r.Breakdown {
+		rows = append(rows, expenseRow(fmt.Sprintf("%s (%d)", c.Label, c.Count), fmt.Sprintf("-%.0f", c.Penalty), "#DC2626"))
+	}
+
+	return []bff.UISnippet{{
+		Type:     "VIEW",
+		Data:     bff.ViewData{BackgroundColor: "#FFFFFF", BorderRadius: 16, Padding: 16, Gap: 8},
+		Children: rows,
+	}}
+}
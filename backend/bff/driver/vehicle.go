@@ -0,0 +1,302 @@
+package driver
+
+import (
+	"backend/bff"
+	"backend/bff/ewaybill"
+	"backend/bff/ledger"
+	"backend/bff/vehicle"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+func VehicleScreen(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	v, err := vehicle.Default.ForDriver(driverID(c))
+	if err != nil {
+		c.JSON(404, bff.ScreenResponse{
+			Status:  "error",
+			Screen:  "Vehicle",
+			Message: "No vehicle is registered to you",
+		})
+		return
+	}
+
+	schedule, _ := vehicle.Default.Schedule(v.Number)
+	health, _ := vehicle.Default.Health(v.Number)
+	history := vehicle.Default.History(v.Number)
+	fuel := vehicle.Default.FuelLog(v.Number)
+	efficiency, hasEfficiency := vehicle.Default.Efficiency(v.Number)
+	level, hasLevel := vehicle.Default.FuelLevel(v.Number)
+	serviceSpend, fuelSpend := vehicle.Default.Spend(v.Number, ledger.MonthStart(time.Now().In(ewaybill.IST)))
+
+	fuelText, mileageText := "-", "-"
+	if hasLevel {
+		fuelText = strconv.Itoa(level) + "%"
+	}
+	if hasEfficiency {
+		mileageText = fmt.Sprintf("%.1f km/L", efficiency)
+	}
+
+	ui := []bff.UISnippet{
+		{
+			Type: "STATUS_BAR",
+			Data: bff.StatusBarData{
+				BackgroundColor: "#1a237e",
+				Style:           "light",
+			},
+		},
+		{
+			Type: "SCROLL",
+			Data: bff.ViewData{
+				FlexGrow:        1,
+				BackgroundColor: "#f5f7fa",
+				PaddingTop:      20,
+				PaddingBottom:   20,
+			},
+			Children: []bff.UISnippet{
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{PaddingHorizontal: 20, MarginBottom: 16},
+					Children: []bff.UISnippet{
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:       "My Vehicle",
+								FontSize:   24,
+								FontWeight: "bold",
+								Color:      "#1a237e",
+							},
+						},
+						{
+							Type: "TEXT",
+							Data: bff.TextData{
+								Text:     fmt.Sprintf("%s · %s · %s", v.Number, v.Model, v.Type),
+								FontSize: 14,
+								Color:    "#666",
+							},
+						},
+					},
+				},
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{FlexDirection: "row", Gap: 12, PaddingHorizontal: 20, MarginBottom: 12},
+					Children: []bff.UISnippet{
+						analyticsStat("Odometer", bff.Km(v.Odometer), "speedometer-outline", "#2196F3"),
+						analyticsStat("Health", health.Label, "checkmark-circle-outline", healthColor(health)),
+					},
+				},
+				{
+					Type: "VIEW",
+					Data: bff.ViewData{FlexDirection: "row", Gap: 12, PaddingHorizontal: 20, MarginBottom: 20},
+					Children: []bff.UISnippet{
+						analyticsStat("Fuel", fuelText, "water-outline", "#FF9800"),
+						analyticsStat("Mileage", mileageText, "trending-up-outline", "#4CAF50"),
+						analyticsStat("Spent This Month", (serviceSpend + fuelSpend).String(), "wallet-outline", "#9C27B0"),
+					},
+				},
+				vehicleActions(v),
+				vehicleCard("Service Schedule", scheduleRows(schedule)),
+				vehicleCard("Service History", serviceRows(history)),
+				vehicleCard("Fuel Log", fuelRows(fuel)),
+			},
+		},
+	}
+
+	response := bff.ScreenResponse{
+		Status: "success",
+		Screen: "Vehicle",
+		UI:     ui,
+		Data: map[string]interface{}{
+			"vehicle":    v,
+			"health":     health,
+			"schedule":   schedule,
+			"services":   history,
+			"fuel":       fuel,
+			"efficiency": efficiency,
+			"fuelLevel":  level,
+		},
+	}
+
+	c.JSON(200, response)
+}
+
+// Helper function to create the log fuel / log service / odometer buttons
+func vehicleActions(v vehicle.Vehicle) bff.UISnippet {
+	var items []string
+	for _, s := range vehicle.Schedule {
+		items = append(items, string(s.Item))
+	}
+
+	button := func(text, value string, data map[string]interface{}) bff.UISnippet {
+		return bff.UISnippet{
+			Type: "BUTTON",
+			Data: bff.ButtonData{
+				Text: text,
+				Style: bff.ViewData{
+					Flex:            1,
+					PaddingVertical: 12,
+					BorderRadius:    12,
+					BackgroundColor: "#1a237e",
+				},
+				Action: bff.ActionData{
+					Type:   "ACTION",
+					Value:  value,
+					Url:    "/bff/driver/home/action",
+					Method: "POST",
+					Data:   data,
+				},
+			},
+		}
+	}
+
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{FlexDirection: "row", Gap: 8, PaddingHorizontal: 20, MarginBottom: 16},
+		Children: []bff.UISnippet{
+			button("Log Fuel", "LOG_FUEL", map[string]interface{}{
+				"odometer": v.Odometer,
+				"fields":   []string{"odometer", "litres", "amount", "fullTank", "station"},
+			}),
+			button("Log Service", "LOG_SERVICE", map[string]interface{}{
+				"odometer": v.Odometer,
+				"items":    items,
+				"fields":   []string{"items", "odometer", "cost", "garage", "note"},
+			}),
+			button("Odometer", "UPDATE_ODOMETER", map[string]interface{}{
+				"odometer": v.Odometer,
+				"fields":   []string{"odometer"},
+			}),
+		},
+	}
+}
+
+// Helper function to wrap vehicle rows in a titled card
+func vehicleCard(title string, rows []bff.UISnippet) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "VIEW",
+		Data: bff.ViewData{
+			BackgroundColor:  "#fff",
+			BorderRadius:     16,
+			Padding:          16,
+			Gap:              10,
+			MarginHorizontal: 20,
+			MarginBottom:     16,
+			Elevation:        1,
+		},
+		Children: append([]bff.UISnippet{
+			{
+				Type: "TEXT",
+				Data: bff.TextData{Text: title, FontSize: 16, FontWeight: "700", Color: "#1a237e"},
+			},
+		}, rows...),
+	}
+}
+
+func scheduleRows(schedule []vehicle.Due) []bff.UISnippet {
+	var rows []bff.UISnippet
+	for _, d := range schedule {
+		color := "#4CAF50"
+		switch d.Status {
+		case vehicle.DueSoon:
+			color = "#FF9800"
+		case vehicle.DueOverdue:
+			color = "#F44336"
+		}
+		rows = append(rows, expenseRow(d.Label, dueText(d), color))
+	}
+	return rows
+}
+
+func serviceRows(history []vehicle.ServiceRecord) []bff.UISnippet {
+	if len(history) == 0 {
+		return []bff.UISnippet{emptyRow("No services logged yet")}
+	}
+	var rows []bff.UISnippet
+	for _, r := range history {
+		var labels string
+		for i, item := range r.Items {
+			if i > 0 {
+				labels += ", "
+			}
+			labels += item.Label()
+		}
+		rows = append(rows, bff.UISnippet{
+			Type: "VIEW",
+			Data: bff.ViewData{Gap: 2},
+			Children: []bff.UISnippet{
+				expenseRow(labels, r.Cost.String(), "#1a1a1a"),
+				{
+					Type: "TEXT",
+					Data: bff.TextData{
+						Text:     fmt.Sprintf("%s · %s · %s", r.At.In(ewaybill.IST).Format("02 Jan 2006"), bff.Km(r.Odometer), r.Garage),
+						FontSize: 12,
+						Color:    "#999",
+					},
+				},
+			},
+		})
+	}
+	return rows
+}
+
+func fuelRows(fuel []vehicle.FuelEntry) []bff.UISnippet {
+	if len(fuel) == 0 {
+		return []bff.UISnippet{emptyRow("No fuel logged yet")}
+	}
+	var rows []bff.UISnippet
+	for _, f := range fuel {
+		detail := fmt.Sprintf("%s · %s · %s/L", f.At.In(ewaybill.IST).Format("02 Jan"), bff.Km(f.Odometer), f.PerLitre())
+		if f.Efficiency > 0 {
+			detail += fmt.Sprintf(" · %.2f km/L", f.Efficiency)
+		} else if !f.Full {
+			detail += " · part fill"
+		}
+		rows = append(rows, bff.UISnippet{
+			Type: "VIEW",
+			Data: bff.ViewData{Gap: 2},
+			Children: []bff.UISnippet{
+				expenseRow(fmt.Sprintf("%.0f L at %s", f.Litres, f.Station), f.Amount.String(), "#1a1a1a"),
+				{
+					Type: "TEXT",
+					Data: bff.TextData{Text: detail, FontSize: 12, Color: "#999"},
+				},
+			},
+		})
+	}
+	return rows
+}
+
+func emptyRow(text string) bff.UISnippet {
+	return bff.UISnippet{
+		Type: "TEXT",
+		Data: bff.TextData{Text: text, FontSize: 14, Color: "#999"},
+	}
+}
+
+// dueText reads "Due in 500 km", "Due in 12 days" or "Overdue by 300 km",
+// whichever limit is nearer
+func dueText(d vehicle.Due) string {
+	byKm := d.NextKm > 0 && (d.NextAt.IsZero() || d.KmLeft < 100*float64(d.DaysLeft))
+	switch {
+	case d.Status == vehicle.DueOverdue && d.NextKm > 0 && d.KmLeft <= 0:
+		return "Overdue by " + bff.Km(-d.KmLeft)
+	case d.Status == vehicle.DueOverdue:
+		return fmt.Sprintf("Overdue by %d days", -d.DaysLeft)
+	case byKm:
+		return "Due in " + bff.Km(d.KmLeft)
+	}
+	return fmt.Sprintf("Due in %d days", d.DaysLeft)
+}
+
+func healthColor(h vehicle.Health) string {
+	switch h.Label {
+	case "Overdue":
+		return "#F44336"
+	case "Service Due":
+		return "#FF9800"
+	}
+	return "#4CAF50"
+}
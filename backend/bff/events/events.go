@@ -0,0 +1,105 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+// Type names what happened, e.g. "trip.assigned"
+type Type string
+
+const (
+	TripAssigned      Type = "trip.assigned"
+	TripStatusChanged Type = "trip.status_changed"
+	LoadPosted        Type = "load.posted"
+	LoadAssigned      Type = "load.assigned"
+	DocumentUploaded  Type = "document.uploaded"
+	PaymentReleased   Type = "payment.released"
+	PODApproved       Type = "payment.pod_approved"
+	PODRejected       Type = "payment.pod_rejected"
+	MemberInvited     Type = "org.member_invited"
+	VehicleServiceDue Type = "vehicle.service_due"
+)
+
+// Event is a change in one of the domain stores
+type Event struct {
+	ID       string            `json:"id"`
+	Type     Type              `json:"type"`
+	Subject  string            `json:"subject"` // the trip, load or payment it is about
+	DriverID string            `json:"driverId,omitempty"`
+	BrokerID string            `json:"brokerId,omitempty"`
+	Actor    string            `json:"actor,omitempty"`
+	Data     map[string]string `json:"data,omitempty"`
+	At       time.Time         `json:"at"`
+}
+
+// Handler reacts to a published event
+type Handler func(e Event)
+
+// Bus delivers events to every subscriber in the order they were published.
+// It keeps the latest events so subscribers that start late, such as services
+// created after the stores have seeded their data, still see them.
+// Handlers run after the bus is unlocked, so they may publish in turn; events
+// from one goroutine reach every subscriber in order, but two publishing at
+// once may reach different subscribers in a different order.
+type Bus struct {
+	// How many past events late subscribers are replayed
+	Retain int
+
+	mu       sync.Mutex
+	seq      int
+	log      []Event
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{Retain: 1000}
+}
+
+// Default bus the stores publish on
+var Default = NewBus()
+
+// Publish stamps an event and hands it to the subscribers
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+	b.seq++
+	e.ID = fmt.Sprintf("EV%06d", b.seq)
+	if e.At.IsZero() {
+		e.At = now()
+	}
+	b.log = append(b.log, e)
+	if len(b.log) > b.Retain {
+		b.log = b.log[len(b.log)-b.Retain:]
+	}
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+	return e
+}
+
+// Subscribe replays the retained events to h, then delivers new ones. The
+// replay holds the bus so nothing published meanwhile is missed or seen twice;
+// h must not publish while it is being replayed to.
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range b.log {
+		h(e)
+	}
+	b.handlers = append(b.handlers, h)
+}
+
+// Follow delivers only events published from now on, for subscribers that
+// act on the outside world and must not repeat history
+func (b *Bus) Follow(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
@@ -0,0 +1,171 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// recorder is a subscriber that keeps what it was handed
+type recorder struct {
+	mu   sync.Mutex
+	seen []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, e)
+}
+
+func (r *recorder) ids() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.seen))
+	for _, e := range r.seen {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func sequence(from, to int) []string {
+	var ids []string
+	for i := from; i <= to; i++ {
+		ids = append(ids, fmt.Sprintf("EV%06d", i))
+	}
+	return ids
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		retain int
+		before int // published before subscribing
+		after  int
+		follow bool
+		want   []string
+	}{
+		{name: "nothing yet", retain: 10, after: 3, want: sequence(1, 3)},
+		{name: "replayed then live", retain: 10, before: 2, after: 3, want: sequence(1, 5)},
+		{name: "only what is retained", retain: 2, before: 5, after: 1, want: sequence(4, 6)},
+		{name: "follow skips history", retain: 10, before: 5, after: 2, follow: true, want: sequence(6, 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBus()
+			b.Retain = tt.retain
+			for i := 0; i < tt.before; i++ {
+				b.Publish(Event{Type: TripAssigned})
+			}
+			r := &recorder{}
+			if tt.follow {
+				b.Follow(r.handle)
+			} else {
+				b.Subscribe(r.handle)
+			}
+			for i := 0; i < tt.after; i++ {
+				b.Publish(Event{Type: TripAssigned})
+			}
+			if got := r.ids(); !equal(got, tt.want) {
+				t.Errorf("saw %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribeWhilePublishing(t *testing.T) {
+	const n = 500
+	b := NewBus()
+	b.Retain = n
+
+	// subscribing part way through must neither miss nor repeat an event
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			if i == n/10 {
+				close(started)
+			}
+			b.Publish(Event{Type: TripAssigned})
+		}
+	}()
+	<-started
+	r := &recorder{}
+	b.Subscribe(r.handle)
+	<-done
+
+	if got := r.ids(); !equal(got, sequence(1, n)) {
+		t.Errorf("saw %d events, want EV000001 to EV%06d in order", len(got), n)
+	}
+}
+
+func TestPublishOrder(t *testing.T) {
+	const publishers, each = 4, 100
+	b := NewBus()
+	subscribers := []*recorder{{}, {}, {}}
+	for _, r := range subscribers {
+		b.Follow(r.handle)
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < publishers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < each; i++ {
+				b.Publish(Event{Type: TripStatusChanged, Subject: fmt.Sprintf("P%d", p), Data: map[string]string{"n": fmt.Sprint(i)}})
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	// every subscriber sees every event once, and each publisher's events in
+	// the order it published them
+	for s, r := range subscribers {
+		if len(r.seen) != publishers*each {
+			t.Fatalf("subscriber %d saw %d events, want %d", s, len(r.seen), publishers*each)
+		}
+		next := map[string]int{}
+		for _, e := range r.seen {
+			if want := fmt.Sprint(next[e.Subject]); e.Data["n"] != want {
+				t.Fatalf("subscriber %d saw %s event %s before %s", s, e.Subject, e.Data["n"], want)
+			}
+			next[e.Subject]++
+		}
+	}
+}
+
+func TestHandlerMayPublish(t *testing.T) {
+	b := NewBus()
+	// a handler that reacts by changing a store publishes a follow-up event
+	b.Follow(func(e Event) {
+		if e.Type == TripAssigned {
+			b.Publish(Event{Type: LoadAssigned, Subject: e.Subject})
+		}
+	})
+	r := &recorder{}
+	b.Follow(r.handle)
+
+	b.Publish(Event{Type: TripAssigned, Subject: "TRK1"})
+
+	types := map[Type]int{}
+	for _, e := range r.seen {
+		types[e.Type]++
+	}
+	if types[TripAssigned] != 1 || types[LoadAssigned] != 1 {
+		t.Errorf("saw %v, want one of each", types)
+	}
+}
@@ -0,0 +1,95 @@
+package ewaybill
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+// E-way bill validity days run on Indian time
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// Distance covered by one day of validity under CGST Rule 138(10)
+const (
+	KmPerDay    = 200.0
+	KmPerDayODC = 20.0 // over dimensional cargo
+)
+
+// An expiring bill can be extended from 8 hours before to 8 hours after it lapses
+const ExtensionWindow = 8 * time.Hour
+
+var (
+	ErrNotFound         = errors.New("e-way bill not found")
+	ErrInvalidNumber    = errors.New("e-way bill number must be 12 digits")
+	ErrMissing          = errors.New("e-way bill not captured for this trip")
+	ErrExpired          = errors.New("e-way bill has expired")
+	ErrCancelled        = errors.New("e-way bill is cancelled on the GST portal")
+	ErrFutureGeneration = errors.New("e-way bill generation time is in the future")
+	ErrNoDistance       = errors.New("distance must be more than zero")
+	ErrOutsideWindow    = errors.New("e-way bill can only be extended from 8 hours before to 8 hours after expiry")
+)
+
+// Bill is the e-way bill a trip moves under
+type Bill struct {
+	Number       string       `json:"number"`
+	TripID       string       `json:"tripId"`
+	Vehicle      string       `json:"vehicle"`
+	DistanceKm   int          `json:"distanceKm"`
+	ODC          bool         `json:"odc,omitempty"`
+	GeneratedAt  time.Time    `json:"generatedAt"`
+	ValidUntil   time.Time    `json:"validUntil"`
+	Verification Verification `json:"verification"`
+	Extensions   []Extension  `json:"extensions,omitempty"`
+	CapturedAt   time.Time    `json:"capturedAt"`
+}
+
+// Extension records one extension of a bill's validity
+type Extension struct {
+	RemainingKm int       `json:"remainingKm"`
+	Place       string    `json:"place"`
+	Reason      string    `json:"reason"`
+	From        time.Time `json:"from"`
+	Until       time.Time `json:"until"`
+	At          time.Time `json:"at"`
+}
+
+// Validity returns when a bill generated at a time for a distance lapses.
+// Each day of validity expires at midnight of the day after generation, so a
+// one day bill made at 10 AM on the 1st is good until the end of the 2nd.
+func Validity(from time.Time, km float64, odc bool) time.Time {
+	perDay := KmPerDay
+	if odc {
+		perDay = KmPerDayODC
+	}
+	days := int(math.Ceil(km / perDay))
+	if days < 1 {
+		days = 1
+	}
+
+	local := from.In(IST)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, IST)
+	return midnight.AddDate(0, 0, days+1).Add(-time.Second)
+}
+
+func (b Bill) Expired(at time.Time) bool {
+	return at.After(b.ValidUntil)
+}
+
+// Extendable reports whether the bill is inside its extension window
+func (b Bill) Extendable(at time.Time) bool {
+	return !at.Before(b.ValidUntil.Add(-ExtensionWindow)) && !at.After(b.ValidUntil.Add(ExtensionWindow))
+}
+
+func validNumber(number string) bool {
+	if len(number) != 12 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package ewaybill
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidity(t *testing.T) {
+	morning := time.Date(2025, 3, 1, 10, 0, 0, 0, IST)
+	tests := []struct {
+		name string
+		from time.Time
+		km   float64
+		odc  bool
+		want time.Time
+	}{
+		{name: "short haul gets a day", from: morning, km: 100, want: time.Date(2025, 3, 2, 23, 59, 59, 0, IST)},
+		{name: "exactly one day", from: morning, km: 200, want: time.Date(2025, 3, 2, 23, 59, 59, 0, IST)},
+		{name: "one km into the second day", from: morning, km: 201, want: time.Date(2025, 3, 3, 23, 59, 59, 0, IST)},
+		{name: "no distance still gets a day", from: morning, km: 0, want: time.Date(2025, 3, 2, 23, 59, 59, 0, IST)},
+		{name: "long haul", from: morning, km: 1400, want: time.Date(2025, 3, 8, 23, 59, 59, 0, IST)},
+		{name: "over dimensional cargo", from: morning, km: 100, odc: true, want: time.Date(2025, 3, 6, 23, 59, 59, 0, IST)},
+		{name: "over dimensional cargo, one day", from: morning, km: 20, odc: true, want: time.Date(2025, 3, 2, 23, 59, 59, 0, IST)},
+		{name: "day is counted in IST", from: time.Date(2025, 3, 1, 19, 0, 0, 0, time.UTC), km: 100, want: time.Date(2025, 3, 3, 23, 59, 59, 0, IST)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validity(tt.from, tt.km, tt.odc); !got.Equal(tt.want) {
+				t.Errorf("Validity = %s, want %s", got.In(IST), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendable(t *testing.T) {
+	b := Bill{ValidUntil: time.Date(2025, 3, 2, 23, 59, 59, 0, IST)}
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "too early", at: b.ValidUntil.Add(-ExtensionWindow - time.Second)},
+		{name: "window opens", at: b.ValidUntil.Add(-ExtensionWindow), want: true},
+		{name: "just lapsed", at: b.ValidUntil.Add(time.Hour), want: true},
+		{name: "window closes", at: b.ValidUntil.Add(ExtensionWindow), want: true},
+		{name: "too late", at: b.ValidUntil.Add(ExtensionWindow + time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Extendable(tt.at); got != tt.want {
+				t.Errorf("Extendable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package ewaybill
+
+import "time"
+
+// Demo trips that already carry every document also carry their e-way bill
+func seed(s *Service) *Service {
+	bills := map[string]CaptureRequest{
+		"TRIP-002": {Number: "331008765432", GeneratedAt: now().Add(-5 * time.Hour)},
+		"TRIP-003": {Number: "191004567812", GeneratedAt: now().Add(-50 * time.Hour)},
+	}
+	for tripID, req := range bills {
+		s.Capture(tripID, req)
+	}
+	return s
+}
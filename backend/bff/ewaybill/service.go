@@ -0,0 +1,276 @@
+package ewaybill
+
+import (
+	"backend/bff/geo"
+	"backend/bff/notify"
+	"backend/bff/routing"
+	"backend/bff/trip"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Warning levels, from least to most urgent
+const (
+	WarnLapsesEnRoute = "lapses_en_route" // the truck will arrive after the bill lapses
+	WarnExpiring      = "expiring"
+	WarnExpired       = "expired"
+)
+
+// Warning tells the driver and broker a moving trip's bill needs attention
+type Warning struct {
+	Level      string    `json:"level"`
+	Message    string    `json:"message"`
+	ValidUntil time.Time `json:"validUntil"`
+	Extendable bool      `json:"extendable"`
+}
+
+type CaptureRequest struct {
+	Number      string
+	GeneratedAt time.Time // zero means just now
+	DistanceKm  int       // zero means the trip's route distance
+	ODC         bool
+	Vehicle     string // empty means the trip's vehicle
+}
+
+type ExtendRequest struct {
+	RemainingKm int    // zero means the distance left on the route
+	Place       string // empty means the truck's last position
+	Reason      string
+}
+
+// Service keeps the e-way bill of every trip and holds trips back without a valid one
+type Service struct {
+	Trips    *trip.Store
+	Routes   *routing.Engine
+	Verifier Verifier
+	Alerts   *notify.Store
+
+	// How long before expiry a moving trip is warned
+	WarnBefore time.Duration
+
+	mu     sync.Mutex
+	bills  map[string]Bill // by trip ID
+	warned map[string]bool
+}
+
+func NewService(trips *trip.Store, routes *routing.Engine, v Verifier, alerts *notify.Store) *Service {
+	s := &Service{
+		Trips:      trips,
+		Routes:     routes,
+		Verifier:   v,
+		Alerts:     alerts,
+		WarnBefore: 12 * time.Hour,
+		bills:      map[string]Bill{},
+		warned:     map[string]bool{},
+	}
+	trips.AddGuard(trip.StatusInTransit, s.ready)
+	return s
+}
+
+// Default service used by the BFF handlers
+var Default = seed(NewService(trip.Default, routing.Default, Local{}, notify.Default))
+
+// Capture records the bill a trip will move under, verifying it first
+func (s *Service) Capture(tripID string, req CaptureRequest) (Bill, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Bill{}, err
+	}
+	if !validNumber(req.Number) {
+		return Bill{}, ErrInvalidNumber
+	}
+
+	b := Bill{
+		Number:      req.Number,
+		TripID:      t.ID,
+		Vehicle:     req.Vehicle,
+		DistanceKm:  req.DistanceKm,
+		ODC:         req.ODC,
+		GeneratedAt: req.GeneratedAt,
+		CapturedAt:  now(),
+	}
+	if b.GeneratedAt.IsZero() {
+		b.GeneratedAt = b.CapturedAt
+	}
+	if b.GeneratedAt.After(b.CapturedAt) {
+		return Bill{}, ErrFutureGeneration
+	}
+	if b.Vehicle == "" {
+		b.Vehicle = t.Details["vehicleNumber"]
+	}
+	if b.DistanceKm == 0 {
+		km, err := s.Routes.Provider.Distance(t.Pickup, t.Drop)
+		if err != nil {
+			return Bill{}, err
+		}
+		b.DistanceKm = int(math.Ceil(km))
+	}
+	if b.DistanceKm <= 0 {
+		return Bill{}, ErrNoDistance
+	}
+	b.ValidUntil = Validity(b.GeneratedAt, float64(b.DistanceKm), b.ODC)
+	if b.Expired(b.CapturedAt) {
+		return Bill{}, ErrExpired
+	}
+
+	b.Verification, err = s.Verifier.Verify(b)
+	if err != nil {
+		return Bill{}, err
+	}
+	if b.Verification.Status == VerificationCancelled {
+		return Bill{}, ErrCancelled
+	}
+
+	s.mu.Lock()
+	s.bills[t.ID] = b
+	s.mu.Unlock()
+
+	if _, err := s.Trips.MarkDocument(t.ID, "eWayBill"); err != nil {
+		return Bill{}, err
+	}
+	return b, nil
+}
+
+func (s *Service) Get(tripID string) (Bill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bills[tripID]
+	if !ok {
+		return Bill{}, ErrNotFound
+	}
+	return b, nil
+}
+
+// Extend renews a bill for the distance still to go, counted from now
+func (s *Service) Extend(tripID string, req ExtendRequest) (Bill, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Bill{}, err
+	}
+	if _, err := s.Get(tripID); err != nil {
+		return Bill{}, err
+	}
+
+	if req.RemainingKm == 0 {
+		est, err := s.Routes.Estimate(t)
+		if err != nil {
+			return Bill{}, err
+		}
+		req.RemainingKm = int(math.Ceil(est.RemainingKm))
+	}
+	if req.RemainingKm <= 0 {
+		return Bill{}, ErrNoDistance
+	}
+	if req.Place == "" {
+		if p, ok := s.Routes.Tracks.Latest(t.ID); ok {
+			req.Place = geo.Describe(p.Coord())
+		}
+	}
+
+	// the bill is read, checked and written back under one lock so two
+	// extensions can't both extend the same validity
+	s.mu.Lock()
+	b, ok := s.bills[t.ID]
+	if !ok {
+		s.mu.Unlock()
+		return Bill{}, ErrNotFound
+	}
+	at := now()
+	if !b.Extendable(at) {
+		s.mu.Unlock()
+		return Bill{}, ErrOutsideWindow
+	}
+	ext := Extension{
+		RemainingKm: req.RemainingKm,
+		Place:       req.Place,
+		Reason:      req.Reason,
+		From:        b.ValidUntil,
+		Until:       Validity(at, float64(req.RemainingKm), b.ODC),
+		At:          at,
+	}
+	b.Extensions = append(append([]Extension(nil), b.Extensions...), ext)
+	b.ValidUntil = ext.Until
+	s.bills[t.ID] = b
+	s.mu.Unlock()
+
+	s.Alerts.Push(t.DriverID, notify.Alert{
+		Kind:    "eway_bill",
+		Title:   "E-way bill extended",
+		Message: fmt.Sprintf("E-way bill %s for trip %s is now valid until %s", b.Number, t.ID, validUntil(b)),
+	})
+	return b, nil
+}
+
+// Warn reports whether a moving trip's bill is lapsing, for showing on a screen
+func (s *Service) Warn(t trip.Trip) (Warning, bool) {
+	if t.Status != trip.StatusInTransit {
+		return Warning{}, false
+	}
+	b, err := s.Get(t.ID)
+	if err != nil {
+		return Warning{}, false
+	}
+
+	at := now()
+	w := Warning{ValidUntil: b.ValidUntil, Extendable: b.Extendable(at)}
+	switch {
+	case b.Expired(at):
+		w.Level = WarnExpired
+		w.Message = fmt.Sprintf("E-way bill %s expired at %s. Stop and get it extended before moving on.", b.Number, validUntil(b))
+	case b.ValidUntil.Sub(at) <= s.WarnBefore:
+		w.Level = WarnExpiring
+		w.Message = fmt.Sprintf("E-way bill %s expires at %s. Ask your broker to extend it.", b.Number, validUntil(b))
+	default:
+		est, err := s.Routes.Estimate(t)
+		if err != nil || !est.ETA.After(b.ValidUntil) {
+			return Warning{}, false
+		}
+		w.Level = WarnLapsesEnRoute
+		w.Message = fmt.Sprintf("E-way bill %s expires at %s, before your expected arrival.", b.Number, validUntil(b))
+	}
+	return w, true
+}
+
+// Check is Warn for when the truck reports its position, and also sends each
+// warning as an alert once
+func (s *Service) Check(t trip.Trip) (Warning, bool) {
+	w, ok := s.Warn(t)
+	if !ok {
+		return Warning{}, false
+	}
+
+	// one alert per level for each validity period
+	key := t.ID + "/" + w.Level + "/" + w.ValidUntil.Format(time.RFC3339)
+	s.mu.Lock()
+	first := !s.warned[key]
+	s.warned[key] = true
+	s.mu.Unlock()
+
+	if first {
+		alert := notify.Alert{Kind: "eway_bill", Title: "E-way bill " + t.ID, Message: w.Message}
+		s.Alerts.Push(t.DriverID, alert)
+		if w.Level != WarnLapsesEnRoute {
+			s.Alerts.Push(t.BrokerID, alert)
+		}
+	}
+	return w, true
+}
+
+// ready is the trip guard for leaving with the goods
+func (s *Service) ready(t trip.Trip) error {
+	b, err := s.Get(t.ID)
+	if err != nil {
+		return ErrMissing
+	}
+	if b.Expired(now()) {
+		return ErrExpired
+	}
+	return nil
+}
+
+func validUntil(b Bill) string {
+	return b.ValidUntil.In(IST).Format("02 Jan 3:04 PM")
+}
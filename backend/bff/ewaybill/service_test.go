@@ -0,0 +1,161 @@
+package ewaybill
+
+import (
+	"backend/bff/geo"
+	"backend/bff/notify"
+	"backend/bff/routing"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestService() *Service {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{
+		ID:       "TRK1",
+		DriverID: "DRV1",
+		BrokerID: "BRK1",
+		Status:   trip.StatusInTransit,
+		Pickup:   geo.Coord{Lat: 19.0760, Lng: 72.8777},
+		Drop:     geo.Coord{Lat: 18.5204, Lng: 73.8567},
+	})
+	routes := &routing.Engine{Provider: routing.GreatCircle{}, Tracks: tracking.NewStore(), DefaultSpeedKmh: 40}
+	return NewService(trips, routes, Local{}, notify.NewStore())
+}
+
+func TestExtendConcurrently(t *testing.T) {
+	// a 100 km bill made on the 1st lapses at the end of the 2nd
+	at := time.Date(2025, 3, 2, 20, 0, 0, 0, IST)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	if _, err := s.Capture("TRK1", CaptureRequest{Number: "123456789012", GeneratedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, IST), DistanceKm: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first extension moves the bill out of its window, so of many
+	// sent at once exactly one goes through
+	const n = 20
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Extend("TRK1", ExtendRequest{RemainingKm: 150, Place: "Lonavala"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	extended := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			extended++
+		case !errors.Is(err, ErrOutsideWindow):
+			t.Errorf("error = %v, want %v", err, ErrOutsideWindow)
+		}
+	}
+	if extended != 1 {
+		t.Errorf("%d extensions went through, want 1", extended)
+	}
+
+	b, _ := s.Get("TRK1")
+	if len(b.Extensions) != 1 {
+		t.Fatalf("%d extensions recorded, want 1", len(b.Extensions))
+	}
+	if want := time.Date(2025, 3, 3, 23, 59, 59, 0, IST); !b.ValidUntil.Equal(want) || !b.Extensions[0].Until.Equal(want) {
+		t.Errorf("valid until %s, want %s", b.ValidUntil, want)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	at := time.Date(2025, 3, 2, 12, 0, 0, 0, IST)
+	tests := []struct {
+		name      string
+		req       CaptureRequest
+		cancelled bool
+		wantErr   error
+		wantUntil time.Time
+	}{
+		{name: "generated now for the route distance", req: CaptureRequest{Number: "123456789012"}, wantUntil: time.Date(2025, 3, 3, 23, 59, 59, 0, IST)},
+		{name: "generated earlier", req: CaptureRequest{Number: "123456789012", GeneratedAt: at.Add(-24 * time.Hour), DistanceKm: 350}, wantUntil: time.Date(2025, 3, 3, 23, 59, 59, 0, IST)},
+		{name: "short number", req: CaptureRequest{Number: "12345"}, wantErr: ErrInvalidNumber},
+		{name: "letters", req: CaptureRequest{Number: "12345678901A"}, wantErr: ErrInvalidNumber},
+		{name: "generated in the future", req: CaptureRequest{Number: "123456789012", GeneratedAt: at.Add(time.Hour)}, wantErr: ErrFutureGeneration},
+		{name: "already lapsed", req: CaptureRequest{Number: "123456789012", GeneratedAt: at.Add(-72 * time.Hour), DistanceKm: 100}, wantErr: ErrExpired},
+		{name: "cancelled on the portal", req: CaptureRequest{Number: "123456789012"}, cancelled: true, wantErr: ErrCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return at }
+			defer func() { now = time.Now }()
+
+			s := newTestService()
+			if tt.cancelled {
+				s.Verifier = Local{Cancelled: map[string]bool{tt.req.Number: true}}
+			}
+			b, err := s.Capture("TRK1", tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			tr, _ := s.Trips.Get("TRK1")
+			if tt.wantErr != nil {
+				if _, err := s.Get("TRK1"); !errors.Is(err, ErrNotFound) || tr.Documents["eWayBill"] {
+					t.Errorf("a refused bill was kept")
+				}
+				return
+			}
+			if !b.ValidUntil.Equal(tt.wantUntil) {
+				t.Errorf("valid until %s, want %s", b.ValidUntil, tt.wantUntil)
+			}
+			if !tr.Documents["eWayBill"] {
+				t.Error("trip's e-way bill document not marked")
+			}
+		})
+	}
+}
+
+func TestLeavingNeedsAValidBill(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, IST)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	documents := map[string]bool{"eWayBill": true, "invoice": true, "vehicleRC": true, "driverLicense": true, "insurance": true, "pollutionCert": true}
+	for _, id := range []string{"TRK2", "TRK3", "TRK4"} {
+		s.Trips.Add(trip.Trip{ID: id, DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusLoading, Documents: documents})
+	}
+	for _, id := range []string{"TRK3", "TRK4"} {
+		if _, err := s.Capture(id, CaptureRequest{Number: "123456789012", DistanceKm: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at = time.Date(2025, 3, 3, 0, 0, 0, 0, IST)
+
+	tests := []struct {
+		tripID  string
+		wantErr error
+	}{
+		{tripID: "TRK2", wantErr: ErrMissing},
+		{tripID: "TRK3", wantErr: ErrExpired},
+	}
+	for _, tt := range tests {
+		if _, err := s.Trips.Transition(tt.tripID, trip.StatusInTransit, "DRV1", ""); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.tripID, err, tt.wantErr)
+		}
+	}
+
+	if _, err := s.Extend("TRK4", ExtendRequest{RemainingKm: 50, Place: "Lonavala"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Trips.Transition("TRK4", trip.StatusInTransit, "DRV1", ""); err != nil {
+		t.Errorf("after extending: %v", err)
+	}
+}
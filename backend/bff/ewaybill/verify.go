@@ -0,0 +1,44 @@
+package ewaybill
+
+import "time"
+
+// Verification states reported by a provider
+const (
+	VerificationActive    = "active"
+	VerificationCancelled = "cancelled"
+)
+
+// Verification is what the GST portal said about a bill
+type Verification struct {
+	Provider string    `json:"provider"`
+	Status   string    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// Verifier checks a captured bill against the GST e-way bill system
+type Verifier interface {
+	Name() string
+	Verify(b Bill) (Verification, error)
+}
+
+// Local is an offline Verifier: every well formed number is active unless
+// it has been marked cancelled
+type Local struct {
+	Cancelled map[string]bool
+}
+
+func (Local) Name() string { return "local" }
+
+func (l Local) Verify(b Bill) (Verification, error) {
+	if !validNumber(b.Number) {
+		return Verification{}, ErrInvalidNumber
+	}
+
+	v := Verification{Provider: l.Name(), Status: VerificationActive, At: now()}
+	if l.Cancelled[b.Number] {
+		v.Status = VerificationCancelled
+		v.Message = "Cancelled by the generator"
+	}
+	return v, nil
+}
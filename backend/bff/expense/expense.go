@@ -0,0 +1,175 @@
+package expense
+
+import (
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+type Category string
+
+const (
+	CategoryToll      Category = "toll"
+	CategoryFuel      Category = "fuel"
+	CategoryLoading   Category = "loading"
+	CategoryUnloading Category = "unloading"
+	CategoryChallan   Category = "challan"
+	CategoryParking   Category = "parking"
+	CategoryFood      Category = "food"
+	CategoryRepair    Category = "repair"
+	CategoryOther     Category = "other"
+)
+
+// Categories in the order the apps list them
+var Categories = []Category{
+	CategoryToll,
+	CategoryFuel,
+	CategoryLoading,
+	CategoryUnloading,
+	CategoryChallan,
+	CategoryParking,
+	CategoryFood,
+	CategoryRepair,
+	CategoryOther,
+}
+
+func (c Category) Valid() bool {
+	for _, v := range Categories {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Category) Label() string {
+	switch c {
+	case CategoryToll:
+		return "Toll"
+	case CategoryFuel:
+		return "Fuel"
+	case CategoryLoading:
+		return "Loading Charges"
+	case CategoryUnloading:
+		return "Unloading Charges"
+	case CategoryChallan:
+		return "Police Challan"
+	case CategoryParking:
+		return "Parking"
+	case CategoryFood:
+		return "Food"
+	case CategoryRepair:
+		return "Repair"
+	}
+	return "Other"
+}
+
+func (c Category) Icon() string {
+	switch c {
+	case CategoryToll:
+		return "road-variant"
+	case CategoryFuel:
+		return "gas-station"
+	case CategoryLoading, CategoryUnloading:
+		return "forklift"
+	case CategoryChallan:
+		return "police-badge"
+	case CategoryParking:
+		return "parking"
+	case CategoryFood:
+		return "food"
+	case CategoryRepair:
+		return "wrench"
+	}
+	return "receipt"
+}
+
+// Treatment is who finally bears an expense under the trip terms
+type Treatment string
+
+const (
+	Reimbursable Treatment = "reimbursable" // the broker pays the driver back
+	Deductible   Treatment = "deductible"   // the driver bears it out of trip earnings
+)
+
+// PaidFrom is how the driver paid
+type PaidFrom string
+
+const (
+	PaidCash   PaidFrom = "cash"
+	PaidWallet PaidFrom = "wallet"
+	PaidFASTag PaidFrom = "fastag"
+)
+
+func (p PaidFrom) Valid() bool {
+	return p == PaidCash || p == PaidWallet || p == PaidFASTag
+}
+
+func (p PaidFrom) Label() string {
+	switch p {
+	case PaidWallet:
+		return "Wallet"
+	case PaidFASTag:
+		return "FASTag"
+	}
+	return "Cash"
+}
+
+// ledgerKind is the journal entry the spend itself is recorded as
+func (p PaidFrom) ledgerKind() ledger.Kind {
+	switch p {
+	case PaidWallet:
+		return ledger.KindExpense
+	case PaidFASTag:
+		return ledger.KindToll
+	}
+	return ledger.KindCashExpense
+}
+
+var (
+	ErrInvalidCategory  = errors.New("unknown expense category")
+	ErrInvalidPaidFrom  = errors.New("paid from must be cash, wallet or fastag")
+	ErrFASTagTollOnly   = errors.New("FASTag can only pay tolls")
+	ErrTripClosed       = errors.New("expenses can only be added while the trip is running")
+	ErrNotYourTrip      = errors.New("trip is not assigned to this driver")
+	ErrReceiptTooLarge  = errors.New("receipt photo must be under 5 MB")
+	ErrInsufficientFund = ledger.ErrInsufficientFund
+)
+
+// Expense is money a driver spent during a trip
+type Expense struct {
+	ID        string         `json:"id"`
+	TripID    string         `json:"tripId"`
+	DriverID  string         `json:"driverId"`
+	BrokerID  string         `json:"brokerId"`
+	Category  Category       `json:"category"`
+	Amount    ledger.Amount  `json:"amount"`
+	PaidFrom  PaidFrom       `json:"paidFrom"`
+	Treatment Treatment      `json:"treatment"`
+	Note      string         `json:"note,omitempty"`
+	ReceiptID string         `json:"receiptId,omitempty"` // receipt photo in document storage
+	Location  geo.Coord      `json:"location"`
+	Place     string         `json:"place,omitempty"`
+	Entries   []ledger.Entry `json:"-"`
+	At        time.Time      `json:"at"`
+}
+
+// Summary totals a trip's expenses
+type Summary struct {
+	TripID       string          `json:"tripId"`
+	Count        int             `json:"count"`
+	Total        ledger.Amount   `json:"total"`
+	Reimbursable ledger.Amount   `json:"reimbursable"`
+	Deductible   ledger.Amount   `json:"deductible"`
+	Reimbursed   bool            `json:"reimbursed"` // reimbursements have cleared into the wallet
+	Categories   []CategoryTotal `json:"categories"`
+}
+
+type CategoryTotal struct {
+	Category Category      `json:"category"`
+	Count    int           `json:"count"`
+	Amount   ledger.Amount `json:"amount"`
+}
@@ -0,0 +1,246 @@
+package expense
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const maxReceiptBytes = 5 << 20
+
+type AddRequest struct {
+	Category    Category
+	Amount      ledger.Amount
+	PaidFrom    PaidFrom // empty means cash
+	Note        string
+	Receipt     []byte // receipt photo, optional
+	ReceiptType string
+	Location    geo.Coord // zero means the truck's last position
+	At          time.Time
+}
+
+// Service records trip expenses and posts them to the ledger
+type Service struct {
+	Trips  *trip.Store
+	Ledger *ledger.Ledger
+	Docs   docstore.Store
+	Tracks *tracking.Store
+
+	mu       sync.Mutex
+	seq      int
+	expenses []Expense
+}
+
+func NewService(trips *trip.Store, l *ledger.Ledger, docs docstore.Store, tracks *tracking.Store) *Service {
+	s := &Service{Trips: trips, Ledger: l, Docs: docs, Tracks: tracks}
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		if e.To != trip.StatusSettled && e.To != trip.StatusCancelled {
+			return
+		}
+		// the trip has already moved, so a failure can only be reported; the
+		// trip stays unreimbursed and shows as owed in its summary
+		if err := s.reimburse(t); err != nil {
+			log.Printf("[expense] reimbursing trip %s: %v", t.ID, err)
+		}
+	})
+	return s
+}
+
+// Default service used by the BFF handlers
+var Default = NewService(trip.Default, ledger.Default, docstore.Default, tracking.Default)
+
+// Add records an expense against one of the driver's running trips
+func (s *Service) Add(driverID, tripID string, req AddRequest) (Expense, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Expense{}, err
+	}
+	if t.DriverID != driverID {
+		return Expense{}, ErrNotYourTrip
+	}
+	switch t.Status {
+	case trip.StatusAssigned, trip.StatusSettled, trip.StatusCancelled:
+		return Expense{}, ErrTripClosed
+	}
+
+	if req.PaidFrom == "" {
+		req.PaidFrom = PaidCash
+	}
+	switch {
+	case !req.Category.Valid():
+		return Expense{}, ErrInvalidCategory
+	case !req.PaidFrom.Valid():
+		return Expense{}, ErrInvalidPaidFrom
+	case req.PaidFrom == PaidFASTag && req.Category != CategoryToll:
+		return Expense{}, ErrFASTagTollOnly
+	case req.Amount <= 0:
+		return Expense{}, ledger.ErrInvalidAmount
+	case len(req.Receipt) > maxReceiptBytes:
+		return Expense{}, ErrReceiptTooLarge
+	}
+	x := Expense{
+		TripID:    t.ID,
+		DriverID:  t.DriverID,
+		BrokerID:  t.BrokerID,
+		Category:  req.Category,
+		Amount:    req.Amount,
+		PaidFrom:  req.PaidFrom,
+		Treatment: treatment(t, req.Category),
+		Note:      req.Note,
+		Location:  req.Location,
+		At:        req.At,
+	}
+	if x.At.IsZero() {
+		x.At = now()
+	}
+	if !x.Location.Valid() || x.Location.IsZero() {
+		if p, ok := s.Tracks.Latest(t.ID); ok {
+			x.Location = p.Coord()
+		}
+	}
+	if !x.Location.IsZero() {
+		x.Place = geo.Describe(x.Location)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// checked under the lock so two expenses can't both spend the same
+	// balance; the ledger checks again when it posts the spend
+	if req.PaidFrom != PaidCash && s.Ledger.Wallet(driverID, now()).Available < req.Amount {
+		return Expense{}, ErrInsufficientFund
+	}
+
+	s.seq++
+	x.ID = fmt.Sprintf("EXP%06d", s.seq)
+
+	if len(req.Receipt) > 0 {
+		contentType := req.ReceiptType
+		if contentType == "" {
+			contentType = "image/jpeg"
+		}
+		doc, err := s.Docs.Put(docstore.Document{
+			Kind:        "expense_receipt",
+			Ref:         x.ID,
+			Name:        "receipt-" + x.ID,
+			ContentType: contentType,
+			Owners:      []string{x.DriverID, x.BrokerID},
+		}, req.Receipt)
+		if err != nil {
+			return Expense{}, err
+		}
+		x.ReceiptID = doc.ID
+	}
+
+	memo := x.Category.Label()
+	if x.Place != "" {
+		memo += " at " + x.Place
+	}
+	// the spend and what the broker owes for it are posted together, so a
+	// refused reimbursement leaves no spend behind
+	legs := []ledger.Leg{{Kind: x.PaidFrom.ledgerKind(), Posting: ledger.Posting{
+		DriverID:     x.DriverID,
+		TripID:       x.TripID,
+		Ref:          x.ID,
+		Amount:       x.Amount,
+		Counterparty: x.Category.Label(),
+		Mode:         x.PaidFrom.Label(),
+		Memo:         memo,
+		At:           x.At,
+	}}}
+	if x.Treatment == Reimbursable {
+		legs = append(legs, ledger.Leg{Kind: ledger.KindReimbursement, Posting: ledger.Posting{
+			DriverID:     x.DriverID,
+			BrokerID:     x.BrokerID,
+			TripID:       x.TripID,
+			Ref:          x.ID,
+			Amount:       x.Amount,
+			Counterparty: t.Details["brokerName"],
+			Mode:         "Wallet",
+			Memo:         memo,
+			At:           x.At,
+		}})
+	}
+	entries, err := s.Ledger.RecordAll(legs...)
+	if err != nil {
+		return Expense{}, err
+	}
+	x.Entries = entries
+
+	s.expenses = append(s.expenses, x)
+	return x, nil
+}
+
+// ForTrip returns a trip's expenses, newest first
+func (s *Service) ForTrip(tripID string) []Expense {
+	return s.list(func(x Expense) bool { return x.TripID == tripID })
+}
+
+// ForDriver returns a driver's expenses across trips, newest first
+func (s *Service) ForDriver(driverID string) []Expense {
+	return s.list(func(x Expense) bool { return x.DriverID == driverID })
+}
+
+func (s *Service) Summary(tripID string) Summary {
+	sum := Summary{TripID: tripID}
+	totals := map[Category]*CategoryTotal{}
+	for _, x := range s.ForTrip(tripID) {
+		sum.Count++
+		sum.Total += x.Amount
+		if x.Treatment == Reimbursable {
+			sum.Reimbursable += x.Amount
+		} else {
+			sum.Deductible += x.Amount
+		}
+		if totals[x.Category] == nil {
+			totals[x.Category] = &CategoryTotal{Category: x.Category}
+		}
+		totals[x.Category].Count++
+		totals[x.Category].Amount += x.Amount
+	}
+	for _, c := range Categories {
+		if ct := totals[c]; ct != nil {
+			sum.Categories = append(sum.Categories, *ct)
+		}
+	}
+	sum.Reimbursed = len(s.Ledger.Entries(func(e ledger.Entry) bool {
+		return e.Kind == ledger.KindReimbursed && e.TripID == tripID
+	})) > 0
+	return sum
+}
+
+// reimburse clears what the broker owes for a closed trip into the driver's wallet
+func (s *Service) reimburse(t trip.Trip) error {
+	sum := s.Summary(t.ID)
+	if sum.Reimbursable == 0 || sum.Reimbursed {
+		return nil
+	}
+	_, err := s.Ledger.Record(ledger.KindReimbursed, ledger.Posting{
+		DriverID:     t.DriverID,
+		TripID:       t.ID,
+		Amount:       sum.Reimbursable,
+		Counterparty: t.Details["brokerName"],
+		Mode:         "Wallet",
+		Memo:         "Trip expenses reimbursed",
+	})
+	return err
+}
+
+func (s *Service) list(match func(Expense) bool) []Expense {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Expense
+	for i := len(s.expenses) - 1; i >= 0; i-- {
+		if match(s.expenses[i]) {
+			out = append(out, s.expenses[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package expense
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/ledger"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestService(details map[string]string) *Service {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusLoading, Details: details})
+	s := NewService(trips, ledger.New(), docstore.NewMemory(), tracking.NewStore())
+	s.Ledger.Record(ledger.KindAdvance, ledger.Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: ledger.Rupees(1000)})
+	return s
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		req           AddRequest
+		wantErr       error
+		wantTreatment Treatment
+		wantAvailable ledger.Amount
+		wantPending   ledger.Amount
+	}{
+		{
+			name:          "toll from the wallet is paid back later",
+			req:           AddRequest{Category: CategoryToll, Amount: ledger.Rupees(400), PaidFrom: PaidWallet},
+			wantTreatment: Reimbursable,
+			wantAvailable: ledger.Rupees(600),
+			wantPending:   ledger.Rupees(400),
+		},
+		{
+			name:          "fuel in cash leaves the wallet alone",
+			req:           AddRequest{Category: CategoryFuel, Amount: ledger.Rupees(3000)},
+			wantTreatment: Deductible,
+			wantAvailable: ledger.Rupees(1000),
+		},
+		{
+			name:          "FASTag toll",
+			req:           AddRequest{Category: CategoryToll, Amount: ledger.Rupees(250), PaidFrom: PaidFASTag},
+			wantTreatment: Reimbursable,
+			wantAvailable: ledger.Rupees(750),
+			wantPending:   ledger.Rupees(250),
+		},
+		{name: "FASTag only pays tolls", req: AddRequest{Category: CategoryFood, Amount: ledger.Rupees(100), PaidFrom: PaidFASTag}, wantErr: ErrFASTagTollOnly, wantAvailable: ledger.Rupees(1000)},
+		{name: "more than the wallet", req: AddRequest{Category: CategoryFood, Amount: ledger.Rupees(1001), PaidFrom: PaidWallet}, wantErr: ErrInsufficientFund, wantAvailable: ledger.Rupees(1000)},
+		{name: "unknown category", req: AddRequest{Category: "gift", Amount: ledger.Rupees(100)}, wantErr: ErrInvalidCategory, wantAvailable: ledger.Rupees(1000)},
+		{name: "unknown payment", req: AddRequest{Category: CategoryFood, Amount: ledger.Rupees(100), PaidFrom: "card"}, wantErr: ErrInvalidPaidFrom, wantAvailable: ledger.Rupees(1000)},
+		{name: "no amount", req: AddRequest{Category: CategoryFood}, wantErr: ledger.ErrInvalidAmount, wantAvailable: ledger.Rupees(1000)},
+		{name: "receipt too large", req: AddRequest{Category: CategoryFood, Amount: ledger.Rupees(100), Receipt: make([]byte, maxReceiptBytes+1)}, wantErr: ErrReceiptTooLarge, wantAvailable: ledger.Rupees(1000)},
+		{name: "someone else's trip", driver: "DRV2", req: AddRequest{Category: CategoryFood, Amount: ledger.Rupees(100)}, wantErr: ErrNotYourTrip, wantAvailable: ledger.Rupees(1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(nil)
+			driver := tt.driver
+			if driver == "" {
+				driver = "DRV1"
+			}
+			x, err := s.Add(driver, "TRK1", tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && x.Treatment != tt.wantTreatment {
+				t.Errorf("treatment = %s, want %s", x.Treatment, tt.wantTreatment)
+			}
+			if err != nil && len(s.ForTrip("TRK1")) != 0 {
+				t.Error("a refused expense was kept")
+			}
+			w := s.Ledger.Wallet("DRV1", time.Time{})
+			if w.Available != tt.wantAvailable || w.Pending != tt.wantPending {
+				t.Errorf("wallet = %s available, %s pending; want %s, %s", w.Available, w.Pending, tt.wantAvailable, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestTreatmentFollowsTripTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		terms    map[string]string
+		category Category
+		want     Treatment
+	}{
+		{name: "toll by default", category: CategoryToll, want: Reimbursable},
+		{name: "food by default", category: CategoryFood, want: Deductible},
+		{name: "trip pays for food", terms: map[string]string{"reimbursableExpenses": "toll, food"}, category: CategoryFood, want: Reimbursable},
+		{name: "trip does not pay tolls", terms: map[string]string{"reimbursableExpenses": "loading"}, category: CategoryToll, want: Deductible},
+		{name: "trip pays for nothing", terms: map[string]string{"reimbursableExpenses": ""}, category: CategoryToll, want: Deductible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := treatment(trip.Trip{Details: tt.terms}, tt.category); got != tt.want {
+				t.Errorf("treatment = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReimbursedWhenTripCloses(t *testing.T) {
+	s := newTestService(nil)
+	for _, req := range []AddRequest{
+		{Category: CategoryToll, Amount: ledger.Rupees(400), PaidFrom: PaidWallet},
+		{Category: CategoryLoading, Amount: ledger.Rupees(600)},
+		{Category: CategoryFood, Amount: ledger.Rupees(200), PaidFrom: PaidWallet},
+	} {
+		if _, err := s.Add("DRV1", "TRK1", req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sum := s.Summary("TRK1")
+	if sum.Total != ledger.Rupees(1200) || sum.Reimbursable != ledger.Rupees(1000) || sum.Deductible != ledger.Rupees(200) || sum.Reimbursed {
+		t.Fatalf("summary = %+v", sum)
+	}
+
+	if _, err := s.Trips.Transition("TRK1", trip.StatusCancelled, "BRK1", ""); err != nil {
+		t.Fatal(err)
+	}
+	// a second close must not pay out again
+	tr, _ := s.Trips.Get("TRK1")
+	if err := s.reimburse(tr); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Summary("TRK1").Reimbursed {
+		t.Error("summary not marked reimbursed")
+	}
+	// ₹1000 advance, less ₹600 spent from the wallet, plus ₹1000 paid back
+	w := s.Ledger.Wallet("DRV1", time.Time{})
+	if w.Available != ledger.Rupees(1400) || w.Pending != 0 {
+		t.Errorf("wallet = %s available, %s pending; want ₹1400, nothing pending", w.Available, w.Pending)
+	}
+	if _, err := s.Add("DRV1", "TRK1", AddRequest{Category: CategoryToll, Amount: ledger.Rupees(100)}); !errors.Is(err, ErrTripClosed) {
+		t.Errorf("error = %v, want %v", err, ErrTripClosed)
+	}
+}
@@ -0,0 +1,34 @@
+package expense
+
+import (
+	"backend/bff/trip"
+	"strings"
+)
+
+// Expenses brokers usually pay back when a trip does not say otherwise;
+// fuel, food, repairs and challans come out of the driver's freight
+var defaultReimbursable = []Category{
+	CategoryToll,
+	CategoryLoading,
+	CategoryUnloading,
+	CategoryParking,
+}
+
+// treatment reads the trip terms; a trip lists what it reimburses in
+// Details["reimbursableExpenses"], e.g. "toll,loading"
+func treatment(t trip.Trip, c Category) Treatment {
+	reimbursable := defaultReimbursable
+	if terms, ok := t.Details["reimbursableExpenses"]; ok {
+		reimbursable = nil
+		for _, s := range strings.Split(terms, ",") {
+			reimbursable = append(reimbursable, Category(strings.TrimSpace(s)))
+		}
+	}
+
+	for _, r := range reimbursable {
+		if r == c {
+			return Reimbursable
+		}
+	}
+	return Deductible
+}
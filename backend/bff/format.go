@@ -0,0 +1,100 @@
+package bff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeAgo renders a timestamp the way the apps show activity times ("5 min ago")
+func TimeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case t.IsZero():
+		return "never"
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	case d < 2*time.Hour:
+		return "1 hour ago"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(d.Hours()))
+	case d < 48*time.Hour:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	}
+}
+
+// Km renders a distance with Indian digit grouping ("1,412 km")
+func Km(km float64) string {
+	return GroupIndian(int64(km+0.5)) + " km"
+}
+
+// GroupIndian groups digits in lakhs and crores ("1,85,500")
+func GroupIndian(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := fmt.Sprint(n)
+	if len(s) <= 3 {
+		return sign + s
+	}
+
+	head, tail := s[:len(s)-3], s[len(s)-3:]
+	var parts []string
+	for len(head) > 2 {
+		parts = append([]string{head[len(head)-2:]}, parts...)
+		head = head[:len(head)-2]
+	}
+	parts = append([]string{head}, parts...)
+	return sign + strings.Join(parts, ",") + "," + tail
+}
+
+// Hours renders a duration the way trip cards show it ("12 hrs 30 min")
+func Hours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%d min", m)
+	case m == 0:
+		return fmt.Sprintf("%d hrs", h)
+	default:
+		return fmt.Sprintf("%d hrs %d min", h, m)
+	}
+}
+
+// DayTime renders a time relative to today ("Tomorrow, 12:00 PM")
+func DayTime(t time.Time) string {
+	today := time.Now().In(t.Location())
+	switch {
+	case sameDay(t, today):
+		return "Today, " + t.Format("3:04 PM")
+	case sameDay(t, today.AddDate(0, 0, 1)):
+		return "Tomorrow, " + t.Format("3:04 PM")
+	case sameDay(t, today.AddDate(0, 0, -1)):
+		return "Yesterday, " + t.Format("3:04 PM")
+	default:
+		return t.Format("2 Jan, 3:04 PM")
+	}
+}
+
+// FinancialYear is the April to March year a date falls in, as invoice and
+// LR numbers carry it ("2627" for 2026-27)
+func FinancialYear(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%02d%02d", start%100, (start+1)%100)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
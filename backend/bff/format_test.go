@@ -0,0 +1,24 @@
+package bff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFinancialYear(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{date: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), want: "2627"},
+		{date: time.Date(2027, 3, 31, 23, 59, 0, 0, time.UTC), want: "2627"},
+		{date: time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC), want: "2627"},
+		{date: time.Date(2099, 6, 1, 0, 0, 0, 0, time.UTC), want: "9900"},
+		{date: time.Date(2100, 2, 1, 0, 0, 0, 0, time.UTC), want: "9900"},
+	}
+	for _, tt := range tests {
+		if got := FinancialYear(tt.date); got != tt.want {
+			t.Errorf("FinancialYear(%s) = %s, want %s", tt.date.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
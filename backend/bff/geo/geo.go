@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+const earthRadiusKm = 6371.0
+
+type Coord struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func (c Coord) IsZero() bool {
+	return c.Lat == 0 && c.Lng == 0
+}
+
+func (c Coord) Valid() bool {
+	return c.Lat >= -90 && c.Lat <= 90 && c.Lng >= -180 && c.Lng <= 180 && !c.IsZero()
+}
+
+func (c Coord) String() string {
+	return fmt.Sprintf("%.4f, %.4f", c.Lat, c.Lng)
+}
+
+// Haversine returns the great-circle distance between two coordinates in km
+func Haversine(a, b Coord) float64 {
+	lat1 := radians(a.Lat)
+	lat2 := radians(b.Lat)
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Lerp returns the coordinate a fraction f of the way from a to b
+func Lerp(a, b Coord, f float64) Coord {
+	return Coord{
+		Lat: a.Lat + (b.Lat-a.Lat)*f,
+		Lng: a.Lng + (b.Lng-a.Lng)*f,
+	}
+}
@@ -0,0 +1,56 @@
+package geo
+
+// Place is a known city used to describe a position without a geocoding service
+type Place struct {
+	Name  string
+	State string
+	Coord Coord
+}
+
+var places = []Place{
+	{"Mumbai", "MH", Coord{19.0760, 72.8777}},
+	{"Pune", "MH", Coord{18.5204, 73.8567}},
+	{"Nashik", "MH", Coord{19.9975, 73.7898}},
+	{"Nagpur", "MH", Coord{21.1458, 79.0882}},
+	{"Surat", "GJ", Coord{21.1702, 72.8311}},
+	{"Vadodara", "GJ", Coord{22.3072, 73.1812}},
+	{"Ahmedabad", "GJ", Coord{23.0225, 72.5714}},
+	{"Udaipur", "RJ", Coord{24.5854, 73.7125}},
+	{"Ajmer", "RJ", Coord{26.4499, 74.6399}},
+	{"Jaipur", "RJ", Coord{26.9124, 75.7873}},
+	{"Indore", "MP", Coord{22.7196, 75.8577}},
+	{"Bhopal", "MP", Coord{23.2599, 77.4126}},
+	{"Gurugram", "HR", Coord{28.4595, 77.0266}},
+	{"Delhi", "DL", Coord{28.7041, 77.1025}},
+	{"Lucknow", "UP", Coord{26.8467, 80.9462}},
+	{"Kanpur", "UP", Coord{26.4499, 80.3319}},
+	{"Kolkata", "WB", Coord{22.5726, 88.3639}},
+	{"Bhubaneswar", "OD", Coord{20.2961, 85.8245}},
+	{"Visakhapatnam", "AP", Coord{17.6868, 83.2185}},
+	{"Hyderabad", "TS", Coord{17.3850, 78.4867}},
+	{"Solapur", "MH", Coord{17.6599, 75.9064}},
+	{"Chennai", "TN", Coord{13.0827, 80.2707}},
+	{"Vellore", "TN", Coord{12.9165, 79.1325}},
+	{"Bangalore", "KA", Coord{12.9716, 77.5946}},
+}
+
+// Describe names the nearest known city, falling back to raw coordinates when nothing is close
+func Describe(c Coord) string {
+	const nearKm = 60.0
+
+	best := -1
+	bestKm := nearKm
+	for i, p := range places {
+		if d := Haversine(c, p.Coord); d < bestKm {
+			best, bestKm = i, d
+		}
+	}
+	if best < 0 {
+		return c.String()
+	}
+	p := places[best]
+	if bestKm < 10 {
+		return p.Name + ", " + p.State
+	}
+	return "Near " + p.Name + ", " + p.State
+}
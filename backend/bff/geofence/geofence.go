@@ -0,0 +1,140 @@
+package geofence
+
+import (
+	"backend/bff/geo"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"sync"
+	"time"
+)
+
+type Site string
+
+const (
+	SitePickup Site = "pickup"
+	SiteDrop   Site = "drop"
+)
+
+type Kind string
+
+const (
+	KindArrived  Kind = "arrived"
+	KindDeparted Kind = "departed"
+)
+
+// Event is a crossing of a trip's pickup or drop geofence
+type Event struct {
+	TripID string    `json:"tripId"`
+	Site   Site      `json:"site"`
+	Kind   Kind      `json:"kind"`
+	At     time.Time `json:"at"`
+
+	// Status change applied or proposed in reaction to the crossing
+	Applied  trip.Status `json:"applied,omitempty"`
+	Proposed trip.Status `json:"proposed,omitempty"`
+}
+
+// Detention is the time a truck spent inside a site geofence
+type Detention struct {
+	Site  Site      `json:"site"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+func (d Detention) Duration(now time.Time) time.Duration {
+	if d.End.IsZero() {
+		return now.Sub(d.Start)
+	}
+	return d.End.Sub(d.Start)
+}
+
+type Monitor struct {
+	Trips *trip.Store
+
+	// Radius of the site geofence in metres
+	RadiusMeters float64
+	// Departure only counts beyond RadiusMeters*ExitFactor so GPS jitter at the edge does not flap
+	ExitFactor float64
+
+	mu         sync.Mutex
+	inside     map[string]map[Site]bool
+	detentions map[string][]Detention
+}
+
+func NewMonitor(trips *trip.Store) *Monitor {
+	return &Monitor{
+		Trips:        trips,
+		RadiusMeters: 500,
+		ExitFactor:   1.5,
+		inside:       map[string]map[Site]bool{},
+		detentions:   map[string][]Detention{},
+	}
+}
+
+// Default monitor used by the BFF handlers
+var Default = NewMonitor(trip.Default)
+
+// Observe checks accepted points against the trip's geofences and reacts to every crossing
+func (m *Monitor) Observe(t trip.Trip, points []tracking.Point) []Event {
+	var events []Event
+	for _, p := range points {
+		for _, site := range []Site{SitePickup, SiteDrop} {
+			if kind, ok := m.cross(t.ID, site, siteCoord(t, site), p); ok {
+				e := m.react(Event{TripID: t.ID, Site: site, Kind: kind, At: p.Timestamp})
+				events = append(events, e)
+				if latest, err := m.Trips.Get(t.ID); err == nil {
+					t = latest
+				}
+			}
+		}
+	}
+	return events
+}
+
+func (m *Monitor) Detentions(tripID string) []Detention {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Detention(nil), m.detentions[tripID]...)
+}
+
+// cross updates the inside/outside state for a site and reports a crossing
+func (m *Monitor) cross(tripID string, site Site, center geo.Coord, p tracking.Point) (Kind, bool) {
+	if center.IsZero() {
+		return "", false
+	}
+	meters := geo.Haversine(center, p.Coord()) * 1000
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.inside[tripID]
+	if !ok {
+		state = map[Site]bool{}
+		m.inside[tripID] = state
+	}
+
+	switch {
+	case !state[site] && meters <= m.RadiusMeters:
+		state[site] = true
+		m.detentions[tripID] = append(m.detentions[tripID], Detention{Site: site, Start: p.Timestamp})
+		return KindArrived, true
+	case state[site] && meters > m.RadiusMeters*m.ExitFactor:
+		state[site] = false
+		list := m.detentions[tripID]
+		for i := len(list) - 1; i >= 0; i-- {
+			if list[i].Site == site && list[i].End.IsZero() {
+				list[i].End = p.Timestamp
+				break
+			}
+		}
+		return KindDeparted, true
+	}
+	return "", false
+}
+
+func siteCoord(t trip.Trip, site Site) geo.Coord {
+	if site == SitePickup {
+		return t.Pickup
+	}
+	return t.Drop
+}
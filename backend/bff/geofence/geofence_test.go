@@ -0,0 +1,125 @@
+package geofence
+
+import (
+	"backend/bff/geo"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"testing"
+	"time"
+)
+
+var (
+	pickup = geo.Coord{Lat: 19, Lng: 73}
+	drop   = geo.Coord{Lat: 20, Lng: 73}
+	start  = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+)
+
+// north is a point the given number of metres due north of c
+func north(c geo.Coord, meters float64, after time.Duration) tracking.Point {
+	return tracking.Point{Lat: c.Lat + meters/111195, Lng: c.Lng, Timestamp: start.Add(after)}
+}
+
+func TestObserve(t *testing.T) {
+	documents := map[string]bool{"eWayBill": true, "invoice": true, "vehicleRC": true, "driverLicense": true, "insurance": true, "pollutionCert": true}
+	tests := []struct {
+		name       string
+		status     trip.Status
+		documents  map[string]bool
+		points     []tracking.Point
+		want       []Event
+		wantStatus trip.Status
+	}{
+		{
+			name:       "arriving at pickup marks it reached",
+			status:     trip.StatusAccepted,
+			points:     []tracking.Point{north(pickup, 2000, 0), north(pickup, 100, 10*time.Minute)},
+			want:       []Event{{Site: SitePickup, Kind: KindArrived, Applied: trip.StatusReachedPickup}},
+			wantStatus: trip.StatusReachedPickup,
+		},
+		{
+			name:       "jitter at the edge is not a departure",
+			status:     trip.StatusLoading,
+			documents:  documents,
+			points:     []tracking.Point{north(pickup, 100, 0), north(pickup, 600, time.Minute), north(pickup, 400, 2*time.Minute), north(pickup, 700, 3*time.Minute)},
+			want:       []Event{{Site: SitePickup, Kind: KindArrived}},
+			wantStatus: trip.StatusLoading,
+		},
+		{
+			name:      "leaving a loaded pickup starts the transit",
+			status:    trip.StatusLoading,
+			documents: documents,
+			points:    []tracking.Point{north(pickup, 100, 0), north(pickup, 1000, 2*time.Hour)},
+			want: []Event{
+				{Site: SitePickup, Kind: KindArrived},
+				{Site: SitePickup, Kind: KindDeparted, Applied: trip.StatusInTransit},
+			},
+			wantStatus: trip.StatusInTransit,
+		},
+		{
+			name:   "leaving without documents only proposes the transit",
+			status: trip.StatusLoading,
+			points: []tracking.Point{north(pickup, 100, 0), north(pickup, 1000, 2*time.Hour)},
+			want: []Event{
+				{Site: SitePickup, Kind: KindArrived},
+				{Site: SitePickup, Kind: KindDeparted, Proposed: trip.StatusInTransit},
+			},
+			wantStatus: trip.StatusLoading,
+		},
+		{
+			name:   "delivery is proposed, never applied",
+			status: trip.StatusInTransit,
+			points: []tracking.Point{north(drop, -5000, 0), north(drop, 0, time.Hour), north(drop, -1000, 3*time.Hour)},
+			want: []Event{
+				{Site: SiteDrop, Kind: KindArrived, Applied: trip.StatusReachedDrop},
+				{Site: SiteDrop, Kind: KindDeparted, Proposed: trip.StatusDelivered},
+			},
+			wantStatus: trip.StatusReachedDrop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trips := trip.NewStore()
+			trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", Status: tt.status, Pickup: pickup, Drop: drop, Documents: tt.documents})
+			m := NewMonitor(trips)
+			tr, _ := trips.Get("TRK1")
+
+			got := m.Observe(tr, tt.points)
+			if len(got) != len(tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			for i, e := range got {
+				w := tt.want[i]
+				if e.Site != w.Site || e.Kind != w.Kind || e.Applied != w.Applied || e.Proposed != w.Proposed {
+					t.Errorf("event %d = %+v, want %+v", i, e, w)
+				}
+			}
+			if tr, _ := trips.Get("TRK1"); tr.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", tr.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDetention(t *testing.T) {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", Status: trip.StatusReachedPickup, Pickup: pickup, Drop: drop})
+	m := NewMonitor(trips)
+	tr, _ := trips.Get("TRK1")
+
+	m.Observe(tr, []tracking.Point{north(pickup, 0, 0), north(pickup, 2000, 3*time.Hour)})
+
+	ds := m.Detentions("TRK1")
+	if len(ds) != 1 || ds[0].Site != SitePickup || ds[0].Duration(start.Add(5*time.Hour)) != 3*time.Hour {
+		t.Fatalf("detentions = %+v, want 3h at pickup", ds)
+	}
+	tr, _ = trips.Get("TRK1")
+	var left string
+	for _, e := range tr.Timeline {
+		if e.Kind == "geofence" {
+			left = e.Label
+		}
+	}
+	if left != "Left pickup after 3 hrs detention" {
+		t.Errorf("timeline = %q", left)
+	}
+}
@@ -0,0 +1,68 @@
+package geofence
+
+import (
+	"backend/bff"
+	"backend/bff/trip"
+	"fmt"
+)
+
+// rule maps a crossing seen in a given status to the status it leads to
+type rule struct {
+	site  Site
+	kind  Kind
+	from  trip.Status
+	to    trip.Status
+	apply bool // false only proposes, the driver confirms in the app
+}
+
+var rules = []rule{
+	{SitePickup, KindArrived, trip.StatusAccepted, trip.StatusReachedPickup, true},
+	{SitePickup, KindDeparted, trip.StatusLoading, trip.StatusInTransit, true},
+	{SiteDrop, KindArrived, trip.StatusInTransit, trip.StatusReachedDrop, true},
+	{SiteDrop, KindDeparted, trip.StatusReachedDrop, trip.StatusDelivered, false},
+}
+
+func (m *Monitor) react(e Event) Event {
+	t, err := m.Trips.Get(e.TripID)
+	if err != nil {
+		return e
+	}
+	m.Trips.AddTimeline(t.ID, trip.TimelineEntry{Kind: "geofence", Label: m.label(e), At: e.At})
+
+	for _, r := range rules {
+		if r.site != e.Site || r.kind != e.Kind || r.from != t.Status {
+			continue
+		}
+		if r.apply {
+			if _, err := m.Trips.Transition(t.ID, r.to, trip.ActorSystem, fmt.Sprintf("%s %s geofence", e.Kind, e.Site)); err == nil {
+				e.Applied = r.to
+				return e
+			}
+		}
+		e.Proposed = r.to
+		m.Trips.AddTimeline(t.ID, trip.TimelineEntry{
+			Kind:  "proposal",
+			Label: "Confirm " + r.to.Label(),
+			At:    e.At,
+		})
+		return e
+	}
+	return e
+}
+
+func (m *Monitor) label(e Event) string {
+	site := "pickup"
+	if e.Site == SiteDrop {
+		site = "drop"
+	}
+	if e.Kind == KindArrived {
+		return "Arrived at " + site
+	}
+
+	for _, d := range m.Detentions(e.TripID) {
+		if d.Site == e.Site && d.End.Equal(e.At) {
+			return fmt.Sprintf("Left %s after %s detention", site, bff.Hours(d.Duration(e.At)))
+		}
+	}
+	return "Left " + site
+}
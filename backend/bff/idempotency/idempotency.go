@@ -0,0 +1,115 @@
+// Package idempotency makes retried POST requests safe. The app sends an
+// Idempotency-Key with each action; the first response for a user and key is
+// kept for a retention window and replayed to any retry instead of running
+// the action again.
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+// DefaultRetention is how long a response is replayed for
+const DefaultRetention = 24 * time.Hour
+
+// MaxKeyLength bounds the keys clients may send
+const MaxKeyLength = 255
+
+var (
+	ErrInvalidKey = errors.New("Idempotency-Key must be 1 to 255 characters")
+	ErrInProgress = errors.New("a request with this Idempotency-Key is still being processed")
+	ErrMismatch   = errors.New("Idempotency-Key was already used with a different request")
+)
+
+// Response is what the first request returned
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type record struct {
+	fingerprint string
+	done        bool
+	response    Response
+	expires     time.Time
+}
+
+// Store remembers requests by user and key
+type Store struct {
+	Retention time.Duration
+
+	mu      sync.Mutex
+	records map[string]*record
+}
+
+func NewStore(retention time.Duration) *Store {
+	return &Store{Retention: retention, records: map[string]*record{}}
+}
+
+// Default store used by the middleware
+var Default = NewStore(DefaultRetention)
+
+// Begin claims the key for a request. It returns the stored response when
+// the same request already completed, nil when this request should run, or
+// an error when the key is busy or was used for something else.
+func (s *Store) Begin(userID, key, fingerprint string) (*Response, error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	id := userID + "\x00" + key
+	r, ok := s.records[id]
+	if !ok {
+		s.records[id] = &record{fingerprint: fingerprint, expires: now().Add(s.Retention)}
+		return nil, nil
+	}
+	if r.fingerprint != fingerprint {
+		return nil, ErrMismatch
+	}
+	if !r.done {
+		return nil, ErrInProgress
+	}
+	res := r.response
+	res.Header = r.response.Header.Clone()
+	res.Body = append([]byte(nil), r.response.Body...)
+	return &res, nil
+}
+
+// Finish stores the response so retries get it back
+func (s *Store) Finish(userID, key string, res Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[userID+"\x00"+key]; ok {
+		r.done, r.response = true, res
+		r.expires = now().Add(s.Retention)
+	}
+}
+
+// Abandon releases the key so a retry runs the request again, as after a
+// server error
+func (s *Store) Abandon(userID, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, userID+"\x00"+key)
+}
+
+// sweep drops records past their retention window
+func (s *Store) sweep() {
+	t := now()
+	for id, r := range s.records {
+		if !t.Before(r.expires) {
+			delete(s.records, id)
+		}
+	}
+}
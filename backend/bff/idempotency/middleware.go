@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"backend/bff"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+const (
+	// KeyHeader is the header clients send the key in
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader marks a response served from the store
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// Middleware applies to POST requests that carry an Idempotency-Key; other
+// requests pass straight through. Keys are scoped to the user, so two users
+// sending the same key never see each other's responses.
+func Middleware(s *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		userID := bff.Caller(c)
+		stored, err := s.Begin(userID, key, fingerprint(c.Request.Method, c.Request.URL.Path, body))
+		if err != nil {
+			c.AbortWithStatusJSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if stored != nil {
+			for k, v := range stored.Header {
+				c.Writer.Header()[k] = v
+			}
+			c.Header(ReplayedHeader, "true")
+			c.Data(stored.Status, stored.Header.Get("Content-Type"), stored.Body)
+			c.Abort()
+			return
+		}
+
+		// a handler that panics never finishes, so the key is released on
+		// the way out unless the response was stored
+		finished := false
+		defer func() {
+			if !finished {
+				s.Abandon(userID, key)
+			}
+		}()
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() >= 500 {
+			return
+		}
+		s.Finish(userID, key, Response{
+			Status: w.Status(),
+			Header: w.Header().Clone(),
+			Body:   w.body.Bytes(),
+		})
+		finished = true
+	}
+}
+
+// fingerprint identifies the request a key was first used for. JSON bodies
+// are re-encoded so whitespace and field order don't count as a change.
+func fingerprint(method, path string, body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		body, _ = json.Marshal(v)
+	}
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInProgress):
+		return 409
+	case errors.Is(err, ErrMismatch):
+		return 422
+	}
+	return 400
+}
+
+// recorder keeps a copy of the response body to replay
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
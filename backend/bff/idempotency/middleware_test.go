@@ -0,0 +1,187 @@
+package idempotency
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBegin(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      string // fingerprint of the first request
+		finished   bool
+		user       string
+		key        string
+		again      string
+		wantErr    error
+		wantReplay bool
+	}{
+		{name: "replay", first: "a", finished: true, user: "DRV1", key: "k", again: "a", wantReplay: true},
+		{name: "different body", first: "a", finished: true, user: "DRV1", key: "k", again: "b", wantErr: ErrMismatch},
+		{name: "still running", first: "a", user: "DRV1", key: "k", again: "a", wantErr: ErrInProgress},
+		{name: "other user", first: "a", finished: true, user: "DRV2", key: "k", again: "a"},
+		{name: "other key", first: "a", finished: true, user: "DRV1", key: "k2", again: "a"},
+		{name: "empty key", first: "a", user: "DRV1", key: "", again: "a", wantErr: ErrInvalidKey},
+		{name: "long key", first: "a", user: "DRV1", key: strings.Repeat("k", MaxKeyLength+1), again: "a", wantErr: ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore(time.Hour)
+			if _, err := s.Begin("DRV1", "k", tt.first); err != nil {
+				t.Fatal(err)
+			}
+			if tt.finished {
+				s.Finish("DRV1", "k", Response{Status: 200, Body: []byte(`{"status":"success"}`)})
+			}
+
+			res, err := s.Begin(tt.user, tt.key, tt.again)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if (res != nil) != tt.wantReplay {
+				t.Fatalf("replayed = %v, want %v", res != nil, tt.wantReplay)
+			}
+			if res != nil && string(res.Body) != `{"status":"success"}` {
+				t.Errorf("replayed body = %s", res.Body)
+			}
+		})
+	}
+}
+
+func TestBeginAfterRetention(t *testing.T) {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := NewStore(time.Hour)
+	s.Begin("DRV1", "k", "a")
+	s.Finish("DRV1", "k", Response{Status: 200})
+
+	at = at.Add(time.Hour)
+	if res, err := s.Begin("DRV1", "k", "b"); err != nil || res != nil {
+		t.Fatalf("expired key: replayed %v, error %v; want a fresh run", res != nil, err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type call struct {
+		path   string
+		key    string
+		body   string
+		status int
+		runs   int // handler runs so far, after this call
+		replay bool
+	}
+	tests := []struct {
+		name  string
+		fail  int // the handler answers this status on its first run
+		panic bool
+		calls []call
+	}{
+		{
+			name: "retry replays",
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 1},
+				{path: "/bff/driver/home/action", key: "k", body: `{ "action": "PAY" }`, status: 200, runs: 1, replay: true},
+			},
+		},
+		{
+			name: "key reused for another request",
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 1},
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"CANCEL"}`, status: 422, runs: 1},
+			},
+		},
+		{
+			name: "no key runs every time",
+			calls: []call{
+				{path: "/bff/driver/home/action", body: `{"action":"PAY"}`, status: 200, runs: 1},
+				{path: "/bff/driver/home/action", body: `{"action":"PAY"}`, status: 200, runs: 2},
+			},
+		},
+		{
+			name: "default driver and explicit driver share keys",
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 1},
+				{path: "/bff/driver/home/action?driverId=DRV001", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 1, replay: true},
+			},
+		},
+		{
+			name: "default broker and default driver don't",
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 1},
+				{path: "/bff/broker/money/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 2},
+			},
+		},
+		{
+			name: "server error lets the retry run",
+			fail: 500,
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 500, runs: 1},
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 2},
+			},
+		},
+		{
+			name: "client error is replayed",
+			fail: 400,
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 400, runs: 1},
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 400, runs: 1, replay: true},
+			},
+		},
+		{
+			name:  "panic lets the retry run",
+			panic: true,
+			calls: []call{
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 500, runs: 1},
+				{path: "/bff/driver/home/action", key: "k", body: `{"action":"PAY"}`, status: 200, runs: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			handler := func(c *gin.Context) {
+				runs++
+				if runs == 1 && tt.panic {
+					panic("handler failed")
+				}
+				if runs == 1 && tt.fail != 0 {
+					c.JSON(tt.fail, gin.H{"status": "error"})
+					return
+				}
+				c.JSON(200, gin.H{"status": "success", "run": runs})
+			}
+			r := gin.New()
+			r.Use(gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, _ interface{}) { c.AbortWithStatus(500) }))
+			r.Use(Middleware(NewStore(time.Hour)))
+			r.POST("/bff/driver/home/action", handler)
+			r.POST("/bff/broker/money/action", handler)
+
+			for i, cl := range tt.calls {
+				req := httptest.NewRequest("POST", cl.path, strings.NewReader(cl.body))
+				if cl.key != "" {
+					req.Header.Set(KeyHeader, cl.key)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				if w.Code != cl.status {
+					t.Errorf("call %d: status = %d, want %d", i, w.Code, cl.status)
+				}
+				if runs != cl.runs {
+					t.Errorf("call %d: handler ran %d times, want %d", i, runs, cl.runs)
+				}
+				if replayed := w.Header().Get(ReplayedHeader) == "true"; replayed != cl.replay {
+					t.Errorf("call %d: replayed = %v, want %v", i, replayed, cl.replay)
+				}
+			}
+		})
+	}
+}
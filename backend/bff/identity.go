@@ -0,0 +1,29 @@
+package bff
+
+import (
+	"backend/bff/trip"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// UserID is who an endpoint shared by the driver and broker apps acts for:
+// the userId the screens put in its URL, or the demo driver until the apps
+// sign in, the same default the driver screens use
+func UserID(c *gin.Context) string {
+	return c.DefaultQuery("userId", trip.DemoDriverID)
+}
+
+// Caller is who any BFF request acts for, resolved the way its handler
+// resolves it: the userId, brokerId or driverId in the URL, else the demo
+// broker on broker routes and the demo driver everywhere else
+func Caller(c *gin.Context) string {
+	for _, k := range []string{"userId", "brokerId", "driverId"} {
+		if id := c.Query(k); id != "" {
+			return id
+		}
+	}
+	if strings.HasPrefix(c.Request.URL.Path, "/bff/broker/") {
+		return trip.DemoBrokerID
+	}
+	return trip.DemoDriverID
+}
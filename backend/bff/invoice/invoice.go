@@ -0,0 +1,104 @@
+package invoice
+
+import (
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotDelivered = errors.New("invoices are issued once the proof of delivery is approved")
+	ErrNothingPaid  = errors.New("no payment has been released for this trip yet")
+	ErrSeriesFull   = errors.New("no invoice numbers left this financial year")
+)
+
+// Goods transport agency services under forward charge
+const (
+	SACGoodsTransport = "996511"
+	gstPercent        = 12
+)
+
+// Party is a business named on an invoice
+type Party struct {
+	Name    string `json:"name"`
+	GSTIN   string `json:"gstin,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// StateCode is the GST state code, the first two digits of the GSTIN
+func (p Party) StateCode() string {
+	if len(p.GSTIN) < 2 {
+		return ""
+	}
+	return p.GSTIN[:2]
+}
+
+type Line struct {
+	Description string        `json:"description"`
+	SAC         string        `json:"sac"`
+	Amount      ledger.Amount `json:"amount"`
+}
+
+// Invoice is a tax invoice a broker issues for freight
+type Invoice struct {
+	Number        string        `json:"number"`
+	Date          time.Time     `json:"date"`
+	PaymentID     string        `json:"paymentId"`
+	TripID        string        `json:"tripId,omitempty"`
+	Supplier      Party         `json:"supplier"`
+	Recipient     Party         `json:"recipient"`
+	PlaceOfSupply string        `json:"placeOfSupply"`
+	Vehicle       string        `json:"vehicle"`
+	Lines         []Line        `json:"lines"`
+	Taxable       ledger.Amount `json:"taxable"`
+	CGST          ledger.Amount `json:"cgst"`
+	SGST          ledger.Amount `json:"sgst"`
+	IGST          ledger.Amount `json:"igst"`
+	Total         ledger.Amount `json:"total"`
+}
+
+// Interstate supplies carry IGST, supplies within a state split the tax into CGST and SGST
+func (inv *Invoice) computeTax() {
+	inv.Taxable = 0
+	for _, l := range inv.Lines {
+		inv.Taxable += l.Amount
+	}
+
+	tax := percent(inv.Taxable, gstPercent)
+	inv.PlaceOfSupply = inv.Recipient.StateCode()
+	if inv.PlaceOfSupply == "" {
+		inv.PlaceOfSupply = inv.Supplier.StateCode()
+	}
+	if inv.PlaceOfSupply == inv.Supplier.StateCode() {
+		inv.CGST = tax / 2
+		inv.SGST = tax - inv.CGST
+	} else {
+		inv.IGST = tax
+	}
+	inv.Total = inv.Taxable + inv.CGST + inv.SGST + inv.IGST
+}
+
+// Receipt confirms to a driver what was paid for a trip
+type Receipt struct {
+	Number     string         `json:"number"`
+	Date       time.Time      `json:"date"`
+	PaymentID  string         `json:"paymentId"`
+	TripID     string         `json:"tripId"`
+	DriverID   string         `json:"driverId"`
+	DriverName string         `json:"driverName"`
+	Vehicle    string         `json:"vehicle"`
+	Route      string         `json:"route"`
+	Broker     Party          `json:"broker"`
+	Payable    ledger.Amount  `json:"payable"`
+	Entries    []ledger.Entry `json:"entries"`
+	Paid       ledger.Amount  `json:"paid"`
+	Deducted   ledger.Amount  `json:"deducted"`
+	Balance    ledger.Amount  `json:"balance"`
+}
+
+// percent rounds to the nearest paisa
+func percent(a ledger.Amount, p int64) ledger.Amount {
+	return ledger.Amount((int64(a)*p + 50) / 100)
+}
@@ -0,0 +1,65 @@
+package invoice
+
+import (
+	"backend/bff/ledger"
+	"testing"
+)
+
+func TestComputeTax(t *testing.T) {
+	maharashtra := Party{Name: "Supplier", GSTIN: "27AAAAA0000A1Z5"}
+	tests := []struct {
+		name      string
+		recipient Party
+		lines     []ledger.Amount
+		wantCGST  ledger.Amount
+		wantSGST  ledger.Amount
+		wantIGST  ledger.Amount
+		wantTotal ledger.Amount
+	}{
+		{
+			name:      "within the state",
+			recipient: Party{GSTIN: "27BBBBB1111B1Z5"},
+			lines:     []ledger.Amount{ledger.Rupees(20000), ledger.Rupees(5000)},
+			wantCGST:  ledger.Rupees(1500),
+			wantSGST:  ledger.Rupees(1500),
+			wantTotal: ledger.Rupees(28000),
+		},
+		{
+			name:      "across states",
+			recipient: Party{GSTIN: "29CCCCC2222C1Z5"},
+			lines:     []ledger.Amount{ledger.Rupees(25000)},
+			wantIGST:  ledger.Rupees(3000),
+			wantTotal: ledger.Rupees(28000),
+		},
+		{
+			name:      "unregistered recipient is taxed where the supplier is",
+			recipient: Party{Name: "Walk-in"},
+			lines:     []ledger.Amount{ledger.Rupees(25000)},
+			wantCGST:  ledger.Rupees(1500),
+			wantSGST:  ledger.Rupees(1500),
+			wantTotal: ledger.Rupees(28000),
+		},
+		{
+			// 12% of ₹1.09 is 13.08 paise, rounded to 13 and split 6 + 7
+			name:      "odd paisa goes to SGST",
+			recipient: Party{GSTIN: "27BBBBB1111B1Z5"},
+			lines:     []ledger.Amount{109},
+			wantCGST:  6,
+			wantSGST:  7,
+			wantTotal: 122,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := Invoice{Supplier: maharashtra, Recipient: tt.recipient}
+			for _, a := range tt.lines {
+				inv.Lines = append(inv.Lines, Line{SAC: SACGoodsTransport, Amount: a})
+			}
+			inv.computeTax()
+			if inv.CGST != tt.wantCGST || inv.SGST != tt.wantSGST || inv.IGST != tt.wantIGST || inv.Total != tt.wantTotal {
+				t.Errorf("CGST %s, SGST %s, IGST %s, total %s; want %s, %s, %s, %s",
+					inv.CGST, inv.SGST, inv.IGST, inv.Total, tt.wantCGST, tt.wantSGST, tt.wantIGST, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package invoice
+
+import (
+	"backend/bff"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GST allows invoice numbers of at most 16 characters: the broker prefix, the
+// financial year and the serial, e.g. "BRK001/2627/0001"
+const (
+	prefixLen = 6
+	maxSerial = 9999
+)
+
+// Sequencer hands out gapless invoice numbers, one series per broker per financial year
+type Sequencer struct {
+	mu   sync.Mutex
+	last map[string]int
+}
+
+func NewSequencer() *Sequencer {
+	return &Sequencer{last: map[string]int{}}
+}
+
+// Next returns the broker's next number for the financial year date falls in
+func (s *Sequencer) Next(brokerID string, date time.Time) (string, error) {
+	year := bff.FinancialYear(date)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the series is the broker's own, so two brokers sharing a prefix don't clash
+	series := brokerID + "/" + year
+	if s.last[series] >= maxSerial {
+		return "", fmt.Errorf("%w: %s has used all %d numbers for %s", ErrSeriesFull, brokerID, maxSerial, year)
+	}
+	s.last[series]++
+	return fmt.Sprintf("%s/%s/%04d", prefix(brokerID), year, s.last[series]), nil
+}
+
+// prefix keeps the letters and digits of a broker ID; one longer than
+// prefixLen keeps its first two and four from its hash
+func prefix(brokerID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(brokerID) {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	p := b.String()
+	if len(p) <= prefixLen {
+		return p
+	}
+	sum := sha256.Sum256([]byte(brokerID))
+	return p[:2] + strings.ToUpper(hex.EncodeToString(sum[:2]))
+}
@@ -0,0 +1,83 @@
+package invoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	march := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		broker string
+		dates  []time.Time
+		want   []string
+	}{
+		{name: "short broker ID", broker: "BRK001", dates: []time.Time{april, april}, want: []string{"BRK001/2627/0001", "BRK001/2627/0002"}},
+		{name: "punctuation dropped", broker: "brk-01", dates: []time.Time{april}, want: []string{"BRK01/2627/0001"}},
+		{name: "new series each financial year", broker: "BRK001", dates: []time.Time{march, april, march}, want: []string{"BRK001/2526/0001", "BRK001/2627/0001", "BRK001/2526/0002"}},
+		{name: "long broker ID is shortened", broker: "BROKER-0000042", dates: []time.Time{april}, want: []string{"BRAC8B/2627/0001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSequencer()
+			for i, date := range tt.dates {
+				got, err := s.Next(tt.broker, date)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want[i] {
+					t.Errorf("number %d = %q, want %q", i, got, tt.want[i])
+				}
+				if len(got) > 16 {
+					t.Errorf("%q is %d characters, GST allows 16", got, len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestNextLongBrokersDoNotClash(t *testing.T) {
+	date := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSequencer()
+	seen := map[string]string{}
+	for _, broker := range []string{"BROKER-0000042", "BROKER-0000043", "BROKER-0000044", "BRK001"} {
+		got, err := s.Next(broker, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) > 16 {
+			t.Errorf("%q is %d characters, GST allows 16", got, len(got))
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("%s and %s were both given %s", other, broker, got)
+		}
+		seen[got] = broker
+	}
+
+	// a long ID's prefix keeps its first letters and a stable hash
+	p := prefix("BROKER-0000042")
+	if len(p) != prefixLen || !strings.HasPrefix(p, "BR") || p != prefix("BROKER-0000042") {
+		t.Errorf("prefix = %q", p)
+	}
+}
+
+func TestNextSeriesFull(t *testing.T) {
+	date := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSequencer()
+	s.last["BRK001/2627"] = maxSerial - 1
+
+	if got, err := s.Next("BRK001", date); err != nil || got != "BRK001/2627/9999" {
+		t.Fatalf("last number = %q, %v", got, err)
+	}
+	if _, err := s.Next("BRK001", date); !errors.Is(err, ErrSeriesFull) {
+		t.Errorf("error = %v, want %v", err, ErrSeriesFull)
+	}
+	// the next financial year starts again
+	if got, err := s.Next("BRK001", date.AddDate(1, 0, 0)); err != nil || got != "BRK001/2728/0001" {
+		t.Errorf("next year = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,32 @@
+package invoice
+
+import "backend/bff/trip"
+
+// Broker billing profiles, until broker onboarding stores GST details
+var brokers = map[string]Party{
+	trip.DemoBrokerID: {
+		Name:    "Sharma Logistics Pvt. Ltd.",
+		GSTIN:   "27AABCS1234F1Z5",
+		Address: "Andheri East, Mumbai, Maharashtra 400069",
+	},
+}
+
+func broker(id, name string) Party {
+	if p, ok := brokers[id]; ok {
+		return p
+	}
+	return Party{Name: name}
+}
+
+// consignor is billed for the freight; trips carry the sender's details
+func consignor(t trip.Trip) Party {
+	d := t.Details
+	if d["senderName"] == "" {
+		return Party{Name: "Consignor"}
+	}
+	return Party{
+		Name:    d["senderName"],
+		GSTIN:   d["senderGSTIN"],
+		Address: d["senderAddress"],
+	}
+}
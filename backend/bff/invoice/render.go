@@ -0,0 +1,121 @@
+package invoice
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/pdf"
+	"fmt"
+)
+
+const (
+	left  = 50.0
+	right = pdf.PageWidth - 50
+)
+
+// PDF renders the tax invoice
+func (inv Invoice) PDF() []byte {
+	d := pdf.New()
+	d.Text(left, 60, 20, true, "TAX INVOICE")
+	d.TextRight(right, 60, 10, false, "Original for Recipient")
+
+	y := partyBlock(d, left, 100, "Supplier", inv.Supplier)
+	d.Text(330, 100, 10, true, "Invoice No: "+inv.Number)
+	d.Text(330, 115, 10, false, "Date: "+inv.Date.Format("02 Jan 2006"))
+	d.Text(330, 130, 10, false, "Place of Supply: "+inv.PlaceOfSupply)
+	d.Text(330, 145, 10, false, "Reverse Charge: No")
+	d.Text(330, 160, 10, false, "Vehicle: "+inv.Vehicle)
+	y = partyBlock(d, left, y+15, "Bill To", inv.Recipient)
+
+	y += 20
+	d.Shade(left, y, right-left, 20)
+	d.Text(left+5, y+14, 10, true, "Description")
+	d.Text(370, y+14, 10, true, "SAC")
+	d.TextRight(right-5, y+14, 10, true, "Amount")
+	y += 20
+	for _, l := range inv.Lines {
+		y += 18
+		d.Text(left+5, y, 10, false, l.Description)
+		d.Text(370, y, 10, false, l.SAC)
+		d.TextRight(right-5, y, 10, false, l.Amount.String())
+	}
+	y += 12
+	d.Line(left, y, right, y, 0.5)
+
+	y = totalRow(d, y+18, "Taxable Value", inv.Taxable.String(), false)
+	if inv.IGST > 0 {
+		y = totalRow(d, y, fmt.Sprintf("IGST @ %d%%", gstPercent), inv.IGST.String(), false)
+	} else {
+		y = totalRow(d, y, fmt.Sprintf("CGST @ %g%%", gstPercent/2.0), inv.CGST.String(), false)
+		y = totalRow(d, y, fmt.Sprintf("SGST @ %g%%", gstPercent/2.0), inv.SGST.String(), false)
+	}
+	d.Line(330, y-8, right, y-8, 0.5)
+	y = totalRow(d, y+4, "Invoice Total", inv.Total.String(), true)
+
+	d.Text(left, y+40, 9, false, "Trip "+inv.TripID+" / Payment "+inv.PaymentID)
+	d.Text(left, y+55, 9, false, "This is a computer generated invoice and does not need a signature.")
+	return d.Bytes()
+}
+
+// PDF renders the driver's payment receipt
+func (r Receipt) PDF() []byte {
+	d := pdf.New()
+	d.Text(left, 60, 20, true, "PAYMENT RECEIPT")
+	d.TextRight(right, 60, 10, false, "Receipt No: "+r.Number)
+	d.TextRight(right, 75, 10, false, "Date: "+r.Date.Format("02 Jan 2006"))
+
+	d.Text(left, 105, 10, true, "Paid To")
+	d.Text(left, 120, 10, false, r.DriverName)
+	d.Text(left, 135, 10, false, "Vehicle: "+r.Vehicle)
+	partyBlock(d, 330, 105, "Paid By", r.Broker)
+	d.Text(left, 160, 10, false, "Trip: "+r.TripID+"   Route: "+r.Route)
+
+	y := 185.0
+	d.Shade(left, y, right-left, 20)
+	d.Text(left+5, y+14, 10, true, "Date")
+	d.Text(130, y+14, 10, true, "Particulars")
+	d.Text(360, y+14, 10, true, "Ledger Ref")
+	d.TextRight(right-5, y+14, 10, true, "Amount")
+	y += 20
+	for _, e := range r.Entries {
+		y += 18
+		amount := e.Net(ledger.DriverWallet(r.DriverID))
+		particulars := e.Kind.Label()
+		if e.Memo != "" {
+			particulars += ": " + e.Memo
+		}
+		d.Text(left+5, y, 10, false, e.At.Format("02 Jan 2006"))
+		d.Text(130, y, 10, false, particulars)
+		d.Text(360, y, 10, false, e.ID)
+		d.TextRight(right-5, y, 10, false, amount.String())
+	}
+	y += 12
+	d.Line(left, y, right, y, 0.5)
+
+	y = totalRow(d, y+18, "Trip Payable", r.Payable.String(), false)
+	y = totalRow(d, y, "Deductions", r.Deducted.String(), false)
+	y = totalRow(d, y, "Paid to Driver", r.Paid.String(), true)
+	y = totalRow(d, y, "Balance Due", r.Balance.String(), false)
+
+	d.Text(left, y+40, 9, false, "Amounts are as recorded in the payment ledger on the receipt date.")
+	return d.Bytes()
+}
+
+func partyBlock(d *pdf.Document, x, y float64, title string, p Party) float64 {
+	d.Text(x, y, 10, true, title)
+	y += 15
+	d.Text(x, y, 10, false, p.Name)
+	if p.Address != "" {
+		y += 15
+		d.Text(x, y, 9, false, p.Address)
+	}
+	if p.GSTIN != "" {
+		y += 15
+		d.Text(x, y, 9, false, "GSTIN: "+p.GSTIN)
+	}
+	return y + 15
+}
+
+func totalRow(d *pdf.Document, y float64, label, value string, bold bool) float64 {
+	d.Text(330, y, 10, bold, label)
+	d.TextRight(right-5, y, 10, bold, value)
+	return y + 16
+}
@@ -0,0 +1,165 @@
+package invoice
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/ledger"
+	"backend/bff/settlement"
+	"backend/bff/trip"
+	"fmt"
+	"sync"
+)
+
+// Service issues invoices and receipts and files them in document storage
+type Service struct {
+	Payments *settlement.Store
+	Trips    *trip.Store
+	Ledger   *ledger.Ledger
+	Docs     docstore.Store
+	Numbers  *Sequencer
+
+	// one invoice per payment, so a repeated download never burns a number
+	mu       sync.Mutex
+	invoices map[string]Invoice
+	receipts map[string]Receipt
+}
+
+func NewService(payments *settlement.Store, trips *trip.Store, l *ledger.Ledger, docs docstore.Store) *Service {
+	return &Service{
+		Payments: payments,
+		Trips:    trips,
+		Ledger:   l,
+		Docs:     docs,
+		Numbers:  NewSequencer(),
+		invoices: map[string]Invoice{},
+		receipts: map[string]Receipt{},
+	}
+}
+
+// Default service used by the BFF handlers
+var Default = NewService(settlement.Default, trip.Default, ledger.Default, docstore.Default)
+
+// Invoice returns the freight invoice for a payment, issuing it on first request
+func (s *Service) Invoice(paymentID string) (docstore.Document, Invoice, error) {
+	p, err := s.Payments.Get(paymentID)
+	if err != nil {
+		return docstore.Document{}, Invoice{}, err
+	}
+	if p.POD != settlement.PODApproved {
+		return docstore.Document{}, Invoice{}, ErrNotDelivered
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inv, ok := s.invoices[p.ID]; ok {
+		if doc, ok := s.Docs.Find("invoice", p.ID); ok {
+			return doc, inv, nil
+		}
+	}
+
+	number, err := s.Numbers.Next(p.BrokerID, now())
+	if err != nil {
+		return docstore.Document{}, Invoice{}, err
+	}
+	t, _ := s.Trips.Get(p.TripID)
+	inv := Invoice{
+		Number:    number,
+		Date:      now(),
+		PaymentID: p.ID,
+		TripID:    p.LedgerRef(),
+		Supplier:  broker(p.BrokerID, p.BrokerName),
+		Recipient: consignor(t),
+		Vehicle:   p.TruckNumber,
+		Lines: []Line{{
+			Description: fmt.Sprintf("Freight: %s → %s, %s, %s", p.From, p.To, p.Distance, p.Cargo),
+			SAC:         SACGoodsTransport,
+			Amount:      p.Gross,
+		}},
+	}
+	inv.computeTax()
+
+	doc, err := s.Docs.Put(docstore.Document{
+		Kind:        "invoice",
+		Ref:         p.ID,
+		Name:        "invoice-" + sanitize(inv.Number) + ".pdf",
+		ContentType: "application/pdf",
+		Owners:      []string{p.BrokerID},
+	}, inv.PDF())
+	if err != nil {
+		return docstore.Document{}, Invoice{}, err
+	}
+	s.invoices[p.ID] = inv
+	return doc, inv, nil
+}
+
+// Receipt returns the driver's receipt for everything paid so far against a payment
+func (s *Service) Receipt(paymentID string) (docstore.Document, Receipt, error) {
+	p, err := s.Payments.Get(paymentID)
+	if err != nil {
+		return docstore.Document{}, Receipt{}, err
+	}
+	if len(p.Releases) == 0 {
+		return docstore.Document{}, Receipt{}, ErrNothingPaid
+	}
+
+	// a new release means a new receipt
+	ref := fmt.Sprintf("%s-%d", p.ID, len(p.Releases))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.receipts[ref]; ok {
+		if doc, ok := s.Docs.Find("receipt", ref); ok {
+			return doc, r, nil
+		}
+	}
+
+	wallet := ledger.DriverWallet(p.DriverID)
+	r := Receipt{
+		Number:     "RCPT-" + ref,
+		Date:       now(),
+		PaymentID:  p.ID,
+		TripID:     p.LedgerRef(),
+		DriverID:   p.DriverID,
+		DriverName: p.DriverName,
+		Vehicle:    p.TruckNumber,
+		Route:      p.From + " → " + p.To,
+		Broker:     broker(p.BrokerID, p.BrokerName),
+		Payable:    p.Payable(),
+		Balance:    p.Balance(),
+	}
+	entries := s.Ledger.Entries(func(e ledger.Entry) bool {
+		return e.TripID == r.TripID && (e.Kind == ledger.KindRelease || e.Kind == ledger.KindDeduction) && e.Touches(wallet)
+	})
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		r.Entries = append(r.Entries, e)
+		if e.Kind == ledger.KindDeduction {
+			r.Deducted -= e.Net(wallet)
+		}
+		r.Paid += e.Net(wallet)
+	}
+
+	doc, err := s.Docs.Put(docstore.Document{
+		Kind:        "receipt",
+		Ref:         ref,
+		Name:        "receipt-" + sanitize(r.Number) + ".pdf",
+		ContentType: "application/pdf",
+		Owners:      []string{p.DriverID, p.BrokerID},
+	}, r.PDF())
+	if err != nil {
+		return docstore.Document{}, Receipt{}, err
+	}
+	s.receipts[ref] = r
+	return doc, r, nil
+}
+
+func sanitize(s string) string {
+	out := []rune(s)
+	for i, r := range out {
+		if r == '/' || r == ' ' {
+			out[i] = '-'
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,96 @@
+package kpi
+
+import (
+	"backend/bff/ledger"
+	"fmt"
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+// Period is the span paid volume and margins are compared over
+type Period string
+
+const (
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+// ParsePeriod reads a period from a request, defaulting to the month
+func ParsePeriod(s string) Period {
+	if Period(s) == Week {
+		return Week
+	}
+	return Month
+}
+
+// bounds returns the current period and the one before it
+func (p Period) bounds(at time.Time) (from, to, prevFrom time.Time) {
+	if p == Week {
+		y, m, d := at.Date()
+		from = time.Date(y, m, d-(int(at.Weekday())+6)%7, 0, 0, 0, 0, at.Location())
+		return from, from.AddDate(0, 0, 7), from.AddDate(0, 0, -7)
+	}
+	from = ledger.MonthStart(at)
+	return from, from.AddDate(0, 1, 0), from.AddDate(0, -1, 0)
+}
+
+// Comparison is a figure for the current period next to the previous one
+type Comparison struct {
+	Current  ledger.Amount `json:"current"`
+	Previous ledger.Amount `json:"previous"`
+	Change   float64       `json:"change"` // percent; zero when there is nothing to compare with
+}
+
+func compare(current, previous ledger.Amount) Comparison {
+	c := Comparison{Current: current, Previous: previous}
+	if previous != 0 {
+		c.Change = math.Round(1000*float64(current-previous)/float64(previous)) / 10
+	}
+	return c
+}
+
+// Label renders the change the way dashboard cards show it ("+15% from last month")
+func (c Comparison) Label(p Period) string {
+	last := "last " + string(p)
+	switch {
+	case c.Previous == 0 && c.Current == 0:
+		return "Nothing this " + string(p) + " yet"
+	case c.Previous == 0:
+		return "Nothing " + last
+	case c.Change == 0:
+		return "Same as " + last
+	case c.Change > 0:
+		return fmt.Sprintf("+%g%% from %s", c.Change, last)
+	}
+	return fmt.Sprintf("%g%% from %s", c.Change, last)
+}
+
+// Snapshot is a broker's dashboard figures at one point in time
+type Snapshot struct {
+	BrokerID string    `json:"brokerId"`
+	Period   Period    `json:"period"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+
+	ActiveLoads  int `json:"activeLoads"`  // loads with a trip that is not settled or cancelled
+	TrucksOnRoad int `json:"trucksOnRoad"` // trips that have left pickup and not been delivered
+	DelayedTrips int `json:"delayedTrips"` // trucks on road expected after the promised time
+
+	PendingPayments   int           `json:"pendingPayments"`
+	PendingAmount     ledger.Amount `json:"pendingAmount"`
+	CompletedPayments int           `json:"completedPayments"`
+	CompletedAmount   ledger.Amount `json:"completedAmount"`
+
+	// Released to drivers in the period
+	PaidVolume   Comparison `json:"paidVolume"`
+	Releases     int        `json:"releases"`
+	LastReleases int        `json:"lastReleases"`
+
+	// Commission and deductions kept per load settled in the period
+	AvgMargin  Comparison `json:"avgMargin"`
+	MarginRate float64    `json:"marginRate"` // percent of gross freight
+
+	ComputedAt time.Time `json:"computedAt"`
+}
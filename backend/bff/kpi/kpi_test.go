@@ -0,0 +1,28 @@
+package kpi
+
+import (
+	"backend/bff/ledger"
+	"testing"
+)
+
+func TestComparisonLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  ledger.Amount
+		previous ledger.Amount
+		want     string
+	}{
+		{name: "up", current: ledger.Rupees(115000), previous: ledger.Rupees(100000), want: "+15% from last month"},
+		{name: "down", current: ledger.Rupees(2000), previous: ledger.Rupees(3000), want: "-33.3% from last month"},
+		{name: "flat", current: ledger.Rupees(500), previous: ledger.Rupees(500), want: "Same as last month"},
+		{name: "nothing before", current: ledger.Rupees(500), want: "Nothing last month"},
+		{name: "nothing at all", want: "Nothing this month yet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compare(tt.current, tt.previous).Label(Month); got != tt.want {
+				t.Errorf("Label = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
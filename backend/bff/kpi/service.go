@@ -0,0 +1,169 @@
+package kpi
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/routing"
+	"backend/bff/settlement"
+	"backend/bff/trip"
+	"math"
+	"sync"
+	"time"
+)
+
+// Service computes broker KPIs, caching each broker's snapshot until
+// something it depends on changes or it goes stale
+type Service struct {
+	Trips    *trip.Store
+	Payments *settlement.Store
+	Routes   *routing.Engine
+
+	// How long a snapshot is served before it is recomputed; delays
+	// change with time alone, so snapshots cannot live forever
+	TTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Snapshot // by broker and period
+	gen   map[string]int      // by broker, bumped on every invalidation
+}
+
+func NewService(trips *trip.Store, payments *settlement.Store, routes *routing.Engine) *Service {
+	s := &Service{
+		Trips:    trips,
+		Payments: payments,
+		Routes:   routes,
+		TTL:      time.Minute,
+		cache:    map[string]Snapshot{},
+		gen:      map[string]int{},
+	}
+	trips.OnAssign(func(t trip.Trip) {
+		s.Invalidate(t.BrokerID)
+	})
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		s.Invalidate(t.BrokerID)
+	})
+	payments.OnRelease(func(p settlement.Payment, rel settlement.Release) {
+		s.Invalidate(p.BrokerID)
+	})
+	return s
+}
+
+// Default service used by the BFF handlers
+var Default = NewService(trip.Default, settlement.Default, routing.Default)
+
+// Snapshot returns the broker's KPIs for the period, from cache when fresh
+func (s *Service) Snapshot(brokerID string, p Period) Snapshot {
+	key := brokerID + "/" + string(p)
+	at := now()
+
+	s.mu.Lock()
+	snap, ok := s.cache[key]
+	gen := s.gen[brokerID]
+	s.mu.Unlock()
+	if ok && at.Sub(snap.ComputedAt) < s.TTL {
+		return snap
+	}
+
+	snap = s.compute(brokerID, p, at)
+	s.mu.Lock()
+	// a change during the computation may be missing from it, so it is
+	// served this once but not cached
+	if s.gen[brokerID] == gen {
+		s.cache[key] = snap
+	}
+	s.mu.Unlock()
+	return snap
+}
+
+// Invalidate drops a broker's cached snapshots
+func (s *Service) Invalidate(brokerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen[brokerID]++
+	for _, p := range []Period{Week, Month} {
+		delete(s.cache, brokerID+"/"+string(p))
+	}
+}
+
+func (s *Service) compute(brokerID string, p Period, at time.Time) Snapshot {
+	from, to, prevFrom := p.bounds(at)
+	snap := Snapshot{BrokerID: brokerID, Period: p, From: from, To: to, ComputedAt: at}
+
+	loads := map[string]bool{}
+	for _, t := range s.Trips.ForBroker(brokerID) {
+		if !t.Active() {
+			continue
+		}
+		load := t.LoadID
+		if load == "" {
+			load = t.ID
+		}
+		loads[load] = true
+
+		if t.Status != trip.StatusInTransit && t.Status != trip.StatusReachedDrop {
+			continue
+		}
+		snap.TrucksOnRoad++
+		if est, err := s.Routes.Estimate(t); err == nil && est.Delayed {
+			snap.DelayedTrips++
+		}
+	}
+	snap.ActiveLoads = len(loads)
+
+	var paid, lastPaid ledger.Amount
+	var margins, lastMargins []ledger.Amount
+	var gross, margin ledger.Amount
+	for _, pay := range s.Payments.ForBroker(brokerID) {
+		for _, rel := range pay.Releases {
+			switch {
+			case !rel.At.Before(from) && rel.At.Before(to):
+				paid += rel.Amount
+				snap.Releases++
+			case !rel.At.Before(prevFrom) && rel.At.Before(from):
+				lastPaid += rel.Amount
+				snap.LastReleases++
+			}
+		}
+
+		if pay.Balance() > 0 {
+			snap.PendingPayments++
+			snap.PendingAmount += pay.Balance()
+			continue
+		}
+		snap.CompletedPayments++
+		snap.CompletedAmount += pay.Advance + pay.Cleared() - pay.Deducted()
+
+		// a load's margin counts in the period it was settled
+		settled := pay.CreatedAt
+		if len(pay.Releases) > 0 {
+			settled = pay.Releases[len(pay.Releases)-1].At
+		}
+		kept := pay.Commission + pay.Deducted()
+		switch {
+		case !settled.Before(from) && settled.Before(to):
+			margins = append(margins, kept)
+			gross += pay.Gross
+			margin += kept
+		case !settled.Before(prevFrom) && settled.Before(from):
+			lastMargins = append(lastMargins, kept)
+		}
+	}
+
+	snap.PaidVolume = compare(paid, lastPaid)
+	snap.AvgMargin = compare(average(margins), average(lastMargins))
+	if gross > 0 {
+		snap.MarginRate = math.Round(1000*float64(margin)/float64(gross)) / 10
+	}
+	return snap
+}
+
+func average(amounts []ledger.Amount) ledger.Amount {
+	if len(amounts) == 0 {
+		return 0
+	}
+	var sum ledger.Amount
+	for _, a := range amounts {
+		sum += a
+	}
+	return sum / ledger.Amount(len(amounts))
+}
@@ -0,0 +1,147 @@
+package kpi
+
+import (
+	"backend/bff/events"
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"backend/bff/routing"
+	"backend/bff/settlement"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"testing"
+	"time"
+)
+
+func newTestService() *Service {
+	trips := trip.NewStore()
+	trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusLoading})
+	payments := settlement.NewStore(ledger.New(), trips, events.NewBus())
+	payments.Add(settlement.Payment{
+		ID:         "PAY1",
+		DriverID:   "DRV1",
+		BrokerID:   "BRK1",
+		Gross:      ledger.Rupees(30000),
+		Commission: ledger.Rupees(1000),
+		Advance:    ledger.Rupees(5000),
+		POD:        settlement.PODApproved,
+	})
+	routes := &routing.Engine{Provider: routing.GreatCircle{}, Tracks: tracking.NewStore(), DefaultSpeedKmh: 40}
+	return NewService(trips, payments, routes)
+}
+
+func TestSnapshotCache(t *testing.T) {
+	start := time.Now()
+	at := start
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	tests := []struct {
+		name        string
+		after       time.Duration
+		change      func()
+		wantFresh   bool // recomputed rather than served from cache
+		wantLoads   int
+		wantPending int
+	}{
+		{name: "first request", wantFresh: true, wantLoads: 1, wantPending: 1},
+		{name: "nothing changed", after: 10 * time.Second, wantLoads: 1, wantPending: 1},
+		{
+			name:        "another broker's trip",
+			after:       15 * time.Second,
+			change:      func() { s.Trips.Add(trip.Trip{ID: "TRK9", DriverID: "DRV9", BrokerID: "BRK9"}) },
+			wantLoads:   1,
+			wantPending: 1,
+		},
+		{
+			name:        "trip assigned",
+			after:       20 * time.Second,
+			change:      func() { s.Trips.Add(trip.Trip{ID: "TRK2", DriverID: "DRV2", BrokerID: "BRK1"}) },
+			wantFresh:   true,
+			wantLoads:   2,
+			wantPending: 1,
+		},
+		{
+			name:        "trip cancelled",
+			after:       25 * time.Second,
+			change:      func() { s.Trips.Transition("TRK2", trip.StatusCancelled, trip.ActorBroker, "") },
+			wantFresh:   true,
+			wantLoads:   1,
+			wantPending: 1,
+		},
+		{
+			name:      "payment released",
+			after:     30 * time.Second,
+			change:    func() { s.Payments.Release("PAY1", settlement.ReleaseRequest{}) },
+			wantFresh: true,
+			wantLoads: 1,
+		},
+		{name: "cached again", after: 40 * time.Second, wantLoads: 1},
+		{name: "stale after the TTL", after: 30*time.Second + time.Minute, wantFresh: true, wantLoads: 1},
+	}
+	var last Snapshot
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at = start.Add(tt.after)
+			if tt.change != nil {
+				tt.change()
+			}
+			snap := s.Snapshot("BRK1", Month)
+			if fresh := snap.ComputedAt.Equal(at); fresh != tt.wantFresh {
+				t.Errorf("computed at %s, fresh = %v; want %v", snap.ComputedAt.Sub(start), fresh, tt.wantFresh)
+			}
+			if !tt.wantFresh && !snap.ComputedAt.Equal(last.ComputedAt) {
+				t.Errorf("served a snapshot from %s, want the cached one from %s", snap.ComputedAt.Sub(start), last.ComputedAt.Sub(start))
+			}
+			if snap.ActiveLoads != tt.wantLoads || snap.PendingPayments != tt.wantPending {
+				t.Errorf("%d active loads, %d pending payments; want %d, %d", snap.ActiveLoads, snap.PendingPayments, tt.wantLoads, tt.wantPending)
+			}
+			last = snap
+		})
+	}
+}
+
+func TestSnapshotPeriodsInvalidateTogether(t *testing.T) {
+	s := newTestService()
+	s.Snapshot("BRK1", Week)
+	s.Snapshot("BRK1", Month)
+
+	s.Trips.Add(trip.Trip{ID: "TRK2", DriverID: "DRV2", BrokerID: "BRK1"})
+	for _, p := range []Period{Week, Month} {
+		if got := s.Snapshot("BRK1", p).ActiveLoads; got != 2 {
+			t.Errorf("%s: %d active loads, want 2", p, got)
+		}
+	}
+}
+
+// invalidating is a distance provider that reports a change while a
+// snapshot is being computed
+type invalidating struct {
+	s *Service
+}
+
+func (p invalidating) Distance(from, to geo.Coord) (float64, error) {
+	p.s.Invalidate("BRK1")
+	return 100, nil
+}
+
+func TestChangeDuringComputeIsNotCached(t *testing.T) {
+	at := time.Now()
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	s.Trips.Add(trip.Trip{ID: "TRK2", DriverID: "DRV2", BrokerID: "BRK1", Status: trip.StatusInTransit})
+	s.Routes.Provider = invalidating{s}
+
+	first := s.Snapshot("BRK1", Month)
+	s.Routes.Provider = routing.GreatCircle{}
+	at = at.Add(time.Second)
+	second := s.Snapshot("BRK1", Month)
+	if second.ComputedAt.Equal(first.ComputedAt) {
+		t.Error("a snapshot that raced a change was cached")
+	}
+	if second.TrucksOnRoad != 1 {
+		t.Errorf("%d trucks on road, want 1", second.TrucksOnRoad)
+	}
+}
@@ -0,0 +1,193 @@
+package ledger
+
+import (
+	"backend/bff"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrEmptyEntry       = errors.New("journal entry needs at least two lines")
+	ErrUnbalanced       = errors.New("journal entry debits and credits do not match")
+	ErrInvalidAmount    = errors.New("amount must be positive")
+	ErrUnknownKind      = errors.New("unknown journal entry kind")
+	ErrInsufficientFund = errors.New("amount is more than the wallet balance")
+)
+
+// Amount is money in paise
+type Amount int64
+
+func Rupees(r int64) Amount {
+	return Amount(r * 100)
+}
+
+// FromRupees converts a rupee value from a request, rounding to the nearest paisa
+func FromRupees(r float64) Amount {
+	return Amount(math.Round(r * 100))
+}
+
+// String renders the amount the way the apps show money ("₹4,520", "₹99.50")
+func (a Amount) String() string {
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	s := sign + "₹" + bff.GroupIndian(int64(a/100))
+	if paise := a % 100; paise != 0 {
+		s += fmt.Sprintf(".%02d", paise)
+	}
+	return s
+}
+
+// Account identifies a ledger account, e.g. "driver:DRV001:wallet"
+type Account string
+
+func DriverWallet(driverID string) Account {
+	return Account("driver:" + driverID + ":wallet")
+}
+
+// DriverPending holds money owed to a driver that has not cleared into the wallet yet
+func DriverPending(driverID string) Account {
+	return Account("driver:" + driverID + ":pending")
+}
+
+// DriverPayout holds withdrawals sent to the payment provider until they settle or fail
+func DriverPayout(driverID string) Account {
+	return Account("driver:" + driverID + ":payout")
+}
+
+// DriverCash tracks what a driver paid out of pocket for trip expenses
+func DriverCash(driverID string) Account {
+	return Account("driver:" + driverID + ":cash")
+}
+
+func Broker(brokerID string) Account {
+	return Account("broker:" + brokerID)
+}
+
+// Platform accounts on the other side of deductions, tolls and payouts
+const (
+	PlatformRevenue Account = "platform:revenue"
+	PlatformTolls   Account = "platform:tolls"
+	PlatformBank    Account = "platform:bank"
+)
+
+// Merchants is everyone outside the platform a trip expense was paid to
+const Merchants Account = "external:merchants"
+
+type Kind string
+
+const (
+	KindAdvance   Kind = "advance"
+	KindBalance   Kind = "balance"
+	KindRelease   Kind = "release"
+	KindDeduction Kind = "deduction"
+	KindToll      Kind = "toll"
+	KindPayout    Kind = "payout"
+
+	KindPayoutSettled  Kind = "payout_settled"
+	KindPayoutReversed Kind = "payout_reversed"
+	KindCollection     Kind = "collection"
+
+	KindExpense       Kind = "expense"
+	KindCashExpense   Kind = "cash_expense"
+	KindReimbursement Kind = "reimbursement"
+	KindReimbursed    Kind = "reimbursed"
+)
+
+func (k Kind) Label() string {
+	switch k {
+	case KindAdvance:
+		return "Advance Received"
+	case KindBalance:
+		return "Balance Payment"
+	case KindRelease:
+		return "Payment Cleared"
+	case KindDeduction:
+		return "Deduction"
+	case KindToll:
+		return "Toll Paid"
+	case KindPayout:
+		return "Withdrawal"
+	case KindPayoutSettled:
+		return "Withdrawal Settled"
+	case KindPayoutReversed:
+		return "Withdrawal Reversed"
+	case KindCollection:
+		return "Funds Added"
+	case KindExpense, KindCashExpense:
+		return "Trip Expense"
+	case KindReimbursement:
+		return "Expense Reimbursement"
+	case KindReimbursed:
+		return "Reimbursement Cleared"
+	}
+	return string(k)
+}
+
+// Line moves money into or out of one account; debits are positive, credits negative
+type Line struct {
+	Account Account `json:"account"`
+	Amount  Amount  `json:"amount"`
+}
+
+// Entry is an immutable journal entry whose lines sum to zero
+type Entry struct {
+	ID           string    `json:"id"`
+	Kind         Kind      `json:"kind"`
+	TripID       string    `json:"tripId,omitempty"`
+	Ref          string    `json:"ref,omitempty"` // payment provider reference, if any
+	Counterparty string    `json:"counterparty,omitempty"`
+	Mode         string    `json:"mode,omitempty"`
+	Memo         string    `json:"memo,omitempty"`
+	At           time.Time `json:"at"`
+	Lines        []Line    `json:"lines"`
+}
+
+// Net returns what the entry credited to the given accounts, negative when it took money out
+func (e Entry) Net(accounts ...Account) Amount {
+	var net Amount
+	for _, l := range e.Lines {
+		for _, a := range accounts {
+			if l.Account == a {
+				net -= l.Amount
+			}
+		}
+	}
+	return net
+}
+
+// Touches reports whether any line of the entry posts to one of the accounts
+func (e Entry) Touches(accounts ...Account) bool {
+	for _, l := range e.Lines {
+		for _, a := range accounts {
+			if l.Account == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e Entry) validate() error {
+	if len(e.Lines) < 2 {
+		return ErrEmptyEntry
+	}
+	var sum Amount
+	for _, l := range e.Lines {
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalanced
+	}
+	return nil
+}
+
+func (e Entry) clone() Entry {
+	e.Lines = append([]Line(nil), e.Lines...)
+	return e
+}
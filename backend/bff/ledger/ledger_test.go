@@ -0,0 +1,205 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type step struct {
+	kind   Kind
+	amount Amount
+}
+
+func TestRecordBalances(t *testing.T) {
+	tests := []struct {
+		name          string
+		steps         []step
+		wantErr       error // from the last step
+		wantAvailable Amount
+		wantPending   Amount
+	}{
+		{
+			name:          "advance lands in the wallet",
+			steps:         []step{{KindAdvance, Rupees(5000)}},
+			wantAvailable: Rupees(5000),
+		},
+		{
+			name:          "balance waits in pending",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindBalance, Rupees(20000)}},
+			wantAvailable: Rupees(5000),
+			wantPending:   Rupees(20000),
+		},
+		{
+			name:          "release clears pending into the wallet",
+			steps:         []step{{KindBalance, Rupees(20000)}, {KindRelease, Rupees(20000)}},
+			wantAvailable: Rupees(20000),
+		},
+		{
+			name:          "payout spends the wallet",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindPayout, Rupees(3000)}},
+			wantAvailable: Rupees(2000),
+		},
+		{
+			name:          "payout of the whole wallet",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindPayout, Rupees(5000)}},
+			wantAvailable: 0,
+		},
+		{
+			name:          "payout beyond the wallet",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindPayout, Rupees(5001)}},
+			wantErr:       ErrInsufficientFund,
+			wantAvailable: Rupees(5000),
+		},
+		{
+			name:        "pending money can't be spent",
+			steps:       []step{{KindBalance, Rupees(20000)}, {KindExpense, Rupees(100)}},
+			wantErr:     ErrInsufficientFund,
+			wantPending: Rupees(20000),
+		},
+		{
+			name:          "toll beyond the wallet",
+			steps:         []step{{KindToll, Rupees(250)}},
+			wantErr:       ErrInsufficientFund,
+			wantAvailable: 0,
+		},
+		{
+			name:          "deduction spends the wallet",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindDeduction, Rupees(500)}},
+			wantAvailable: Rupees(4500),
+		},
+		{
+			name:          "reversed payout comes back",
+			steps:         []step{{KindAdvance, Rupees(5000)}, {KindPayout, Rupees(5000)}, {KindPayoutReversed, Rupees(5000)}},
+			wantAvailable: Rupees(5000),
+		},
+		{
+			name:          "zero amount",
+			steps:         []step{{KindAdvance, 0}},
+			wantErr:       ErrInvalidAmount,
+			wantAvailable: 0,
+		},
+		{
+			name:          "unknown kind",
+			steps:         []step{{Kind("gift"), Rupees(100)}},
+			wantErr:       ErrUnknownKind,
+			wantAvailable: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			var err error
+			for i, s := range tt.steps {
+				_, err = l.Record(s.kind, Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: s.amount})
+				if i < len(tt.steps)-1 && err != nil {
+					t.Fatalf("step %d (%s): %v", i, s.kind, err)
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			w := l.Wallet("DRV1", time.Time{})
+			if w.Available != tt.wantAvailable || w.Pending != tt.wantPending {
+				t.Errorf("wallet = %s available, %s pending; want %s, %s", w.Available, w.Pending, tt.wantAvailable, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestPostRejectsUnbalancedEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []Line
+		wantErr error
+	}{
+		{name: "one line", lines: []Line{{Account: PlatformBank, Amount: 100}}, wantErr: ErrEmptyEntry},
+		{name: "unbalanced", lines: []Line{{Account: PlatformBank, Amount: 100}, {Account: PlatformRevenue, Amount: -90}}, wantErr: ErrUnbalanced},
+		{name: "balanced", lines: []Line{{Account: PlatformBank, Amount: 100}, {Account: PlatformRevenue, Amount: -100}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			_, err := l.Post(Entry{Kind: KindCollection, Lines: tt.lines})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			want := 1
+			if tt.wantErr != nil {
+				want = 0
+			}
+			if got := len(l.Entries(nil)); got != want {
+				t.Errorf("%d entries, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWalletEarnedSince(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	l := New()
+	l.Record(KindAdvance, Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: Rupees(1000), At: start.Add(-time.Hour)})
+	l.Record(KindAdvance, Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: Rupees(2000), At: start.Add(time.Hour)})
+	l.Record(KindBalance, Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: Rupees(3000), At: start.Add(2 * time.Hour)})
+	// releases move money the driver already earned, so they don't count again
+	l.Record(KindRelease, Posting{DriverID: "DRV1", Amount: Rupees(3000), At: start.Add(3 * time.Hour)})
+
+	if got, want := l.Wallet("DRV1", start).Earned, Rupees(5000); got != want {
+		t.Errorf("earned = %s, want %s", got, want)
+	}
+}
+
+func TestRecordAll(t *testing.T) {
+	posting := func(amount Amount) Posting {
+		return Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: amount}
+	}
+	tests := []struct {
+		name          string
+		legs          []Leg
+		wantErr       error
+		wantEntries   int
+		wantAvailable Amount
+	}{
+		{
+			name:          "later legs spend what earlier ones paid in",
+			legs:          []Leg{{KindBalance, posting(Rupees(1000))}, {KindRelease, posting(Rupees(1000))}, {KindDeduction, posting(Rupees(200))}},
+			wantEntries:   3,
+			wantAvailable: Rupees(800),
+		},
+		{
+			name:    "an overdrawn leg posts nothing",
+			legs:    []Leg{{KindBalance, posting(Rupees(1000))}, {KindRelease, posting(Rupees(1000))}, {KindDeduction, posting(Rupees(1001))}},
+			wantErr: ErrInsufficientFund,
+		},
+		{
+			name:    "an invalid leg posts nothing",
+			legs:    []Leg{{KindAdvance, posting(Rupees(1000))}, {KindToll, posting(0)}},
+			wantErr: ErrInvalidAmount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			posted, err := l.RecordAll(tt.legs...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(posted) != tt.wantEntries || len(l.Entries(nil)) != tt.wantEntries {
+				t.Errorf("posted %d, ledger holds %d; want %d", len(posted), len(l.Entries(nil)), tt.wantEntries)
+			}
+			if got := l.Wallet("DRV1", time.Time{}).Available; got != tt.wantAvailable {
+				t.Errorf("available = %s, want %s", got, tt.wantAvailable)
+			}
+		})
+	}
+
+	// entries posted after a refused transaction number on from the last one kept
+	l := New()
+	l.Record(KindAdvance, posting(Rupees(100)))
+	l.RecordAll(Leg{KindAdvance, posting(Rupees(100))}, Leg{KindPayout, posting(Rupees(500))})
+	e, _ := l.Record(KindAdvance, posting(Rupees(100)))
+	if e.ID != "JE000002" {
+		t.Errorf("next entry is %s, want JE000002", e.ID)
+	}
+}
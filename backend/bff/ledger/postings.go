@@ -0,0 +1,115 @@
+package ledger
+
+import "time"
+
+// Posting describes a business money movement between a driver and a broker or the platform
+type Posting struct {
+	DriverID     string
+	BrokerID     string
+	TripID       string
+	Ref          string
+	Amount       Amount
+	Counterparty string
+	Mode         string
+	Memo         string
+	At           time.Time
+}
+
+// Leg is one posting of a transaction made with RecordAll
+type Leg struct {
+	Kind    Kind
+	Posting Posting
+}
+
+// Record turns a posting into the balanced journal entry for its kind
+func (l *Ledger) Record(kind Kind, p Posting) (Entry, error) {
+	posted, err := l.RecordAll(Leg{Kind: kind, Posting: p})
+	if err != nil {
+		return Entry{}, err
+	}
+	return posted[0], nil
+}
+
+// RecordAll records several postings as one transaction, for business
+// events that move money in more than one step: either every entry is
+// posted, or none is if one of them is refused
+func (l *Ledger) RecordAll(legs ...Leg) ([]Entry, error) {
+	txn := make([]draft, len(legs))
+	for i, leg := range legs {
+		d, err := leg.draft()
+		if err != nil {
+			return nil, err
+		}
+		txn[i] = d
+	}
+	return l.post(txn)
+}
+
+// draft is the balanced journal entry for the leg's kind
+func (leg Leg) draft() (draft, error) {
+	kind, p := leg.Kind, leg.Posting
+	if p.Amount <= 0 {
+		return draft{}, ErrInvalidAmount
+	}
+
+	var from, to Account
+	switch kind {
+	case KindAdvance:
+		// advances are paid straight into the wallet
+		from, to = Broker(p.BrokerID), DriverWallet(p.DriverID)
+	case KindBalance:
+		// balance payments wait in pending until they clear
+		from, to = Broker(p.BrokerID), DriverPending(p.DriverID)
+	case KindRelease:
+		from, to = DriverPending(p.DriverID), DriverWallet(p.DriverID)
+	case KindDeduction:
+		// deductions withheld by a broker go back to the broker, the rest are platform charges
+		from, to = DriverWallet(p.DriverID), PlatformRevenue
+		if p.BrokerID != "" {
+			to = Broker(p.BrokerID)
+		}
+	case KindToll:
+		from, to = DriverWallet(p.DriverID), PlatformTolls
+	case KindPayout:
+		// withdrawals leave the wallet at once and sit in payout until the provider settles them
+		from, to = DriverWallet(p.DriverID), DriverPayout(p.DriverID)
+	case KindPayoutSettled:
+		from, to = DriverPayout(p.DriverID), PlatformBank
+	case KindPayoutReversed:
+		from, to = DriverPayout(p.DriverID), DriverWallet(p.DriverID)
+	case KindCollection:
+		from, to = PlatformBank, Broker(p.BrokerID)
+	case KindExpense:
+		from, to = DriverWallet(p.DriverID), Merchants
+	case KindCashExpense:
+		from, to = DriverCash(p.DriverID), Merchants
+	case KindReimbursement:
+		// reimbursements are owed by the broker and clear into the wallet when the trip closes
+		from, to = Broker(p.BrokerID), DriverPending(p.DriverID)
+	case KindReimbursed:
+		from, to = DriverPending(p.DriverID), DriverWallet(p.DriverID)
+	default:
+		return draft{}, ErrUnknownKind
+	}
+
+	// money leaving the wallet can only spend what is in it
+	var drawn Account
+	switch kind {
+	case KindDeduction, KindToll, KindPayout, KindExpense:
+		drawn = from
+	}
+
+	return draft{Entry: Entry{
+		Kind:         kind,
+		TripID:       p.TripID,
+		Ref:          p.Ref,
+		Counterparty: p.Counterparty,
+		Mode:         p.Mode,
+		Memo:         p.Memo,
+		At:           p.At,
+		Lines: []Line{
+			{Account: from, Amount: p.Amount},
+			{Account: to, Amount: -p.Amount},
+		},
+	}, drawn: drawn}, nil
+}
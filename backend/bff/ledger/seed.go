@@ -0,0 +1,32 @@
+package ledger
+
+import (
+	"backend/bff/trip"
+	"time"
+)
+
+func seed(l *Ledger) *Ledger {
+	start := now().Add(-72 * time.Hour)
+	driver := trip.DemoDriverID
+
+	postings := []struct {
+		kind Kind
+		p    Posting
+	}{
+		{KindAdvance, Posting{BrokerID: "BRK003", TripID: "TRIP#4498", Amount: Rupees(3000), Counterparty: "PQR Freight", Mode: "UPI", Memo: "Delhi → Kolkata"}},
+		{KindToll, Posting{TripID: "TRIP#4498", Amount: Rupees(680), Counterparty: "FASTag", Mode: "FASTag", Memo: "Delhi → Kolkata"}},
+		{KindBalance, Posting{BrokerID: "BRK003", TripID: "TRIP#4498", Amount: Rupees(2300), Counterparty: "PQR Freight", Mode: "Bank Transfer", Memo: "Delhi → Kolkata"}},
+		{KindAdvance, Posting{BrokerID: "BRK002", TripID: "TRIP#4587", Amount: Rupees(3200), Counterparty: "ABC Logistics", Mode: "Bank Transfer", Memo: "Mumbai → Delhi"}},
+		{KindDeduction, Posting{TripID: "TRIP#4587", Amount: Rupees(500), Counterparty: "Platform", Mode: "Wallet", Memo: "Commission deducted"}},
+		{KindPayout, Posting{Amount: Rupees(500), Counterparty: "Bank Account", Mode: "Bank Transfer", Memo: "Withdrawal to bank"}},
+		{KindPayoutSettled, Posting{Amount: Rupees(500), Counterparty: "Bank Account", Mode: "Bank Transfer", Memo: "Withdrawal to bank"}},
+	}
+	for i, e := range postings {
+		e.p.DriverID = driver
+		e.p.At = start.Add(time.Duration(i) * 10 * time.Hour)
+		if _, err := l.Record(e.kind, e.p); err != nil {
+			panic(err)
+		}
+	}
+	return l
+}
@@ -0,0 +1,108 @@
+package ledger
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Ledger is an append-only journal; balances are always derived from it
+type Ledger struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+func New() *Ledger {
+	return &Ledger{}
+}
+
+// Default ledger used by the BFF handlers
+var Default = seed(New())
+
+// Post validates and appends a journal entry, returning it with its ID and time set
+func (l *Ledger) Post(e Entry) (Entry, error) {
+	posted, err := l.post([]draft{{Entry: e}})
+	if err != nil {
+		return Entry{}, err
+	}
+	return posted[0], nil
+}
+
+// draft is an entry waiting to be posted; when drawn is set the entry may
+// not take that wallet below zero
+type draft struct {
+	Entry
+	drawn Account
+}
+
+// post appends the entries as one transaction: all of them, or none if one
+// is refused. Wallet checks run under the same lock as the append and see
+// the entries before them in the transaction.
+func (l *Ledger) post(txn []draft) ([]Entry, error) {
+	for i := range txn {
+		if err := txn[i].validate(); err != nil {
+			return nil, err
+		}
+		txn[i].Entry = txn[i].clone()
+		if txn[i].At.IsZero() {
+			txn[i].At = now()
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	start := len(l.entries)
+	posted := make([]Entry, 0, len(txn))
+	for _, d := range txn {
+		if d.drawn != "" {
+			var debit Amount
+			for _, line := range d.Lines {
+				if line.Account == d.drawn {
+					debit += line.Amount
+				}
+			}
+			// wallets are liabilities, so what is available is the negated balance
+			if debit > 0 && debit > -l.balance(d.drawn) {
+				l.entries = l.entries[:start]
+				return nil, ErrInsufficientFund
+			}
+		}
+		d.ID = fmt.Sprintf("JE%06d", len(l.entries)+1)
+		l.entries = append(l.entries, d.Entry)
+		posted = append(posted, d.clone())
+	}
+	return posted, nil
+}
+
+// Balance returns the debit balance of an account; liabilities such as wallets are negative
+func (l *Ledger) Balance(account Account) Amount {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.balance(account)
+}
+
+func (l *Ledger) balance(account Account) Amount {
+	var sum Amount
+	for _, e := range l.entries {
+		for _, line := range e.Lines {
+			if line.Account == account {
+				sum += line.Amount
+			}
+		}
+	}
+	return sum
+}
+
+// Entries returns matching entries, newest first
+func (l *Ledger) Entries(match func(Entry) bool) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []Entry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if match == nil || match(l.entries[i]) {
+			out = append(out, l.entries[i].clone())
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package ledger
+
+import "time"
+
+// Wallet is a driver's balances derived from the ledger
+type Wallet struct {
+	Available Amount `json:"available"`
+	Pending   Amount `json:"pending"`
+	Earned    Amount `json:"earned"`
+}
+
+// Wallet returns a driver's balances, with earnings counted from since
+func (l *Ledger) Wallet(driverID string, since time.Time) Wallet {
+	w := Wallet{
+		Available: -l.Balance(DriverWallet(driverID)),
+		Pending:   -l.Balance(DriverPending(driverID)),
+	}
+	for _, e := range l.Earnings(driverID) {
+		if !e.At.Before(since) {
+			w.Earned += e.Net(DriverWallet(driverID), DriverPending(driverID))
+		}
+	}
+	return w
+}
+
+// Transactions returns every entry that moved money in or out of a driver's accounts, newest first
+func (l *Ledger) Transactions(driverID string) []Entry {
+	wallet, pending := DriverWallet(driverID), DriverPending(driverID)
+	return l.Entries(func(e Entry) bool {
+		return e.Touches(wallet, pending)
+	})
+}
+
+// Earnings returns the payments a driver received from brokers, newest first
+func (l *Ledger) Earnings(driverID string) []Entry {
+	wallet, pending := DriverWallet(driverID), DriverPending(driverID)
+	return l.Entries(func(e Entry) bool {
+		return (e.Kind == KindAdvance || e.Kind == KindBalance) && e.Touches(wallet, pending)
+	})
+}
+
+// MonthStart returns the start of the calendar month containing t
+func MonthStart(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
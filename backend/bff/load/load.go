@@ -0,0 +1,77 @@
+package load
+
+import (
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotFound      = errors.New("load not found")
+	ErrMissingRoute  = errors.New("pickup and drop are required")
+	ErrMissingCargo  = errors.New("cargo and vehicle type are required")
+	ErrInvalidWindow = errors.New("bidding must stay open between 1 and 168 hours")
+)
+
+// Status of a posted load, as the loads screen shows it
+type Status string
+
+const (
+	StatusBiddingOpen  Status = "Bidding Open"
+	StatusBidsReceived Status = "Bids Received"
+	StatusAssigned     Status = "Driver Assigned"
+	StatusDelivered    Status = "Delivered"
+)
+
+// Tabs of the loads screen, in order
+var Tabs = []string{"Active Loads", "Pending Loads", "Completed Loads"}
+
+// Tab is the loads screen tab a load is listed under
+func (s Status) Tab() string {
+	switch s {
+	case StatusAssigned:
+		return Tabs[1]
+	case StatusDelivered:
+		return Tabs[2]
+	}
+	return Tabs[0]
+}
+
+// Load is freight a broker posts for drivers to bid on. It belongs to the
+// broker account, so everyone in the brokerage sees it.
+type Load struct {
+	ID           string        `json:"id"`
+	BrokerID     string        `json:"brokerId"`
+	PostedBy     string        `json:"postedBy"`
+	Pickup       string        `json:"pickup"`
+	Drop         string        `json:"drop"`
+	Cargo        string        `json:"cargoType"`
+	VehicleType  string        `json:"vehicleType"`
+	Budget       ledger.Amount `json:"budget"`
+	Status       Status        `json:"status"`
+	Bids         int           `json:"bids"`
+	DistanceKm   float64       `json:"distanceKm,omitempty"`
+	Weight       string        `json:"weight,omitempty"`
+	Dimensions   string        `json:"dimensions,omitempty"`
+	Notes        string        `json:"notes,omitempty"`
+	BiddingStart time.Time     `json:"biddingStart"`
+	BiddingEnd   time.Time     `json:"biddingEnd"`
+	At           time.Time     `json:"at"`
+}
+
+// PostRequest is a new load from the post load form
+type PostRequest struct {
+	Pickup      string
+	Drop        string
+	Cargo       string
+	VehicleType string
+	Budget      ledger.Amount
+	DistanceKm  float64
+	Weight      string
+	Dimensions  string
+	Notes       string
+	// BiddingHours is how long drivers can bid; zero means two days
+	BiddingHours int
+}
@@ -0,0 +1,33 @@
+package load
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/trip"
+	"time"
+)
+
+// seed posts the demo broker's loads; new loads are numbered after them
+func seed(s *Store) *Store {
+	hour := time.Hour
+	add := func(l Load, age time.Duration) {
+		l.BrokerID, l.PostedBy = trip.DemoBrokerID, trip.DemoBrokerID
+		l.At = now().Add(-age)
+		l.BiddingStart, l.BiddingEnd = l.At, l.At.Add(56*hour)
+		s.loads[l.ID] = &l
+	}
+
+	add(Load{ID: "LD-7890", Pickup: "Chennai Warehouse", Drop: "Kolkata Depot", Cargo: "Consumer Goods", VehicleType: "24ft Truck",
+		Budget: ledger.Rupees(32000), Status: StatusDelivered, DistanceKm: 1700, Weight: "18 Tons", Dimensions: "24x8x8 ft",
+		Notes: "Standard handling"}, 240*hour)
+	add(Load{ID: "LD-7891", Pickup: "Mumbai Warehouse", Drop: "Delhi Distribution Center", Cargo: "Electronics", VehicleType: "20ft Container Truck",
+		Budget: ledger.Rupees(45000), Status: StatusBiddingOpen, Bids: 5, DistanceKm: 1400, Weight: "15 Tons", Dimensions: "20x8x8 ft",
+		Notes: "Fragile items - Handle with care"}, 6*hour)
+	add(Load{ID: "LD-7892", Pickup: "Ahmedabad Factory", Drop: "Chennai Port", Cargo: "Textiles", VehicleType: "32ft Trailer",
+		Budget: ledger.Rupees(38000), Status: StatusBidsReceived, Bids: 3, DistanceKm: 1600, Weight: "22 Tons", Dimensions: "32x8x8 ft",
+		Notes: "Waterproof packaging required"}, 30*hour)
+	add(Load{ID: "LD-7893", Pickup: "Pune Industrial Area", Drop: "Bangalore Tech Park", Cargo: "Machinery Parts", VehicleType: "10ft Truck",
+		Budget: ledger.Rupees(25000), Status: StatusAssigned, DistanceKm: 850, Weight: "8 Tons", Dimensions: "10x6x6 ft",
+		Notes: "Heavy machinery - secure properly"}, 54*hour)
+	s.seq = 7893
+	return s
+}
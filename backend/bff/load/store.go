@@ -0,0 +1,113 @@
+package load
+
+import (
+	"backend/bff/events"
+	"backend/bff/ledger"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Store struct {
+	Events *events.Bus
+
+	mu    sync.Mutex
+	seq   int
+	loads map[string]*Load
+}
+
+func NewStore(bus *events.Bus) *Store {
+	return &Store{Events: bus, loads: map[string]*Load{}}
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore(events.Default))
+
+// Post opens a load for bidding on the broker account
+func (s *Store) Post(brokerID, postedBy string, req PostRequest) (Load, error) {
+	req.Pickup, req.Drop = strings.TrimSpace(req.Pickup), strings.TrimSpace(req.Drop)
+	req.Cargo, req.VehicleType = strings.TrimSpace(req.Cargo), strings.TrimSpace(req.VehicleType)
+	switch {
+	case req.Pickup == "" || req.Drop == "":
+		return Load{}, ErrMissingRoute
+	case req.Cargo == "" || req.VehicleType == "":
+		return Load{}, ErrMissingCargo
+	case req.Budget <= 0:
+		return Load{}, ledger.ErrInvalidAmount
+	case req.BiddingHours < 0 || req.BiddingHours > 168:
+		return Load{}, ErrInvalidWindow
+	}
+	if req.BiddingHours == 0 {
+		req.BiddingHours = 48
+	}
+
+	s.mu.Lock()
+	s.seq++
+	at := now()
+	l := &Load{
+		ID:           fmt.Sprintf("LD-%04d", s.seq),
+		BrokerID:     brokerID,
+		PostedBy:     postedBy,
+		Pickup:       req.Pickup,
+		Drop:         req.Drop,
+		Cargo:        req.Cargo,
+		VehicleType:  req.VehicleType,
+		Budget:       req.Budget,
+		Status:       StatusBiddingOpen,
+		DistanceKm:   req.DistanceKm,
+		Weight:       strings.TrimSpace(req.Weight),
+		Dimensions:   strings.TrimSpace(req.Dimensions),
+		Notes:        strings.TrimSpace(req.Notes),
+		BiddingStart: at,
+		BiddingEnd:   at.Add(time.Duration(req.BiddingHours) * time.Hour),
+		At:           at,
+	}
+	s.loads[l.ID] = l
+	out := *l
+	s.mu.Unlock()
+
+	// drivers with a matching truck hear about it while bidding is open
+	s.Events.Publish(events.Event{
+		Type:     events.LoadPosted,
+		Subject:  out.ID,
+		BrokerID: out.BrokerID,
+		Actor:    postedBy,
+		Data: map[string]string{
+			"loadId":      out.ID,
+			"origin":      out.Pickup,
+			"destination": out.Drop,
+			"vehicleType": out.VehicleType,
+			"weight":      out.Weight,
+			"amount":      out.Budget.String(),
+		},
+	})
+	return out, nil
+}
+
+func (s *Store) Get(id string) (Load, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.loads[id]
+	if !ok {
+		return Load{}, ErrNotFound
+	}
+	return *l, nil
+}
+
+// ForBroker lists the account's loads, newest first
+func (s *Store) ForBroker(brokerID string) []Load {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Load
+	for _, l := range s.loads {
+		if l.BrokerID == brokerID {
+			out = append(out, *l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	return out
+}
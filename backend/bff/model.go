@@ -28,6 +28,7 @@ type ViewData struct {
 	Top              int         `json:"top,omitempty"`
 	Horizontal    	 bool			`json:"horizontal,omitempty"`
 	MaxHeight        interface{} `json:"maxHeight,omitempty"`
+	MaxWidth         interface{} `json:"maxWidth,omitempty"`
 	ShowsHorizontalScrollIndicator bool `json:"showsHorizontalScrollIndicator,omitempty"`
 	Right            int         `json:"right,omitempty"`
 	FlexWrap         string      `json:"flexWrap,omitempty"`
@@ -175,6 +176,7 @@ type IconButtonData struct {
 	OnPress ActionData `json:"onPress"`
 	Size    int        `json:"size,omitempty"`
 	Color   string     `json:"color,omitempty"`
+	Badge   int        `json:"badge,omitempty"` // count shown over the icon
 }
 
 type CardData struct {
@@ -222,21 +224,79 @@ type IconSnippet struct {
 	Data IconData `json:"data"`
 }
 
+type LatLng struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type RegionData struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	LatitudeDelta  float64 `json:"latitudeDelta"`
+	LongitudeDelta float64 `json:"longitudeDelta"`
+}
+
+type MapData struct {
+	Style             ViewData `json:"style"`
+	ShowsUserLocation bool     `json:"showsUserLocation,omitempty"`
+	FitToElements     bool     `json:"fitToElements,omitempty"`
+}
+
+type PolylineData struct {
+	ID              string   `json:"id"`
+	Coordinates     []LatLng `json:"coordinates"`
+	StrokeColor     string   `json:"strokeColor,omitempty"`
+	StrokeWidth     int      `json:"strokeWidth,omitempty"`
+	LineDashPattern []int    `json:"lineDashPattern,omitempty"`
+}
+
+type MarkerData struct {
+	ID          string  `json:"id"`
+	Coordinate  LatLng  `json:"coordinate"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Icon        string  `json:"icon,omitempty"`
+	PinColor    string  `json:"pinColor,omitempty"`
+	Rotation    float64 `json:"rotation,omitempty"`
+}
+
+// ChartData draws a bar or line chart; every series has one value per label
+type ChartData struct {
+	Title  string        `json:"title,omitempty"`
+	Labels []string      `json:"labels"`
+	Series []ChartSeries `json:"series"`
+	Prefix string        `json:"prefix,omitempty"` // e.g. "₹" before axis values
+	Suffix string        `json:"suffix,omitempty"` // e.g. " km" after axis values
+	Height int           `json:"height,omitempty"`
+}
+
+type ChartSeries struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+	Color  string    `json:"color,omitempty"`
+}
+
 type HomeScreenData struct {
 	IsTripStarted     bool              `json:"isTripStarted"`
 	LocationSharing   bool              `json:"locationSharing"`
 	TripStatus        string            `json:"tripStatus"`
 	DocumentsUploaded map[string]bool   `json:"documentsUploaded"`
 	ActiveTrip        map[string]string `json:"activeTrip"`
+	NextStatuses      []string          `json:"nextStatuses,omitempty"`
 	QuickActions      []QuickAction     `json:"quickActions,omitempty"`
 	RecentActivities  []RecentActivity  `json:"recentActivities,omitempty"`
+	Metrics           map[string]string `json:"metrics,omitempty"`
+	Notifications     int               `json:"notifications"` // unread count for the bell badge
+	Messages          int               `json:"messages"`      // unread chat messages
+	Rating            string            `json:"rating"`        // from brokers' reviews, "New" until the first
 }
 
 type QuickAction struct {
-	ID    int    `json:"id"`
-	Icon  string `json:"icon"`
-	Title string `json:"title"`
-	Color string `json:"color"`
+	ID       int    `json:"id"`
+	Icon     string `json:"icon"`
+	Title    string `json:"title"`
+	Color    string `json:"color"`
+	Navigate string `json:"navigate,omitempty"` // screen to open instead of posting an action
 }
 
 type RecentActivity struct {
@@ -263,4 +323,13 @@ type RouteData struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
-}
\ No newline at end of file
+}
+// Join lays groups of snippets out one after another, so optional sections
+// can return nil and drop out of a screen instead of sending an empty snippet
+func Join(groups ...[]UISnippet) []UISnippet {
+	var out []UISnippet
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"backend/bff/events"
+	"backend/bff/trip"
+	"fmt"
+)
+
+// Alert kinds raised from domain events
+const (
+	KindTrip     = "trip"
+	KindLoad     = "load"
+	KindDocument = "document"
+	KindPayment  = "payment"
+	KindPOD      = "pod"
+	KindVehicle  = "vehicle"
+)
+
+// listen turns every event on the bus into alerts for the people it concerns
+func listen(s *Store, bus *events.Bus) *Store {
+	bus.Subscribe(func(e events.Event) {
+		for userID, a := range alerts(e) {
+			if userID == "" {
+				continue
+			}
+			a.Ref, a.At = e.Subject, e.At
+			s.Push(userID, a)
+		}
+	})
+	return s
+}
+
+// alerts words an event for each recipient, keyed by user ID
+func alerts(e events.Event) map[string]Alert {
+	d := e.Data
+	route := d["origin"] + " to " + d["destination"]
+	if d["origin"] == "" || d["destination"] == "" {
+		route = e.Subject
+	}
+
+	switch e.Type {
+	case events.TripAssigned:
+		return map[string]Alert{e.DriverID: {
+			Kind:    KindTrip,
+			Title:   "New trip assigned: " + route,
+			Message: fmt.Sprintf("Trip %s is on your list", e.Subject),
+		}}
+
+	case events.LoadAssigned:
+		return map[string]Alert{e.BrokerID: {
+			Kind:    KindLoad,
+			Title:   "Load " + e.Subject + " booked",
+			Message: fmt.Sprintf("%s is assigned to %s (%s)", route, driverName(d), d["tripId"]),
+		}}
+
+	case events.TripStatusChanged:
+		to := trip.Status(d["to"])
+		title := "Trip " + e.Subject + ": " + to.Label()
+		out := map[string]Alert{}
+		if e.Actor != trip.ActorDriver {
+			out[e.DriverID] = Alert{Kind: KindTrip, Title: title, Message: route + " is now " + to.Label()}
+		}
+		if e.Actor != trip.ActorBroker {
+			out[e.BrokerID] = Alert{Kind: KindTrip, Title: title, Message: fmt.Sprintf("%s: %s → %s", driverName(d), trip.Status(d["from"]).Label(), to.Label())}
+		}
+		return out
+
+	case events.DocumentUploaded:
+		return map[string]Alert{e.BrokerID: {
+			Kind:    KindDocument,
+			Title:   documentLabel(d["document"]) + " uploaded",
+			Message: fmt.Sprintf("%s for trip %s (%s)", documentLabel(d["document"]), e.Subject, route),
+		}}
+
+	case events.PaymentReleased:
+		return map[string]Alert{e.DriverID: {
+			Kind:    KindPayment,
+			Title:   "Payment received for trip " + d["tripId"],
+			Message: d["amount"] + " credited to wallet",
+		}}
+
+	case events.PODApproved:
+		return map[string]Alert{e.DriverID: {
+			Kind:    KindPOD,
+			Title:   "POD approved for trip " + d["tripId"],
+			Message: d["brokerName"] + " approved your proof of delivery; the balance payment is unlocked",
+		}}
+
+	case events.PODRejected:
+		return map[string]Alert{e.DriverID: {
+			Kind:    KindPOD,
+			Title:   "POD rejected for trip " + d["tripId"],
+			Message: d["reason"] + ". Please upload it again.",
+		}}
+
+	case events.VehicleServiceDue:
+		return map[string]Alert{e.DriverID: {
+			Kind:    KindVehicle,
+			Title:   d["title"],
+			Message: d["message"],
+		}}
+	}
+	return nil
+}
+
+func driverName(d map[string]string) string {
+	if d["driverName"] != "" {
+		return d["driverName"]
+	}
+	return "Driver"
+}
+
+func documentLabel(doc string) string {
+	switch doc {
+	case "eWayBill":
+		return "E-way bill"
+	case "invoice":
+		return "Invoice"
+	case "vehicleRC":
+		return "Vehicle RC"
+	case "driverLicense":
+		return "Driving licence"
+	case "insurance":
+		return "Insurance"
+	case "pollutionCert":
+		return "PUC certificate"
+	case "pod":
+		return "Proof of delivery"
+	}
+	return doc
+}
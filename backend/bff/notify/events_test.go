@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"backend/bff/events"
+	"backend/bff/trip"
+	"testing"
+)
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      events.Event
+		wantDriver string // title of the driver's alert, empty for none
+		wantBroker string
+	}{
+		{
+			name:       "trip assigned",
+			event:      events.Event{Type: events.TripAssigned, Subject: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Data: map[string]string{"origin": "Mumbai", "destination": "Pune"}},
+			wantDriver: "New trip assigned: Mumbai to Pune",
+		},
+		{
+			name:       "driver moved the trip",
+			event:      events.Event{Type: events.TripStatusChanged, Subject: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Actor: trip.ActorDriver, Data: map[string]string{"from": "loading", "to": "in_transit"}},
+			wantBroker: "Trip TRK1: " + trip.StatusInTransit.Label(),
+		},
+		{
+			name:       "broker moved the trip",
+			event:      events.Event{Type: events.TripStatusChanged, Subject: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Actor: trip.ActorBroker, Data: map[string]string{"from": "loading", "to": "cancelled"}},
+			wantDriver: "Trip TRK1: " + trip.StatusCancelled.Label(),
+		},
+		{
+			name:       "system moved the trip",
+			event:      events.Event{Type: events.TripStatusChanged, Subject: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Actor: trip.ActorSystem, Data: map[string]string{"from": "in_transit", "to": "reached_drop"}},
+			wantDriver: "Trip TRK1: " + trip.StatusReachedDrop.Label(),
+			wantBroker: "Trip TRK1: " + trip.StatusReachedDrop.Label(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := events.NewBus()
+			s := listen(NewStore(), bus)
+			e := bus.Publish(tt.event)
+
+			for user, want := range map[string]string{"DRV1": tt.wantDriver, "BRK1": tt.wantBroker} {
+				list := s.List(user)
+				if want == "" {
+					if len(list) != 0 {
+						t.Errorf("%s was alerted: %+v", user, list)
+					}
+					continue
+				}
+				if len(list) != 1 || list[0].Title != want || list[0].Ref != "TRK1" || !list[0].At.Equal(e.At) {
+					t.Errorf("%s alerts = %+v, want %q", user, list, want)
+				}
+			}
+		})
+	}
+}
+
+func TestListenReplaysEarlierEvents(t *testing.T) {
+	bus := events.NewBus()
+	bus.Publish(events.Event{Type: events.TripAssigned, Subject: "TRK1", DriverID: "DRV1"})
+
+	// a store that starts after the trip was assigned still raises its alert
+	s := listen(NewStore(), bus)
+	if got := s.Unread("DRV1"); got != 1 {
+		t.Errorf("%d unread, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+)
+
+// Badge returns the unread count for a user's notification bell
+func Badge(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"unread": Default.Unread(bff.UserID(c))},
+	})
+}
+
+// HandleAction marks notifications read: "markRead" with an id, or "markAllRead"
+func HandleAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	userID := bff.UserID(c)
+	switch req.Action {
+	case "markRead":
+		id, _ := req.Data["id"].(string)
+		a, err := Default.MarkRead(userID, id)
+		if err != nil {
+			c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status: "success",
+			Data:   gin.H{"notification": a, "unread": Default.Unread(userID)},
+		})
+
+	case "markAllRead":
+		n := Default.MarkAllRead(userID)
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: "All notifications marked as read",
+			Data:    gin.H{"marked": n, "unread": 0},
+		})
+
+	default:
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Unknown action: " + req.Action})
+	}
+}
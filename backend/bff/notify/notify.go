@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"backend/bff/events"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+var ErrNotFound = errors.New("notification not found")
+
+// Alert is a message shown to a driver or broker in the app
+type Alert struct {
+	ID      string    `json:"id"`
+	UserID  string    `json:"userId"`
+	Kind    string    `json:"kind"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Ref     string    `json:"ref,omitempty"` // trip, load or payment the alert opens
+	Read    bool      `json:"read"`
+	ReadAt  time.Time `json:"readAt,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+type Store struct {
+	mu     sync.RWMutex
+	seq    int
+	alerts map[string][]Alert
+}
+
+func NewStore() *Store {
+	return &Store{alerts: map[string][]Alert{}}
+}
+
+// Default store used by the BFF handlers, fed by the default event bus
+var Default = listen(NewStore(), events.Default)
+
+// Push records an alert for a user and returns it with its ID and time set
+func (s *Store) Push(userID string, a Alert) Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	a.ID = fmt.Sprintf("AL%06d", s.seq)
+	a.UserID = userID
+	if a.At.IsZero() {
+		a.At = now()
+	}
+	s.alerts[userID] = append(s.alerts[userID], a)
+	return a
+}
+
+// List returns a user's alerts, newest first
+func (s *Store) List(userID string) []Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.alerts[userID]
+	out := make([]Alert, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		out = append(out, list[i])
+	}
+	// replayed events can arrive out of time order
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	return out
+}
+
+// Unread counts the alerts a user has not opened yet
+func (s *Store) Unread(userID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, a := range s.alerts[userID] {
+		if !a.Read {
+			n++
+		}
+	}
+	return n
+}
+
+// MarkRead marks one of the user's alerts as read
+func (s *Store) MarkRead(userID, id string) (Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.alerts[userID]
+	for i := range list {
+		if list[i].ID == id {
+			if !list[i].Read {
+				list[i].Read, list[i].ReadAt = true, now()
+			}
+			return list[i], nil
+		}
+	}
+	return Alert{}, ErrNotFound
+}
+
+// MarkAllRead marks every alert of the user as read and returns how many changed
+func (s *Store) MarkAllRead(userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, at := 0, now()
+	list := s.alerts[userID]
+	for i := range list {
+		if !list[i].Read {
+			list[i].Read, list[i].ReadAt = true, at
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Store) Latest(userID string) (Alert, bool) {
+	list := s.List(userID)
+	if len(list) == 0 {
+		return Alert{}, false
+	}
+	return list[0], true
+}
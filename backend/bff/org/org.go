@@ -0,0 +1,264 @@
+package org
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotFound       = errors.New("organization not found")
+	ErrNotMember      = errors.New("not a member of this organization")
+	ErrForbidden      = errors.New("your role does not allow this")
+	ErrInvalidName    = errors.New("organization name is required")
+	ErrInvalidPhone   = errors.New("enter a 10 digit mobile number")
+	ErrInvalidRole    = errors.New("unknown role")
+	ErrNoRoles        = errors.New("choose at least one role")
+	ErrAlreadyMember  = errors.New("already a member of an organization")
+	ErrAlreadyInvited = errors.New("this number already has a pending invitation")
+	ErrInviteNotFound = errors.New("invitation not found")
+	ErrInviteClosed   = errors.New("invitation is no longer open")
+	ErrWrongPhone     = errors.New("invitation was sent to a different number")
+	ErrNoPhone        = errors.New("sign in with your mobile number to answer invitations")
+	ErrLastOwner      = errors.New("an organization needs at least one owner")
+)
+
+// How long an invitation stays open
+const InviteTTL = 7 * 24 * time.Hour
+
+// Role is a job in a brokerage; a member can hold several
+type Role string
+
+const (
+	RoleOwner      Role = "owner"
+	RoleDispatcher Role = "dispatcher"
+	RoleAccounts   Role = "accounts"
+	RoleViewer     Role = "viewer"
+)
+
+// Roles in the order the apps list them
+var Roles = []Role{RoleOwner, RoleDispatcher, RoleAccounts, RoleViewer}
+
+func (r Role) Valid() bool {
+	for _, x := range Roles {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) Label() string {
+	switch r {
+	case RoleOwner:
+		return "Owner"
+	case RoleDispatcher:
+		return "Dispatcher"
+	case RoleAccounts:
+		return "Accounts"
+	case RoleViewer:
+		return "Viewer"
+	}
+	return string(r)
+}
+
+func (r Role) plural() string {
+	if r == RoleAccounts {
+		return "accounts"
+	}
+	return strings.ToLower(r.Label()) + "s"
+}
+
+// Permission is an action only some roles may take; every member can view
+// the organization's loads, trucks and payments
+type Permission string
+
+const (
+	PermManageTeam     Permission = "manage_team"
+	PermPostLoad       Permission = "post_load"
+	PermManageTrucks   Permission = "manage_trucks"
+	PermManageTrips    Permission = "manage_trips"
+	PermReviewPOD      Permission = "review_pod"
+	PermReleasePayment Permission = "release_payment"
+	PermAddFunds       Permission = "add_funds"
+)
+
+var grants = map[Role][]Permission{
+	RoleOwner:      {PermManageTeam, PermAddFunds},
+	RoleDispatcher: {PermPostLoad, PermManageTrucks, PermManageTrips, PermReviewPOD},
+	RoleAccounts:   {PermReleasePayment, PermReviewPOD, PermAddFunds},
+	RoleViewer:     nil,
+}
+
+func (r Role) Can(p Permission) bool {
+	for _, x := range grants[r] {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) verb() string {
+	switch p {
+	case PermManageTeam:
+		return "manage the team"
+	case PermPostLoad:
+		return "post loads"
+	case PermManageTrucks:
+		return "manage trucks"
+	case PermManageTrips:
+		return "update lorry receipts and e-way bills"
+	case PermReviewPOD:
+		return "review PODs"
+	case PermReleasePayment:
+		return "release payments"
+	case PermAddFunds:
+		return "add funds"
+	}
+	return string(p)
+}
+
+// Rule explains who may take the action, e.g. "only accounts can release payments"
+func (p Permission) Rule() string {
+	var who []string
+	for _, r := range Roles {
+		if r.Can(p) {
+			who = append(who, r.plural())
+		}
+	}
+	return "only " + strings.Join(who, " and ") + " can " + p.verb()
+}
+
+// Org is a brokerage. Its loads, trucks, trips and payments belong to the
+// broker account it was created from, which every member works on.
+type Org struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	BrokerID  string    `json:"brokerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Member struct {
+	OrgID     string    `json:"orgId"`
+	UserID    string    `json:"userId"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Roles     []Role    `json:"roles"`
+	InvitedBy string    `json:"invitedBy,omitempty"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+func (m Member) Has(r Role) bool {
+	for _, x := range m.Roles {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Member) Can(p Permission) bool {
+	for _, r := range m.Roles {
+		if r.Can(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleText lists the member's roles, e.g. "Owner, Accounts"
+func (m Member) RoleText() string {
+	return roleText(m.Roles)
+}
+
+func (m Member) clone() Member {
+	m.Roles = append([]Role(nil), m.Roles...)
+	return m
+}
+
+type InviteStatus string
+
+const (
+	InvitePending  InviteStatus = "pending"
+	InviteAccepted InviteStatus = "accepted"
+	InviteDeclined InviteStatus = "declined"
+	InviteRevoked  InviteStatus = "revoked"
+	InviteExpired  InviteStatus = "expired"
+)
+
+// Invite asks whoever signs in with the phone number to join with the roles
+type Invite struct {
+	ID          string       `json:"id"`
+	OrgID       string       `json:"orgId"`
+	Phone       string       `json:"phone"`
+	Roles       []Role       `json:"roles"`
+	InvitedBy   string       `json:"invitedBy"`
+	Status      InviteStatus `json:"status"`
+	UserID      string       `json:"userId,omitempty"` // who accepted
+	At          time.Time    `json:"at"`
+	ExpiresAt   time.Time    `json:"expiresAt"`
+	RespondedAt time.Time    `json:"respondedAt,omitempty"`
+}
+
+func (i Invite) RoleText() string {
+	return roleText(i.Roles)
+}
+
+func (i Invite) clone() Invite {
+	i.Roles = append([]Role(nil), i.Roles...)
+	return i
+}
+
+func roleText(roles []Role) string {
+	var labels []string
+	for _, r := range roles {
+		labels = append(labels, r.Label())
+	}
+	return strings.Join(labels, ", ")
+}
+
+// roles checks and orders a role list, dropping repeats
+func roles(in []Role) ([]Role, error) {
+	if len(in) == 0 {
+		return nil, ErrNoRoles
+	}
+	seen := map[Role]bool{}
+	for _, r := range in {
+		if !r.Valid() {
+			return nil, ErrInvalidRole
+		}
+		seen[r] = true
+	}
+	var out []Role
+	for _, r := range Roles {
+		if seen[r] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// NormalizePhone turns "98200 11223", "+91 98200-11223" or "919820011223"
+// into "+919820011223"
+func NormalizePhone(phone string) (string, error) {
+	var d []rune
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			d = append(d, r)
+		}
+	}
+	s := string(d)
+	switch {
+	case len(s) == 12 && strings.HasPrefix(s, "91"):
+		s = s[2:]
+	case len(s) == 11 && strings.HasPrefix(s, "0"):
+		s = s[1:]
+	}
+	if len(s) != 10 || s[0] < '6' {
+		return "", ErrInvalidPhone
+	}
+	return "+91" + s, nil
+}
@@ -0,0 +1,70 @@
+package org
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr error
+	}{
+		{phone: "98200 11223", want: "+919820011223"},
+		{phone: "+91 98200-11223", want: "+919820011223"},
+		{phone: "919820011223", want: "+919820011223"},
+		{phone: "09820011223", want: "+919820011223"},
+		{phone: "9820011", wantErr: ErrInvalidPhone},
+		{phone: "5820011223", wantErr: ErrInvalidPhone},
+		{phone: "", wantErr: ErrInvalidPhone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got, err := NormalizePhone(tt.phone)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tt.phone, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []Role
+		want    string
+		wantErr error
+	}{
+		{name: "ordered and without repeats", in: []Role{RoleAccounts, RoleOwner, RoleAccounts}, want: "Owner, Accounts"},
+		{name: "none", wantErr: ErrNoRoles},
+		{name: "unknown", in: []Role{RoleViewer, "admin"}, wantErr: ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := roles(tt.in)
+			if !errors.Is(err, tt.wantErr) || roleText(got) != tt.want {
+				t.Errorf("roles = %q, %v; want %q, %v", roleText(got), err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRule(t *testing.T) {
+	tests := []struct {
+		perm Permission
+		want string
+	}{
+		{PermReleasePayment, "only accounts can release payments"},
+		{PermReviewPOD, "only dispatchers and accounts can review PODs"},
+		{PermAddFunds, "only owners and accounts can add funds"},
+		{PermManageTeam, "only owners can manage the team"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.perm), func(t *testing.T) {
+			if got := tt.perm.Rule(); got != tt.want {
+				t.Errorf("Rule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
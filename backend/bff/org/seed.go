@@ -0,0 +1,48 @@
+package org
+
+import (
+	"backend/bff/trip"
+	"time"
+)
+
+// seed puts the demo broker at the head of a small brokerage with a
+// dispatcher, an accountant and one invitation waiting
+func seed(s *Store) *Store {
+	o, err := s.Create("Kumar Logistics Solutions", Member{
+		UserID: trip.DemoBrokerID,
+		Name:   "Rajesh Kumar",
+		Phone:  "+919123456789",
+		Roles:  []Role{RoleOwner, RoleDispatcher, RoleAccounts},
+	})
+	if err != nil {
+		return s
+	}
+
+	day := 24 * time.Hour
+	join := func(userID, name, phone string, rs []Role, ago time.Duration) {
+		s.phones[userID] = phone
+		s.members[userID] = &Member{
+			OrgID:     o.ID,
+			UserID:    userID,
+			Name:      name,
+			Phone:     phone,
+			Roles:     rs,
+			InvitedBy: o.BrokerID,
+			JoinedAt:  now().Add(-ago),
+		}
+	}
+
+	s.mu.Lock()
+	s.phones[o.BrokerID] = s.members[o.BrokerID].Phone
+	s.orgs[o.ID].CreatedAt = now().Add(-400 * day)
+	s.members[o.BrokerID].JoinedAt = s.orgs[o.ID].CreatedAt
+	join("BRK004", "Anil Mehta", "+919820011223", []Role{RoleDispatcher}, 200*day)
+	join("BRK005", "Priya Nair", "+919820044556", []Role{RoleAccounts}, 90*day)
+	join("BRK006", "Sunil Rao", "+919820066778", []Role{RoleViewer}, 30*day)
+	s.mu.Unlock()
+
+	// a broker who has signed in but not yet answered their invitation
+	s.Invite(o.BrokerID, "+919820077889", []Role{RoleDispatcher})
+	s.SignIn("BRK007", "+919820077889")
+	return s
+}
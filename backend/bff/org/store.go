@@ -0,0 +1,419 @@
+package org
+
+import (
+	"backend/bff/events"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store holds brokerages, their members and open invitations. A user
+// belongs to at most one organization; brokers outside any organization
+// work alone with every permission, as a single login always has.
+type Store struct {
+	Events *events.Bus
+
+	mu      sync.Mutex
+	seq     int
+	orgs    map[string]*Org
+	members map[string]*Member // by user
+	invites map[string]*Invite
+	phones  map[string]string // number each user signed in with, by user
+}
+
+func NewStore(bus *events.Bus) *Store {
+	return &Store{
+		Events:  bus,
+		orgs:    map[string]*Org{},
+		members: map[string]*Member{},
+		invites: map[string]*Invite{},
+		phones:  map[string]string{},
+	}
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore(events.Default))
+
+// Create starts an organization on the owner's broker account
+func (s *Store) Create(name string, owner Member) (Org, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Org{}, ErrInvalidName
+	}
+	phone, err := NormalizePhone(owner.Phone)
+	if err != nil {
+		return Org{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[owner.UserID]; ok {
+		return Org{}, ErrAlreadyMember
+	}
+	s.seq++
+	o := &Org{ID: fmt.Sprintf("ORG%03d", s.seq), Name: name, BrokerID: owner.UserID, CreatedAt: now()}
+	s.orgs[o.ID] = o
+
+	owner.Roles, _ = roles(append(owner.Roles, RoleOwner))
+	owner.OrgID, owner.Phone, owner.JoinedAt = o.ID, phone, o.CreatedAt
+	s.members[owner.UserID] = &owner
+	return *o, nil
+}
+
+func (s *Store) Get(orgID string) (Org, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orgs[orgID]
+	if !ok {
+		return Org{}, ErrNotFound
+	}
+	return *o, nil
+}
+
+// Of returns the user's organization and membership
+func (s *Store) Of(userID string) (Org, Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.members[userID]
+	if !ok {
+		return Org{}, Member{}, ErrNotMember
+	}
+	return *s.orgs[m.OrgID], m.clone(), nil
+}
+
+// Account is the broker account the user works on: their organization's,
+// or their own when they are not in one
+func (s *Store) Account(userID string) string {
+	if o, _, err := s.Of(userID); err == nil {
+		return o.BrokerID
+	}
+	return userID
+}
+
+// Members lists an organization's members, longest standing first
+func (s *Store) Members(orgID string) []Member {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Member
+	for _, m := range s.members {
+		if m.OrgID == orgID {
+			out = append(out, m.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].JoinedAt.Equal(out[j].JoinedAt) {
+			return out[i].UserID < out[j].UserID
+		}
+		return out[i].JoinedAt.Before(out[j].JoinedAt)
+	})
+	return out
+}
+
+// Check returns ErrForbidden, with the rule, unless the user may take the action
+func (s *Store) Check(userID string, p Permission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.members[userID]
+	if !ok || m.Can(p) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrForbidden, p.Rule())
+}
+
+// Invite asks a phone number to join the actor's organization
+func (s *Store) Invite(actorID, phone string, rs []Role) (Invite, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return Invite{}, err
+	}
+	rs, err = roles(rs)
+	if err != nil {
+		return Invite{}, err
+	}
+
+	s.mu.Lock()
+	actor, err := s.manager(actorID)
+	if err != nil {
+		s.mu.Unlock()
+		return Invite{}, err
+	}
+	for _, m := range s.members {
+		if m.OrgID == actor.OrgID && m.Phone == phone {
+			s.mu.Unlock()
+			return Invite{}, ErrAlreadyMember
+		}
+	}
+	for _, i := range s.invites {
+		if i.OrgID == actor.OrgID && i.Phone == phone && s.open(i) {
+			s.mu.Unlock()
+			return Invite{}, ErrAlreadyInvited
+		}
+	}
+
+	s.seq++
+	at := now()
+	i := &Invite{
+		ID:        fmt.Sprintf("INV%05d", s.seq),
+		OrgID:     actor.OrgID,
+		Phone:     phone,
+		Roles:     rs,
+		InvitedBy: actorID,
+		Status:    InvitePending,
+		At:        at,
+		ExpiresAt: at.Add(InviteTTL),
+	}
+	s.invites[i.ID] = i
+	o := *s.orgs[i.OrgID]
+	out := i.clone()
+	s.mu.Unlock()
+
+	// the invitee may not have an account yet, so the SMS goes to the number
+	s.Events.Publish(events.Event{
+		Type:     events.MemberInvited,
+		Subject:  out.ID,
+		BrokerID: o.BrokerID,
+		Actor:    actorID,
+		Data: map[string]string{
+			"phone":   out.Phone,
+			"orgName": o.Name,
+			"inviter": actor.Name,
+			"roles":   out.RoleText(),
+		},
+	})
+	return out, nil
+}
+
+// Invites lists an organization's invitations, newest first
+func (s *Store) Invites(orgID string) []Invite {
+	return s.listInvites(func(i *Invite) bool { return i.OrgID == orgID })
+}
+
+// InvitesFor lists the open invitations sent to a phone number
+func (s *Store) InvitesFor(phone string) []Invite {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return nil
+	}
+	return s.listInvites(func(i *Invite) bool { return i.Phone == phone && i.Status == InvitePending })
+}
+
+// SignIn records the number a user proved they own at sign-in; invitations
+// to that number are theirs to answer
+func (s *Store) SignIn(userID, phone string) error {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.phones[userID] = phone
+	return nil
+}
+
+// Phone is the number the user signed in with, or "" if they have not
+func (s *Store) Phone(userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.phones[userID]
+}
+
+// Accept joins the user to the organization; they must have signed in with
+// the invited number
+func (s *Store) Accept(inviteID, userID, name string) (Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.respond(inviteID, userID)
+	if err != nil {
+		return Member{}, err
+	}
+	if _, ok := s.members[userID]; ok {
+		return Member{}, ErrAlreadyMember
+	}
+
+	at := now()
+	i.Status, i.UserID, i.RespondedAt = InviteAccepted, userID, at
+	m := &Member{
+		OrgID:     i.OrgID,
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Phone:     i.Phone,
+		Roles:     append([]Role(nil), i.Roles...),
+		InvitedBy: i.InvitedBy,
+		JoinedAt:  at,
+	}
+	s.members[userID] = m
+	return m.clone(), nil
+}
+
+// Decline turns an invitation down; like Accept, only the user signed in
+// with the invited number may
+func (s *Store) Decline(inviteID, userID string) (Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.respond(inviteID, userID)
+	if err != nil {
+		return Invite{}, err
+	}
+	i.Status, i.RespondedAt = InviteDeclined, now()
+	return i.clone(), nil
+}
+
+// Revoke withdraws an open invitation
+func (s *Store) Revoke(actorID, inviteID string) (Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actor, err := s.manager(actorID)
+	if err != nil {
+		return Invite{}, err
+	}
+	i, ok := s.invites[inviteID]
+	if !ok || i.OrgID != actor.OrgID {
+		return Invite{}, ErrInviteNotFound
+	}
+	if !s.open(i) {
+		return Invite{}, ErrInviteClosed
+	}
+	i.Status, i.RespondedAt = InviteRevoked, now()
+	return i.clone(), nil
+}
+
+// SetRoles replaces a member's roles
+func (s *Store) SetRoles(actorID, userID string, rs []Role) (Member, error) {
+	rs, err := roles(rs)
+	if err != nil {
+		return Member{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.colleague(actorID, userID)
+	if err != nil {
+		return Member{}, err
+	}
+	keepsOwner := false
+	for _, r := range rs {
+		keepsOwner = keepsOwner || r == RoleOwner
+	}
+	if m.Has(RoleOwner) && !keepsOwner && s.owners(m.OrgID) == 1 {
+		return Member{}, ErrLastOwner
+	}
+	m.Roles = rs
+	return m.clone(), nil
+}
+
+// Remove takes a member out of the organization; members may also leave
+// on their own
+func (s *Store) Remove(actorID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var m *Member
+	if actorID == userID {
+		var ok bool
+		if m, ok = s.members[userID]; !ok {
+			return ErrNotMember
+		}
+	} else {
+		var err error
+		if m, err = s.colleague(actorID, userID); err != nil {
+			return err
+		}
+	}
+	if m.Has(RoleOwner) && s.owners(m.OrgID) == 1 {
+		return ErrLastOwner
+	}
+	delete(s.members, userID)
+	return nil
+}
+
+// manager returns the actor's membership when they may manage the team
+func (s *Store) manager(actorID string) (*Member, error) {
+	actor, ok := s.members[actorID]
+	if !ok {
+		return nil, ErrNotMember
+	}
+	if !actor.Can(PermManageTeam) {
+		return nil, fmt.Errorf("%w: %s", ErrForbidden, PermManageTeam.Rule())
+	}
+	return actor, nil
+}
+
+// colleague returns a member of the actor's organization the actor manages
+func (s *Store) colleague(actorID, userID string) (*Member, error) {
+	actor, err := s.manager(actorID)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := s.members[userID]
+	if !ok || m.OrgID != actor.OrgID {
+		return nil, ErrNotMember
+	}
+	return m, nil
+}
+
+// respond finds an open invitation addressed to the number the user
+// signed in with
+func (s *Store) respond(inviteID, userID string) (*Invite, error) {
+	i, ok := s.invites[inviteID]
+	if !ok {
+		return nil, ErrInviteNotFound
+	}
+	phone, ok := s.phones[userID]
+	if !ok {
+		return nil, ErrNoPhone
+	}
+	if phone != i.Phone {
+		return nil, ErrWrongPhone
+	}
+	if !s.open(i) {
+		return nil, ErrInviteClosed
+	}
+	return i, nil
+}
+
+// open reports whether an invitation can still be answered, expiring it
+// once its time is up
+func (s *Store) open(i *Invite) bool {
+	if i.Status == InvitePending && !now().Before(i.ExpiresAt) {
+		i.Status, i.RespondedAt = InviteExpired, i.ExpiresAt
+	}
+	return i.Status == InvitePending
+}
+
+func (s *Store) owners(orgID string) int {
+	n := 0
+	for _, m := range s.members {
+		if m.OrgID == orgID && m.Has(RoleOwner) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Store) listInvites(match func(*Invite) bool) []Invite {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Invite
+	for _, i := range s.invites {
+		s.open(i)
+		if match(i) {
+			out = append(out, i.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out
+}
@@ -0,0 +1,185 @@
+package org
+
+import (
+	"backend/bff/events"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestStore is an organization owned by BRK1 with a member in each
+// other role, all of whom signed in with their numbers
+func newTestStore(t *testing.T) *Store {
+	s := NewStore(events.NewBus())
+	if _, err := s.Create("Acme Logistics", Member{UserID: "BRK1", Name: "Owner", Phone: "9820000001"}); err != nil {
+		t.Fatal(err)
+	}
+	team := []struct {
+		user  string
+		phone string
+		role  Role
+	}{
+		{"BRK2", "9820000002", RoleDispatcher},
+		{"BRK3", "9820000003", RoleAccounts},
+		{"BRK4", "9820000004", RoleViewer},
+	}
+	for _, m := range team {
+		i, err := s.Invite("BRK1", m.phone, []Role{m.role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.SignIn(m.user, m.phone)
+		if _, err := s.Accept(i.ID, m.user, m.user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	s := newTestStore(t)
+	tests := []struct {
+		name    string
+		user    string
+		perm    Permission
+		wantErr error
+	}{
+		{name: "owner manages the team", user: "BRK1", perm: PermManageTeam},
+		{name: "dispatcher posts loads", user: "BRK2", perm: PermPostLoad},
+		{name: "dispatcher can't release payments", user: "BRK2", perm: PermReleasePayment, wantErr: ErrForbidden},
+		{name: "accounts release payments", user: "BRK3", perm: PermReleasePayment},
+		{name: "viewer can't post loads", user: "BRK4", perm: PermPostLoad, wantErr: ErrForbidden},
+		{name: "a broker working alone can do anything", user: "BRK9", perm: PermReleasePayment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Check(tt.user, tt.perm)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.perm.Rule()) {
+				t.Errorf("error %q does not say who may", err)
+			}
+		})
+	}
+}
+
+func TestInvite(t *testing.T) {
+	s := newTestStore(t)
+	var sent []events.Event
+	s.Events.Follow(func(e events.Event) { sent = append(sent, e) })
+
+	tests := []struct {
+		name    string
+		actor   string
+		phone   string
+		roles   []Role
+		wantErr error
+	}{
+		{name: "new dispatcher", actor: "BRK1", phone: "+91 98200 00009", roles: []Role{RoleDispatcher}},
+		{name: "invited again", actor: "BRK1", phone: "9820000009", roles: []Role{RoleViewer}, wantErr: ErrAlreadyInvited},
+		{name: "already a member", actor: "BRK1", phone: "9820000002", roles: []Role{RoleViewer}, wantErr: ErrAlreadyMember},
+		{name: "not a phone number", actor: "BRK1", phone: "12345", roles: []Role{RoleViewer}, wantErr: ErrInvalidPhone},
+		{name: "no roles", actor: "BRK1", phone: "9820000008", wantErr: ErrNoRoles},
+		{name: "only owners invite", actor: "BRK2", phone: "9820000008", roles: []Role{RoleViewer}, wantErr: ErrForbidden},
+		{name: "outsider", actor: "BRK9", phone: "9820000008", roles: []Role{RoleViewer}, wantErr: ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Invite(tt.actor, tt.phone, tt.roles); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the SMS goes out once, to the number as invited
+	if len(sent) != 1 || sent[0].Type != events.MemberInvited || sent[0].Data["phone"] != "+919820000009" {
+		t.Errorf("events = %+v, want one invitation to +919820000009", sent)
+	}
+	if got := s.InvitesFor("98200 00009"); len(got) != 1 {
+		t.Errorf("invites for the number = %+v, want one", got)
+	}
+}
+
+func TestAccept(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name    string
+		signIn  string
+		after   time.Duration
+		revoke  bool
+		wantErr error
+	}{
+		{name: "signed in with the invited number", signIn: "98200 00009"},
+		{name: "on the last day", signIn: "9820000009", after: InviteTTL - time.Minute},
+		{name: "not signed in", wantErr: ErrNoPhone},
+		{name: "someone else's number", signIn: "9820000008", wantErr: ErrWrongPhone},
+		{name: "expired", signIn: "9820000009", after: InviteTTL, wantErr: ErrInviteClosed},
+		{name: "revoked", signIn: "9820000009", revoke: true, wantErr: ErrInviteClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return at }
+			s := newTestStore(t)
+			i, err := s.Invite("BRK1", "9820000009", []Role{RoleAccounts, RoleDispatcher})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.signIn != "" {
+				s.SignIn("BRK9", tt.signIn)
+			}
+			if tt.revoke {
+				s.Revoke("BRK1", i.ID)
+			}
+			now = func() time.Time { return at.Add(tt.after) }
+
+			m, err := s.Accept(i.ID, "BRK9", "New Hire")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if s.Account("BRK9") != "BRK9" {
+					t.Errorf("account = %s, want their own", s.Account("BRK9"))
+				}
+				return
+			}
+			if m.RoleText() != "Dispatcher, Accounts" || s.Account("BRK9") != "BRK1" {
+				t.Errorf("joined as %q on %s, want Dispatcher, Accounts on BRK1", m.RoleText(), s.Account("BRK9"))
+			}
+			if _, err := s.Accept(i.ID, "BRK9", "New Hire"); !errors.Is(err, ErrInviteClosed) {
+				t.Errorf("accepting twice: error = %v, want %v", err, ErrInviteClosed)
+			}
+		})
+	}
+}
+
+func TestLastOwner(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.SetRoles("BRK1", "BRK1", []Role{RoleDispatcher}); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("stepping down: error = %v, want %v", err, ErrLastOwner)
+	}
+	if err := s.Remove("BRK1", "BRK1"); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("leaving: error = %v, want %v", err, ErrLastOwner)
+	}
+
+	// with a second owner the first may step down
+	if _, err := s.SetRoles("BRK1", "BRK3", []Role{RoleOwner, RoleAccounts}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetRoles("BRK1", "BRK1", []Role{RoleDispatcher}); err != nil {
+		t.Errorf("stepping down: %v", err)
+	}
+	if err := s.Check("BRK1", PermManageTeam); !errors.Is(err, ErrForbidden) {
+		t.Errorf("former owner: error = %v, want %v", err, ErrForbidden)
+	}
+	if err := s.Remove("BRK3", "BRK2"); err != nil {
+		t.Errorf("removing a dispatcher: %v", err)
+	}
+	if len(s.Members("ORG001")) != 3 {
+		t.Errorf("members = %+v, want three left", s.Members("ORG001"))
+	}
+}
@@ -0,0 +1,112 @@
+package payments
+
+import (
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrUnknownTransfer  = errors.New("unknown transfer")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrNoSecret         = errors.New("webhooks are refused until a signing secret is configured")
+	ErrInvalidRequest   = errors.New("transfer needs a party, an account and a positive amount")
+	ErrUnknownMethod    = errors.New("unsupported payment method")
+	ErrInsufficientFund = ledger.ErrInsufficientFund
+)
+
+type Method string
+
+const (
+	MethodUPI  Method = "upi"
+	MethodIMPS Method = "imps"
+	MethodNEFT Method = "neft"
+)
+
+func (m Method) Valid() bool {
+	return m == MethodUPI || m == MethodIMPS || m == MethodNEFT
+}
+
+// Label is how the method is shown to users ("UPI", "Bank Transfer")
+func (m Method) Label() string {
+	switch m {
+	case MethodUPI:
+		return "UPI"
+	case MethodIMPS:
+		return "IMPS"
+	}
+	return "Bank Transfer"
+}
+
+type Direction string
+
+const (
+	// Payout sends money from the platform to a driver's bank account or UPI ID
+	DirectionPayout Direction = "payout"
+	// Collection pulls money from a broker into the platform
+	DirectionCollection Direction = "collection"
+)
+
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateFailed  State = "failed"
+)
+
+func (s State) Final() bool {
+	return s == StateSuccess || s == StateFailed
+}
+
+// Request asks a provider to move money
+type Request struct {
+	// Our reference; providers treat a repeated reference as the same transfer
+	Reference string
+	Direction Direction
+	Method    Method
+	Amount    ledger.Amount
+	// Driver or broker the money belongs to
+	Party string
+	// UPI ID or bank account the money moves to or from
+	Account string
+	Note    string
+}
+
+// Transfer is a provider's view of a payout or collection
+type Transfer struct {
+	ID        string        `json:"id"`
+	Reference string        `json:"reference"`
+	Provider  string        `json:"provider"`
+	Direction Direction     `json:"direction"`
+	Method    Method        `json:"method"`
+	Amount    ledger.Amount `json:"amount"`
+	Party     string        `json:"party"`
+	Account   string        `json:"account"`
+	Note      string        `json:"note,omitempty"`
+	State     State         `json:"state"`
+	Reason    string        `json:"reason,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Callback is a provider webhook reporting a transfer's state
+type Callback struct {
+	EventID    string    `json:"eventId"`
+	TransferID string    `json:"transferId"`
+	Reference  string    `json:"reference"`
+	State      State     `json:"state"`
+	Reason     string    `json:"reason,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Provider is a payment gateway able to run UPI, IMPS and NEFT transfers
+type Provider interface {
+	Name() string
+	Initiate(req Request) (Transfer, error)
+	Status(transferID string) (Transfer, error)
+	// ParseWebhook verifies a callback body against its signature header
+	ParseWebhook(body []byte, signature string) (Callback, error)
+}
@@ -0,0 +1,171 @@
+package payments
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Hook runs once when a transfer reaches success or failure
+type Hook func(t Transfer)
+
+// Reconciler keeps our record of every transfer in step with the provider.
+// Callbacks may arrive late, out of order or more than once; a transfer only
+// ever moves from pending to a final state and hooks run exactly once.
+type Reconciler struct {
+	Provider Provider
+
+	mu        sync.Mutex
+	seq       int
+	transfers map[string]*Transfer // by reference
+	seen      map[string]bool      // callback event IDs already applied
+	hooks     []Hook
+}
+
+func NewReconciler(p Provider) *Reconciler {
+	return &Reconciler{
+		Provider:  p,
+		transfers: map[string]*Transfer{},
+		seen:      map[string]bool{},
+	}
+}
+
+func (r *Reconciler) OnFinal(h Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, h)
+}
+
+// Initiate sends a transfer to the provider; a reference already in use returns the earlier transfer
+func (r *Reconciler) Initiate(req Request) (Transfer, error) {
+	r.mu.Lock()
+	if req.Reference == "" {
+		r.seq++
+		req.Reference = fmt.Sprintf("PAY%06d", r.seq)
+	}
+	if t, ok := r.transfers[req.Reference]; ok {
+		r.mu.Unlock()
+		return *t, nil
+	}
+	r.mu.Unlock()
+
+	t, err := r.Provider.Initiate(req)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	r.mu.Lock()
+	if existing, ok := r.transfers[t.Reference]; ok {
+		// another Initiate with this reference was recorded while the
+		// provider call was in flight; keep that one
+		r.mu.Unlock()
+		return *existing, nil
+	}
+	final := t.State
+	t.State = StatePending
+	r.transfers[t.Reference] = &t
+	r.mu.Unlock()
+
+	if final.Final() {
+		t, _ = r.settle(t.Reference, final, t.Reason)
+	}
+	return t, nil
+}
+
+// Apply reconciles a webhook callback and reports whether it changed anything
+func (r *Reconciler) Apply(cb Callback) (Transfer, bool, error) {
+	r.mu.Lock()
+	t, ok := r.transfers[cb.Reference]
+	if !ok {
+		r.mu.Unlock()
+		return Transfer{}, false, ErrUnknownTransfer
+	}
+	if cb.EventID != "" && r.seen[cb.EventID] {
+		current := *t
+		r.mu.Unlock()
+		return current, false, nil
+	}
+	if cb.EventID != "" {
+		r.seen[cb.EventID] = true
+	}
+	r.mu.Unlock()
+
+	if !cb.State.Final() {
+		current, err := r.Get(cb.Reference)
+		return current, false, err
+	}
+	settled, changed := r.settle(cb.Reference, cb.State, cb.Reason)
+	return settled, changed, nil
+}
+
+// Sync asks the provider for a transfer's state, for when a webhook never arrives
+func (r *Reconciler) Sync(reference string) (Transfer, error) {
+	t, err := r.Get(reference)
+	if err != nil || t.State.Final() {
+		return t, err
+	}
+	latest, err := r.Provider.Status(t.ID)
+	if err != nil {
+		return t, err
+	}
+	if latest.State.Final() {
+		t, _ = r.settle(reference, latest.State, latest.Reason)
+	}
+	return t, nil
+}
+
+func (r *Reconciler) Get(reference string) (Transfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.transfers[reference]
+	if !ok {
+		return Transfer{}, ErrUnknownTransfer
+	}
+	return *t, nil
+}
+
+// List returns matching transfers
+func (r *Reconciler) List(match func(Transfer) bool) []Transfer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []Transfer
+	for _, t := range r.transfers {
+		if match == nil || match(*t) {
+			out = append(out, *t)
+		}
+	}
+	return out
+}
+
+// settle moves a pending transfer to a final state and runs the hooks; anything else is a no-op
+func (r *Reconciler) settle(reference string, state State, reason string) (Transfer, bool) {
+	r.mu.Lock()
+	t, ok := r.transfers[reference]
+	if !ok || t.State.Final() {
+		var current Transfer
+		if ok {
+			current = *t
+		}
+		r.mu.Unlock()
+		return current, false
+	}
+	t.State, t.Reason, t.UpdatedAt = state, reason, now()
+	settled := *t
+	hooks := append([]Hook(nil), r.hooks...)
+	r.mu.Unlock()
+
+	for _, h := range hooks {
+		h(settled)
+	}
+	return settled, true
+}
+
+// HandleWebhook verifies a provider callback and reconciles it
+func (r *Reconciler) HandleWebhook(body []byte, signature string) (Transfer, bool, error) {
+	cb, err := r.Provider.ParseWebhook(body, signature)
+	if err != nil {
+		return Transfer{}, false, err
+	}
+	return r.Apply(cb)
+}
@@ -0,0 +1,160 @@
+package payments
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/notify"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestSandbox leaves pending transfers pending and sends no webhooks, so
+// tests deliver every callback themselves
+func newTestSandbox() *Sandbox {
+	s := NewSandbox("s3cret")
+	s.SettleAfter = time.Hour
+	return s
+}
+
+func TestApply(t *testing.T) {
+	type callback struct {
+		event       string
+		state       State
+		wantApplied bool
+	}
+	tests := []struct {
+		name      string
+		callbacks []callback
+		wantState State
+		wantHooks int
+	}{
+		{
+			name:      "settled once",
+			callbacks: []callback{{"EVT1", StateSuccess, true}},
+			wantState: StateSuccess,
+			wantHooks: 1,
+		},
+		{
+			name:      "redelivered event",
+			callbacks: []callback{{"EVT1", StateSuccess, true}, {"EVT1", StateSuccess, false}},
+			wantState: StateSuccess,
+			wantHooks: 1,
+		},
+		{
+			name:      "pending arriving after the result",
+			callbacks: []callback{{"EVT2", StateFailed, true}, {"EVT1", StatePending, false}},
+			wantState: StateFailed,
+			wantHooks: 1,
+		},
+		{
+			name:      "conflicting results keep the first",
+			callbacks: []callback{{"EVT1", StateFailed, true}, {"EVT2", StateSuccess, false}},
+			wantState: StateFailed,
+			wantHooks: 1,
+		},
+		{
+			name:      "still pending",
+			callbacks: []callback{{"EVT1", StatePending, false}},
+			wantState: StatePending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReconciler(newTestSandbox())
+			hooks := 0
+			r.OnFinal(func(Transfer) { hooks++ })
+
+			tr, err := r.Initiate(Request{Direction: DirectionPayout, Method: MethodUPI, Amount: ledger.Rupees(500), Party: "DRV1", Account: "pending@upi"})
+			if err != nil || tr.State != StatePending {
+				t.Fatalf("Initiate = %s, %v; want pending", tr.State, err)
+			}
+			for i, cb := range tt.callbacks {
+				_, applied, err := r.Apply(Callback{EventID: cb.event, Reference: tr.Reference, State: cb.state})
+				if err != nil || applied != cb.wantApplied {
+					t.Errorf("callback %d: applied %v, %v; want %v", i, applied, err, cb.wantApplied)
+				}
+			}
+			if got, _ := r.Get(tr.Reference); got.State != tt.wantState {
+				t.Errorf("state = %s, want %s", got.State, tt.wantState)
+			}
+			if hooks != tt.wantHooks {
+				t.Errorf("hooks ran %d times, want %d", hooks, tt.wantHooks)
+			}
+		})
+	}
+}
+
+func TestApplyUnknownTransfer(t *testing.T) {
+	r := NewReconciler(newTestSandbox())
+	if _, _, err := r.Apply(Callback{EventID: "EVT1", Reference: "PAY999999", State: StateSuccess}); !errors.Is(err, ErrUnknownTransfer) {
+		t.Errorf("error = %v, want %v", err, ErrUnknownTransfer)
+	}
+}
+
+func TestInitiateRepeatedReference(t *testing.T) {
+	r := NewReconciler(newTestSandbox())
+	req := Request{Reference: "WD000001", Direction: DirectionPayout, Method: MethodIMPS, Amount: ledger.Rupees(500), Party: "DRV1", Account: "1234567890"}
+	first, err := r.Initiate(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.Initiate(req)
+	if err != nil || second.ID != first.ID {
+		t.Errorf("second Initiate = %s, %v; want the first transfer %s", second.ID, err, first.ID)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	tests := []struct {
+		name          string
+		account       string
+		amount        ledger.Amount
+		result        State // delivered by webhook, twice
+		wantErr       error
+		wantAvailable ledger.Amount
+		wantAlert     string
+	}{
+		{name: "paid out at once", account: "driver@upi", amount: ledger.Rupees(3000), wantAvailable: ledger.Rupees(2000), wantAlert: "Withdrawal successful"},
+		{name: "rejected at once", account: "fail@upi", amount: ledger.Rupees(3000), wantAvailable: ledger.Rupees(5000), wantAlert: "Withdrawal failed"},
+		{name: "paid out later", account: "pending@upi", amount: ledger.Rupees(3000), result: StateSuccess, wantAvailable: ledger.Rupees(2000), wantAlert: "Withdrawal successful"},
+		{name: "refunded once when it fails later", account: "pending@upi", amount: ledger.Rupees(3000), result: StateFailed, wantAvailable: ledger.Rupees(5000), wantAlert: "Withdrawal failed"},
+		{name: "more than the wallet", account: "driver@upi", amount: ledger.Rupees(5001), wantErr: ErrInsufficientFund, wantAvailable: ledger.Rupees(5000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := ledger.New()
+			alerts := notify.NewStore()
+			s := NewService(NewReconciler(newTestSandbox()), l, alerts)
+			if _, err := l.Record(ledger.KindAdvance, ledger.Posting{DriverID: "DRV1", BrokerID: "BRK1", Amount: ledger.Rupees(5000)}); err != nil {
+				t.Fatal(err)
+			}
+
+			tr, err := s.Withdraw("DRV1", tt.amount, MethodUPI, tt.account)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.result != "" {
+				if w := l.Wallet("DRV1", time.Time{}); w.Available != ledger.Rupees(2000) {
+					t.Errorf("while pending %s available, want the withdrawal held back", w.Available)
+				}
+				for i := 0; i < 2; i++ {
+					s.Payments.Apply(Callback{EventID: "EVT" + tr.Reference, Reference: tr.Reference, State: tt.result})
+				}
+			}
+
+			if w := l.Wallet("DRV1", time.Time{}); w.Available != tt.wantAvailable {
+				t.Errorf("available = %s, want %s", w.Available, tt.wantAvailable)
+			}
+			list := alerts.List("DRV1")
+			if tt.wantAlert == "" {
+				if len(list) != 0 {
+					t.Errorf("alerts = %+v, want none", list)
+				}
+				return
+			}
+			if len(list) != 1 || list[0].Title != tt.wantAlert {
+				t.Errorf("alerts = %+v, want one %q", list, tt.wantAlert)
+			}
+		})
+	}
+}
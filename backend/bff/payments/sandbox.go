@@ -0,0 +1,160 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sandbox is a local provider that never moves real money.
+// The account decides the outcome: one containing "fail" is rejected, one
+// containing "pending" settles after SettleAfter ("pendingfail" then fails),
+// anything else succeeds at once.
+type Sandbox struct {
+	Secret      []byte
+	SettleAfter time.Duration
+	// Each webhook is sent this many extra times, like real gateways retrying
+	Redeliveries int
+	// Deliver receives every webhook; it stands in for the HTTP callback
+	Deliver func(body []byte, signature string)
+
+	mu        sync.Mutex
+	seq       int
+	events    int
+	transfers map[string]*Transfer
+	byRef     map[string]string
+}
+
+func NewSandbox(secret string) *Sandbox {
+	return &Sandbox{
+		Secret:       []byte(secret),
+		SettleAfter:  30 * time.Second,
+		Redeliveries: 1,
+		transfers:    map[string]*Transfer{},
+		byRef:        map[string]string{},
+	}
+}
+
+func (s *Sandbox) Name() string {
+	return "sandbox"
+}
+
+func (s *Sandbox) Initiate(req Request) (Transfer, error) {
+	if !req.Method.Valid() {
+		return Transfer{}, ErrUnknownMethod
+	}
+	if req.Party == "" || req.Account == "" || req.Amount <= 0 {
+		return Transfer{}, ErrInvalidRequest
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.byRef[req.Reference]; ok && req.Reference != "" {
+		return *s.transfers[id], nil
+	}
+
+	s.seq++
+	at := now()
+	t := &Transfer{
+		ID:        fmt.Sprintf("sbx_%06d", s.seq),
+		Reference: req.Reference,
+		Provider:  s.Name(),
+		Direction: req.Direction,
+		Method:    req.Method,
+		Amount:    req.Amount,
+		Party:     req.Party,
+		Account:   req.Account,
+		Note:      req.Note,
+		State:     StateSuccess,
+		CreatedAt: at,
+		UpdatedAt: at,
+	}
+	s.transfers[t.ID] = t
+	s.byRef[t.Reference] = t.ID
+
+	account := strings.ToLower(req.Account)
+	switch {
+	case strings.Contains(account, "pendingfail"):
+		t.State = StatePending
+		s.later(t.ID, StateFailed, "Beneficiary bank declined the transfer")
+	case strings.Contains(account, "pending"):
+		t.State = StatePending
+		s.later(t.ID, StateSuccess, "")
+	case strings.Contains(account, "fail"):
+		t.State, t.Reason = StateFailed, "Invalid beneficiary account"
+	}
+	s.notify(*t)
+	return *t, nil
+}
+
+func (s *Sandbox) Status(transferID string) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transfers[transferID]
+	if !ok {
+		return Transfer{}, ErrUnknownTransfer
+	}
+	return *t, nil
+}
+
+func (s *Sandbox) ParseWebhook(body []byte, signature string) (Callback, error) {
+	if len(s.Secret) == 0 {
+		return Callback{}, ErrNoSecret
+	}
+	if !hmac.Equal([]byte(s.sign(body)), []byte(signature)) {
+		return Callback{}, ErrInvalidSignature
+	}
+	var cb Callback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return Callback{}, err
+	}
+	return cb, nil
+}
+
+// later settles a pending transfer after SettleAfter
+func (s *Sandbox) later(id string, state State, reason string) {
+	time.AfterFunc(s.SettleAfter, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		t := s.transfers[id]
+		t.State, t.Reason, t.UpdatedAt = state, reason, now()
+		s.notify(*t)
+	})
+}
+
+// notify sends the webhook for a transfer's current state; callers hold s.mu
+func (s *Sandbox) notify(t Transfer) {
+	if s.Deliver == nil {
+		return
+	}
+	s.events++
+	body, _ := json.Marshal(Callback{
+		EventID:    fmt.Sprintf("evt_%06d", s.events),
+		TransferID: t.ID,
+		Reference:  t.Reference,
+		State:      t.State,
+		Reason:     t.Reason,
+		At:         t.UpdatedAt,
+	})
+	signature := s.sign(body)
+	deliver, times := s.Deliver, s.Redeliveries+1
+	go func() {
+		for i := 0; i < times; i++ {
+			deliver(body, signature)
+		}
+	}()
+}
+
+func (s *Sandbox) sign(body []byte) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
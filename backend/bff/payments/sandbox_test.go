@@ -0,0 +1,32 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSandboxParseWebhook(t *testing.T) {
+	body := []byte(`{"eventId":"EVT1","reference":"PAY000001","state":"failed"}`)
+	tests := []struct {
+		name    string
+		secret  string
+		signer  string // secret the body is signed with
+		wantErr error
+	}{
+		{name: "signed", secret: "s3cret", signer: "s3cret"},
+		{name: "forged", secret: "s3cret", signer: "guess", wantErr: ErrInvalidSignature},
+		{name: "no secret configured", signer: "", wantErr: ErrNoSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := NewSandbox(tt.signer).sign(body)
+			cb, err := NewSandbox(tt.secret).ParseWebhook(body, signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (cb.Reference != "PAY000001" || cb.State != StateFailed) {
+				t.Errorf("callback = %+v", cb)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package payments
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/notify"
+	"fmt"
+	"sync"
+)
+
+// Service moves driver and broker money through the provider and books the result in the ledger
+type Service struct {
+	Payments *Reconciler
+	Ledger   *ledger.Ledger
+	Alerts   *notify.Store
+
+	// serialises the wallet check with the posting that reserves the money
+	mu  sync.Mutex
+	seq int
+}
+
+func NewService(r *Reconciler, l *ledger.Ledger, alerts *notify.Store) *Service {
+	s := &Service{Payments: r, Ledger: l, Alerts: alerts}
+	r.OnFinal(s.settled)
+	return s
+}
+
+// Default service used by the BFF handlers, backed by the local sandbox. It
+// has no webhook secret, so it refuses webhooks until main replaces it with
+// NewDefault; transfers then settle when their status is checked.
+var Default = NewDefault("")
+
+// NewDefault wires the sandbox to the ledger, signing and verifying its
+// webhooks with secret
+func NewDefault(secret string) *Service {
+	sandbox := NewSandbox(secret)
+	r := NewReconciler(sandbox)
+	// the sandbox calls back in-process through the same path as the webhook endpoint
+	sandbox.Deliver = func(body []byte, signature string) {
+		r.HandleWebhook(body, signature)
+	}
+	return NewService(r, ledger.Default, notify.Default)
+}
+
+// Withdraw pays a driver's wallet balance out to their bank account or UPI ID
+func (s *Service) Withdraw(driverID string, amount ledger.Amount, method Method, account string) (Transfer, error) {
+	if !method.Valid() {
+		return Transfer{}, ErrUnknownMethod
+	}
+	if amount <= 0 || account == "" {
+		return Transfer{}, ErrInvalidRequest
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if available := -s.Ledger.Balance(ledger.DriverWallet(driverID)); amount > available {
+		return Transfer{}, ErrInsufficientFund
+	}
+
+	s.seq++
+	posting := ledger.Posting{
+		DriverID:     driverID,
+		Ref:          fmt.Sprintf("WD%06d", s.seq),
+		Amount:       amount,
+		Counterparty: account,
+		Mode:         method.Label(),
+		Memo:         "Withdrawal to " + account,
+	}
+	// the money leaves the wallet before the provider sees it so it cannot be withdrawn twice
+	if _, err := s.Ledger.Record(ledger.KindPayout, posting); err != nil {
+		return Transfer{}, err
+	}
+
+	t, err := s.Payments.Initiate(Request{
+		Reference: posting.Ref,
+		Direction: DirectionPayout,
+		Method:    method,
+		Amount:    amount,
+		Party:     driverID,
+		Account:   account,
+		Note:      posting.Memo,
+	})
+	if err != nil {
+		posting.Memo = "Withdrawal could not be sent"
+		s.Ledger.Record(ledger.KindPayoutReversed, posting)
+		return Transfer{}, err
+	}
+	return t, nil
+}
+
+// Collect pulls funds from a broker into their platform account
+func (s *Service) Collect(brokerID string, amount ledger.Amount, method Method, account string) (Transfer, error) {
+	s.mu.Lock()
+	s.seq++
+	ref := fmt.Sprintf("CL%06d", s.seq)
+	s.mu.Unlock()
+
+	return s.Payments.Initiate(Request{
+		Reference: ref,
+		Direction: DirectionCollection,
+		Method:    method,
+		Amount:    amount,
+		Party:     brokerID,
+		Account:   account,
+		Note:      "Funds added from " + account,
+	})
+}
+
+// settled books a finished transfer and tells the driver or broker
+func (s *Service) settled(t Transfer) {
+	posting := ledger.Posting{
+		Ref:          t.Reference,
+		Amount:       t.Amount,
+		Counterparty: t.Account,
+		Mode:         t.Method.Label(),
+		Memo:         t.Note,
+		At:           t.UpdatedAt,
+	}
+
+	switch {
+	case t.Direction == DirectionPayout && t.State == StateSuccess:
+		posting.DriverID = t.Party
+		s.Ledger.Record(ledger.KindPayoutSettled, posting)
+		s.alert(t.Party, "Withdrawal successful", t.Amount.String()+" sent to "+t.Account)
+
+	case t.Direction == DirectionPayout:
+		posting.DriverID = t.Party
+		posting.Memo = t.Reason
+		s.Ledger.Record(ledger.KindPayoutReversed, posting)
+		s.alert(t.Party, "Withdrawal failed", t.Amount.String()+" returned to wallet: "+t.Reason)
+
+	case t.State == StateSuccess:
+		posting.BrokerID = t.Party
+		s.Ledger.Record(ledger.KindCollection, posting)
+		s.alert(t.Party, "Funds added", t.Amount.String()+" received from "+t.Account)
+
+	default:
+		s.alert(t.Party, "Adding funds failed", t.Reason)
+	}
+}
+
+func (s *Service) alert(userID, title, message string) {
+	s.Alerts.Push(userID, notify.Alert{Kind: "transfer", Title: title, Message: message})
+}
@@ -0,0 +1,71 @@
+package payments
+
+import (
+	"backend/bff"
+	"backend/bff/org"
+	"backend/bff/trip"
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Webhook receives transfer callbacks from the payment provider
+func Webhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Invalid request format"})
+		return
+	}
+
+	t, applied, err := Default.Payments.HandleWebhook(body, c.GetHeader("X-Signature"))
+	switch {
+	case errors.Is(err, ErrNoSecret):
+		c.JSON(503, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	case errors.Is(err, ErrInvalidSignature):
+		c.JSON(401, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	case errors.Is(err, ErrUnknownTransfer):
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	// duplicates are acknowledged too so the provider stops retrying
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data: map[string]interface{}{
+			"reference": t.Reference,
+			"state":     t.State,
+			"applied":   applied,
+		},
+	})
+}
+
+// TransferStatus returns one of the caller's transfers, checking with the
+// provider if it is still pending. Someone else's transfer answers as unknown.
+func TransferStatus(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	reference := c.Param("reference")
+	if t, err := Default.Payments.Get(reference); err != nil || t.Party != party(c) {
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: ErrUnknownTransfer.Error()})
+		return
+	}
+	t, err := Default.Payments.Sync(reference)
+	if err != nil {
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	c.JSON(200, bff.ActionResponse{Status: "success", Data: t})
+}
+
+// party is who the caller moves money as: a broker's organization account,
+// or the driver
+func party(c *gin.Context) string {
+	if id := c.Query("brokerId"); id != "" {
+		return org.Default.Account(id)
+	}
+	return c.DefaultQuery("driverId", trip.DemoDriverID)
+}
@@ -0,0 +1,128 @@
+// Package pdf writes plain text-and-line documents using the PDF base fonts,
+// which is all invoices and receipts need and keeps the build free of dependencies.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 in points
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+type Document struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// Text draws a string with its baseline y points from the top of the page
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(s))
+}
+
+// TextRight draws a string ending at x, for amount columns
+func (d *Document) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-Width(s, size), y, size, bold, s)
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Shade fills a light grey band, used behind table headers
+func (d *Document) Shade(x, y, w, h float64) {
+	fmt.Fprintf(d.page, "q 0.93 g %.2f %.2f %.2f %.2f re f Q\n", x, PageHeight-y-h, w, h)
+}
+
+// Bytes assembles the pages into a PDF file
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	n := len(d.pages)
+	// 1 catalog, 2 page tree, 3-4 fonts, then a page and a content stream per page
+	kids := make([]string, n)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// The base fonts cannot show the rupee sign or arrows, so they are spelled out
+var replacer = strings.NewReplacer(
+	"₹", "Rs. ",
+	"→", "->",
+	"•", "-",
+	"–", "-",
+	`\`, `\\`,
+	"(", `\(`,
+	")", `\)`,
+)
+
+func escape(s string) string {
+	s = replacer.Replace(s)
+	var b strings.Builder
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Width approximates the width of a string in Helvetica at the given size
+func Width(s string, size float64) float64 {
+	var units float64
+	for _, r := range replacer.Replace(s) {
+		switch {
+		case r == '.' || r == ',' || r == ' ' || r == 'i' || r == 'l' || r == '/':
+			units += 278
+		case r >= 'A' && r <= 'Z':
+			units += 667
+		default:
+			units += 556
+		}
+	}
+	return units * size / 1000
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Memory keeps buckets in process. Limits then apply per server; a shared
+// Store is needed once the BFF runs on more than one.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	limit  Limit
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}}
+}
+
+func (m *Memory) Take(at time.Time, buckets []Bucket) Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(at)
+	var wait time.Duration
+	state := make([]*bucket, len(buckets))
+	for i, b := range buckets {
+		s, ok := m.buckets[b.Key]
+		if !ok {
+			s = &bucket{tokens: float64(b.Limit.Burst), at: at}
+			m.buckets[b.Key] = s
+		}
+		s.limit = b.Limit
+		s.refill(at)
+		state[i] = s
+		if s.tokens < 1 {
+			need := time.Duration((1 - s.tokens) / b.Limit.rate() * float64(time.Second))
+			if need > wait {
+				wait = need
+			}
+		}
+	}
+	if wait > 0 {
+		return Decision{RetryAfter: wait}
+	}
+	for _, s := range state {
+		s.tokens--
+	}
+	return Decision{Allowed: true}
+}
+
+func (b *bucket) refill(at time.Time) {
+	if at.After(b.at) {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+at.Sub(b.at).Seconds()*b.limit.rate())
+		b.at = at
+	}
+}
+
+// sweep forgets buckets that have filled back up, once a minute, so
+// one-off phone numbers and IPs don't pile up
+func (m *Memory) sweep(at time.Time) {
+	if at.Sub(m.swept) < time.Minute {
+		return
+	}
+	m.swept = at
+	for k, b := range m.buckets {
+		b.refill(at)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(m.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryTake(t *testing.T) {
+	limit := Limit{Burst: 2, Per: time.Minute} // a token every 30 seconds
+	type take struct {
+		after     time.Duration // since the first take
+		keys      []string
+		want      bool
+		wantRetry time.Duration
+	}
+	tests := []struct {
+		name  string
+		takes []take
+	}{
+		{
+			name: "burst then empty",
+			takes: []take{
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a"}, wantRetry: 30 * time.Second},
+			},
+		},
+		{
+			name: "refills over time",
+			takes: []take{
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a"}, want: true},
+				{after: 20 * time.Second, keys: []string{"a"}, wantRetry: 10 * time.Second},
+				{after: 30 * time.Second, keys: []string{"a"}, want: true},
+				{after: 30 * time.Second, keys: []string{"a"}, wantRetry: 30 * time.Second},
+			},
+		},
+		{
+			name: "refill stops at the burst",
+			takes: []take{
+				{keys: []string{"a"}, want: true},
+				{after: time.Hour, keys: []string{"a"}, want: true},
+				{after: time.Hour, keys: []string{"a"}, want: true},
+				{after: time.Hour, keys: []string{"a"}, wantRetry: 30 * time.Second},
+			},
+		},
+		{
+			name: "keys are separate",
+			takes: []take{
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"b"}, want: true},
+				{keys: []string{"a"}, wantRetry: 30 * time.Second},
+				{keys: []string{"b"}, want: true},
+			},
+		},
+		{
+			name: "one empty bucket blocks the rest",
+			takes: []take{
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a"}, want: true},
+				{keys: []string{"a", "b"}, wantRetry: 30 * time.Second},
+				// b kept its tokens while a was empty
+				{keys: []string{"b"}, want: true},
+				{keys: []string{"b"}, want: true},
+				{keys: []string{"b"}, wantRetry: 30 * time.Second},
+			},
+		},
+	}
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory()
+			for i, tk := range tt.takes {
+				var buckets []Bucket
+				for _, k := range tk.keys {
+					buckets = append(buckets, Bucket{Key: k, Limit: limit})
+				}
+				d := m.Take(start.Add(tk.after), buckets)
+				if d.Allowed != tk.want || d.RetryAfter != tk.wantRetry {
+					t.Errorf("take %d: allowed %v, retry after %s; want %v, %s", i, d.Allowed, d.RetryAfter, tk.want, tk.wantRetry)
+				}
+			}
+		})
+	}
+}
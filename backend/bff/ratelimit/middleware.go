@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"backend/bff"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DeviceHeader carries the app install's device ID
+const DeviceHeader = "X-Device-ID"
+
+// maxIdentifyBody is how much of a body is read for the phone number and
+// action; larger bodies are uploads and are passed on unread
+const maxIdentifyBody = 64 << 10
+
+// Limiter applies policies against a store
+type Limiter struct {
+	Store Store
+}
+
+// Default limiter, keeping its buckets in memory
+var Default = &Limiter{Store: NewMemory()}
+
+// Middleware limits requests with the policy on the default limiter
+func Middleware(p Policy, skip ...string) gin.HandlerFunc {
+	return Default.Middleware(p, skip...)
+}
+
+// Middleware answers 429 with Retry-After once any of the request's
+// buckets is empty. Dimensions the request doesn't identify, such as the
+// phone number on an action, are not counted. Routes in skip, such as
+// provider callbacks, are let through uncounted.
+func (l *Limiter) Middleware(p Policy, skip ...string) gin.HandlerFunc {
+	skipped := map[string]bool{}
+	for _, path := range skip {
+		skipped[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skipped[c.FullPath()] {
+			c.Next()
+			return
+		}
+		keys, action := identify(c)
+		if !p.applies(c.Request.Method, action) {
+			c.Next()
+			return
+		}
+
+		var buckets []Bucket
+		for d, limit := range p.Limits {
+			if k := keys[d]; k != "" {
+				buckets = append(buckets, Bucket{Key: p.Name + ":" + string(d) + ":" + k, Limit: limit})
+			}
+		}
+
+		d := l.Store.Take(now(), buckets)
+		if d.Allowed {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(d.RetrySeconds()))
+		c.Header("X-RateLimit-Policy", p.Name)
+		c.AbortWithStatusJSON(429, bff.ActionResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Too many requests, please try again in %s", wait(d.RetrySeconds())),
+			Data:    gin.H{"retryAfter": d.RetrySeconds(), "policy": p.Name},
+		})
+	}
+}
+
+// identify reads the request's keys: the phone number from the body or
+// query, the device header, the client IP and the user the handlers act as.
+// It also returns the action the body asks for, if any.
+func identify(c *gin.Context) (map[Dimension]string, string) {
+	keys := map[Dimension]string{
+		ByDevice: c.GetHeader(DeviceHeader),
+		ByIP:     c.ClientIP(),
+	}
+	for _, k := range []string{"userId", "brokerId", "driverId"} {
+		if id := c.Query(k); id != "" {
+			keys[ByUser] = id
+			break
+		}
+	}
+
+	var req struct {
+		Action string                 `json:"action"`
+		Phone  string                 `json:"phone"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	if c.Request.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(c.Request.Body, maxIdentifyBody))
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		json.Unmarshal(body, &req)
+	}
+
+	phone := c.Query("phone")
+	if phone == "" {
+		phone = req.Phone
+	}
+	if phone == "" {
+		phone, _ = req.Data["phone"].(string)
+	}
+	keys[ByPhone] = phoneKey(phone)
+	return keys, req.Action
+}
+
+// readCloser puts the part of a body identify read back in front of the rest
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// phoneKey reduces a number to its last ten digits so +91, 0 and spacing
+// variants share a bucket
+func phoneKey(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+	if len(d) > 10 {
+		d = d[len(d)-10:]
+	}
+	return d
+}
+
+func wait(seconds int) string {
+	if seconds == 1 {
+		return "1 second"
+	}
+	if seconds < 60 {
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+	return fmt.Sprintf("%d minutes", (seconds+59)/60)
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	policy := Policy{
+		Name: "test",
+		Limits: map[Dimension]Limit{
+			ByPhone: {Burst: 1, Per: time.Minute},
+			ByIP:    {Burst: 3, Per: time.Minute},
+		},
+		Methods: []string{"POST"},
+	}
+	type call struct {
+		method string
+		path   string
+		body   string
+		ip     string
+		status int
+		retry  string
+	}
+	tests := []struct {
+		name   string
+		policy Policy
+		calls  []call
+	}{
+		{
+			name:   "second code to a number waits",
+			policy: policy,
+			calls: []call{
+				{body: `{"phone":"+91 98200 11223"}`, status: 200},
+				{body: `{"phone":"09820011223"}`, status: 429, retry: "60"},
+			},
+		},
+		{
+			name:   "numbers don't share a bucket",
+			policy: policy,
+			calls: []call{
+				{body: `{"phone":"9820011223"}`, status: 200},
+				{body: `{"data":{"phone":"9820011224"}}`, status: 200},
+			},
+		},
+		{
+			name:   "one IP across many numbers",
+			policy: policy,
+			calls: []call{
+				{body: `{"phone":"9820011221"}`, status: 200},
+				{body: `{"phone":"9820011222"}`, status: 200},
+				{body: `{"phone":"9820011223"}`, status: 200},
+				{body: `{"phone":"9820011224"}`, status: 429, retry: "20"},
+				{body: `{"phone":"9820011225"}`, ip: "10.0.0.2", status: 200},
+			},
+		},
+		{
+			name:   "other methods are not counted",
+			policy: policy,
+			calls: []call{
+				{body: `{"phone":"9820011223"}`, status: 200},
+				{method: "GET", path: "/send?phone=9820011223", status: 200},
+			},
+		},
+		{
+			name:   "only the named actions",
+			policy: policy.Only("invite"),
+			calls: []call{
+				{body: `{"action":"invite","data":{"phone":"9820011223"}}`, status: 200},
+				{body: `{"action":"remove","data":{"phone":"9820011223"}}`, status: 200},
+				{body: `{"action":"invite","data":{"phone":"9820011223"}}`, status: 429, retry: "60"},
+			},
+		},
+		{
+			name:   "skipped routes",
+			policy: policy,
+			calls: []call{
+				{path: "/callback", body: `{"phone":"9820011223"}`, status: 200},
+				{path: "/callback", body: `{"phone":"9820011223"}`, status: 200},
+				{body: `{"phone":"9820011223"}`, status: 200},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Limiter{Store: NewMemory()}
+			echo := func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				c.String(200, "%s", body)
+			}
+			r := gin.New()
+			r.Use(l.Middleware(tt.policy, "/callback"))
+			r.Any("/send", echo)
+			r.POST("/callback", echo)
+
+			for i, cl := range tt.calls {
+				if cl.method == "" {
+					cl.method = "POST"
+				}
+				if cl.path == "" {
+					cl.path = "/send"
+				}
+				if cl.ip == "" {
+					cl.ip = "10.0.0.1"
+				}
+				req := httptest.NewRequest(cl.method, cl.path, strings.NewReader(cl.body))
+				req.RemoteAddr = cl.ip + ":40000"
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				if w.Code != cl.status {
+					t.Fatalf("call %d: status = %d, want %d", i, w.Code, cl.status)
+				}
+				if got := w.Header().Get("Retry-After"); got != cl.retry {
+					t.Errorf("call %d: Retry-After = %q, want %q", i, got, cl.retry)
+				}
+				if w.Code == 200 && w.Body.String() != cl.body {
+					t.Errorf("call %d: handler read %q, want the body sent", i, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareLargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got int
+	r := gin.New()
+	r.Use((&Limiter{Store: NewMemory()}).Middleware(Actions))
+	r.POST("/upload", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		got = len(body)
+	})
+
+	body := `{"action":"upload","data":{"image":"` + strings.Repeat("A", 4*maxIdentifyBody) + `"}}`
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/upload", strings.NewReader(body)))
+	if got != len(body) {
+		t.Errorf("handler read %d bytes, want all %d", got, len(body))
+	}
+}
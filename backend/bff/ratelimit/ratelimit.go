@@ -0,0 +1,131 @@
+// Package ratelimit throttles requests with token buckets keyed by phone
+// number, device, IP and user. Each policy sets a bucket per dimension and a
+// request has to find a token in every one of them, so spreading requests
+// over many numbers from one device or IP is caught as well.
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+// Dimension is what a bucket is keyed on
+type Dimension string
+
+const (
+	ByPhone  Dimension = "phone"
+	ByDevice Dimension = "device"
+	ByIP     Dimension = "ip"
+	ByUser   Dimension = "user"
+)
+
+// Limit allows Burst requests at once, refilled evenly over Per
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// rate is tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Per.Seconds()
+}
+
+// Policy is a named set of limits; only requests using one of Methods and,
+// for action routes, asking for one of Actions are counted. Either left
+// empty counts everything.
+type Policy struct {
+	Name    string
+	Limits  map[Dimension]Limit
+	Methods []string
+	Actions []string
+}
+
+// Only narrows the policy to some of a route's actions. The buckets keep the
+// policy's name, so they are shared with the policy's other routes.
+func (p Policy) Only(actions ...string) Policy {
+	p.Actions = actions
+	return p
+}
+
+func (p Policy) applies(method, action string) bool {
+	return within(p.Methods, method) && within(p.Actions, action)
+}
+
+func within(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Policies for each kind of endpoint. They are variables so deployments can
+// tune them before the routes are set up.
+var (
+	// OTPSend protects the SMS budget and the numbers being sent to, by
+	// the sign-in code and by team invitations alike. The code itself is
+	// sent and checked by the user API's request-otp and verify-otp, not
+	// by this router, so here only invitations are counted.
+	OTPSend = Policy{
+		Name: "otp_send",
+		Limits: map[Dimension]Limit{
+			ByPhone:  {Burst: 3, Per: 10 * time.Minute},
+			ByDevice: {Burst: 5, Per: time.Hour},
+			ByIP:     {Burst: 20, Per: time.Hour},
+		},
+	}
+	// OTPVerify stops codes being guessed
+	OTPVerify = Policy{
+		Name: "otp_verify",
+		Limits: map[Dimension]Limit{
+			ByPhone:  {Burst: 5, Per: 10 * time.Minute},
+			ByDevice: {Burst: 10, Per: 10 * time.Minute},
+			ByIP:     {Burst: 50, Per: 10 * time.Minute},
+		},
+	}
+	Search = Policy{
+		Name: "search",
+		Limits: map[Dimension]Limit{
+			ByUser: {Burst: 30, Per: time.Minute},
+			ByIP:   {Burst: 120, Per: time.Minute},
+		},
+	}
+	Actions = Policy{
+		Name: "actions",
+		Limits: map[Dimension]Limit{
+			ByUser:   {Burst: 60, Per: time.Minute},
+			ByDevice: {Burst: 60, Per: time.Minute},
+			ByIP:     {Burst: 300, Per: time.Minute},
+		},
+		Methods: []string{"POST"},
+	}
+)
+
+// Bucket is one limit applied to one key, e.g. otp_send:phone:+919820011223
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Decision is whether a request may go ahead and, if not, when it may
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// RetrySeconds rounds the wait up for the Retry-After header
+func (d Decision) RetrySeconds() int {
+	return int(math.Ceil(d.RetryAfter.Seconds()))
+}
+
+// Store holds bucket state. Take must be atomic across the buckets: it
+// takes a token from each only when all of them have one.
+type Store interface {
+	Take(at time.Time, buckets []Bucket) Decision
+}
@@ -0,0 +1,162 @@
+package rating
+
+import (
+	"backend/bff"
+	"backend/bff/trip"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Summary returns a user's aggregate rating and the reviews they received
+func Summary(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	userID := bff.UserID(c)
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data: gin.H{
+			"aggregate": Default.Aggregate(userID),
+			"reviews":   Default.Received(userID),
+		},
+	})
+}
+
+// HandleAction takes "submit" with tripId, a score per criterion (either
+// in scores or as top-level form fields) and a comment, and "report" with
+// reviewId, reason and note
+func HandleAction(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	userID := bff.UserID(c)
+	switch req.Action {
+	case "submit":
+		tripID, _ := req.Data["tripId"].(string)
+		comment, _ := req.Data["comment"].(string)
+		form, _ := req.Data["scores"].(map[string]interface{})
+		if form == nil {
+			form = req.Data
+		}
+		scores := map[Criterion]int{}
+		for k, v := range form {
+			scores[Criterion(k)] = score(v)
+		}
+
+		r, err := Default.Submit(tripID, userID, scores, comment)
+		if err != nil {
+			c.JSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: "Thanks for rating this trip",
+			Data:    gin.H{"review": r},
+		})
+
+	case "report":
+		reviewID, _ := req.Data["reviewId"].(string)
+		reason, _ := req.Data["reason"].(string)
+		note, _ := req.Data["note"].(string)
+		p, err := Default.Report(reviewID, userID, Reason(reason), note)
+		if err != nil {
+			c.JSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: "Review reported, our team will look into it",
+			Data:    gin.H{"report": p},
+		})
+
+	default:
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Unknown action: " + req.Action})
+	}
+}
+
+// Reports lists abuse reports for moderators, open ones unless status says
+// otherwise
+func Reports(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	status := ReportStatus(c.DefaultQuery("status", string(ReportOpen)))
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"reports": Default.Reports(status)},
+	})
+}
+
+// Moderate takes "resolve" with reportId and uphold; an upheld report hides
+// the review
+func Moderate(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", "application/json")
+
+	var req bff.ActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, bff.ActionResponse{
+			Status:  "error",
+			Message: "Invalid request format",
+		})
+		return
+	}
+
+	switch req.Action {
+	case "resolve":
+		reportID, _ := req.Data["reportId"].(string)
+		uphold, _ := req.Data["uphold"].(bool)
+		p, err := Default.Resolve(reportID, uphold)
+		if err != nil {
+			c.JSON(errorStatus(err), bff.ActionResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		message := "Report dismissed, the review stays up"
+		if uphold {
+			message = "Report upheld, the review is hidden"
+		}
+		c.JSON(200, bff.ActionResponse{
+			Status:  "success",
+			Message: message,
+			Data:    gin.H{"report": p},
+		})
+
+	default:
+		c.JSON(400, bff.ActionResponse{Status: "error", Message: "Unknown action: " + req.Action})
+	}
+}
+
+// score reads a criterion score sent as a number or as form text; half
+// stars round to the nearest whole one
+func score(v interface{}) int {
+	switch v := v.(type) {
+	case float64:
+		return int(math.Round(v))
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return int(math.Round(f))
+	}
+	return 0
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, trip.ErrNotFound), errors.Is(err, ErrReviewNotFound), errors.Is(err, ErrReportNotFound):
+		return 404
+	case errors.Is(err, ErrNotParticipant), errors.Is(err, ErrNotYourReview):
+		return 403
+	case errors.Is(err, ErrAlreadyRated), errors.Is(err, ErrAlreadyReported), errors.Is(err, ErrReportResolved):
+		return 409
+	}
+	return 400
+}
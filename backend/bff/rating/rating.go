@@ -0,0 +1,165 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotParticipant  = errors.New("only the trip's driver and broker can rate it")
+	ErrNotDelivered    = errors.New("ratings open once the trip is delivered")
+	ErrWindowClosed    = errors.New("the rating window for this trip has closed")
+	ErrAlreadyRated    = errors.New("you have already rated this trip")
+	ErrInvalidScore    = errors.New("each criterion needs a score from 1 to 5")
+	ErrCommentTooLong  = errors.New("comment must be under 500 characters")
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrNotYourReview   = errors.New("only the person reviewed can report a review")
+	ErrAlreadyReported = errors.New("this review is already under moderation")
+	ErrInvalidReason   = errors.New("unknown report reason")
+	ErrReportNotFound  = errors.New("report not found")
+	ErrReportResolved  = errors.New("report has already been resolved")
+)
+
+const maxComment = 500
+
+// Role is the side of the trip that wrote a review
+type Role string
+
+const (
+	RoleDriver Role = "driver" // the driver rating the broker
+	RoleBroker Role = "broker" // the broker rating the driver
+)
+
+type Criterion string
+
+const (
+	CriterionPaymentOnTime   Criterion = "paymentOnTime"
+	CriterionLoadAccuracy    Criterion = "loadAccuracy"
+	CriterionPunctuality     Criterion = "punctuality"
+	CriterionCargoCare       Criterion = "cargoCare"
+	CriterionCommunication   Criterion = "communication"
+	CriterionProfessionalism Criterion = "professionalism"
+)
+
+// Criteria is what each side rates the other on, in the order the apps
+// show them
+func Criteria(r Role) []Criterion {
+	if r == RoleDriver {
+		return []Criterion{CriterionPaymentOnTime, CriterionLoadAccuracy, CriterionCommunication}
+	}
+	return []Criterion{CriterionPunctuality, CriterionCargoCare, CriterionCommunication, CriterionProfessionalism}
+}
+
+func (c Criterion) Label() string {
+	switch c {
+	case CriterionPaymentOnTime:
+		return "Paid on Time"
+	case CriterionLoadAccuracy:
+		return "Load as Described"
+	case CriterionPunctuality:
+		return "Punctuality"
+	case CriterionCargoCare:
+		return "Cargo Care"
+	case CriterionCommunication:
+		return "Communication"
+	case CriterionProfessionalism:
+		return "Professionalism"
+	}
+	return string(c)
+}
+
+// Review is one side's rating of the other after a trip
+type Review struct {
+	ID      string            `json:"id"`
+	TripID  string            `json:"tripId"`
+	Role    Role              `json:"role"`
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Scores  map[Criterion]int `json:"scores"`
+	Overall float64           `json:"overall"`
+	Comment string            `json:"comment,omitempty"`
+	At      time.Time         `json:"at"`
+	// Hidden reviews were taken down after an upheld abuse report and no
+	// longer count towards aggregates
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+func (r Review) clone() Review {
+	scores := make(map[Criterion]int, len(r.Scores))
+	for k, v := range r.Scores {
+		scores[k] = v
+	}
+	r.Scores = scores
+	return r
+}
+
+// overall is the mean of the criterion scores, to one decimal
+func overall(scores map[Criterion]int) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, v := range scores {
+		sum += v
+	}
+	return math.Round(10*float64(sum)/float64(len(scores))) / 10
+}
+
+// Reason is why a review was reported
+type Reason string
+
+const (
+	ReasonAbusive      Reason = "abusive"
+	ReasonFalse        Reason = "false"
+	ReasonPersonalInfo Reason = "personal_info"
+	ReasonOther        Reason = "other"
+)
+
+func (r Reason) Valid() bool {
+	switch r {
+	case ReasonAbusive, ReasonFalse, ReasonPersonalInfo, ReasonOther:
+		return true
+	}
+	return false
+}
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportUpheld    ReportStatus = "upheld"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Report is an abuse report against a review, waiting for moderation
+type Report struct {
+	ID         string       `json:"id"`
+	ReviewID   string       `json:"reviewId"`
+	ReporterID string       `json:"reporterId"`
+	Reason     Reason       `json:"reason"`
+	Note       string       `json:"note,omitempty"`
+	Status     ReportStatus `json:"status"`
+	At         time.Time    `json:"at"`
+	ResolvedAt time.Time    `json:"resolvedAt,omitempty"`
+}
+
+// Aggregate is a user's standing from the reviews they received, with
+// recent trips weighing more than old ones
+type Aggregate struct {
+	UserID   string                `json:"userId"`
+	Score    float64               `json:"score"`
+	Criteria map[Criterion]float64 `json:"criteria"`
+	Count    int                   `json:"count"`
+}
+
+// Text is the score as the apps show it, "New" before the first review
+func (a Aggregate) Text() string {
+	if a.Count == 0 {
+		return "New"
+	}
+	return fmt.Sprintf("%.1f", a.Score)
+}
@@ -0,0 +1,51 @@
+package rating
+
+import (
+	"backend/bff/trip"
+	"time"
+)
+
+// seed rates the demo driver's settled trips both ways
+func seed(s *Store) *Store {
+	review := func(tripID string, role Role, scores map[Criterion]int, comment string, after time.Duration) {
+		t, err := s.Trips.Get(tripID)
+		if err != nil {
+			return
+		}
+		delivered, ok := t.Reached(trip.StatusDelivered)
+		if !ok {
+			return
+		}
+		from, to := t.DriverID, t.BrokerID
+		if role == RoleBroker {
+			from, to = to, from
+		}
+		s.add(Review{TripID: t.ID, Role: role, From: from, To: to, Scores: scores, Comment: comment, At: delivered.Add(after)})
+	}
+
+	review("TRIP#4498", RoleBroker, map[Criterion]int{
+		CriterionPunctuality:     5,
+		CriterionCargoCare:       5,
+		CriterionCommunication:   5,
+		CriterionProfessionalism: 5,
+	}, "Reached Kolkata ahead of schedule, cargo in perfect shape.", 3*time.Hour)
+	review("TRIP#4498", RoleDriver, map[Criterion]int{
+		CriterionPaymentOnTime: 5,
+		CriterionLoadAccuracy:  5,
+		CriterionCommunication: 4,
+	}, "Payment came the same day.", 5*time.Hour)
+
+	review("TRIP#4587", RoleBroker, map[Criterion]int{
+		CriterionPunctuality:     4,
+		CriterionCargoCare:       5,
+		CriterionCommunication:   5,
+		CriterionProfessionalism: 4,
+	}, "Good driver, a little late at the pickup.", 2*time.Hour)
+	review("TRIP#4587", RoleDriver, map[Criterion]int{
+		CriterionPaymentOnTime: 4,
+		CriterionLoadAccuracy:  5,
+		CriterionCommunication: 5,
+	}, "", 4*time.Hour)
+
+	return s
+}
@@ -0,0 +1,280 @@
+package rating
+
+import (
+	"backend/bff/trip"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store keeps the reviews drivers and brokers leave each other once a
+// trip is delivered
+type Store struct {
+	Trips *trip.Store
+	// HalfLife is how long until a review counts half as much as a new one
+	HalfLife time.Duration
+	// Window is how long after delivery a trip can still be rated
+	Window time.Duration
+
+	mu        sync.Mutex
+	seq       int
+	reportSeq int
+	reviews   []Review
+	reports   []Report
+}
+
+func NewStore(trips *trip.Store) *Store {
+	return &Store{
+		Trips:    trips,
+		HalfLife: 90 * 24 * time.Hour,
+		Window:   14 * 24 * time.Hour,
+	}
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore(trip.Default))
+
+// RoleOf returns the side userID rates from on the trip
+func RoleOf(t trip.Trip, userID string) (Role, error) {
+	switch {
+	case userID == "":
+	case userID == t.DriverID:
+		return RoleDriver, nil
+	case userID == t.BrokerID:
+		return RoleBroker, nil
+	}
+	return "", ErrNotParticipant
+}
+
+// Submit records userID's rating of the other side of a delivered trip
+func (s *Store) Submit(tripID, userID string, scores map[Criterion]int, comment string) (Review, error) {
+	t, err := s.Trips.Get(tripID)
+	if err != nil {
+		return Review{}, err
+	}
+	role, err := RoleOf(t, userID)
+	if err != nil {
+		return Review{}, err
+	}
+	delivered, ok := t.Reached(trip.StatusDelivered)
+	if !ok {
+		return Review{}, ErrNotDelivered
+	}
+	if now().Sub(delivered) > s.Window {
+		return Review{}, ErrWindowClosed
+	}
+
+	criteria := Criteria(role)
+	clean := make(map[Criterion]int, len(criteria))
+	for _, c := range criteria {
+		v := scores[c]
+		if v < 1 || v > 5 {
+			return Review{}, ErrInvalidScore
+		}
+		clean[c] = v
+	}
+	comment = strings.TrimSpace(comment)
+	if len([]rune(comment)) > maxComment {
+		return Review{}, ErrCommentTooLong
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.given(tripID, userID); ok {
+		return Review{}, ErrAlreadyRated
+	}
+	to := t.BrokerID
+	if role == RoleBroker {
+		to = t.DriverID
+	}
+	r := s.add(Review{
+		TripID:  tripID,
+		Role:    role,
+		From:    userID,
+		To:      to,
+		Scores:  clean,
+		Comment: comment,
+		At:      now(),
+	})
+	return r.clone(), nil
+}
+
+// Due reports whether userID still owes the trip a rating
+func (s *Store) Due(t trip.Trip, userID string) bool {
+	if _, err := RoleOf(t, userID); err != nil {
+		return false
+	}
+	delivered, ok := t.Reached(trip.StatusDelivered)
+	if !ok || now().Sub(delivered) > s.Window {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, rated := s.given(t.ID, userID)
+	return !rated
+}
+
+// Given returns the review userID left on the trip
+func (s *Store) Given(tripID, userID string) (Review, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.given(tripID, userID)
+	return r.clone(), ok
+}
+
+// Received returns the visible reviews about userID, newest first
+func (s *Store) Received(userID string) []Review {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Review
+	for i := len(s.reviews) - 1; i >= 0; i-- {
+		if r := s.reviews[i]; r.To == userID && !r.Hidden {
+			out = append(out, r.clone())
+		}
+	}
+	return out
+}
+
+// Aggregate weighs every visible review about userID by its age, halving
+// its weight every HalfLife
+func (s *Store) Aggregate(userID string) Aggregate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := Aggregate{UserID: userID, Criteria: map[Criterion]float64{}}
+	var total float64
+	sums := map[Criterion]float64{}
+	weights := map[Criterion]float64{}
+	for _, r := range s.reviews {
+		if r.To != userID || r.Hidden {
+			continue
+		}
+		w := math.Pow(0.5, float64(now().Sub(r.At))/float64(s.HalfLife))
+		a.Count++
+		a.Score += w * r.Overall
+		total += w
+		for c, v := range r.Scores {
+			sums[c] += w * float64(v)
+			weights[c] += w
+		}
+	}
+	if total > 0 {
+		a.Score = math.Round(10*a.Score/total) / 10
+	}
+	for c, sum := range sums {
+		a.Criteria[c] = math.Round(10*sum/weights[c]) / 10
+	}
+	return a
+}
+
+// Report flags a review for moderation. Only the person it is about can
+// report it, and only once while a report is open.
+func (s *Store) Report(reviewID, reporterID string, reason Reason, note string) (Report, error) {
+	if reason == "" {
+		reason = ReasonOther
+	}
+	if !reason.Valid() {
+		return Report{}, ErrInvalidReason
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.review(reviewID)
+	if r == nil || r.Hidden {
+		return Report{}, ErrReviewNotFound
+	}
+	if r.To != reporterID {
+		return Report{}, ErrNotYourReview
+	}
+	for _, p := range s.reports {
+		if p.ReviewID == reviewID && p.Status == ReportOpen {
+			return Report{}, ErrAlreadyReported
+		}
+	}
+
+	s.reportSeq++
+	p := Report{
+		ID:         fmt.Sprintf("RPT%05d", s.reportSeq),
+		ReviewID:   reviewID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Note:       strings.TrimSpace(note),
+		Status:     ReportOpen,
+		At:         now(),
+	}
+	s.reports = append(s.reports, p)
+	return p, nil
+}
+
+// Resolve closes a report for moderators; an upheld report hides the review
+func (s *Store) Resolve(reportID string, uphold bool) (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.reports {
+		p := &s.reports[i]
+		if p.ID != reportID {
+			continue
+		}
+		if p.Status != ReportOpen {
+			return Report{}, ErrReportResolved
+		}
+		p.Status, p.ResolvedAt = ReportDismissed, now()
+		if uphold {
+			p.Status = ReportUpheld
+			if r := s.review(p.ReviewID); r != nil {
+				r.Hidden = true
+			}
+		}
+		return *p, nil
+	}
+	return Report{}, ErrReportNotFound
+}
+
+// Reports lists abuse reports with the status, oldest first
+func (s *Store) Reports(status ReportStatus) []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Report
+	for _, p := range s.reports {
+		if p.Status == status {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+func (s *Store) add(r Review) Review {
+	s.seq++
+	r.ID = fmt.Sprintf("RVW%05d", s.seq)
+	r.Overall = overall(r.Scores)
+	s.reviews = append(s.reviews, r)
+	return r
+}
+
+func (s *Store) given(tripID, userID string) (Review, bool) {
+	for _, r := range s.reviews {
+		if r.TripID == tripID && r.From == userID {
+			return r, true
+		}
+	}
+	return Review{}, false
+}
+
+func (s *Store) review(id string) *Review {
+	for i := range s.reviews {
+		if s.reviews[i].ID == id {
+			return &s.reviews[i]
+		}
+	}
+	return nil
+}
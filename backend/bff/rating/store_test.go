@@ -0,0 +1,203 @@
+package rating
+
+import (
+	"backend/bff/trip"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// deliver adds a trip for DRV1 and BRK1 delivered at the given time
+func deliver(trips *trip.Store, id string, at time.Time) {
+	trips.Add(trip.Trip{
+		ID:       id,
+		DriverID: "DRV1",
+		BrokerID: "BRK1",
+		Status:   trip.StatusDelivered,
+		History:  []trip.Event{{From: trip.StatusReachedDrop, To: trip.StatusDelivered, At: at}},
+	})
+}
+
+// scores gives every criterion a broker rates a driver on the same score
+func scores(v int) map[Criterion]int {
+	out := map[Criterion]int{}
+	for _, c := range Criteria(RoleBroker) {
+		out[c] = v
+	}
+	return out
+}
+
+func TestSubmit(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		tripID  string
+		scores  map[Criterion]int
+		comment string
+		after   time.Duration // since delivery
+		wantErr error
+	}{
+		{name: "broker rates driver", userID: "BRK1", tripID: "TRK1", scores: scores(5)},
+		{name: "driver rates broker", userID: "DRV1", tripID: "TRK1", scores: map[Criterion]int{CriterionPaymentOnTime: 4, CriterionLoadAccuracy: 5, CriterionCommunication: 3}},
+		{name: "last day of the window", userID: "BRK1", tripID: "TRK1", scores: scores(5), after: 14 * 24 * time.Hour},
+		{name: "window closed", userID: "BRK1", tripID: "TRK1", scores: scores(5), after: 14*24*time.Hour + time.Second, wantErr: ErrWindowClosed},
+		{name: "not delivered", userID: "BRK1", tripID: "TRK2", scores: scores(5), wantErr: ErrNotDelivered},
+		{name: "outsider", userID: "BRK2", tripID: "TRK1", scores: scores(5), wantErr: ErrNotParticipant},
+		{name: "missing criterion", userID: "DRV1", tripID: "TRK1", scores: map[Criterion]int{CriterionPaymentOnTime: 4}, wantErr: ErrInvalidScore},
+		{name: "score above 5", userID: "BRK1", tripID: "TRK1", scores: scores(6), wantErr: ErrInvalidScore},
+		{name: "comment too long", userID: "BRK1", tripID: "TRK1", scores: scores(5), comment: strings.Repeat("x", maxComment+1), wantErr: ErrCommentTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := start.Add(tt.after)
+			now = func() time.Time { return at }
+			defer func() { now = time.Now }()
+
+			trips := trip.NewStore()
+			deliver(trips, "TRK1", start)
+			trips.Add(trip.Trip{ID: "TRK2", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusInTransit})
+			s := NewStore(trips)
+
+			r, err := s.Submit(tt.tripID, tt.userID, tt.scores, tt.comment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := s.Submit(tt.tripID, tt.userID, tt.scores, tt.comment); !errors.Is(err, ErrAlreadyRated) {
+				t.Errorf("second rating: %v, want %v", err, ErrAlreadyRated)
+			}
+			tr, _ := trips.Get(tt.tripID)
+			if s.Due(tr, tt.userID) {
+				t.Error("still due after rating")
+			}
+			if r.From != tt.userID || r.To == tt.userID {
+				t.Errorf("review from %s to %s", r.From, r.To)
+			}
+		})
+	}
+}
+
+func TestAggregateWeighsRecentReviews(t *testing.T) {
+	day := 24 * time.Hour
+	type review struct {
+		score int
+		age   time.Duration
+	}
+	tests := []struct {
+		name    string
+		reviews []review
+		want    float64
+	}{
+		{
+			name:    "same age is a plain mean",
+			reviews: []review{{5, 0}, {3, 0}},
+			want:    4,
+		},
+		{
+			// weights 1 and 0.5: (5 + 0.5*2) / 1.5
+			name:    "one half-life older counts half",
+			reviews: []review{{5, 0}, {2, 90 * day}},
+			want:    4,
+		},
+		{
+			// weights 1 and 0.25: (2 + 0.25*5) / 1.25
+			name:    "a recent poor trip outweighs an old good one",
+			reviews: []review{{2, 0}, {5, 180 * day}},
+			want:    2.6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := start
+			now = func() time.Time { return at }
+			defer func() { now = time.Now }()
+
+			trips := trip.NewStore()
+			s := NewStore(trips)
+			for i, r := range tt.reviews {
+				id := fmt.Sprintf("TRK%d", i+1)
+				at = start.Add(-r.age)
+				deliver(trips, id, at)
+				if _, err := s.Submit(id, "BRK1", scores(r.score), ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			at = start
+
+			a := s.Aggregate("DRV1")
+			if a.Score != tt.want || a.Count != len(tt.reviews) {
+				t.Errorf("score %.1f from %d reviews, want %.1f from %d", a.Score, a.Count, tt.want, len(tt.reviews))
+			}
+			if got := a.Criteria[CriterionPunctuality]; got != tt.want {
+				t.Errorf("punctuality %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateNew(t *testing.T) {
+	s := NewStore(trip.NewStore())
+	if a := s.Aggregate("DRV1"); a.Count != 0 || a.Text() != "New" {
+		t.Errorf("aggregate = %+v (%s), want New", a, a.Text())
+	}
+}
+
+func TestModeration(t *testing.T) {
+	trips := trip.NewStore()
+	deliver(trips, "TRK1", time.Now())
+	deliver(trips, "TRK2", time.Now())
+	s := NewStore(trips)
+	abusive, _ := s.Submit("TRK1", "BRK1", scores(1), "useless")
+	fair, _ := s.Submit("TRK2", "BRK1", scores(4), "")
+
+	tests := []struct {
+		name     string
+		reviewID string
+		reporter string
+		reason   Reason
+		wantErr  error
+	}{
+		{name: "someone else's review", reviewID: abusive.ID, reporter: "BRK1", reason: ReasonAbusive, wantErr: ErrNotYourReview},
+		{name: "unknown reason", reviewID: abusive.ID, reporter: "DRV1", reason: "boring", wantErr: ErrInvalidReason},
+		{name: "unknown review", reviewID: "RVW99999", reporter: "DRV1", reason: ReasonAbusive, wantErr: ErrReviewNotFound},
+		{name: "reported", reviewID: abusive.ID, reporter: "DRV1", reason: ReasonAbusive},
+		{name: "already under moderation", reviewID: abusive.ID, reporter: "DRV1", reason: ReasonFalse, wantErr: ErrAlreadyReported},
+	}
+	var report Report
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := s.Report(tt.reviewID, tt.reporter, tt.reason, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				report = p
+			}
+		})
+	}
+
+	if a := s.Aggregate("DRV1"); a.Count != 2 {
+		t.Fatalf("%d reviews count before moderation, want 2", a.Count)
+	}
+	if _, err := s.Resolve(report.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Resolve(report.ID, false); !errors.Is(err, ErrReportResolved) {
+		t.Errorf("second resolve: %v, want %v", err, ErrReportResolved)
+	}
+
+	// the upheld review no longer counts or shows
+	a := s.Aggregate("DRV1")
+	if a.Count != 1 || a.Score != 4 {
+		t.Errorf("aggregate = %.1f from %d, want 4.0 from 1", a.Score, a.Count)
+	}
+	if got := s.Received("DRV1"); len(got) != 1 || got[0].ID != fair.ID {
+		t.Errorf("received = %+v, want only %s", got, fair.ID)
+	}
+}
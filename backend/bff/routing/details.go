@@ -0,0 +1,48 @@
+package routing
+
+import (
+	"backend/bff"
+	"backend/bff/trip"
+	"fmt"
+	"time"
+)
+
+// Details returns the display fields of a trip that come from the ETA engine
+func (e *Engine) Details(t trip.Trip) map[string]string {
+	est, err := e.Estimate(t)
+	if err != nil {
+		return map[string]string{}
+	}
+
+	d := map[string]string{
+		"distance":      bff.Km(est.TotalKm),
+		"distanceLeft":  bff.Km(est.RemainingKm),
+		"progress":      fmt.Sprint(est.Progress),
+		"estimatedTime": bff.Hours(time.Duration(est.TotalKm / est.AvgSpeedKmh * float64(time.Hour))),
+		"eta":           bff.Hours(est.Remaining),
+		"delayed":       fmt.Sprint(est.Delayed),
+	}
+	if !est.ETA.IsZero() {
+		d["estimatedArrival"] = bff.DayTime(est.ETA)
+	}
+	if est.Delayed {
+		d["delay"] = bff.Hours(est.Delay)
+	}
+	return d
+}
+
+// LiveDetails is a trip's stored details with the fields its positions and
+// the ETA engine give now laid over them; the trip's own map is not touched
+func (e *Engine) LiveDetails(t trip.Trip) map[string]string {
+	d := make(map[string]string, len(t.Details))
+	for k, v := range t.Details {
+		d[k] = v
+	}
+	for k, v := range e.Tracks.LiveDetails(t) {
+		d[k] = v
+	}
+	for k, v := range e.Details(t) {
+		d[k] = v
+	}
+	return d
+}
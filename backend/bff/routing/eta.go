@@ -0,0 +1,121 @@
+package routing
+
+import (
+	"backend/bff/geo"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"math"
+	"time"
+)
+
+var now = time.Now
+
+// Estimate is the computed distance and time picture of a trip
+type Estimate struct {
+	TotalKm     float64       `json:"totalKm"`
+	RemainingKm float64       `json:"remainingKm"`
+	Progress    int           `json:"progress"`
+	AvgSpeedKmh float64       `json:"avgSpeedKmh"`
+	Remaining   time.Duration `json:"remaining"`
+	ETA         time.Time     `json:"eta"`
+	PromisedBy  time.Time     `json:"promisedBy,omitempty"`
+	Delayed     bool          `json:"delayed"`
+	Delay       time.Duration `json:"delay,omitempty"`
+}
+
+type Engine struct {
+	Provider DistanceProvider
+	Tracks   *tracking.Store
+
+	// Speed assumed until the track has enough history to average
+	DefaultSpeedKmh float64
+	// How far back the rolling average speed looks
+	Window time.Duration
+	// Minimum span of track needed before trusting the rolling average
+	MinSample time.Duration
+}
+
+// Default engine used by the BFF handlers
+var Default = &Engine{
+	Provider:        GreatCircle{RoadFactor: defaultRoadFactor},
+	Tracks:          tracking.Default,
+	DefaultSpeedKmh: 40,
+	Window:          3 * time.Hour,
+	MinSample:       15 * time.Minute,
+}
+
+func (e *Engine) Estimate(t trip.Trip) (Estimate, error) {
+	total, err := e.Provider.Distance(t.Pickup, t.Drop)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	est := Estimate{
+		TotalKm:     total,
+		RemainingKm: total,
+		AvgSpeedKmh: e.DefaultSpeedKmh,
+		PromisedBy:  t.PromisedBy,
+	}
+
+	switch t.Status {
+	case trip.StatusDelivered, trip.StatusPODUploaded, trip.StatusSettled:
+		est.RemainingKm = 0
+		est.Progress = 100
+		est.ETA, _ = t.Reached(trip.StatusDelivered)
+		return est, nil
+	case trip.StatusReachedDrop:
+		est.RemainingKm = 0
+		est.Progress = 100
+		est.ETA, _ = t.Reached(trip.StatusReachedDrop)
+		return est, nil
+	}
+
+	track := e.Tracks.Track(t.ID)
+	if t.Started() && len(track) > 0 {
+		last := track[len(track)-1]
+		remaining, err := e.Provider.Distance(last.Coord(), t.Drop)
+		if err != nil {
+			return Estimate{}, err
+		}
+		est.RemainingKm = math.Min(remaining, total)
+		if speed, ok := e.averageSpeed(track); ok {
+			est.AvgSpeedKmh = speed
+		}
+	}
+
+	if total > 0 {
+		est.Progress = int(math.Round(100 * (1 - est.RemainingKm/total)))
+	}
+	est.Remaining = time.Duration(est.RemainingKm / est.AvgSpeedKmh * float64(time.Hour))
+	est.ETA = now().Add(est.Remaining).Truncate(time.Minute)
+
+	if !t.PromisedBy.IsZero() && est.ETA.After(t.PromisedBy) {
+		est.Delayed = true
+		est.Delay = est.ETA.Sub(t.PromisedBy)
+	}
+	return est, nil
+}
+
+// averageSpeed is the distance driven over elapsed time within the rolling window
+func (e *Engine) averageSpeed(track []tracking.Point) (float64, bool) {
+	end := track[len(track)-1].Timestamp
+	start := end.Add(-e.Window)
+
+	var km float64
+	var first time.Time
+	for i := 1; i < len(track); i++ {
+		if track[i-1].Timestamp.Before(start) {
+			continue
+		}
+		if first.IsZero() {
+			first = track[i-1].Timestamp
+		}
+		km += geo.Haversine(track[i-1].Coord(), track[i].Coord())
+	}
+
+	elapsed := end.Sub(first)
+	if first.IsZero() || elapsed < e.MinSample || km == 0 {
+		return 0, false
+	}
+	return km / elapsed.Hours(), true
+}
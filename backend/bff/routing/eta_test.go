@@ -0,0 +1,147 @@
+package routing
+
+import (
+	"backend/bff/geo"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"testing"
+	"time"
+)
+
+func TestEstimate(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	// a trip two degrees due north, so each degree driven is a fixed distance
+	pickup := geo.Coord{Lat: 18, Lng: 73}
+	drop := geo.Coord{Lat: 20, Lng: 73}
+	degree := geo.Haversine(pickup, geo.Coord{Lat: 19, Lng: 73})
+	point := func(lat float64, ago time.Duration) tracking.Point {
+		return tracking.Point{Lat: lat, Lng: 73, Accuracy: 10, Timestamp: at.Add(-ago)}
+	}
+
+	tests := []struct {
+		name          string
+		status        trip.Status
+		promisedBy    time.Time
+		track         []tracking.Point
+		wantRemaining float64 // km
+		wantSpeed     float64 // km/h
+		wantTime      time.Duration
+		wantProgress  int
+		wantDelay     time.Duration
+	}{
+		{
+			name:          "not started uses the default speed",
+			status:        trip.StatusAssigned,
+			wantRemaining: 2 * degree,
+			wantSpeed:     degree / 2,
+			wantTime:      4 * time.Hour,
+		},
+		{
+			name:          "halfway at the tracked average",
+			status:        trip.StatusInTransit,
+			track:         []tracking.Point{point(18, 2*time.Hour), point(18.5, time.Hour), point(19, 0)},
+			wantRemaining: degree,
+			wantSpeed:     degree / 2,
+			wantTime:      2 * time.Hour,
+			wantProgress:  50,
+		},
+		{
+			name:          "late against the promised time",
+			status:        trip.StatusInTransit,
+			promisedBy:    at.Add(time.Hour),
+			track:         []tracking.Point{point(18, 2*time.Hour), point(18.5, time.Hour), point(19, 0)},
+			wantRemaining: degree,
+			wantSpeed:     degree / 2,
+			wantTime:      2 * time.Hour,
+			wantProgress:  50,
+			wantDelay:     time.Hour,
+		},
+		{
+			name:          "too short a track keeps the default speed",
+			status:        trip.StatusInTransit,
+			track:         []tracking.Point{point(18.9, 5*time.Minute), point(19, 0)},
+			wantRemaining: degree,
+			wantSpeed:     degree / 2,
+			wantTime:      2 * time.Hour,
+			wantProgress:  50,
+		},
+		{
+			name:         "delivered",
+			status:       trip.StatusDelivered,
+			wantProgress: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{
+				Provider:        GreatCircle{RoadFactor: 1},
+				Tracks:          tracking.NewStore(),
+				DefaultSpeedKmh: degree / 2,
+				Window:          3 * time.Hour,
+				MinSample:       15 * time.Minute,
+			}
+			tr := trip.Trip{ID: "TRK1", Status: tt.status, Pickup: pickup, Drop: drop, PromisedBy: tt.promisedBy}
+			if tt.status == trip.StatusDelivered {
+				tr.History = []trip.Event{{From: trip.StatusReachedDrop, To: trip.StatusDelivered, At: at.Add(-time.Hour)}}
+			}
+			if len(tt.track) > 0 {
+				if _, err := e.Tracks.Ingest(tr.ID, tt.track); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			est, err := e.Estimate(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(est.RemainingKm, tt.wantRemaining) || est.Progress != tt.wantProgress {
+				t.Errorf("remaining %.1f km at %d%%, want %.1f km at %d%%", est.RemainingKm, est.Progress, tt.wantRemaining, tt.wantProgress)
+			}
+			if tt.status == trip.StatusDelivered {
+				if !est.ETA.Equal(at.Add(-time.Hour)) {
+					t.Errorf("ETA = %s, want the delivery time", est.ETA)
+				}
+				return
+			}
+			if !near(est.AvgSpeedKmh, tt.wantSpeed) {
+				t.Errorf("speed = %.2f km/h, want %.2f", est.AvgSpeedKmh, tt.wantSpeed)
+			}
+			if d := est.Remaining - tt.wantTime; d < -time.Second || d > time.Second {
+				t.Errorf("remaining time = %s, want %s", est.Remaining, tt.wantTime)
+			}
+			if d := est.ETA.Sub(at.Add(tt.wantTime)); d < -time.Minute || d > 0 {
+				t.Errorf("ETA = %s, want %s", est.ETA, at.Add(tt.wantTime))
+			}
+			if est.Delayed != (tt.wantDelay > 0) {
+				t.Errorf("delayed = %v, want %v", est.Delayed, tt.wantDelay > 0)
+			}
+			if d := est.Delay - tt.wantDelay; d < -time.Minute || d > time.Minute {
+				t.Errorf("delay = %s, want %s", est.Delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestGreatCircleRoute(t *testing.T) {
+	from := geo.Coord{Lat: 19.0760, Lng: 72.8777}
+	to := geo.Coord{Lat: 18.5204, Lng: 73.8567}
+	route, err := GreatCircle{}.Route(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route[0] != from || route[len(route)-1] != to {
+		t.Errorf("route runs %s to %s, want %s to %s", route[0], route[len(route)-1], from, to)
+	}
+	for i := 1; i < len(route); i++ {
+		if km := geo.Haversine(route[i-1], route[i]); km > routeStepKm {
+			t.Errorf("vertices %d and %d are %.1f km apart, want at most %.0f", i-1, i, km, routeStepKm)
+		}
+	}
+}
+
+func near(got, want float64) bool {
+	return got-want < 0.01 && want-got < 0.01
+}
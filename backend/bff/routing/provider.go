@@ -0,0 +1,24 @@
+package routing
+
+import "backend/bff/geo"
+
+// DistanceProvider returns the road distance between two coordinates in km
+type DistanceProvider interface {
+	Distance(from, to geo.Coord) (float64, error)
+}
+
+// Indian highway routes run roughly 30% longer than the straight line
+const defaultRoadFactor = 1.3
+
+// GreatCircle estimates road distance offline from the great-circle distance
+type GreatCircle struct {
+	RoadFactor float64
+}
+
+func (g GreatCircle) Distance(from, to geo.Coord) (float64, error) {
+	factor := g.RoadFactor
+	if factor <= 0 {
+		factor = defaultRoadFactor
+	}
+	return geo.Haversine(from, to) * factor, nil
+}
@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"math"
+)
+
+// RouteProvider returns the path a truck is expected to drive between two coordinates
+type RouteProvider interface {
+	Route(from, to geo.Coord) ([]geo.Coord, error)
+}
+
+// Spacing of the vertices GreatCircle puts on a planned route
+const routeStepKm = 25.0
+
+// Route approximates the road path with evenly spaced points on the straight line
+func (g GreatCircle) Route(from, to geo.Coord) ([]geo.Coord, error) {
+	steps := int(math.Ceil(geo.Haversine(from, to) / routeStepKm))
+	if steps < 1 {
+		steps = 1
+	}
+	route := make([]geo.Coord, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		route = append(route, geo.Lerp(from, to, float64(i)/float64(steps)))
+	}
+	return route, nil
+}
+
+// PlannedRoute returns the expected path from pickup to drop
+func (e *Engine) PlannedRoute(t trip.Trip) []geo.Coord {
+	if rp, ok := e.Provider.(RouteProvider); ok {
+		if route, err := rp.Route(t.Pickup, t.Drop); err == nil && len(route) > 1 {
+			return route
+		}
+	}
+	return []geo.Coord{t.Pickup, t.Drop}
+}
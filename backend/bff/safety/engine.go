@@ -0,0 +1,275 @@
+package safety
+
+import (
+	"backend/bff/geo"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// GPS speeds only give a usable acceleration between close fixes
+	maxAccelGap = 10 * time.Second
+	// readings this close together are one harsh event, not several
+	harshDebounce = 5 * time.Second
+	// anything stronger is the phone being dropped, not the truck
+	maxMotion = 20.0
+	// fixes further apart than this say nothing about the speed between them
+	maxSpeedGap = 2 * time.Minute
+)
+
+type state struct {
+	last    tracking.Point
+	speed   float64 // km/h at the last fix
+	samples int
+	km      float64
+	driving time.Duration
+	motion  bool // the phone sends accelerometer readings
+
+	since   time.Time // start of the current continuous drive
+	stopped time.Time // when the truck last slowed below moving speed
+
+	// open stretches, as indexes into events
+	overspeed, night, long int
+
+	events []Event
+}
+
+func newState() *state {
+	return &state{overspeed: -1, night: -1, long: -1}
+}
+
+// Engine scores how safely trips are driven from their location stream and
+// the phone's accelerometer
+type Engine struct {
+	Trips      *trip.Store
+	Thresholds Thresholds
+
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+func NewEngine(trips *trip.Store) *Engine {
+	return &Engine{
+		Trips:      trips,
+		Thresholds: DefaultThresholds,
+		states:     map[string]*state{},
+	}
+}
+
+// Default engine used by the BFF handlers
+var Default = seed(NewEngine(trip.Default), tracking.Default)
+
+// Observe runs a trip's newly accepted points and accelerometer readings
+// through the detectors and returns the events they started
+func (e *Engine) Observe(t trip.Trip, points []tracking.Point, motion []Motion) []Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.states[t.ID]
+	if !ok {
+		st = newState()
+		e.states[t.ID] = st
+	}
+	before := len(st.events)
+
+	// accelerometer readings beat speeds differenced from GPS
+	readings := append([]Motion(nil), motion...)
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].Timestamp.Before(readings[j].Timestamp) })
+	latest := now().Add(5 * time.Minute)
+	for _, m := range readings {
+		if m.Timestamp.IsZero() || m.Timestamp.After(latest) || math.Abs(m.Longitudinal) > maxMotion {
+			continue
+		}
+		st.motion = true
+		e.harsh(st, m.Longitudinal, m.Timestamp, st.last.Coord())
+	}
+	for _, p := range points {
+		e.point(st, p)
+	}
+
+	return append([]Event(nil), st.events[before:]...)
+}
+
+// point advances every detector by one GPS fix
+func (e *Engine) point(st *state, p tracking.Point) {
+	th := e.Thresholds
+	speed := p.Speed
+	if st.samples > 0 {
+		gap := p.Timestamp.Sub(st.last.Timestamp)
+		if gap <= 0 {
+			return
+		}
+		km := geo.Haversine(st.last.Coord(), p.Coord())
+		st.km += km
+		if speed == 0 && gap <= maxSpeedGap {
+			speed = km / gap.Hours()
+		}
+
+		if !st.motion && gap <= maxAccelGap {
+			e.harsh(st, (speed-st.speed)/3.6/gap.Seconds(), p.Timestamp, p.Coord())
+		}
+
+		// a long gap without fixes is a break when the truck barely moved
+		if gap >= th.MinBreak && km/gap.Hours() < th.MovingSpeed {
+			st.rest(st.last.Timestamp)
+		} else if st.speed >= th.MovingSpeed || speed >= th.MovingSpeed {
+			st.driving += gap
+		}
+	}
+	st.samples++
+	st.last, st.speed = p, speed
+
+	moving := speed >= th.MovingSpeed
+	if moving {
+		st.stopped = time.Time{}
+		if st.since.IsZero() {
+			st.since = p.Timestamp
+		}
+		if due := st.since.Add(th.MaxContinuous); st.long < 0 && p.Timestamp.After(due) {
+			st.long = st.open(Event{Kind: KindLongDrive, Severity: SeverityMajor, Start: due, Location: p.Coord()})
+		}
+	} else {
+		if st.stopped.IsZero() {
+			st.stopped = p.Timestamp
+		}
+		if p.Timestamp.Sub(st.stopped) >= th.MinBreak {
+			st.rest(st.stopped)
+		}
+	}
+	st.extend(st.long, p.Timestamp, 0)
+
+	// overspeeding lasts as long as the truck stays over the limit
+	if speed > th.SpeedLimit {
+		if st.overspeed < 0 {
+			st.overspeed = st.open(Event{Kind: KindOverspeed, Severity: SeverityMinor, Start: p.Timestamp, Location: p.Coord()})
+		}
+		st.extend(st.overspeed, p.Timestamp, speed)
+		if speed-th.SpeedLimit >= th.MajorExcess {
+			st.events[st.overspeed].Severity = SeverityMajor
+		}
+	} else {
+		st.close(&st.overspeed, p.Timestamp)
+	}
+
+	// night driving lasts until the truck stops or the sun is up
+	if moving && th.night(p.Timestamp) {
+		if st.night < 0 {
+			st.night = st.open(Event{Kind: KindNight, Severity: SeverityMinor, Start: p.Timestamp, Location: p.Coord()})
+		}
+		st.extend(st.night, p.Timestamp, 0)
+		if st.events[st.night].Duration() >= 2*time.Hour {
+			st.events[st.night].Severity = SeverityMajor
+		}
+	} else {
+		st.close(&st.night, p.Timestamp)
+	}
+}
+
+// harsh records a braking or acceleration force past its threshold
+func (e *Engine) harsh(st *state, accel float64, at time.Time, where geo.Coord) {
+	th := e.Thresholds
+	kind, limit := KindHarshAccel, th.HarshAccel
+	if accel < 0 {
+		kind, limit = KindHarshBrake, th.HarshBrake
+	}
+	force := math.Abs(accel)
+	if force < limit {
+		return
+	}
+
+	severity := SeverityMinor
+	if force >= 1.5*limit {
+		severity = SeverityMajor
+	}
+	for i := len(st.events) - 1; i >= 0; i-- {
+		prev := &st.events[i]
+		if prev.Kind != kind {
+			continue
+		}
+		if at.Sub(prev.End) < harshDebounce {
+			prev.End = at
+			if force > prev.Peak {
+				prev.Peak, prev.Severity = force, severity
+			}
+			return
+		}
+		break
+	}
+	st.events = append(st.events, Event{Kind: kind, Severity: severity, Start: at, End: at, Location: where, Peak: force})
+}
+
+// rest ends the current drive at a break that started at
+func (st *state) rest(at time.Time) {
+	st.close(&st.long, at)
+	st.close(&st.night, at)
+	st.since = time.Time{}
+}
+
+func (st *state) open(ev Event) int {
+	ev.End = ev.Start
+	st.events = append(st.events, ev)
+	return len(st.events) - 1
+}
+
+func (st *state) extend(i int, at time.Time, peak float64) {
+	if i < 0 {
+		return
+	}
+	if at.After(st.events[i].End) {
+		st.events[i].End = at
+	}
+	if peak > st.events[i].Peak {
+		st.events[i].Peak = peak
+	}
+}
+
+func (st *state) close(i *int, at time.Time) {
+	if *i < 0 {
+		return
+	}
+	if at.Before(st.events[*i].End) {
+		at = st.events[*i].End
+	}
+	st.events[*i].End = at
+	*i = -1
+}
+
+// Report scores a trip from the events seen so far
+func (e *Engine) Report(tripID string) Report {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := Report{TripID: tripID, Score: 100, Breakdown: []Count{}, Events: []Event{}}
+	st, ok := e.states[tripID]
+	if !ok {
+		return r
+	}
+	r.Samples = st.samples
+	r.Km = math.Round(10*st.km) / 10
+	r.Driving = st.driving
+	r.Events = append(r.Events, st.events...)
+
+	counts := map[Kind]*Count{}
+	var penalty float64
+	for _, ev := range st.events {
+		c, ok := counts[ev.Kind]
+		if !ok {
+			c = &Count{Kind: ev.Kind, Label: ev.Kind.Label()}
+			counts[ev.Kind] = c
+		}
+		c.Count++
+		c.Penalty += ev.Penalty()
+		penalty += ev.Penalty()
+	}
+	for _, k := range Kinds {
+		if c, ok := counts[k]; ok {
+			r.Breakdown = append(r.Breakdown, *c)
+		}
+	}
+	r.Score = int(math.Max(0, 100-penalty))
+	return r
+}
@@ -0,0 +1,219 @@
+package safety
+
+import (
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"testing"
+	"time"
+)
+
+var (
+	day   = time.Date(2025, 3, 1, 4, 30, 0, 0, time.UTC)  // 10 AM IST
+	night = time.Date(2025, 3, 1, 17, 30, 0, 0, time.UTC) // 11 PM IST
+)
+
+// drive is a fix every step heading due north, each one as far from the last
+// as its speed covers, so speeds worked out from the fixes match
+func drive(from time.Time, step time.Duration, speeds ...float64) []tracking.Point {
+	var points []tracking.Point
+	lat := 19.0
+	for i, s := range speeds {
+		if i > 0 {
+			lat += s * step.Hours() / 111.195
+		}
+		points = append(points, tracking.Point{Lat: lat, Lng: 73, Speed: s, Timestamp: from.Add(time.Duration(i) * step)})
+	}
+	return points
+}
+
+// repeat is n copies of a speed
+func repeat(speed float64, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = speed
+	}
+	return s
+}
+
+func TestObserve(t *testing.T) {
+	now = func() time.Time { return day.Add(24 * time.Hour) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name   string
+		points []tracking.Point
+		motion []Motion
+		want   []Event
+	}{
+		{
+			name:   "within the limit",
+			points: drive(day, time.Minute, 60, 70, 80, 70),
+		},
+		{
+			name:   "overspeeding is one stretch",
+			points: drive(day, time.Minute, 60, 85, 90, 85, 60),
+			want:   []Event{{Kind: KindOverspeed, Severity: SeverityMinor}},
+		},
+		{
+			name:   "far over the limit is major",
+			points: drive(day, time.Minute, 60, 85, 105, 60),
+			want:   []Event{{Kind: KindOverspeed, Severity: SeverityMajor}},
+		},
+		{
+			name:   "hard stop felt by the phone",
+			motion: []Motion{{Longitudinal: -4, Timestamp: day}},
+			want:   []Event{{Kind: KindHarshBrake, Severity: SeverityMinor}},
+		},
+		{
+			name:   "readings close together are one event",
+			motion: []Motion{{Longitudinal: -6, Timestamp: day.Add(2 * time.Second)}, {Longitudinal: -4, Timestamp: day}},
+			want:   []Event{{Kind: KindHarshBrake, Severity: SeverityMajor}},
+		},
+		{
+			name:   "flooring it",
+			motion: []Motion{{Longitudinal: 3, Timestamp: day}},
+			want:   []Event{{Kind: KindHarshAccel, Severity: SeverityMinor}},
+		},
+		{
+			name:   "a dropped phone is ignored",
+			motion: []Motion{{Longitudinal: -30, Timestamp: day}},
+		},
+		{
+			name:   "readings from the future are ignored",
+			motion: []Motion{{Longitudinal: -6, Timestamp: day.Add(48 * time.Hour)}},
+		},
+		{
+			name:   "hard stop seen in close fixes",
+			points: drive(day, 4*time.Second, 60, 0),
+			want:   []Event{{Kind: KindHarshBrake, Severity: SeverityMinor}},
+		},
+		{
+			name:   "fixes too far apart for a stop to be harsh",
+			points: drive(day, time.Minute, 60, 0),
+		},
+		{
+			name:   "driving at night",
+			points: drive(night, 10*time.Minute, repeat(60, 7)...),
+			want:   []Event{{Kind: KindNight, Severity: SeverityMinor}},
+		},
+		{
+			name:   "two hours at night is major",
+			points: drive(night, 10*time.Minute, repeat(60, 13)...),
+			want:   []Event{{Kind: KindNight, Severity: SeverityMajor}},
+		},
+		{
+			name:   "five hours without a break",
+			points: drive(day, 10*time.Minute, repeat(60, 31)...),
+			want:   []Event{{Kind: KindLongDrive, Severity: SeverityMajor}},
+		},
+		{
+			name:   "five hours with a break",
+			points: drive(day, 10*time.Minute, append(append(repeat(60, 19), 0, 0, 0), repeat(60, 13)...)...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(trip.NewStore())
+			got := e.Observe(trip.Trip{ID: "TRK1"}, tt.points, tt.motion)
+			if len(got) != len(tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			for i, ev := range got {
+				if ev.Kind != tt.want[i].Kind || ev.Severity != tt.want[i].Severity {
+					t.Errorf("event %d = %s %s, want %s %s", i, ev.Severity, ev.Kind, tt.want[i].Severity, tt.want[i].Kind)
+				}
+			}
+		})
+	}
+}
+
+func TestOverspeedStretch(t *testing.T) {
+	e := NewEngine(trip.NewStore())
+	points := drive(day, time.Minute, 60, 85, 90, 85, 60)
+
+	// the stretch stays open until the truck is back under the limit
+	e.Observe(trip.Trip{ID: "TRK1"}, points[:3], nil)
+	if got := e.Observe(trip.Trip{ID: "TRK1"}, points[3:], nil); len(got) != 0 {
+		t.Errorf("later fixes started %+v, want the open stretch extended", got)
+	}
+
+	r := e.Report("TRK1")
+	if len(r.Events) != 1 {
+		t.Fatalf("events = %+v, want one", r.Events)
+	}
+	ev := r.Events[0]
+	if !ev.Start.Equal(points[1].Timestamp) || !ev.End.Equal(points[4].Timestamp) || ev.Peak != 90 {
+		t.Errorf("overspeeding %s to %s at %.0f km/h, want %s to %s at 90", ev.Start, ev.End, ev.Peak, points[1].Timestamp, points[4].Timestamp)
+	}
+}
+
+func TestLongDriveStartsWhenTheBreakWasDue(t *testing.T) {
+	e := NewEngine(trip.NewStore())
+	got := e.Observe(trip.Trip{ID: "TRK1"}, drive(day, 10*time.Minute, repeat(60, 31)...), nil)
+	if len(got) != 1 {
+		t.Fatalf("events = %+v, want one", got)
+	}
+	if want := day.Add(DefaultThresholds.MaxContinuous); !got[0].Start.Equal(want) {
+		t.Errorf("start = %s, want %s", got[0].Start, want)
+	}
+	if got[0].Duration() != time.Hour {
+		t.Errorf("duration = %s, want 1h", got[0].Duration())
+	}
+}
+
+func TestPenalty(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   Event
+		want float64
+	}{
+		{name: "minor overspeeding", ev: Event{Kind: KindOverspeed, Severity: SeverityMinor}, want: 2},
+		{name: "major overspeeding", ev: Event{Kind: KindOverspeed, Severity: SeverityMajor}, want: 5},
+		{name: "minor harsh braking", ev: Event{Kind: KindHarshBrake, Severity: SeverityMinor}, want: 3},
+		{name: "major harsh acceleration", ev: Event{Kind: KindHarshAccel, Severity: SeverityMajor}, want: 3},
+		{name: "a few minutes at night", ev: Event{Kind: KindNight, Start: night, End: night.Add(10 * time.Minute)}, want: 1},
+		{name: "two hours at night", ev: Event{Kind: KindNight, Start: night, End: night.Add(2 * time.Hour)}, want: 4},
+		{name: "break due just now", ev: Event{Kind: KindLongDrive, Start: day, End: day}, want: 5},
+		{name: "an hour past the break", ev: Event{Kind: KindLongDrive, Start: day, End: day.Add(time.Hour)}, want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ev.Penalty(); got != tt.want {
+				t.Errorf("penalty = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	now = func() time.Time { return day.Add(24 * time.Hour) }
+	defer func() { now = time.Now }()
+
+	e := NewEngine(trip.NewStore())
+	if r := e.Report("TRK1"); r.Score != 100 || r.Text() != "" {
+		t.Errorf("before telemetry score %d %q, want 100 and no text", r.Score, r.Text())
+	}
+
+	e.Observe(trip.Trip{ID: "TRK1"}, drive(day, time.Minute, 60, 85, 105, 60), []Motion{{Longitudinal: -4, Timestamp: day}})
+	r := e.Report("TRK1")
+	if r.Score != 92 || r.Text() != "92" {
+		t.Errorf("score = %d %q, want 92", r.Score, r.Text())
+	}
+	if r.Samples != 4 || r.Driving != 3*time.Minute {
+		t.Errorf("%d samples over %s driving, want 4 over 3m", r.Samples, r.Driving)
+	}
+
+	// breakdown follows the order the apps list kinds in
+	want := []Count{
+		{Kind: KindOverspeed, Label: "Overspeeding", Count: 1, Penalty: 5},
+		{Kind: KindHarshBrake, Label: "Harsh Braking", Count: 1, Penalty: 3},
+	}
+	if len(r.Breakdown) != len(want) {
+		t.Fatalf("breakdown = %+v, want %+v", r.Breakdown, want)
+	}
+	for i, c := range r.Breakdown {
+		if c != want[i] {
+			t.Errorf("breakdown %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package safety
+
+import (
+	"backend/bff"
+	"github.com/gin-gonic/gin"
+)
+
+// TripReport returns a trip's safety score and events to its driver or
+// broker: GET /bff/safety/:tripId?userId=
+func TripReport(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	userID := bff.UserID(c)
+	t, err := Default.Trips.Get(c.Param("tripId"))
+	if err != nil {
+		c.JSON(404, bff.ActionResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if userID == "" || (userID != t.DriverID && userID != t.BrokerID) {
+		c.JSON(403, bff.ActionResponse{Status: "error", Message: "only the trip's driver and broker can see its safety report"})
+		return
+	}
+
+	c.JSON(200, bff.ActionResponse{
+		Status: "success",
+		Data:   gin.H{"report": Default.Report(t.ID)},
+	})
+}
@@ -0,0 +1,178 @@
+package safety
+
+import (
+	"backend/bff/geo"
+	"math"
+	"strconv"
+	"time"
+)
+
+var now = time.Now
+
+var ist = time.FixedZone("IST", 5*3600+1800)
+
+// Kind is a type of risky driving the engine looks for
+type Kind string
+
+const (
+	KindOverspeed  Kind = "overspeed"
+	KindHarshBrake Kind = "harsh_brake"
+	KindHarshAccel Kind = "harsh_acceleration"
+	KindNight      Kind = "night_driving"
+	KindLongDrive  Kind = "long_driving"
+)
+
+// Kinds in the order the apps list them
+var Kinds = []Kind{KindOverspeed, KindHarshBrake, KindHarshAccel, KindNight, KindLongDrive}
+
+func (k Kind) Label() string {
+	switch k {
+	case KindOverspeed:
+		return "Overspeeding"
+	case KindHarshBrake:
+		return "Harsh Braking"
+	case KindHarshAccel:
+		return "Harsh Acceleration"
+	case KindNight:
+		return "Night Driving"
+	case KindLongDrive:
+		return "Driving Without a Break"
+	}
+	return string(k)
+}
+
+func (k Kind) Icon() string {
+	switch k {
+	case KindOverspeed:
+		return "speedometer-outline"
+	case KindHarshBrake:
+		return "hand-left-outline"
+	case KindHarshAccel:
+		return "flash-outline"
+	case KindNight:
+		return "moon-outline"
+	case KindLongDrive:
+		return "time-outline"
+	}
+	return "warning-outline"
+}
+
+type Severity string
+
+const (
+	SeverityMinor Severity = "minor"
+	SeverityMajor Severity = "major"
+)
+
+// Thresholds decide what counts as risky driving for a loaded truck
+type Thresholds struct {
+	SpeedLimit  float64 // km/h
+	MajorExcess float64 // km/h over the limit that makes overspeeding major
+	HarshBrake  float64 // m/s² of deceleration
+	HarshAccel  float64 // m/s² of acceleration
+	NightFrom   int     // hour of the day, IST
+	NightTo     int
+	// MaxContinuous is the longest drive before a break is expected; a
+	// break is at least MinBreak below MovingSpeed
+	MaxContinuous time.Duration
+	MinBreak      time.Duration
+	MovingSpeed   float64 // km/h
+}
+
+var DefaultThresholds = Thresholds{
+	SpeedLimit:    80,
+	MajorExcess:   20,
+	HarshBrake:    3.5,
+	HarshAccel:    2.5,
+	NightFrom:     22,
+	NightTo:       5,
+	MaxContinuous: 4 * time.Hour,
+	MinBreak:      15 * time.Minute,
+	MovingSpeed:   5,
+}
+
+func (th Thresholds) night(t time.Time) bool {
+	h := t.In(ist).Hour()
+	if th.NightFrom > th.NightTo {
+		return h >= th.NightFrom || h < th.NightTo
+	}
+	return h >= th.NightFrom && h < th.NightTo
+}
+
+// Motion is an accelerometer reading from the driver's phone, along the
+// direction of travel
+type Motion struct {
+	Longitudinal float64   `json:"longitudinal"` // m/s², positive when speeding up
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Event is one moment or stretch of risky driving
+type Event struct {
+	Kind     Kind      `json:"kind"`
+	Severity Severity  `json:"severity"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Location geo.Coord `json:"location"`
+	// Peak is the top speed in km/h for overspeeding and the strongest
+	// force in m/s² for harsh braking and acceleration
+	Peak float64 `json:"peak,omitempty"`
+}
+
+func (e Event) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Penalty is how many points the event takes off the trip's score
+func (e Event) Penalty() float64 {
+	switch e.Kind {
+	case KindOverspeed:
+		if e.Severity == SeverityMajor {
+			return 5
+		}
+		return 2
+	case KindHarshBrake:
+		if e.Severity == SeverityMajor {
+			return 4
+		}
+		return 3
+	case KindHarshAccel:
+		if e.Severity == SeverityMajor {
+			return 3
+		}
+		return 2
+	case KindNight:
+		// two points an hour, at least one
+		return math.Max(1, math.Round(2*e.Duration().Hours()))
+	case KindLongDrive:
+		// the event starts when the break was due; every hour past it costs more
+		return 5 + math.Round(2*e.Duration().Hours())
+	}
+	return 0
+}
+
+// Count is the events of one kind on a trip
+type Count struct {
+	Kind    Kind    `json:"kind"`
+	Label   string  `json:"label"`
+	Count   int     `json:"count"`
+	Penalty float64 `json:"penalty"`
+}
+
+// Report is a trip's safety score out of 100 with what took points off
+type Report struct {
+	TripID    string        `json:"tripId"`
+	Score     int           `json:"score"`
+	Samples   int           `json:"samples"`
+	Km        float64       `json:"km"`
+	Driving   time.Duration `json:"driving"`
+	Breakdown []Count       `json:"breakdown"`
+	Events    []Event       `json:"events"`
+}
+
+// Text is the score as the apps show it, empty before any telemetry
+func (r Report) Text() string {
+	if r.Samples == 0 {
+		return ""
+	}
+	return strconv.Itoa(r.Score)
+}
@@ -0,0 +1,21 @@
+package safety
+
+import (
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"time"
+)
+
+// seed replays the demo trips' tracks, with a hard stop the phone felt on
+// the way to Pune
+func seed(e *Engine, tracks *tracking.Store) *Engine {
+	for _, t := range e.Trips.List(func(trip.Trip) bool { return true }) {
+		if points := tracks.Track(t.ID); len(points) > 0 {
+			e.Observe(t, points, nil)
+		}
+	}
+	if t, err := e.Trips.Get("TRIP-003"); err == nil {
+		e.Observe(t, nil, []Motion{{Longitudinal: -4.1, Timestamp: now().Add(-95 * time.Minute)}})
+	}
+	return e
+}
@@ -0,0 +1,73 @@
+package settlement
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/trip"
+	"time"
+)
+
+func seed(s *Store) *Store {
+	start := now().Add(-96 * time.Hour)
+	broker := trip.DemoBrokerID
+
+	payments := []Payment{
+		{
+			ID: "TRK-2455", DriverID: "DRV005", DriverName: "Anil Sharma", DriverPhone: "+91 98765 43213", TruckNumber: "KA05 GH 3456",
+			Cargo: "Machinery", From: "Chennai", To: "Kolkata", Distance: "1700 km",
+			Gross: ledger.Rupees(28900), Commission: ledger.Rupees(2890), POD: PODApproved,
+		},
+		{
+			ID: "TRK-2456", DriverID: "DRV003", DriverName: "Vikram Singh", DriverPhone: "+91 98765 43212", TruckNumber: "DL09 EF 9012",
+			Cargo: "Automobile Parts", From: "Pune", To: "Bangalore", Distance: "850 km",
+			Gross: ledger.Rupees(15800), Commission: ledger.Rupees(1580), POD: PODApproved,
+		},
+		{
+			ID: "TRK-2457", DriverID: "DRV004", DriverName: "Suresh Patel", DriverPhone: "+91 98765 43211", TruckNumber: "GJ01 CD 5678",
+			Cargo: "Textiles", From: "Ahmedabad", To: "Chennai", Distance: "1600 km",
+			Gross: ledger.Rupees(22300), Commission: ledger.Rupees(2230), POD: PODApproved,
+		},
+		{
+			ID: "TRK-2458", TripID: "TRK789012", DriverID: trip.DemoDriverID, DriverName: "Rajesh Kumar", DriverPhone: "+91 98765 43210", TruckNumber: "MH12 AB 1234",
+			Cargo: "Electronics", From: "Mumbai", To: "Delhi", Distance: "1400 km",
+			Gross: ledger.Rupees(18500), Commission: ledger.Rupees(1850),
+		},
+	}
+	for i, p := range payments {
+		p.BrokerID = broker
+		p.BrokerName = "Sharma Logistics Pvt. Ltd."
+		p.CreatedAt = start.Add(time.Duration(i) * 24 * time.Hour)
+		s.Add(p)
+	}
+
+	// two of the trips are already paid in full
+	for _, id := range []string{"TRK-2455", "TRK-2457"} {
+		if _, _, err := s.Release(id, ReleaseRequest{Note: "Full payment"}); err != nil {
+			panic(err)
+		}
+	}
+
+	// last month's trips, settled back then
+	lastMonth := ledger.MonthStart(now()).AddDate(0, -1, 9)
+	history := []Payment{
+		{
+			ID: "TRK-2441", DriverID: "DRV004", DriverName: "Suresh Patel", DriverPhone: "+91 98765 43211", TruckNumber: "GJ01 CD 5678",
+			Cargo: "Cement", From: "Surat", To: "Jaipur", Distance: "780 km",
+			Gross: ledger.Rupees(16400), Commission: ledger.Rupees(1640), POD: PODApproved,
+		},
+		{
+			ID: "TRK-2446", DriverID: "DRV005", DriverName: "Anil Sharma", DriverPhone: "+91 98765 43213", TruckNumber: "KA05 GH 3456",
+			Cargo: "FMCG Goods", From: "Bangalore", To: "Hyderabad", Distance: "570 km",
+			Gross: ledger.Rupees(21500), Commission: ledger.Rupees(2150), POD: PODApproved,
+		},
+	}
+	for i, p := range history {
+		p.BrokerID = broker
+		p.BrokerName = "Sharma Logistics Pvt. Ltd."
+		p.CreatedAt = lastMonth.Add(time.Duration(i) * 72 * time.Hour)
+		s.Add(p)
+		if _, _, err := s.release(p.ID, ReleaseRequest{Note: "Full payment"}, p.CreatedAt.Add(48*time.Hour)); err != nil {
+			panic(err)
+		}
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package settlement
+
+import (
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotFound          = errors.New("payment not found")
+	ErrPODNotSubmitted   = errors.New("proof of delivery has not been submitted")
+	ErrPODNotApproved    = errors.New("balance payment unlocks after the proof of delivery is approved")
+	ErrAlreadySettled    = errors.New("payment is already settled")
+	ErrInvalidAmount     = errors.New("release amount must be positive")
+	ErrOverpayment       = errors.New("release exceeds the balance due")
+	ErrDeductionReason   = errors.New("every deduction needs a reason and a positive amount")
+	ErrRejectionRequired = errors.New("a reason is required to reject a proof of delivery")
+)
+
+type PODStatus string
+
+const (
+	PODWaiting   PODStatus = "waiting"
+	PODSubmitted PODStatus = "submitted"
+	PODApproved  PODStatus = "approved"
+	PODRejected  PODStatus = "rejected"
+)
+
+// Label is the text shown on payment cards ("POD: Approved")
+func (s PODStatus) Label() string {
+	switch s {
+	case PODWaiting:
+		return "Waiting"
+	case PODSubmitted:
+		return "Submitted"
+	case PODApproved:
+		return "Approved"
+	case PODRejected:
+		return "Rejected"
+	}
+	return string(s)
+}
+
+type Deduction struct {
+	Reason string        `json:"reason"`
+	Amount ledger.Amount `json:"amount"`
+}
+
+// Release is one payment a broker made against a trip's balance
+type Release struct {
+	ID         string        `json:"id"`
+	Amount     ledger.Amount `json:"amount"`
+	Deductions []Deduction   `json:"deductions,omitempty"`
+	Note       string        `json:"note,omitempty"`
+	At         time.Time     `json:"at"`
+	Entries    []string      `json:"entries"`
+}
+
+// Total is the part of the balance the release cleared, deductions included
+func (r Release) Total() ledger.Amount {
+	total := r.Amount
+	for _, d := range r.Deductions {
+		total += d.Amount
+	}
+	return total
+}
+
+// Payment is what a broker owes a driver for a trip
+type Payment struct {
+	ID          string `json:"id"`
+	TripID      string `json:"tripId,omitempty"`
+	DriverID    string `json:"driverId"`
+	BrokerID    string `json:"brokerId"`
+	BrokerName  string `json:"brokerName"`
+	DriverName  string `json:"driverName"`
+	DriverPhone string `json:"-"` // brokers only ever see it redacted
+	TruckNumber string `json:"truckNumber"`
+	Cargo       string `json:"cargoType"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Distance    string `json:"distance"`
+
+	Gross      ledger.Amount `json:"gross"`
+	Commission ledger.Amount `json:"commission"`
+	Advance    ledger.Amount `json:"advance"`
+
+	POD       PODStatus `json:"podStatus"`
+	PODNote   string    `json:"podNote,omitempty"`
+	Releases  []Release `json:"releases,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Payable is the trip amount after the platform commission
+func (p Payment) Payable() ledger.Amount {
+	return p.Gross - p.Commission
+}
+
+// Cleared is everything the releases paid or deducted
+func (p Payment) Cleared() ledger.Amount {
+	var sum ledger.Amount
+	for _, r := range p.Releases {
+		sum += r.Total()
+	}
+	return sum
+}
+
+func (p Payment) Deducted() ledger.Amount {
+	var sum ledger.Amount
+	for _, r := range p.Releases {
+		for _, d := range r.Deductions {
+			sum += d.Amount
+		}
+	}
+	return sum
+}
+
+// Balance is what is still due to the driver
+func (p Payment) Balance() ledger.Amount {
+	return p.Payable() - p.Advance - p.Cleared()
+}
+
+// Status is the text shown on payment cards
+func (p Payment) Status() string {
+	if p.Balance() <= 0 {
+		return "Completed"
+	}
+	return "Pending"
+}
+
+// LedgerRef is how the trip is named in ledger entries and alerts
+func (p Payment) LedgerRef() string {
+	if p.TripID != "" {
+		return p.TripID
+	}
+	return p.ID
+}
+
+func (p Payment) clone() Payment {
+	p.Releases = append([]Release(nil), p.Releases...)
+	return p
+}
@@ -0,0 +1,337 @@
+package settlement
+
+import (
+	"backend/bff/events"
+	"backend/bff/ledger"
+	"backend/bff/trip"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReleaseRequest is a broker's instruction to pay part or all of the balance
+type ReleaseRequest struct {
+	// Zero releases the whole balance left after deductions
+	Amount     ledger.Amount
+	Deductions []Deduction
+	Note       string
+}
+
+type Store struct {
+	Ledger *ledger.Ledger
+	Trips  *trip.Store
+	Events *events.Bus
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*Payment
+	released []ReleaseHook
+}
+
+// ReleaseHook runs after a release has been paid and posted
+type ReleaseHook func(p Payment, rel Release)
+
+func NewStore(l *ledger.Ledger, trips *trip.Store, bus *events.Bus) *Store {
+	s := &Store{
+		Ledger:   l,
+		Trips:    trips,
+		Events:   bus,
+		payments: map[string]*Payment{},
+	}
+	// a POD uploaded by the driver goes to the broker for approval
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		if e.To != trip.StatusPODUploaded {
+			return
+		}
+		for _, p := range s.ForTrip(t.ID) {
+			s.SubmitPOD(p.ID)
+		}
+	})
+	return s
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore(ledger.Default, trip.Default, events.Default))
+
+func (s *Store) Add(p Payment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.POD == "" {
+		p.POD = PODWaiting
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now()
+	}
+	p = p.clone()
+	s.payments[p.ID] = &p
+}
+
+func (s *Store) Get(id string) (Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[id]
+	if !ok {
+		return Payment{}, ErrNotFound
+	}
+	return p.clone(), nil
+}
+
+// List returns matching payments, newest first
+func (s *Store) List(match func(Payment) bool) []Payment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Payment
+	for _, p := range s.payments {
+		if match == nil || match(*p) {
+			out = append(out, p.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out
+}
+
+func (s *Store) ForBroker(brokerID string) []Payment {
+	return s.List(func(p Payment) bool { return p.BrokerID == brokerID })
+}
+
+func (s *Store) ForTrip(tripID string) []Payment {
+	return s.List(func(p Payment) bool { return tripID != "" && p.TripID == tripID })
+}
+
+// OnRelease registers a side effect that runs after every release
+func (s *Store) OnRelease(h ReleaseHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, h)
+}
+
+// SubmitPOD marks the proof of delivery as ready for the broker's review
+func (s *Store) SubmitPOD(id string) (Payment, error) {
+	return s.update(id, func(p *Payment) error {
+		if p.POD == PODApproved {
+			return nil
+		}
+		p.POD, p.PODNote = PODSubmitted, ""
+		return nil
+	})
+}
+
+// ApprovePOD unlocks the balance payment
+func (s *Store) ApprovePOD(id string) (Payment, error) {
+	p, err := s.Get(id)
+	if err != nil {
+		return Payment{}, err
+	}
+	// a POD on a tracked trip must actually have been uploaded
+	if t, err := s.Trips.Get(p.TripID); err == nil && !t.PODUploaded {
+		return Payment{}, ErrPODNotSubmitted
+	}
+
+	p, err = s.update(id, func(p *Payment) error {
+		if p.POD != PODSubmitted && p.POD != PODApproved {
+			return ErrPODNotSubmitted
+		}
+		p.POD, p.PODNote = PODApproved, ""
+		return nil
+	})
+	if err == nil {
+		s.publish(events.PODApproved, p, nil)
+	}
+	return p, err
+}
+
+func (s *Store) RejectPOD(id, reason string) (Payment, error) {
+	if reason == "" {
+		return Payment{}, ErrRejectionRequired
+	}
+	p, err := s.update(id, func(p *Payment) error {
+		if p.POD != PODSubmitted {
+			return ErrPODNotSubmitted
+		}
+		p.POD, p.PODNote = PODRejected, reason
+		return nil
+	})
+	if err == nil {
+		s.publish(events.PODRejected, p, map[string]string{"reason": reason})
+	}
+	return p, err
+}
+
+// Release pays the driver from the balance, posting the ledger entries and publishing the payment
+func (s *Store) Release(id string, req ReleaseRequest) (Payment, Release, error) {
+	return s.release(id, req, now())
+}
+
+func (s *Store) release(id string, req ReleaseRequest, at time.Time) (Payment, Release, error) {
+	var rel Release
+	p, err := s.update(id, func(p *Payment) error {
+		if p.POD != PODApproved {
+			return ErrPODNotApproved
+		}
+		balance := p.Balance()
+		if balance <= 0 {
+			return ErrAlreadySettled
+		}
+
+		var deducted ledger.Amount
+		for _, d := range req.Deductions {
+			if d.Reason == "" || d.Amount <= 0 {
+				return ErrDeductionReason
+			}
+			deducted += d.Amount
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = balance - deducted
+		}
+		switch {
+		case amount < 0:
+			return ErrOverpayment
+		case amount+deducted <= 0:
+			return ErrInvalidAmount
+		case amount+deducted > balance:
+			return ErrOverpayment
+		}
+
+		// the release is held on the payment before the ledger is posted, so
+		// a concurrent release already sees the smaller balance
+		s.seq++
+		rel = Release{
+			ID:         fmt.Sprintf("REL%05d", s.seq),
+			Amount:     amount,
+			Deductions: append([]Deduction(nil), req.Deductions...),
+			Note:       req.Note,
+			At:         at,
+		}
+		p.Releases = append(p.Releases, rel)
+		return nil
+	})
+	if err != nil {
+		return Payment{}, Release{}, err
+	}
+
+	// the ledger is posted outside the store lock; a failed posting gives the
+	// balance back
+	entries, err := s.post(p, rel)
+	p, _ = s.update(id, func(p *Payment) error {
+		for i, r := range p.Releases {
+			if r.ID != rel.ID {
+				continue
+			}
+			if err != nil {
+				p.Releases = append(p.Releases[:i], p.Releases[i+1:]...)
+			} else {
+				p.Releases[i].Entries = entries
+			}
+			break
+		}
+		return nil
+	})
+	if err != nil {
+		return Payment{}, Release{}, err
+	}
+	rel.Entries = entries
+
+	s.mu.Lock()
+	hooks := append([]ReleaseHook(nil), s.released...)
+	s.mu.Unlock()
+	for _, h := range hooks {
+		h(p, rel)
+	}
+
+	s.publish(events.PaymentReleased, p, map[string]string{
+		"amount":  rel.Amount.String(),
+		"balance": p.Balance().String(),
+		"release": rel.ID,
+	})
+	if p.Balance() <= 0 {
+		if t, err := s.Trips.Get(p.TripID); err == nil && t.Status == trip.StatusPODUploaded {
+			s.Trips.Transition(t.ID, trip.StatusSettled, trip.ActorBroker, "Payment "+p.ID+" settled")
+		}
+	}
+	return p, rel, nil
+}
+
+// post books a release as one ledger transaction: the broker pays the
+// balance, it clears into the wallet and deductions go back to the broker
+func (s *Store) post(p Payment, rel Release) ([]string, error) {
+	base := ledger.Posting{
+		DriverID:     p.DriverID,
+		BrokerID:     p.BrokerID,
+		TripID:       p.LedgerRef(),
+		Counterparty: p.BrokerName,
+		Mode:         "Bank Transfer",
+		Memo:         p.From + " → " + p.To,
+		At:           rel.At,
+	}
+
+	var legs []ledger.Leg
+	leg := func(kind ledger.Kind, amount ledger.Amount, memo string) {
+		posting := base
+		posting.Amount = amount
+		if memo != "" {
+			posting.Memo = memo
+		}
+		legs = append(legs, ledger.Leg{Kind: kind, Posting: posting})
+	}
+	leg(ledger.KindBalance, rel.Total(), rel.Note)
+	leg(ledger.KindRelease, rel.Total(), "")
+	for _, d := range rel.Deductions {
+		leg(ledger.KindDeduction, d.Amount, d.Reason)
+	}
+
+	entries, err := s.Ledger.RecordAll(legs...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids, nil
+}
+
+func (s *Store) publish(typ events.Type, p Payment, data map[string]string) {
+	if data == nil {
+		data = map[string]string{}
+	}
+	data["tripId"] = p.LedgerRef()
+	data["driverName"] = p.DriverName
+	data["brokerName"] = p.BrokerName
+
+	at := now()
+	if n := len(p.Releases); typ == events.PaymentReleased && n > 0 {
+		at = p.Releases[n-1].At
+	}
+	s.Events.Publish(events.Event{
+		Type:     typ,
+		Subject:  p.ID,
+		DriverID: p.DriverID,
+		BrokerID: p.BrokerID,
+		Actor:    trip.ActorBroker,
+		Data:     data,
+		At:       at,
+	})
+}
+
+func (s *Store) update(id string, fn func(p *Payment) error) (Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[id]
+	if !ok {
+		return Payment{}, ErrNotFound
+	}
+	next := p.clone()
+	if err := fn(&next); err != nil {
+		return Payment{}, err
+	}
+	*p = next
+	return next.clone(), nil
+}
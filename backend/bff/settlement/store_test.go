@@ -0,0 +1,255 @@
+package settlement
+
+import (
+	"backend/bff/events"
+	"backend/bff/ledger"
+	"backend/bff/trip"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestStore() *Store {
+	return NewStore(ledger.New(), trip.NewStore(), events.NewBus())
+}
+
+// payment leaves ₹24,000 due: ₹30,000 less ₹1,000 commission and a ₹5,000 advance
+func payment(pod PODStatus) Payment {
+	return Payment{
+		ID:         "PAY1",
+		DriverID:   "DRV1",
+		BrokerID:   "BRK1",
+		Gross:      ledger.Rupees(30000),
+		Commission: ledger.Rupees(1000),
+		Advance:    ledger.Rupees(5000),
+		POD:        pod,
+	}
+}
+
+func TestRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		pod         PODStatus
+		before      []ReleaseRequest // made first and expected to succeed
+		req         ReleaseRequest
+		wantErr     error
+		wantBalance ledger.Amount
+		wantWallet  ledger.Amount
+	}{
+		{
+			name:        "whole balance",
+			pod:         PODApproved,
+			wantBalance: 0,
+			wantWallet:  ledger.Rupees(24000),
+		},
+		{
+			name:        "part of the balance",
+			pod:         PODApproved,
+			req:         ReleaseRequest{Amount: ledger.Rupees(10000)},
+			wantBalance: ledger.Rupees(14000),
+			wantWallet:  ledger.Rupees(10000),
+		},
+		{
+			name:        "rest after deductions",
+			pod:         PODApproved,
+			req:         ReleaseRequest{Deductions: []Deduction{{Reason: "Late delivery", Amount: ledger.Rupees(500)}}},
+			wantBalance: 0,
+			wantWallet:  ledger.Rupees(23500),
+		},
+		{
+			name:        "before the POD is approved",
+			pod:         PODSubmitted,
+			wantErr:     ErrPODNotApproved,
+			wantBalance: ledger.Rupees(24000),
+		},
+		{
+			name:        "after a rejected POD",
+			pod:         PODRejected,
+			wantErr:     ErrPODNotApproved,
+			wantBalance: ledger.Rupees(24000),
+		},
+		{
+			name:        "more than is due",
+			pod:         PODApproved,
+			req:         ReleaseRequest{Amount: ledger.Rupees(24001)},
+			wantErr:     ErrOverpayment,
+			wantBalance: ledger.Rupees(24000),
+		},
+		{
+			name:        "deductions on top of the whole balance",
+			pod:         PODApproved,
+			req:         ReleaseRequest{Amount: ledger.Rupees(24000), Deductions: []Deduction{{Reason: "Damage", Amount: 1}}},
+			wantErr:     ErrOverpayment,
+			wantBalance: ledger.Rupees(24000),
+		},
+		{
+			name:        "deduction without a reason",
+			pod:         PODApproved,
+			req:         ReleaseRequest{Deductions: []Deduction{{Amount: ledger.Rupees(500)}}},
+			wantErr:     ErrDeductionReason,
+			wantBalance: ledger.Rupees(24000),
+		},
+		{
+			name:        "already settled",
+			pod:         PODApproved,
+			before:      []ReleaseRequest{{}},
+			wantErr:     ErrAlreadySettled,
+			wantBalance: 0,
+			wantWallet:  ledger.Rupees(24000),
+		},
+		{
+			name:        "second part",
+			pod:         PODApproved,
+			before:      []ReleaseRequest{{Amount: ledger.Rupees(4000)}},
+			wantBalance: 0,
+			wantWallet:  ledger.Rupees(24000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore()
+			s.Add(payment(tt.pod))
+			for _, req := range tt.before {
+				if _, _, err := s.Release("PAY1", req); err != nil {
+					t.Fatalf("earlier release: %v", err)
+				}
+			}
+
+			_, rel, err := s.Release("PAY1", tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(rel.Entries) == 0 {
+				t.Error("release posted no ledger entries")
+			}
+
+			p, _ := s.Get("PAY1")
+			if got := p.Balance(); got != tt.wantBalance {
+				t.Errorf("balance = %s, want %s", got, tt.wantBalance)
+			}
+			w := s.Ledger.Wallet("DRV1", time.Time{})
+			if w.Available != tt.wantWallet || w.Pending != 0 {
+				t.Errorf("wallet = %s available, %s pending; want %s, nothing pending", w.Available, w.Pending, tt.wantWallet)
+			}
+		})
+	}
+}
+
+func TestPODReview(t *testing.T) {
+	type step struct {
+		do      string // submit, approve or reject
+		reason  string
+		wantErr error
+		want    PODStatus
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "approve after submitting",
+			steps: []step{
+				{do: "submit", want: PODSubmitted},
+				{do: "approve", want: PODApproved},
+			},
+		},
+		{
+			name: "approve before submitting",
+			steps: []step{
+				{do: "approve", wantErr: ErrPODNotSubmitted, want: PODWaiting},
+			},
+		},
+		{
+			name: "reject without a reason",
+			steps: []step{
+				{do: "submit", want: PODSubmitted},
+				{do: "reject", wantErr: ErrRejectionRequired, want: PODSubmitted},
+			},
+		},
+		{
+			name: "reject, resubmit and approve",
+			steps: []step{
+				{do: "submit", want: PODSubmitted},
+				{do: "reject", reason: "Signature missing", want: PODRejected},
+				{do: "approve", wantErr: ErrPODNotSubmitted, want: PODRejected},
+				{do: "reject", reason: "Still blurry", wantErr: ErrPODNotSubmitted, want: PODRejected},
+				{do: "submit", want: PODSubmitted},
+				{do: "approve", want: PODApproved},
+			},
+		},
+		{
+			name: "resubmitting keeps an approval",
+			steps: []step{
+				{do: "submit", want: PODSubmitted},
+				{do: "approve", want: PODApproved},
+				{do: "submit", want: PODApproved},
+				{do: "reject", reason: "Changed my mind", wantErr: ErrPODNotSubmitted, want: PODApproved},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore()
+			s.Add(payment(""))
+			for i, st := range tt.steps {
+				var err error
+				switch st.do {
+				case "submit":
+					_, err = s.SubmitPOD("PAY1")
+				case "approve":
+					_, err = s.ApprovePOD("PAY1")
+				case "reject":
+					_, err = s.RejectPOD("PAY1", st.reason)
+				}
+				if !errors.Is(err, st.wantErr) {
+					t.Fatalf("step %d %s: error = %v, want %v", i, st.do, err, st.wantErr)
+				}
+				p, _ := s.Get("PAY1")
+				if p.POD != st.want {
+					t.Fatalf("step %d %s: POD = %s, want %s", i, st.do, p.POD, st.want)
+				}
+				if st.do == "reject" && st.wantErr == nil && p.PODNote != st.reason {
+					t.Errorf("step %d: note = %q, want the reason", i, p.PODNote)
+				}
+				if st.do == "submit" && p.PODNote != "" {
+					t.Errorf("step %d: resubmitting kept the rejection note %q", i, p.PODNote)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseSettlesTrip(t *testing.T) {
+	s := newTestStore()
+	s.Trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusDelivered})
+	p := payment("")
+	p.TripID = "TRK1"
+	s.Add(p)
+
+	// the POD can't be approved until the driver has uploaded it
+	if _, err := s.ApprovePOD("PAY1"); !errors.Is(err, ErrPODNotSubmitted) {
+		t.Fatalf("approve before upload: error = %v, want %v", err, ErrPODNotSubmitted)
+	}
+	if _, err := s.Trips.UploadPOD("TRK1", trip.ActorDriver); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get("PAY1"); got.POD != PODSubmitted {
+		t.Fatalf("upload left the POD %s, want %s", got.POD, PODSubmitted)
+	}
+	if _, err := s.ApprovePOD("PAY1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.Release("PAY1", ReleaseRequest{Amount: ledger.Rupees(20000)}); err != nil {
+		t.Fatal(err)
+	}
+	if tr, _ := s.Trips.Get("TRK1"); tr.Status != trip.StatusPODUploaded {
+		t.Fatalf("part payment moved the trip to %s", tr.Status)
+	}
+	if _, _, err := s.Release("PAY1", ReleaseRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if tr, _ := s.Trips.Get("TRK1"); tr.Status != trip.StatusSettled {
+		t.Errorf("trip is %s after the balance was paid, want %s", tr.Status, trip.StatusSettled)
+	}
+}
@@ -0,0 +1,42 @@
+package tracking
+
+import (
+	"backend/bff"
+	"backend/bff/geo"
+	"backend/bff/trip"
+	"fmt"
+)
+
+// Snapshot is the live view of a trip derived from its latest position
+type Snapshot struct {
+	Position Point  `json:"position"`
+	Location string `json:"location"`
+}
+
+func (s *Store) Snapshot(t trip.Trip) (Snapshot, bool) {
+	p, ok := s.Latest(t.ID)
+	if !ok {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Position: p,
+		Location: geo.Describe(p.Coord()),
+	}, true
+}
+
+// LiveDetails returns the display fields of a trip that come from its positions
+func (s *Store) LiveDetails(t trip.Trip) map[string]string {
+	snap, ok := s.Snapshot(t)
+	if !ok {
+		return map[string]string{
+			"currentLocation": "Location not shared",
+			"lastUpdated":     "never",
+			"speed":           "0 km/h",
+		}
+	}
+	return map[string]string{
+		"currentLocation": snap.Location,
+		"lastUpdated":     bff.TimeAgo(snap.Position.Timestamp),
+		"speed":           fmt.Sprintf("%.0f km/h", snap.Position.Speed),
+	}
+}
@@ -0,0 +1,21 @@
+package tracking
+
+import "time"
+
+func seed(s *Store) *Store {
+	at := now()
+
+	s.Ingest("TRK789012", []Point{
+		{Lat: 18.9492, Lng: 72.8438, Accuracy: 12, Timestamp: at.Add(-10 * time.Minute)},
+	})
+	s.Ingest("TRIP-002", []Point{
+		{Lat: 13.0830, Lng: 80.2712, Accuracy: 15, Timestamp: at.Add(-5 * time.Minute)},
+	})
+	s.Ingest("TRIP-003", []Point{
+		{Lat: 19.8762, Lng: 75.3433, Accuracy: 10, Speed: 58, Timestamp: at.Add(-4 * time.Hour)},
+		{Lat: 19.0948, Lng: 74.7480, Accuracy: 10, Speed: 62, Timestamp: at.Add(-150 * time.Minute)},
+		{Lat: 18.7500, Lng: 74.2000, Accuracy: 8, Speed: 55, Timestamp: at.Add(-70 * time.Minute)},
+		{Lat: 18.5642, Lng: 73.9567, Accuracy: 8, Speed: 45, Timestamp: at.Add(-2 * time.Minute)},
+	})
+	return s
+}
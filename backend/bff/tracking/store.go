@@ -0,0 +1,107 @@
+package tracking
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+type track struct {
+	points []Point
+	latest Point
+}
+
+type Store struct {
+	mu     sync.RWMutex
+	tracks map[string]*track
+}
+
+func NewStore() *Store {
+	return &Store{tracks: map[string]*track{}}
+}
+
+// Default store used by the BFF handlers
+var Default = seed(NewStore())
+
+// Ingest validates, orders and downsamples a batch of points for a trip
+func (s *Store) Ingest(tripID string, points []Point) (Result, error) {
+	if len(points) == 0 {
+		return Result{}, ErrNoPoints
+	}
+
+	batch := append([]Point(nil), points...)
+	sort.SliceStable(batch, func(i, j int) bool {
+		return batch[i].Timestamp.Before(batch[j].Timestamp)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tr, ok := s.tracks[tripID]
+	if !ok {
+		tr = &track{}
+		s.tracks[tripID] = tr
+	}
+
+	res := Result{Received: len(batch)}
+	latest := now().Add(maxClockSkew)
+	for _, p := range batch {
+		switch {
+		case !p.Coord().Valid(),
+			p.Timestamp.IsZero(),
+			p.Timestamp.After(latest),
+			p.Accuracy > maxAccuracyMeters,
+			!tr.latest.Timestamp.IsZero() && !p.Timestamp.After(tr.latest.Timestamp):
+			res.Rejected++
+			continue
+		}
+
+		res.Accepted++
+		res.Points = append(res.Points, p)
+		tr.latest = p
+		if n := len(tr.points); n == 0 || keep(tr.points[n-1], p) {
+			tr.points = append(tr.points, p)
+			res.Stored++
+		}
+	}
+	if len(tr.points) > maxTrackPoints {
+		tr.points = thin(tr.points)
+	}
+
+	if res.Accepted > 0 {
+		p := tr.latest
+		res.Latest = &p
+	}
+	return res, nil
+}
+
+// Latest returns the most recent accepted position for a trip
+func (s *Store) Latest(tripID string) (Point, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tr, ok := s.tracks[tripID]
+	if !ok || tr.latest.Timestamp.IsZero() {
+		return Point{}, false
+	}
+	return tr.latest, true
+}
+
+// Track returns the stored breadcrumb trail for a trip, oldest first
+func (s *Store) Track(tripID string) []Point {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tr, ok := s.tracks[tripID]
+	if !ok {
+		return nil
+	}
+	out := append([]Point(nil), tr.points...)
+	// the latest fix may have been downsampled away but still belongs on the trail
+	if n := len(out); n > 0 && out[n-1].Timestamp.Before(tr.latest.Timestamp) {
+		out = append(out, tr.latest)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package tracking
+
+import (
+	"backend/bff/geo"
+	"errors"
+	"time"
+)
+
+// Downsampling limits applied on ingest
+const (
+	maxAccuracyMeters = 100.0
+	minSpacingMeters  = 50.0
+	maxGap            = 2 * time.Minute
+	maxClockSkew      = 5 * time.Minute
+	maxTrackPoints    = 2000
+)
+
+var ErrNoPoints = errors.New("no location points")
+
+type Point struct {
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	Accuracy  float64   `json:"accuracy"` // metres
+	Speed     float64   `json:"speed"`    // km/h
+	Heading   float64   `json:"heading"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (p Point) Coord() geo.Coord {
+	return geo.Coord{Lat: p.Lat, Lng: p.Lng}
+}
+
+// Result summarises one ingested batch
+type Result struct {
+	Received int    `json:"received"`
+	Accepted int    `json:"accepted"`
+	Stored   int    `json:"stored"`
+	Rejected int    `json:"rejected"`
+	Latest   *Point `json:"latest,omitempty"`
+
+	// Accepted points in time order, for consumers reacting to movement
+	Points []Point `json:"-"`
+}
+
+// keep decides whether an accepted point is worth storing in the track
+func keep(last, p Point) bool {
+	if geo.Haversine(last.Coord(), p.Coord())*1000 >= minSpacingMeters {
+		return true
+	}
+	return p.Timestamp.Sub(last.Timestamp) >= maxGap
+}
+
+// thin halves the density of the older half of a track, keeping its end points
+func thin(points []Point) []Point {
+	half := len(points) / 2
+	out := make([]Point, 0, len(points))
+	for i := 0; i < half; i++ {
+		if i%2 == 0 || i == half-1 {
+			out = append(out, points[i])
+		}
+	}
+	return append(out, points[half:]...)
+}
@@ -0,0 +1,101 @@
+package trip
+
+import "fmt"
+
+var requiredDocuments = []string{
+	"eWayBill",
+	"invoice",
+	"vehicleRC",
+	"driverLicense",
+	"insurance",
+	"pollutionCert",
+}
+
+// Allowed next statuses for every status
+var transitions = map[Status][]Status{
+	StatusAssigned:      {StatusAccepted, StatusCancelled},
+	StatusAccepted:      {StatusReachedPickup, StatusCancelled},
+	StatusReachedPickup: {StatusLoading, StatusCancelled},
+	StatusLoading:       {StatusInTransit, StatusCancelled},
+	StatusInTransit:     {StatusReachedDrop},
+	StatusReachedDrop:   {StatusDelivered},
+	StatusDelivered:     {StatusPODUploaded},
+	StatusPODUploaded:   {StatusSettled},
+	StatusSettled:       {},
+	StatusCancelled:     {},
+}
+
+// Guard decides whether a trip may enter a status
+type Guard func(t Trip) error
+
+var guards = map[Status]Guard{
+	StatusInTransit: func(t Trip) error {
+		if missing := t.MissingDocuments(); len(missing) > 0 {
+			return fmt.Errorf("%w: %v", ErrDocumentsMissing, missing)
+		}
+		return nil
+	},
+	StatusPODUploaded: requirePOD,
+	StatusSettled:     requirePOD,
+}
+
+func requirePOD(t Trip) error {
+	if !t.PODUploaded {
+		return ErrPODMissing
+	}
+	return nil
+}
+
+// Hook runs after a transition has been committed
+type Hook func(t Trip, e Event)
+
+// AssignHook runs after a new trip has been added
+type AssignHook func(t Trip)
+
+func Next(s Status) []Status {
+	return append([]Status(nil), transitions[s]...)
+}
+
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func check(t Trip, to Status) error {
+	if !to.Valid() {
+		return fmt.Errorf("%w: %s", ErrUnknownStatus, to)
+	}
+	if !CanTransition(t.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, t.Status, to)
+	}
+	if guard, ok := guards[to]; ok {
+		return guard(t)
+	}
+	return nil
+}
+
+// apply mutates the trip into the new status and returns the recorded event
+func apply(t *Trip, to Status, actor, note string) Event {
+	e := Event{
+		From:  t.Status,
+		To:    to,
+		Actor: actor,
+		Note:  note,
+		At:    now(),
+	}
+	t.Status = to
+	t.History = append(t.History, e)
+	t.UpdatedAt = e.At
+
+	switch to {
+	case StatusInTransit:
+		t.LocationSharing = true
+	case StatusDelivered, StatusCancelled:
+		t.LocationSharing = false
+	}
+	return e
+}
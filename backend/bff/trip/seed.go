@@ -0,0 +1,183 @@
+package trip
+
+import (
+	"backend/bff/geo"
+	"time"
+)
+
+// Demo IDs used until the apps send a logged-in identity
+const (
+	DemoDriverID = "DRV001"
+	DemoBrokerID = "BRK001"
+)
+
+func seed(s *Store) *Store {
+	start := now().Add(-6 * time.Hour)
+
+	s.Add(seeded(Trip{
+		ID:         "TRK789012",
+		DriverID:   DemoDriverID,
+		BrokerID:   DemoBrokerID,
+		LoadID:     "LD-7891",
+		Pickup:     geo.Coord{Lat: 18.9490, Lng: 72.8440},
+		Drop:       geo.Coord{Lat: 28.5355, Lng: 77.2710},
+		PromisedBy: start.Add(54 * time.Hour),
+		Documents: map[string]bool{
+			"eWayBill":      false,
+			"invoice":       false,
+			"vehicleRC":     true,
+			"driverLicense": true,
+			"insurance":     true,
+			"pollutionCert": true,
+		},
+		Details: map[string]string{
+			"tripNumber":      "TRIP-2024-001",
+			"origin":          "Mumbai Port",
+			"originCity":      "Mumbai, MH",
+			"destination":     "Delhi Logistics Park",
+			"destinationCity": "Delhi, DL",
+			"cargo":           "Electronics & Appliances",
+			"cargoType":       "General Goods",
+			"weight":          "15 Tons",
+			"payment":         "₹68,500",
+			"advancePaid":     "₹20,000",
+			"balanceDue":      "₹48,500",
+			"startTime":       "Today, 10:00 AM",
+			"brokerName":      "Sharma Logistics Pvt. Ltd.",
+			"brokerPhone":     "+91 9876543210",
+			"brokerRating":    "4.8",
+			"senderName":      "TechCorp India Ltd.",
+			"senderPhone":     "+91 9876543211",
+			"senderGSTIN":     "27AAACT5678K1Z2",
+			"senderAddress":   "MIDC Industrial Area, Andheri East, Mumbai 400093",
+			"receiverName":    "Metro Retail Chains",
+			"receiverPhone":   "+91 9876543212",
+			"receiverGSTIN":   "07AABCM4321Q1Z9",
+			"receiverAddress": "Okhla Industrial Area Phase II, New Delhi 110020",
+			"packages":        "240 cartons",
+			"freightTerms":    "to_pay",
+			"vehicleNumber":   "MH01AB1234",
+			"truckType":       "Open Half Body",
+			"driverName":      "Rajesh Kumar",
+			"driverPhone":     "+91 98765 43210",
+			"fuelLevel":       "65",
+			"vehicleHealth":   "Good",
+			"tripScore":       "92",
+		},
+	}, start, StatusAccepted, StatusReachedPickup, StatusLoading))
+
+	s.Add(seeded(Trip{
+		ID:         "TRIP-002",
+		DriverID:   "DRV002",
+		BrokerID:   DemoBrokerID,
+		Pickup:     geo.Coord{Lat: 13.0827, Lng: 80.2707},
+		Drop:       geo.Coord{Lat: 12.9716, Lng: 77.5946},
+		PromisedBy: start.Add(16 * time.Hour),
+		Documents:  allDocuments(),
+		Details: map[string]string{
+			"tripNumber":      "TRIP-2024-002",
+			"originCity":      "Chennai, Tamil Nadu",
+			"destinationCity": "Bangalore, Karnataka",
+			"cargo":           "Textiles",
+			"driverName":      "Amit Sharma",
+			"driverPhone":     "+91 98765 43211",
+			"vehicleNumber":   "DL 01 AB 5678",
+			"truckType":       "Container",
+		},
+	}, start.Add(time.Hour), StatusAccepted, StatusReachedPickup, StatusLoading))
+
+	s.Add(seeded(Trip{
+		ID:         "TRIP-003",
+		DriverID:   "DRV003",
+		BrokerID:   DemoBrokerID,
+		Pickup:     geo.Coord{Lat: 22.5726, Lng: 88.3639},
+		Drop:       geo.Coord{Lat: 18.5204, Lng: 73.8567},
+		PromisedBy: start.Add(6 * time.Hour),
+		Documents:  allDocuments(),
+		Details: map[string]string{
+			"tripNumber":      "TRIP-2024-003",
+			"originCity":      "Kolkata, West Bengal",
+			"destinationCity": "Pune, Maharashtra",
+			"cargo":           "Machinery",
+			"driverName":      "Vikram Singh",
+			"driverPhone":     "+91 98765 43212",
+			"vehicleNumber":   "UP 32 CD 9012",
+			"truckType":       "Trailer",
+		},
+	}, start.Add(-48*time.Hour), StatusAccepted, StatusReachedPickup, StatusLoading, StatusInTransit))
+
+	// settled trips behind the driver's wallet history
+	history := now().Add(-200 * time.Hour)
+	s.Add(seededEvery(Trip{
+		ID:          "TRIP#4498",
+		DriverID:    DemoDriverID,
+		BrokerID:    "BRK003",
+		Pickup:      geo.Coord{Lat: 28.6139, Lng: 77.2090},
+		Drop:        geo.Coord{Lat: 22.5726, Lng: 88.3639},
+		PromisedBy:  history.Add(80 * time.Hour),
+		Documents:   allDocuments(),
+		PODUploaded: true,
+		Details: map[string]string{
+			"tripNumber":      "TRIP-2024-4498",
+			"originCity":      "Delhi, DL",
+			"destinationCity": "Kolkata, WB",
+			"cargo":           "FMCG Goods",
+			"payment":         "₹5,300",
+			"brokerName":      "PQR Freight",
+			"vehicleNumber":   "MH01AB1234",
+			"driverName":      "Rajesh Kumar",
+		},
+	}, history, 12*time.Hour, StatusAccepted, StatusReachedPickup, StatusLoading, StatusInTransit,
+		StatusReachedDrop, StatusDelivered, StatusPODUploaded, StatusSettled))
+
+	history = now().Add(-120 * time.Hour)
+	s.Add(seededEvery(Trip{
+		ID:          "TRIP#4587",
+		DriverID:    DemoDriverID,
+		BrokerID:    "BRK002",
+		Pickup:      geo.Coord{Lat: 19.0760, Lng: 72.8777},
+		Drop:        geo.Coord{Lat: 28.6139, Lng: 77.2090},
+		PromisedBy:  history.Add(40 * time.Hour),
+		Documents:   allDocuments(),
+		PODUploaded: true,
+		Details: map[string]string{
+			"tripNumber":      "TRIP-2024-4587",
+			"originCity":      "Mumbai, MH",
+			"destinationCity": "Delhi, DL",
+			"cargo":           "Auto Parts",
+			"payment":         "₹3,200",
+			"brokerName":      "ABC Logistics",
+			"vehicleNumber":   "MH01AB1234",
+			"driverName":      "Rajesh Kumar",
+		},
+	}, history, 8*time.Hour, StatusAccepted, StatusReachedPickup, StatusLoading, StatusInTransit,
+		StatusReachedDrop, StatusDelivered, StatusPODUploaded, StatusSettled))
+
+	return s
+}
+
+func allDocuments() map[string]bool {
+	docs := map[string]bool{}
+	for _, doc := range requiredDocuments {
+		docs[doc] = true
+	}
+	return docs
+}
+
+// seeded replays a path through the state machine so demo trips carry a real history
+func seeded(t Trip, at time.Time, path ...Status) Trip {
+	return seededEvery(t, at, 30*time.Minute, path...)
+}
+
+// seededEvery is seeded with the given time between steps
+func seededEvery(t Trip, at time.Time, gap time.Duration, path ...Status) Trip {
+	t.Status = StatusAssigned
+	t.CreatedAt = at
+	for i, to := range path {
+		e := apply(&t, to, ActorSystem, "")
+		e.At = at.Add(time.Duration(i+1) * gap)
+		t.History[len(t.History)-1] = e
+	}
+	t.UpdatedAt = t.CreatedAt
+	return t
+}
@@ -0,0 +1,275 @@
+package trip
+
+import (
+	"backend/bff/events"
+	"sort"
+	"sync"
+	"time"
+)
+
+var now = time.Now
+
+type Store struct {
+	mu    sync.RWMutex
+	trips map[string]*Trip
+	hooks []Hook
+	added []AssignHook
+
+	// guards other packages add on top of the state machine's own
+	guards map[Status][]Guard
+
+	// where assignments, transitions and documents are published; nil publishes nothing
+	bus *events.Bus
+}
+
+func NewStore() *Store {
+	return &Store{trips: map[string]*Trip{}, guards: map[Status][]Guard{}}
+}
+
+// Default store used by the BFF handlers; it publishes on the default event bus
+var Default = seed(NewStore().publishTo(events.Default))
+
+func (s *Store) publishTo(bus *events.Bus) *Store {
+	s.bus = bus
+	return s
+}
+
+// Add assigns a new trip; a trip without a status starts out assigned
+func (s *Store) Add(t Trip) {
+	s.mu.Lock()
+	c := t.clone()
+	if c.Status == "" {
+		c.Status = StatusAssigned
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now()
+	}
+	c.UpdatedAt = c.CreatedAt
+	s.trips[c.ID] = &c
+	out := c.clone()
+	hooks := append([]AssignHook(nil), s.added...)
+	s.mu.Unlock()
+
+	for _, h := range hooks {
+		h(out)
+	}
+	s.publish(events.TripAssigned, out, "", out.CreatedAt, nil)
+	if out.LoadID != "" {
+		s.publish(events.LoadAssigned, out, "", out.CreatedAt, nil)
+	}
+}
+
+func (s *Store) Get(id string) (Trip, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.trips[id]
+	if !ok {
+		return Trip{}, ErrNotFound
+	}
+	return t.clone(), nil
+}
+
+// List returns every trip matching the filter, oldest first
+func (s *Store) List(match func(Trip) bool) []Trip {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []Trip
+	for _, t := range s.trips {
+		if match == nil || match(*t) {
+			out = append(out, t.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+func (s *Store) ForDriver(driverID string) []Trip {
+	return s.List(func(t Trip) bool { return t.DriverID == driverID })
+}
+
+func (s *Store) ForBroker(brokerID string) []Trip {
+	return s.List(func(t Trip) bool { return t.BrokerID == brokerID })
+}
+
+// ForLoad returns the latest trip booked against a load
+func (s *Store) ForLoad(loadID string) (Trip, error) {
+	trips := s.List(func(t Trip) bool { return loadID != "" && t.LoadID == loadID })
+	if len(trips) == 0 {
+		return Trip{}, ErrNotFound
+	}
+	return trips[len(trips)-1], nil
+}
+
+// ActiveForDriver returns the driver's current trip, if any
+func (s *Store) ActiveForDriver(driverID string) (Trip, error) {
+	trips := s.List(func(t Trip) bool { return t.DriverID == driverID && t.Active() })
+	if len(trips) == 0 {
+		return Trip{}, ErrNotFound
+	}
+	return trips[len(trips)-1], nil
+}
+
+// OnTransition registers a side effect that runs after every committed transition
+func (s *Store) OnTransition(h Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, h)
+}
+
+// OnAssign registers a side effect that runs after a trip is added
+func (s *Store) OnAssign(h AssignHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.added = append(s.added, h)
+}
+
+// AddGuard makes entering a status also depend on a check owned elsewhere
+func (s *Store) AddGuard(to Status, g Guard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guards[to] = append(s.guards[to], g)
+}
+
+// Transition moves a trip to a new status, enforcing the state machine and its guards
+func (s *Store) Transition(id string, to Status, actor, note string) (Trip, error) {
+	s.mu.Lock()
+	t, ok := s.trips[id]
+	if !ok {
+		s.mu.Unlock()
+		return Trip{}, ErrNotFound
+	}
+	if err := check(*t, to); err != nil {
+		s.mu.Unlock()
+		return Trip{}, err
+	}
+	for _, guard := range s.guards[to] {
+		if err := guard(t.clone()); err != nil {
+			s.mu.Unlock()
+			return Trip{}, err
+		}
+	}
+	e := apply(t, to, actor, note)
+	out := t.clone()
+	hooks := append([]Hook(nil), s.hooks...)
+	s.mu.Unlock()
+
+	for _, h := range hooks {
+		h(out, e)
+	}
+	s.publish(events.TripStatusChanged, out, e.Actor, e.At, map[string]string{
+		"from": string(e.From),
+		"to":   string(e.To),
+		"note": e.Note,
+	})
+	return out, nil
+}
+
+func (s *Store) MarkDocument(id, doc string) (Trip, error) {
+	t, err := s.update(id, func(t *Trip) {
+		t.Documents[doc] = true
+	})
+	if err == nil {
+		s.publish(events.DocumentUploaded, t, "", t.UpdatedAt, map[string]string{"document": doc})
+	}
+	return t, err
+}
+
+// UploadPOD records the proof of delivery and moves the trip to pod_uploaded
+// in one step, so a trip that cannot take the transition is left untouched
+func (s *Store) UploadPOD(id, actor string) (Trip, error) {
+	s.mu.Lock()
+	t, ok := s.trips[id]
+	if !ok {
+		s.mu.Unlock()
+		return Trip{}, ErrNotFound
+	}
+	next := t.clone()
+	next.PODUploaded = true
+	if err := check(next, StatusPODUploaded); err != nil {
+		s.mu.Unlock()
+		return Trip{}, err
+	}
+	for _, guard := range s.guards[StatusPODUploaded] {
+		if err := guard(next.clone()); err != nil {
+			s.mu.Unlock()
+			return Trip{}, err
+		}
+	}
+	t.PODUploaded = true
+	e := apply(t, StatusPODUploaded, actor, "")
+	out := t.clone()
+	hooks := append([]Hook(nil), s.hooks...)
+	s.mu.Unlock()
+
+	s.publish(events.DocumentUploaded, out, actor, e.At, map[string]string{"document": "pod"})
+	for _, h := range hooks {
+		h(out, e)
+	}
+	s.publish(events.TripStatusChanged, out, e.Actor, e.At, map[string]string{
+		"from": string(e.From),
+		"to":   string(e.To),
+		"note": e.Note,
+	})
+	return out, nil
+}
+
+func (s *Store) AddTimeline(id string, e TimelineEntry) (Trip, error) {
+	if e.At.IsZero() {
+		e.At = now()
+	}
+	return s.update(id, func(t *Trip) {
+		t.Timeline = append(t.Timeline, e)
+	})
+}
+
+// publish sends a trip change out with the route and parties every listener needs
+func (s *Store) publish(typ events.Type, t Trip, actor string, at time.Time, data map[string]string) {
+	if s.bus == nil {
+		return
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	data["tripId"] = t.ID
+	data["loadId"] = t.LoadID
+	data["origin"] = t.Details["originCity"]
+	data["destination"] = t.Details["destinationCity"]
+	data["driverName"] = t.Details["driverName"]
+
+	subject := t.ID
+	if typ == events.LoadAssigned {
+		subject = t.LoadID
+	}
+	s.bus.Publish(events.Event{
+		Type:     typ,
+		Subject:  subject,
+		DriverID: t.DriverID,
+		BrokerID: t.BrokerID,
+		Actor:    actor,
+		Data:     data,
+		At:       at,
+	})
+}
+
+func (s *Store) update(id string, fn func(t *Trip)) (Trip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.trips[id]
+	if !ok {
+		return Trip{}, ErrNotFound
+	}
+	if t.Documents == nil {
+		t.Documents = map[string]bool{}
+	}
+	fn(t)
+	t.UpdatedAt = now()
+	return t.clone(), nil
+}
@@ -0,0 +1,83 @@
+package trip
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Status
+		to      Status
+		docs    map[string]bool
+		pod     bool
+		wantErr error
+		sharing bool
+	}{
+		{name: "accept", from: StatusAssigned, to: StatusAccepted},
+		{name: "cancel before loading ends", from: StatusLoading, to: StatusCancelled},
+		{name: "depart with documents", from: StatusLoading, to: StatusInTransit, docs: allDocuments(), sharing: true},
+		{name: "depart without documents", from: StatusLoading, to: StatusInTransit, wantErr: ErrDocumentsMissing},
+		{name: "deliver", from: StatusReachedDrop, to: StatusDelivered},
+		{name: "settle with POD", from: StatusPODUploaded, to: StatusSettled, pod: true},
+		{name: "settle without POD", from: StatusPODUploaded, to: StatusSettled, wantErr: ErrPODMissing},
+		{name: "skip ahead", from: StatusAssigned, to: StatusInTransit, wantErr: ErrInvalidTransition},
+		{name: "cancel in transit", from: StatusInTransit, to: StatusCancelled, wantErr: ErrInvalidTransition},
+		{name: "leave settled", from: StatusSettled, to: StatusAccepted, wantErr: ErrInvalidTransition},
+		{name: "unknown status", from: StatusAssigned, to: "parked", wantErr: ErrUnknownStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			s.Add(Trip{ID: "TRK1", DriverID: "DRV1", Status: tt.from, Documents: tt.docs, PODUploaded: tt.pod})
+			var fired []Event
+			s.OnTransition(func(_ Trip, e Event) { fired = append(fired, e) })
+
+			got, err := s.Transition("TRK1", tt.to, ActorDriver, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transition(%s -> %s) error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+
+			stored, _ := s.Get("TRK1")
+			if tt.wantErr != nil {
+				if stored.Status != tt.from || len(stored.History) != 0 || len(fired) != 0 {
+					t.Errorf("rejected transition changed the trip: status %s, %d history, %d hooks", stored.Status, len(stored.History), len(fired))
+				}
+				return
+			}
+			if got.Status != tt.to || stored.Status != tt.to {
+				t.Errorf("status = %s, stored %s, want %s", got.Status, stored.Status, tt.to)
+			}
+			if len(got.History) != 1 || got.History[0].From != tt.from || got.History[0].To != tt.to {
+				t.Errorf("history = %+v, want one %s -> %s", got.History, tt.from, tt.to)
+			}
+			if len(fired) != 1 {
+				t.Errorf("hooks fired %d times, want 1", len(fired))
+			}
+			if got.LocationSharing != tt.sharing {
+				t.Errorf("location sharing = %v, want %v", got.LocationSharing, tt.sharing)
+			}
+		})
+	}
+}
+
+func TestTransitionUnknownTrip(t *testing.T) {
+	if _, err := NewStore().Transition("TRK404", StatusAccepted, ActorDriver, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestAddGuard(t *testing.T) {
+	blocked := errors.New("blocked")
+	s := NewStore()
+	s.Add(Trip{ID: "TRK1", Status: StatusAssigned})
+	s.AddGuard(StatusAccepted, func(Trip) error { return blocked })
+
+	if _, err := s.Transition("TRK1", StatusAccepted, ActorDriver, ""); !errors.Is(err, blocked) {
+		t.Fatalf("error = %v, want the guard's", err)
+	}
+	if _, err := s.Transition("TRK1", StatusCancelled, ActorBroker, ""); err != nil {
+		t.Fatalf("guard on accepted blocked a cancel: %v", err)
+	}
+}
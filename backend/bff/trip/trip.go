@@ -0,0 +1,165 @@
+package trip
+
+import (
+	"backend/bff/geo"
+	"errors"
+	"sort"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusAssigned      Status = "assigned"
+	StatusAccepted      Status = "accepted"
+	StatusReachedPickup Status = "reached_pickup"
+	StatusLoading       Status = "loading"
+	StatusInTransit     Status = "in_transit"
+	StatusReachedDrop   Status = "reached_drop"
+	StatusDelivered     Status = "delivered"
+	StatusPODUploaded   Status = "pod_uploaded"
+	StatusSettled       Status = "settled"
+	StatusCancelled     Status = "cancelled"
+)
+
+// Actors recorded on trip events
+const (
+	ActorDriver = "driver"
+	ActorBroker = "broker"
+	ActorSystem = "system"
+)
+
+var (
+	ErrNotFound          = errors.New("trip not found")
+	ErrUnknownStatus     = errors.New("unknown trip status")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrDocumentsMissing  = errors.New("required documents not uploaded")
+	ErrPODMissing        = errors.New("proof of delivery not uploaded")
+)
+
+type Trip struct {
+	ID              string            `json:"id"`
+	DriverID        string            `json:"driverId"`
+	BrokerID        string            `json:"brokerId"`
+	LoadID          string            `json:"loadId,omitempty"`
+	Status          Status            `json:"status"`
+	Pickup          geo.Coord         `json:"pickup"`
+	Drop            geo.Coord         `json:"drop"`
+	PromisedBy      time.Time         `json:"promisedBy"`
+	Documents       map[string]bool   `json:"documents"`
+	PODUploaded     bool              `json:"podUploaded"`
+	LocationSharing bool              `json:"locationSharing"`
+	Details         map[string]string `json:"details"`
+	History         []Event           `json:"history"`
+	Timeline        []TimelineEntry   `json:"timeline"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// Event is one entry in a trip's transition history
+type Event struct {
+	From  Status    `json:"from"`
+	To    Status    `json:"to"`
+	Actor string    `json:"actor"`
+	Note  string    `json:"note,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// TimelineEntry is a non-status event shown on the trip timeline
+type TimelineEntry struct {
+	Kind  string    `json:"kind"`
+	Label string    `json:"label"`
+	At    time.Time `json:"at"`
+}
+
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+func (s Status) Label() string {
+	switch s {
+	case StatusAssigned:
+		return "Assigned"
+	case StatusAccepted:
+		return "Accepted"
+	case StatusReachedPickup:
+		return "Reached Pickup"
+	case StatusLoading:
+		return "Loading"
+	case StatusInTransit:
+		return "In Transit"
+	case StatusReachedDrop:
+		return "Reached Drop"
+	case StatusDelivered:
+		return "Delivered"
+	case StatusPODUploaded:
+		return "POD Uploaded"
+	case StatusSettled:
+		return "Settled"
+	case StatusCancelled:
+		return "Cancelled"
+	default:
+		return string(s)
+	}
+}
+
+// Started reports whether the truck has left the pickup point
+func (t Trip) Started() bool {
+	switch t.Status {
+	case StatusInTransit, StatusReachedDrop, StatusDelivered, StatusPODUploaded, StatusSettled:
+		return true
+	}
+	return false
+}
+
+// Active reports whether the trip still needs attention from driver or broker
+func (t Trip) Active() bool {
+	return t.Status != StatusSettled && t.Status != StatusCancelled
+}
+
+func (t Trip) MissingDocuments() []string {
+	var missing []string
+	for _, doc := range requiredDocuments {
+		if !t.Documents[doc] {
+			missing = append(missing, doc)
+		}
+	}
+	return missing
+}
+
+// Reached returns when the trip last entered the given status
+func (t Trip) Reached(s Status) (time.Time, bool) {
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].To == s {
+			return t.History[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FullTimeline merges status changes with the other timeline entries, oldest first
+func (t Trip) FullTimeline() []TimelineEntry {
+	out := make([]TimelineEntry, 0, len(t.History)+len(t.Timeline))
+	for _, e := range t.History {
+		out = append(out, TimelineEntry{Kind: "status", Label: e.To.Label(), At: e.At})
+	}
+	out = append(out, t.Timeline...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+func (t Trip) clone() Trip {
+	c := t
+	c.Documents = make(map[string]bool, len(t.Documents))
+	for k, v := range t.Documents {
+		c.Documents[k] = v
+	}
+	c.Details = make(map[string]string, len(t.Details))
+	for k, v := range t.Details {
+		c.Details[k] = v
+	}
+	c.History = append([]Event(nil), t.History...)
+	c.Timeline = append([]TimelineEntry(nil), t.Timeline...)
+	return c
+}
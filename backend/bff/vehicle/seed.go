@@ -0,0 +1,73 @@
+package vehicle
+
+import (
+	"backend/bff/ledger"
+	"backend/bff/trip"
+	"time"
+)
+
+// seed registers the demo broker's fleet. The demo driver's truck has a
+// service history and fuel log that leave the oil change due in 500 km and
+// the tank about 65% full.
+func seed(s *Service) *Service {
+	day := 24 * time.Hour
+	s.Register(Vehicle{
+		Number:       "GJ05CD5678",
+		BrokerID:     trip.DemoBrokerID,
+		DriverID:     "DRV004",
+		DriverName:   "Mahesh Reddy",
+		Model:        "Ashok Leyland 2820",
+		Type:         "24ft Truck",
+		Year:         2022,
+		Capacity:     18,
+		Odometer:     52300,
+		TankLitres:   300,
+		RegisteredAt: now().Add(-2 * 365 * day),
+	})
+	s.LogService("GJ05CD5678", ServiceRequest{
+		Items:    []Item{ItemOilChange, ItemAirFilter, ItemBrakes, ItemTyreRotation, ItemCoolant, ItemBatteryCheck},
+		Odometer: 50000,
+		Cost:     ledger.Rupees(14500),
+		Garage:   "Ashok Leyland Service, Vadodara",
+		At:       now().Add(-40 * day),
+	})
+	v, err := s.Register(Vehicle{
+		Number:       "MH01AB1234",
+		BrokerID:     trip.DemoBrokerID,
+		DriverID:     trip.DemoDriverID,
+		DriverName:   "Rajesh Kumar",
+		Model:        "Tata Signa 3118.T",
+		Type:         "Open Half Body",
+		Year:         2021,
+		Capacity:     15,
+		Odometer:     84500,
+		TankLitres:   350,
+		RegisteredAt: now().Add(-3 * 365 * day),
+	})
+	if err != nil {
+		return s
+	}
+
+	services := []ServiceRequest{
+		{Items: []Item{ItemCoolant}, Odometer: 50000, Cost: ledger.Rupees(2800), Garage: "Tata Motors Service, Bhiwandi", At: now().Add(-400 * day)},
+		{Items: []Item{ItemAirFilter}, Odometer: 60000, Cost: ledger.Rupees(1900), Garage: "Tata Motors Service, Bhiwandi", At: now().Add(-200 * day)},
+		{Items: []Item{ItemOilChange, ItemBrakes}, Odometer: 75000, Cost: ledger.Rupees(9400), Garage: "Highway Truck Care, Nashik", At: now().Add(-70 * day)},
+		{Items: []Item{ItemTyreRotation, ItemBatteryCheck}, Odometer: 80000, Cost: ledger.Rupees(1500), Garage: "Sai Tyres, Vapi", Note: "Front tyres to rear", At: now().Add(-35 * day)},
+	}
+	for _, r := range services {
+		s.LogService(v.Number, r)
+	}
+
+	fills := []FuelRequest{
+		{Odometer: 81800, Litres: 310, Amount: ledger.FromRupees(310 * 89.6), Full: true, Station: "HP, Panvel", At: now().Add(-20 * day)},
+		{Odometer: 83000, Litres: 295, Amount: ledger.FromRupees(295 * 90.1), Full: true, Station: "Indian Oil, Surat", At: now().Add(-12 * day)},
+		{Odometer: 83500, Litres: 120, Amount: ledger.FromRupees(120 * 89.9), Station: "BPCL, Udaipur", At: now().Add(-8 * day)},
+		{Odometer: 84000, Litres: 125, Amount: ledger.FromRupees(125 * 90.4), Full: true, Station: "Reliance, Jaipur", At: now().Add(-5 * day)},
+	}
+	for _, f := range fills {
+		s.LogFuel(v.Number, f)
+	}
+
+	s.Remind(v.Number)
+	return s
+}
@@ -0,0 +1,677 @@
+package vehicle
+
+import (
+	"backend/bff"
+	"backend/bff/events"
+	"backend/bff/expense"
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"backend/bff/routing"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ServiceRequest struct {
+	Items    []Item
+	Odometer float64 // zero means the current odometer
+	Cost     ledger.Amount
+	Garage   string
+	Note     string
+	At       time.Time // zero means now
+}
+
+type FuelRequest struct {
+	Odometer float64 // zero means the current odometer
+	Litres   float64
+	Amount   ledger.Amount
+	Full     bool
+	Station  string
+	PaidFrom expense.PaidFrom // empty means cash
+	At       time.Time        // zero means now
+}
+
+// Service tracks each truck's odometer, maintenance schedule, service
+// history and fuel log, and reminds drivers when service falls due
+type Service struct {
+	Trips  *trip.Store
+	Routes *routing.Engine
+	Tracks *tracking.Store
+	Events *events.Bus
+	// Fuel bought on a trip is a trip expense; the fuel log keeps the
+	// litres and odometer for mileage, the expense is what is paid back
+	Expenses *expense.Service
+
+	mu       sync.Mutex
+	seq      int
+	vehicles map[string]*Vehicle
+	services []ServiceRecord
+	fuel     []FuelEntry
+	reminded map[string]bool
+}
+
+func NewService(trips *trip.Store, routes *routing.Engine, tracks *tracking.Store, bus *events.Bus, expenses *expense.Service) *Service {
+	s := &Service{
+		Trips:    trips,
+		Routes:   routes,
+		Tracks:   tracks,
+		Events:   bus,
+		Expenses: expenses,
+		vehicles: map[string]*Vehicle{},
+		reminded: map[string]bool{},
+	}
+	trips.OnTransition(func(t trip.Trip, e trip.Event) {
+		if e.To == trip.StatusDelivered {
+			s.drove(t)
+		}
+	})
+	return s
+}
+
+// Default service used by the BFF handlers
+var Default = seed(NewService(trip.Default, routing.Default, tracking.Default, events.Default, expense.Default))
+
+// Register adds a vehicle to the fleet
+func (s *Service) Register(v Vehicle) (Vehicle, error) {
+	v.Number = strings.TrimSpace(v.Number)
+	if key(v.Number) == "" {
+		return Vehicle{}, ErrInvalidNumber
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.vehicles[key(v.Number)]; ok {
+		return Vehicle{}, ErrAlreadyRegistered
+	}
+	if v.RegisteredAt.IsZero() {
+		v.RegisteredAt = now()
+	}
+	if v.OdometerAt.IsZero() {
+		v.OdometerAt = v.RegisteredAt
+	}
+	s.vehicles[key(v.Number)] = &v
+	return v, nil
+}
+
+func (s *Service) Get(number string) (Vehicle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.vehicles[key(number)]
+	if !ok {
+		return Vehicle{}, ErrNotFound
+	}
+	return *v, nil
+}
+
+// ForDriver returns the vehicle the driver drives
+func (s *Service) ForDriver(driverID string) (Vehicle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.vehicles {
+		if v.DriverID == driverID {
+			return *v, nil
+		}
+	}
+	return Vehicle{}, ErrNoVehicle
+}
+
+// Idle lists the vehicles of a type whose drivers are not on a trip
+func (s *Service) Idle(vehicleType string) []Vehicle {
+	s.mu.Lock()
+	var fleet []Vehicle
+	for _, v := range s.vehicles {
+		if v.DriverID != "" && strings.EqualFold(strings.TrimSpace(v.Type), strings.TrimSpace(vehicleType)) {
+			fleet = append(fleet, *v)
+		}
+	}
+	s.mu.Unlock()
+
+	var out []Vehicle
+	for _, v := range fleet {
+		if _, err := s.Trips.ActiveForDriver(v.DriverID); err != nil {
+			out = append(out, v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// ForBroker lists the account's fleet by registration number
+func (s *Service) ForBroker(brokerID string) []Vehicle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Vehicle
+	for _, v := range s.vehicles {
+		if v.BrokerID == brokerID {
+			out = append(out, *v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// UpdateOdometer records a reading off the dashboard
+func (s *Service) UpdateOdometer(number string, km float64) (Vehicle, error) {
+	if km <= 0 {
+		return Vehicle{}, ErrInvalidReading
+	}
+
+	s.mu.Lock()
+	v, ok := s.vehicles[key(number)]
+	if !ok {
+		s.mu.Unlock()
+		return Vehicle{}, ErrNotFound
+	}
+	if km < v.Odometer {
+		s.mu.Unlock()
+		return Vehicle{}, ErrOdometerBackwards
+	}
+	v.Odometer, v.OdometerAt = km, now()
+	out := *v
+	s.mu.Unlock()
+
+	s.remind(out)
+	return out, nil
+}
+
+// LogService records a garage visit; its items restart their schedule
+func (s *Service) LogService(number string, req ServiceRequest) (ServiceRecord, error) {
+	if len(req.Items) == 0 {
+		return ServiceRecord{}, ErrNoItems
+	}
+	for _, i := range req.Items {
+		if !i.Valid() {
+			return ServiceRecord{}, ErrUnknownItem
+		}
+	}
+	if req.Cost < 0 {
+		return ServiceRecord{}, ledger.ErrInvalidAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.vehicles[key(number)]
+	if !ok {
+		return ServiceRecord{}, ErrNotFound
+	}
+	odometer, err := s.reading(v, req.Odometer)
+	if err != nil {
+		return ServiceRecord{}, err
+	}
+	if req.At.IsZero() {
+		req.At = now()
+	}
+
+	s.seq++
+	r := ServiceRecord{
+		ID:       fmt.Sprintf("SRV%05d", s.seq),
+		Vehicle:  v.Number,
+		Items:    append([]Item(nil), req.Items...),
+		Odometer: odometer,
+		Cost:     req.Cost,
+		Garage:   strings.TrimSpace(req.Garage),
+		Note:     strings.TrimSpace(req.Note),
+		At:       req.At,
+	}
+	s.services = append(s.services, r)
+	return r, nil
+}
+
+// LogFuel records a fill-up and works out the efficiency since the last
+// full tank when this one is full too. A fill bought on a running trip is
+// also added to the trip's expenses, so it is paid back like any other.
+func (s *Service) LogFuel(number string, req FuelRequest) (FuelEntry, error) {
+	if req.Litres <= 0 {
+		return FuelEntry{}, ErrInvalidLitres
+	}
+	if req.Amount <= 0 {
+		return FuelEntry{}, ledger.ErrInvalidAmount
+	}
+	if req.Odometer < 0 {
+		return FuelEntry{}, ErrInvalidReading
+	}
+	if req.At.IsZero() {
+		req.At = now()
+	}
+
+	s.mu.Lock()
+	v, ok := s.vehicles[key(number)]
+	if !ok {
+		s.mu.Unlock()
+		return FuelEntry{}, ErrNotFound
+	}
+	if v.TankLitres > 0 && req.Litres > v.TankLitres {
+		s.mu.Unlock()
+		return FuelEntry{}, ErrOverCapacity
+	}
+	driverID := v.DriverID
+	s.mu.Unlock()
+
+	// the expense checks the wallet and the trip, so it goes first and a
+	// fill it turns down is not logged either
+	var expenseID string
+	if t, ok := s.tripFor(driverID, req.At); ok {
+		station := strings.TrimSpace(req.Station)
+		note := fmt.Sprintf("%.0f L", req.Litres)
+		if station != "" {
+			note += " at " + station
+		}
+		x, err := s.Expenses.Add(driverID, t.ID, expense.AddRequest{
+			Category: expense.CategoryFuel,
+			Amount:   req.Amount,
+			PaidFrom: req.PaidFrom,
+			Note:     note,
+			At:       req.At,
+		})
+		if err != nil {
+			return FuelEntry{}, err
+		}
+		expenseID = x.ID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// vehicles are never removed and the reading was checked above
+	v = s.vehicles[key(number)]
+	odometer, _ := s.reading(v, req.Odometer)
+
+	s.seq++
+	f := FuelEntry{
+		ID:        fmt.Sprintf("FUEL%05d", s.seq),
+		Vehicle:   v.Number,
+		Odometer:  odometer,
+		Litres:    req.Litres,
+		Amount:    req.Amount,
+		Full:      req.Full,
+		Station:   strings.TrimSpace(req.Station),
+		ExpenseID: expenseID,
+		At:        req.At,
+	}
+	if f.Full {
+		// litres since the last full tank, this fill included, burnt the km since
+		litres := f.Litres
+		for i := len(s.fuel) - 1; i >= 0; i-- {
+			prev := s.fuel[i]
+			if prev.Vehicle != v.Number {
+				continue
+			}
+			if prev.Full {
+				if km := f.Odometer - prev.Odometer; km > 0 {
+					f.Efficiency = math.Round(100*km/litres) / 100
+				}
+				break
+			}
+			litres += prev.Litres
+		}
+	}
+	s.fuel = append(s.fuel, f)
+	return f, nil
+}
+
+// Schedule returns where the vehicle stands on every maintenance item
+func (s *Service) Schedule(number string) ([]Due, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.vehicles[key(number)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.schedule(*v), nil
+}
+
+// History returns the vehicle's garage visits, newest first
+func (s *Service) History(number string) []ServiceRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []ServiceRecord
+	for i := len(s.services) - 1; i >= 0; i-- {
+		if r := s.services[i]; key(r.Vehicle) == key(number) {
+			r.Items = append([]Item(nil), r.Items...)
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FuelLog returns the vehicle's fill-ups, newest first
+func (s *Service) FuelLog(number string) []FuelEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []FuelEntry
+	for i := len(s.fuel) - 1; i >= 0; i-- {
+		if f := s.fuel[i]; key(f.Vehicle) == key(number) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Efficiency is the vehicle's km per litre over every full-to-full stretch
+func (s *Service) Efficiency(number string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.efficiency(number)
+}
+
+// FuelLevel estimates how full the tank is in percent, from the last full
+// tank, the fuel added since and the km driven at the average efficiency
+func (s *Service) FuelLevel(number string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.vehicles[key(number)]
+	kmpl, known := s.efficiency(number)
+	if !ok || !known || v.TankLitres <= 0 {
+		return 0, false
+	}
+
+	var litres, from float64
+	found := false
+	for i := len(s.fuel) - 1; i >= 0; i-- {
+		f := s.fuel[i]
+		if f.Vehicle != v.Number {
+			continue
+		}
+		if f.Full {
+			litres, from, found = litres+v.TankLitres, f.Odometer, true
+			break
+		}
+		litres += f.Litres
+	}
+	if !found {
+		return 0, false
+	}
+	litres -= (v.Odometer - from) / kmpl
+	return int(math.Round(100 * math.Max(0, math.Min(litres, v.TankLitres)) / v.TankLitres)), true
+}
+
+// Spend totals what the vehicle cost in service and fuel since a time
+func (s *Service) Spend(number string, since time.Time) (service, fuel ledger.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.services {
+		if key(r.Vehicle) == key(number) && !r.At.Before(since) {
+			service += r.Cost
+		}
+	}
+	for _, f := range s.fuel {
+		if key(f.Vehicle) == key(number) && !f.At.Before(since) {
+			fuel += f.Amount
+		}
+	}
+	return service, fuel
+}
+
+// Health sums up the schedule: Good, Service Due or Overdue
+func (s *Service) Health(number string) (Health, error) {
+	due, err := s.Schedule(number)
+	if err != nil {
+		return Health{}, err
+	}
+	h := Health{Label: "Good", Percent: 100}
+	for _, d := range due {
+		switch d.Status {
+		case DueSoon:
+			h.Due++
+			h.Percent -= 10
+			if h.Label == "Good" {
+				h.Label = "Service Due"
+			}
+		case DueOverdue:
+			h.Due++
+			h.Percent -= 25
+			h.Label = "Overdue"
+		}
+	}
+	if h.Percent < 10 {
+		h.Percent = 10
+	}
+	return h, nil
+}
+
+// Remind sends any reminders the vehicle is owed
+func (s *Service) Remind(number string) {
+	if v, err := s.Get(number); err == nil {
+		s.remind(v)
+	}
+}
+
+// Watch checks the whole fleet for reminders every so often; time-based
+// items fall due while the truck is parked, so no write would catch them
+func (s *Service) Watch(every time.Duration) {
+	time.AfterFunc(every, func() {
+		s.mu.Lock()
+		fleet := make([]Vehicle, 0, len(s.vehicles))
+		for _, v := range s.vehicles {
+			fleet = append(fleet, *v)
+		}
+		s.mu.Unlock()
+
+		for _, v := range fleet {
+			s.remind(v)
+		}
+		s.Watch(every)
+	})
+}
+
+// tripFor finds the running trip a fill-up was bought on. Fills from
+// before the trip started, such as ones logged late, are left off it.
+func (s *Service) tripFor(driverID string, at time.Time) (trip.Trip, bool) {
+	if driverID == "" {
+		return trip.Trip{}, false
+	}
+	t, err := s.Trips.ActiveForDriver(driverID)
+	if err != nil || t.Status == trip.StatusAssigned || at.Before(t.CreatedAt) {
+		return trip.Trip{}, false
+	}
+	return t, true
+}
+
+// drove advances the odometer of the trip's truck by the km it covered:
+// the GPS track, or the road distance when the track has gaps
+func (s *Service) drove(t trip.Trip) {
+	km := 0.0
+	track := s.Tracks.Track(t.ID)
+	for i := 1; i < len(track); i++ {
+		km += geo.Haversine(track[i-1].Coord(), track[i].Coord())
+	}
+	if planned, err := s.Routes.Provider.Distance(t.Pickup, t.Drop); err == nil && planned > km {
+		km = planned
+	}
+	if km <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	v, ok := s.vehicles[key(t.Details["vehicleNumber"])]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	v.Odometer = math.Round(v.Odometer + km)
+	v.OdometerAt = now()
+	out := *v
+	s.mu.Unlock()
+
+	s.remind(out)
+}
+
+// remind tells the driver once per item as it becomes due soon and again
+// when it is overdue; a service starts the item's next window, so asking
+// again within the same window sends nothing
+func (s *Service) remind(v Vehicle) {
+	if v.DriverID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	var due []events.Event
+	for _, d := range s.schedule(v) {
+		if d.Status == DueOK {
+			continue
+		}
+		k := reminderKey(v.Number, d, d.Status)
+		if s.reminded[k] {
+			continue
+		}
+		s.reminded[k] = true
+
+		title := d.Label + " due " + dueIn(d)
+		if d.Status == DueOverdue {
+			title = d.Label + " overdue"
+		}
+		due = append(due, events.Event{
+			Type:     events.VehicleServiceDue,
+			Subject:  v.Number,
+			DriverID: v.DriverID,
+			BrokerID: v.BrokerID,
+			Data: map[string]string{
+				"item":     string(d.Item),
+				"status":   string(d.Status),
+				"odometer": bff.Km(v.Odometer),
+				"title":    title,
+				"message":  fmt.Sprintf("%s is at %s. Book a service to stay on schedule.", v.Number, bff.Km(v.Odometer)),
+			},
+		})
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		s.Events.Publish(e)
+	}
+}
+
+func (s *Service) schedule(v Vehicle) []Due {
+	out := make([]Due, 0, len(Schedule))
+	for _, iv := range Schedule {
+		d := Due{Item: iv.Item, Label: iv.Item.Label(), Status: DueOK, LastAt: v.RegisteredAt}
+		for i := len(s.services) - 1; i >= 0; i-- {
+			r := s.services[i]
+			if r.Vehicle == v.Number && r.has(iv.Item) {
+				d.LastKm, d.LastAt = r.Odometer, r.At
+				break
+			}
+		}
+
+		if iv.Km > 0 {
+			d.NextKm = d.LastKm + iv.Km
+			d.KmLeft = math.Round(d.NextKm - v.Odometer)
+			switch {
+			case d.KmLeft <= 0:
+				d.Status = DueOverdue
+			case d.KmLeft <= soonKm:
+				d.Status = DueSoon
+			}
+		}
+		if iv.Every > 0 {
+			d.NextAt = d.LastAt.Add(iv.Every)
+			left := d.NextAt.Sub(now())
+			d.DaysLeft = int(math.Floor(left.Hours() / 24))
+			switch {
+			case left <= 0:
+				d.Status = DueOverdue
+			case left <= soonTime && d.Status == DueOK:
+				d.Status = DueSoon
+			}
+		}
+		out = append(out, d)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return rank(out[i].Status) > rank(out[j].Status) })
+	return out
+}
+
+func (s *Service) efficiency(number string) (float64, bool) {
+	var km, litres float64
+	var last *FuelEntry
+	var since float64
+	for i := range s.fuel {
+		f := &s.fuel[i]
+		if key(f.Vehicle) != key(number) {
+			continue
+		}
+		if last != nil {
+			since += f.Litres
+			if f.Full {
+				km += f.Odometer - last.Odometer
+				litres += since
+			}
+		}
+		if f.Full {
+			last, since = f, 0
+		}
+	}
+	if km <= 0 || litres <= 0 {
+		return 0, false
+	}
+	return math.Round(100*km/litres) / 100, true
+}
+
+// reading checks a reading given with a log entry, which also moves the
+// odometer on; zero takes the current odometer
+func (s *Service) reading(v *Vehicle, km float64) (float64, error) {
+	switch {
+	case km == 0:
+		return v.Odometer, nil
+	case km < 0:
+		return 0, ErrInvalidReading
+	}
+	if km > v.Odometer {
+		v.Odometer, v.OdometerAt = km, now()
+	}
+	return km, nil
+}
+
+func (r ServiceRecord) has(i Item) bool {
+	for _, x := range r.Items {
+		if x == i {
+			return true
+		}
+	}
+	return false
+}
+
+func rank(d DueStatus) int {
+	switch d {
+	case DueOverdue:
+		return 2
+	case DueSoon:
+		return 1
+	}
+	return 0
+}
+
+func dueIn(d Due) string {
+	if d.NextKm > 0 && d.KmLeft <= soonKm {
+		return "in " + bff.Km(d.KmLeft)
+	}
+	if d.DaysLeft == 1 {
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", d.DaysLeft)
+}
+
+// reminderKey names a reminder by the service window it falls in, which
+// starts at the item's last service
+func reminderKey(number string, d Due, level DueStatus) string {
+	return fmt.Sprintf("%s|%s|%s|%.0f|%d", key(number), d.Item, level, d.LastKm, d.LastAt.Unix())
+}
+
+// key normalises a registration number: "MH 01 AB 1234" is MH01AB1234
+func key(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
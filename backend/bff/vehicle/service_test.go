@@ -0,0 +1,288 @@
+package vehicle
+
+import (
+	"backend/bff/docstore"
+	"backend/bff/events"
+	"backend/bff/expense"
+	"backend/bff/geo"
+	"backend/bff/ledger"
+	"backend/bff/routing"
+	"backend/bff/tracking"
+	"backend/bff/trip"
+	"errors"
+	"testing"
+	"time"
+)
+
+var at = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() *Service {
+	trips := trip.NewStore()
+	tracks := tracking.NewStore()
+	routes := &routing.Engine{Provider: routing.GreatCircle{RoadFactor: 1}, Tracks: tracks}
+	expenses := expense.NewService(trips, ledger.New(), docstore.NewMemory(), tracks)
+	return NewService(trips, routes, tracks, events.NewBus(), expenses)
+}
+
+func TestRegister(t *testing.T) {
+	s := newTestService()
+	if _, err := s.Register(Vehicle{Number: " MH 01 AB 1234 ", DriverID: "DRV1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		number  string
+		wantErr error
+	}{
+		{name: "same number written differently", number: "mh01ab1234", wantErr: ErrAlreadyRegistered},
+		{name: "no number", number: "  ", wantErr: ErrInvalidNumber},
+		{name: "another truck", number: "MH 01 AB 9999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Register(Vehicle{Number: tt.number}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if v, err := s.ForDriver("DRV1"); err != nil || v.Number != "MH 01 AB 1234" {
+		t.Errorf("driver's vehicle = %q, %v", v.Number, err)
+	}
+}
+
+func TestUpdateOdometer(t *testing.T) {
+	s := newTestService()
+	s.Register(Vehicle{Number: "MH01AB1234", Odometer: 5000})
+
+	tests := []struct {
+		name    string
+		km      float64
+		wantErr error
+		wantKm  float64
+	}{
+		{name: "forward", km: 5200, wantKm: 5200},
+		{name: "backwards", km: 5100, wantErr: ErrOdometerBackwards, wantKm: 5200},
+		{name: "not a reading", km: -1, wantErr: ErrInvalidReading, wantKm: 5200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.UpdateOdometer("MH01AB1234", tt.km); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if v, _ := s.Get("MH01AB1234"); v.Odometer != tt.wantKm {
+				t.Errorf("odometer = %.0f, want %.0f", v.Odometer, tt.wantKm)
+			}
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	day := 24 * time.Hour
+	tests := []struct {
+		name          string
+		registeredAgo time.Duration
+		odometer      float64
+		serviced      []ServiceRequest
+		want          map[Item]DueStatus
+		wantHealth    string
+	}{
+		{
+			name:       "new truck",
+			want:       map[Item]DueStatus{ItemOilChange: DueOK, ItemBatteryCheck: DueOK},
+			wantHealth: "Good",
+		},
+		{
+			name:       "oil due within 1,000 km",
+			odometer:   9500,
+			want:       map[Item]DueStatus{ItemOilChange: DueSoon},
+			wantHealth: "Service Due",
+		},
+		{
+			name:       "oil overdue at 10,000 km",
+			odometer:   10000,
+			want:       map[Item]DueStatus{ItemOilChange: DueOverdue},
+			wantHealth: "Overdue",
+		},
+		{
+			name:       "a service restarts the window",
+			odometer:   9500,
+			serviced:   []ServiceRequest{{Items: []Item{ItemOilChange}, Odometer: 9000, At: at}},
+			want:       map[Item]DueStatus{ItemOilChange: DueOK},
+			wantHealth: "Good",
+		},
+		{
+			name:          "oil due soon by date",
+			registeredAgo: 170 * day,
+			want:          map[Item]DueStatus{ItemOilChange: DueSoon, ItemBatteryCheck: DueOverdue},
+			wantHealth:    "Overdue",
+		},
+		{
+			name:          "battery checked recently",
+			registeredAgo: 100 * day,
+			serviced:      []ServiceRequest{{Items: []Item{ItemBatteryCheck}, At: at.Add(-10 * day)}},
+			want:          map[Item]DueStatus{ItemOilChange: DueOK, ItemBatteryCheck: DueOK},
+			wantHealth:    "Good",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			s.Register(Vehicle{Number: "MH01AB1234", Odometer: tt.odometer, RegisteredAt: at.Add(-tt.registeredAgo)})
+			for _, req := range tt.serviced {
+				if _, err := s.LogService("MH01AB1234", req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			due, err := s.Schedule("MH01AB1234")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, d := range due {
+				if want, ok := tt.want[d.Item]; ok && d.Status != want {
+					t.Errorf("%s is %s, want %s", d.Item, d.Status, want)
+				}
+				// what needs doing comes first
+				if i > 0 && rank(d.Status) > rank(due[i-1].Status) {
+					t.Errorf("%s (%s) listed after %s (%s)", d.Item, d.Status, due[i-1].Item, due[i-1].Status)
+				}
+			}
+			if h, _ := s.Health("MH01AB1234"); h.Label != tt.wantHealth {
+				t.Errorf("health = %q, want %q", h.Label, tt.wantHealth)
+			}
+		})
+	}
+}
+
+func TestRemind(t *testing.T) {
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	s.Register(Vehicle{Number: "MH01AB1234", DriverID: "DRV1", RegisteredAt: at})
+	var got []string
+	s.Events.Subscribe(func(e events.Event) {
+		if e.Type == events.VehicleServiceDue && e.Data["item"] == string(ItemOilChange) {
+			got = append(got, e.Data["status"])
+		}
+	})
+
+	steps := []struct {
+		name    string
+		odo     float64
+		service bool
+		want    []string
+	}{
+		{name: "falls due", odo: 9500, want: []string{"due_soon"}},
+		{name: "still due", odo: 9600},
+		{name: "overdue", odo: 10000, want: []string{"overdue"}},
+		{name: "still overdue", odo: 10100},
+		{name: "serviced", service: true},
+		{name: "due again in the next window", odo: 19500, want: []string{"due_soon"}},
+	}
+	for _, st := range steps {
+		got = nil
+		if st.service {
+			if _, err := s.LogService("MH01AB1234", ServiceRequest{Items: []Item{ItemOilChange}}); err != nil {
+				t.Fatal(err)
+			}
+			s.Remind("MH01AB1234")
+		} else if _, err := s.UpdateOdometer("MH01AB1234", st.odo); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(st.want) || (len(got) > 0 && got[0] != st.want[0]) {
+			t.Errorf("%s: reminders %v, want %v", st.name, got, st.want)
+		}
+	}
+}
+
+func TestLogFuel(t *testing.T) {
+	s := newTestService()
+	s.Register(Vehicle{Number: "MH01AB1234", TankLitres: 400})
+
+	fills := []struct {
+		req            FuelRequest
+		wantErr        error
+		wantEfficiency float64
+	}{
+		{req: FuelRequest{Odometer: 1000, Litres: 100, Amount: ledger.Rupees(9000), Full: true}},
+		{req: FuelRequest{Odometer: 1200, Litres: 20, Amount: ledger.Rupees(1800)}},
+		// 500 km on the 20 and 30 litres put in since the last full tank
+		{req: FuelRequest{Odometer: 1500, Litres: 30, Amount: ledger.Rupees(2700), Full: true}, wantEfficiency: 10},
+		{req: FuelRequest{Litres: 401, Amount: ledger.Rupees(36000)}, wantErr: ErrOverCapacity},
+		{req: FuelRequest{Litres: 10}, wantErr: ledger.ErrInvalidAmount},
+	}
+	for i, f := range fills {
+		got, err := s.LogFuel("MH01AB1234", f.req)
+		if !errors.Is(err, f.wantErr) {
+			t.Fatalf("fill %d: error = %v, want %v", i, err, f.wantErr)
+		}
+		if got.Efficiency != f.wantEfficiency {
+			t.Errorf("fill %d: efficiency = %.2f, want %.2f", i, got.Efficiency, f.wantEfficiency)
+		}
+	}
+	if kmpl, ok := s.Efficiency("MH01AB1234"); !ok || kmpl != 10 {
+		t.Errorf("efficiency = %.2f, %v; want 10", kmpl, ok)
+	}
+	if len(s.FuelLog("MH01AB1234")) != 3 {
+		t.Errorf("fuel log = %+v, want the three fills", s.FuelLog("MH01AB1234"))
+	}
+
+	// 1,000 km at 10 km/l burns a quarter of the tank
+	s.UpdateOdometer("MH01AB1234", 2500)
+	if level, ok := s.FuelLevel("MH01AB1234"); !ok || level != 75 {
+		t.Errorf("fuel level = %d%%, %v; want 75%%", level, ok)
+	}
+}
+
+func TestLogFuelOnATrip(t *testing.T) {
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := newTestService()
+	s.Register(Vehicle{Number: "MH01AB1234", DriverID: "DRV1"})
+	s.Trips.Add(trip.Trip{ID: "TRK1", DriverID: "DRV1", BrokerID: "BRK1", Status: trip.StatusInTransit, CreatedAt: at.Add(-time.Hour)})
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		wantExpense bool
+	}{
+		{name: "bought on the trip", at: at, wantExpense: true},
+		{name: "bought before the trip", at: at.Add(-2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := s.LogFuel("MH01AB1234", FuelRequest{Litres: 50, Amount: ledger.Rupees(4500), At: tt.at})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (f.ExpenseID != "") != tt.wantExpense {
+				t.Errorf("expense = %q, want one: %v", f.ExpenseID, tt.wantExpense)
+			}
+		})
+	}
+	if xs := s.Expenses.ForTrip("TRK1"); len(xs) != 1 || xs[0].Category != expense.CategoryFuel {
+		t.Errorf("trip expenses = %+v, want the one fill", xs)
+	}
+}
+
+func TestDeliveryAdvancesOdometer(t *testing.T) {
+	s := newTestService()
+	s.Register(Vehicle{Number: "MH01AB1234", Odometer: 5000})
+	pickup, drop := geo.Coord{Lat: 19, Lng: 73}, geo.Coord{Lat: 20, Lng: 73}
+	s.Trips.Add(trip.Trip{ID: "TRK1", Status: trip.StatusReachedDrop, Pickup: pickup, Drop: drop, Details: map[string]string{"vehicleNumber": "MH 01 AB 1234"}})
+
+	if _, err := s.Trips.Transition("TRK1", trip.StatusDelivered, "DRV1", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := 5000 + float64(int(geo.Haversine(pickup, drop)+0.5))
+	if v, _ := s.Get("MH01AB1234"); v.Odometer != want {
+		t.Errorf("odometer = %.0f, want %.0f", v.Odometer, want)
+	}
+}
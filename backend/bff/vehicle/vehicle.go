@@ -0,0 +1,169 @@
+package vehicle
+
+import (
+	"backend/bff/ledger"
+	"errors"
+	"time"
+)
+
+var now = time.Now
+
+var (
+	ErrNotFound          = errors.New("vehicle not found")
+	ErrInvalidNumber     = errors.New("registration number is required")
+	ErrAlreadyRegistered = errors.New("a vehicle with this number is already registered")
+	ErrNoVehicle         = errors.New("no vehicle is registered to this driver")
+	ErrOdometerBackwards = errors.New("odometer reading is lower than the last one")
+	ErrInvalidReading    = errors.New("odometer reading must be a positive number of km")
+	ErrInvalidLitres     = errors.New("litres must be more than zero")
+	ErrOverCapacity      = errors.New("litres are more than the tank holds")
+	ErrUnknownItem       = errors.New("unknown service item")
+	ErrNoItems           = errors.New("choose at least one service item")
+)
+
+// Item is a piece of routine maintenance
+type Item string
+
+const (
+	ItemOilChange    Item = "oil_change"
+	ItemAirFilter    Item = "air_filter"
+	ItemBrakes       Item = "brakes"
+	ItemTyreRotation Item = "tyre_rotation"
+	ItemCoolant      Item = "coolant"
+	ItemBatteryCheck Item = "battery_check"
+)
+
+// Interval is how often an item is due, by distance and by time, whichever
+// comes first; zero means the item has no limit of that kind
+type Interval struct {
+	Item  Item          `json:"item"`
+	Km    float64       `json:"km,omitempty"`
+	Every time.Duration `json:"every,omitempty"`
+}
+
+// Schedule is the maintenance plan for a loaded truck
+var Schedule = []Interval{
+	{ItemOilChange, 10000, 180 * 24 * time.Hour},
+	{ItemAirFilter, 30000, 365 * 24 * time.Hour},
+	{ItemBrakes, 20000, 180 * 24 * time.Hour},
+	{ItemTyreRotation, 15000, 0},
+	{ItemCoolant, 40000, 2 * 365 * 24 * time.Hour},
+	{ItemBatteryCheck, 0, 90 * 24 * time.Hour},
+}
+
+func (i Item) Valid() bool {
+	for _, s := range Schedule {
+		if s.Item == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (i Item) Label() string {
+	switch i {
+	case ItemOilChange:
+		return "Engine Oil Change"
+	case ItemAirFilter:
+		return "Air Filter"
+	case ItemBrakes:
+		return "Brake Inspection"
+	case ItemTyreRotation:
+		return "Tyre Rotation"
+	case ItemCoolant:
+		return "Coolant Flush"
+	case ItemBatteryCheck:
+		return "Battery Check"
+	}
+	return string(i)
+}
+
+// Reminder windows before an item falls due
+const (
+	soonKm   = 1000
+	soonTime = 15 * 24 * time.Hour
+)
+
+type DueStatus string
+
+const (
+	DueOK      DueStatus = "ok"
+	DueSoon    DueStatus = "due_soon"
+	DueOverdue DueStatus = "overdue"
+)
+
+// Due is where a vehicle stands on one schedule item
+type Due struct {
+	Item   Item      `json:"item"`
+	Label  string    `json:"label"`
+	Status DueStatus `json:"status"`
+	LastKm float64   `json:"lastKm"`
+	LastAt time.Time `json:"lastAt"`
+	// NextKm and NextAt are zero when the item has no limit of that kind
+	NextKm float64   `json:"nextKm,omitempty"`
+	NextAt time.Time `json:"nextAt,omitempty"`
+	// KmLeft and DaysLeft go negative once overdue
+	KmLeft   float64 `json:"kmLeft,omitempty"`
+	DaysLeft int     `json:"daysLeft,omitempty"`
+}
+
+// Vehicle is a truck and the running state of its odometer and tank. It
+// belongs to a broker account's fleet and is driven by one driver.
+type Vehicle struct {
+	Number     string  `json:"number"`
+	BrokerID   string  `json:"brokerId,omitempty"`
+	DriverID   string  `json:"driverId,omitempty"`
+	DriverName string  `json:"driverName,omitempty"`
+	Model      string  `json:"model"`
+	Type       string  `json:"type"`
+	Year       int     `json:"year,omitempty"`
+	Capacity   float64 `json:"capacity,omitempty"` // tonnes
+	// Odometer in km, advanced by delivered trips and corrected by readings
+	Odometer     float64   `json:"odometer"`
+	OdometerAt   time.Time `json:"odometerAt"`
+	TankLitres   float64   `json:"tankLitres"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// ServiceRecord is one visit to the garage
+type ServiceRecord struct {
+	ID       string        `json:"id"`
+	Vehicle  string        `json:"vehicle"`
+	Items    []Item        `json:"items"`
+	Odometer float64       `json:"odometer"`
+	Cost     ledger.Amount `json:"cost"`
+	Garage   string        `json:"garage,omitempty"`
+	Note     string        `json:"note,omitempty"`
+	At       time.Time     `json:"at"`
+}
+
+// FuelEntry is one fill-up. Efficiency is worked out full tank to full
+// tank, so it is only set on full fills that follow another full fill.
+type FuelEntry struct {
+	ID         string        `json:"id"`
+	Vehicle    string        `json:"vehicle"`
+	Odometer   float64       `json:"odometer"`
+	Litres     float64       `json:"litres"`
+	Amount     ledger.Amount `json:"amount"`
+	Full       bool          `json:"full"`
+	Station    string        `json:"station,omitempty"`
+	Efficiency float64       `json:"efficiency,omitempty"` // km per litre
+	// ExpenseID is the trip expense the fill was added to, if any
+	ExpenseID string    `json:"expenseId,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// PerLitre is the price paid per litre
+func (f FuelEntry) PerLitre() ledger.Amount {
+	if f.Litres <= 0 {
+		return 0
+	}
+	return ledger.Amount(float64(f.Amount) / f.Litres)
+}
+
+// Health sums up a vehicle's maintenance for the apps
+type Health struct {
+	Label   string `json:"label"`   // Good, Service Due or Overdue
+	Percent int    `json:"percent"` // for progress bars
+	Due     int    `json:"due"`     // items due soon or overdue
+}
@@ -59,15 +59,37 @@ package main
 import (
 	"log"
 	"backend/bff"
+	"backend/bff/audit"
 	"backend/bff/auth"
 	"backend/bff/driver"
 	"backend/bff/broker"
+	"backend/bff/calls"
+	"backend/bff/chat"
+	"backend/bff/consignment"
+	"backend/bff/delivery"
+	"backend/bff/docstore"
+	"backend/bff/idempotency"
+	"backend/bff/notify"
+	"backend/bff/payments"
+	"backend/bff/rating"
+	"backend/bff/ratelimit"
+	"backend/bff/safety"
+	"backend/bff/support"
+	"backend/bff/vehicle"
 	"github.com/gin-gonic/gin"
+	"os"
+	"time"
 )
 
 func main() {
 	// Create a Gin router
 	r := gin.Default()
+	// The server faces clients directly, so X-Forwarded-For is whatever the
+	// client wrote; trust none of it and take the connection's address.
+	// Behind a load balancer, list its addresses here instead.
+	if err := r.SetTrustedProxies(nil); err != nil {
+		log.Fatal(err)
+	}
 
 	// Optional: allow CORS for all routes
 	r.Use(func(c *gin.Context) {
@@ -82,6 +104,14 @@ func main() {
 
 	// BFF routes grouped by feature
 	bffGroup := r.Group("/bff")
+	// Actions are rate limited first, apart from provider callbacks; retried
+	// POSTs then replay their first response instead of running again, so
+	// only the first is audited; location pings are telemetry
+	bffGroup.Use(
+		ratelimit.Middleware(ratelimit.Actions, "/bff/payments/webhook", "/bff/calls/connect"),
+		idempotency.Middleware(idempotency.Default),
+		audit.Middleware(audit.Default, "/bff/driver/location"),
+	)
 	{
 		// Splash
 		bffGroup.GET("/splash", bff.SplashScreenHandler)
@@ -115,10 +145,60 @@ func main() {
 			driverGroup.GET("/tripCompleted", driver.TripCompletedScreen)
 			driverGroup.GET("/profile", driver.ProfileScreen)
 			driverGroup.GET("/payment", driver.PaymentScreen)
-			driverGroup.GET("/market", driver.MarketScreen)
+			driverGroup.GET("/market", ratelimit.Middleware(ratelimit.Search), driver.MarketScreen)
 			driverGroup.GET("/home", driver.HomeScreen)
 			driverGroup.POST("/home/action", driver.HandleHomeAction) // POST if it's an action
+			driverGroup.POST("/location", driver.LocationIngest)
 			driverGroup.GET("/mytrip", driver.MyTripScreen)
+			driverGroup.GET("/analytics", driver.AnalyticsScreen)
+			driverGroup.GET("/notifications", driver.NotificationsScreen)
+			driverGroup.GET("/chat", driver.ChatScreen)
+			driverGroup.GET("/vehicle", driver.VehicleScreen)
+		}
+
+		// Generated and uploaded documents
+		bffGroup.GET("/documents/:id", docstore.Download)
+		bffGroup.GET("/lr/share/:token", consignment.Share)
+		bffGroup.GET("/notifications/badge", notify.Badge)
+		bffGroup.POST("/notifications/action", notify.HandleAction)
+		bffGroup.GET("/notifications/settings", delivery.Settings)
+		bffGroup.POST("/notifications/settings/action", delivery.HandleAction)
+
+		// Trip chat between driver and broker; the socket has an HTTP fallback
+		chatGroup := bffGroup.Group("/chat")
+		{
+			chatGroup.GET("/unread", chat.Unread)
+			chatGroup.GET("/:tripId/ws", chat.Socket)
+			chatGroup.GET("/:tripId/messages", chat.History)
+			chatGroup.POST("/:tripId/action", chat.HandleAction)
+		}
+
+		// Masked calling: contacts for the apps, routing for the call provider
+		bffGroup.GET("/calls/contacts", calls.Contacts)
+		bffGroup.POST("/calls/connect", calls.Connect)
+
+		// Two-way trip ratings and abuse reports
+		bffGroup.GET("/ratings", rating.Summary)
+		bffGroup.POST("/ratings/action", rating.HandleAction)
+
+		// Driving behaviour scored from the location stream
+		bffGroup.GET("/safety/:tripId", safety.TripReport)
+
+		// Support tools, for staff holding the support token
+		supportGroup := bffGroup.Group("/support", support.Middleware(os.Getenv("SUPPORT_TOKEN")))
+		{
+			supportGroup.GET("/audit", ratelimit.Middleware(ratelimit.Search), audit.Query)
+			supportGroup.GET("/audit/verify", audit.Verify)
+			supportGroup.GET("/audit/entry/:id", audit.Get)
+			supportGroup.GET("/reviews/reports", rating.Reports)
+			supportGroup.POST("/reviews/reports/action", rating.Moderate)
+		}
+
+		// Payment provider routes
+		paymentsGroup := bffGroup.Group("/payments")
+		{
+			paymentsGroup.POST("/webhook", payments.Webhook)
+			paymentsGroup.GET("/transfer/:reference", payments.TransferStatus)
 		}
 
 		// Broker routes
@@ -128,12 +208,36 @@ func main() {
 			brokerGroup.GET("/addtruck", broker.AddTruckScreen)
 			brokerGroup.GET("/profile", broker.ProfileScreen)
 			brokerGroup.GET("/money", broker.MoneyScreen)
-			brokerGroup.GET("/load", broker.LoadScreen)
+			brokerGroup.GET("/payment-detail", broker.PaymentDetailScreen)
+			brokerGroup.POST("/money/action", broker.HandleMoneyAction)
+			brokerGroup.GET("/load", ratelimit.Middleware(ratelimit.Search), broker.LoadScreen)
+			brokerGroup.GET("/load-detail", broker.LoadDetailScreen)
 			brokerGroup.GET("/home", broker.HomeScreen)
 			brokerGroup.GET("/livetrip", broker.LiveTripScreen)
+			brokerGroup.GET("/map/:id", broker.MapScreen)
+			brokerGroup.POST("/lr/action", broker.HandleLRAction)
+			brokerGroup.POST("/ewaybill/action", broker.HandleEwayBillAction)
+			brokerGroup.GET("/notifications", broker.NotificationsScreen)
+			brokerGroup.GET("/chat", broker.ChatScreen)
+			brokerGroup.POST("/load/action", broker.HandleLoadAction)
+			brokerGroup.POST("/truck/action", broker.HandleTruckAction)
+			brokerGroup.GET("/team", broker.TeamScreen)
+			// invitations are sent by SMS, so they share the OTP send budget
+			brokerGroup.POST("/team/action", ratelimit.Middleware(ratelimit.OTPSend.Only("invite")), broker.HandleTeamAction)
 		}
 	}
 
+	// Provider callbacks hand out real numbers and move money, so they are
+	// only trusted when signed with a secret kept out of the source
+	calls.Default.Provider = calls.NewStub(os.Getenv("CALLS_CALLBACK_SECRET"))
+	payments.Default = payments.NewDefault(os.Getenv("PAYMENTS_WEBHOOK_SECRET"))
+
+	// Service reminders for trucks standing idle
+	vehicle.Default.Watch(time.Hour)
+
+	// Push and SMS delivery, including retries and messages held over quiet hours
+	delivery.Default.Watch(5 * time.Second)
+
 	// Start server
 	log.Println("BFF server running on http://localhost:8080")
 	if err := r.Run(":8080"); err != nil {